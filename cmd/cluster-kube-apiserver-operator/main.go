@@ -17,12 +17,16 @@ import (
 	"github.com/openshift/library-go/pkg/operator/staticpod/prune"
 	"github.com/openshift/library-go/pkg/operator/staticpod/startupmonitor"
 
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/certinventory"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/certregenerationcontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/certrotation"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/checkendpoints"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/explainconfig"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/insecurereadyz"
 	operatorcmd "github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/operator"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/render"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/resourcegraph"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/simulate"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/encryptionstatusprovider"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/startupmonitorreadiness"
@@ -59,6 +63,10 @@ func NewOperatorCommand(ctx context.Context) *cobra.Command {
 	cmd.AddCommand(resourcegraph.NewResourceChainCommand())
 	cmd.AddCommand(certsyncpod.NewCertSyncControllerCommand(operator.CertConfigMaps, operator.CertSecrets))
 	cmd.AddCommand(certregenerationcontroller.NewCertRegenerationControllerCommand(ctx))
+	cmd.AddCommand(certinventory.NewCertInventoryCommand())
+	cmd.AddCommand(explainconfig.NewExplainConfigCommand())
+	cmd.AddCommand(simulate.NewSimulateCommand())
+	cmd.AddCommand(certrotation.NewCertRotationCommand())
 	cmd.AddCommand(insecurereadyz.NewInsecureReadyzCommand())
 	cmd.AddCommand(checkendpoints.NewCheckEndpointsCommand())
 	cmd.AddCommand(startupmonitor.NewCommand(startupmonitorreadiness.New(), func(config *rest.Config) (operatorclientv1.KubeAPIServerInterface, error) {
@@ -0,0 +1,77 @@
+// kube-apiserver-flags reads the help of the kube-apiserver on stdin and
+// prints the flags along with their type, as the Go source of the table the
+// TargetConfigController validates the apiServerArguments against. Run it on
+// a Kubernetes rebase with "make update-kube-apiserver-flags
+// KUBE_APISERVER=<kube-apiserver of the release>".
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"regexp"
+	"sort"
+)
+
+// flagLine matches the flags of the help, "--name type  usage" or
+// "--name  usage" for the bools.
+var flagLine = regexp.MustCompile(`^\s+(?:-\w, )?--([a-z0-9][a-z0-9-]*)(?: (\S+))?(?:\s{2,}|$)`)
+
+func main() {
+	version := flag.String("version", "", "the Kubernetes minor version of the kube-apiserver, e.g. 1.36")
+	flag.Parse()
+	if len(*version) == 0 {
+		fmt.Fprintln(os.Stderr, "-version is required")
+		os.Exit(1)
+	}
+
+	flagTypes := map[string]string{}
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		match := flagLine.FindStringSubmatch(scanner.Text())
+		if match == nil || match[1] == "help" || match[1] == "version" {
+			continue
+		}
+		flagTypes[match[1]] = match[2]
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if len(flagTypes) == 0 {
+		fmt.Fprintln(os.Stderr, "no flag found in the help on stdin")
+		os.Exit(1)
+	}
+
+	names := make([]string, 0, len(flagTypes))
+	for name := range flagTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := &bytes.Buffer{}
+	fmt.Fprintln(out, "// Code generated by hack/kube-apiserver-flags. DO NOT EDIT.")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "package targetconfigcontroller")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "// kubeAPIServerFlagsVersion is the Kubernetes minor version of the")
+	fmt.Fprintln(out, "// kube-apiserver kubeAPIServerFlagTypes were generated from.")
+	fmt.Fprintf(out, "const kubeAPIServerFlagsVersion = %q\n", *version)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "// kubeAPIServerFlagTypes are the flags of the kube-apiserver along with the")
+	fmt.Fprintln(out, "// type its help shows for them, empty for the bools.")
+	fmt.Fprintln(out, "var kubeAPIServerFlagTypes = map[string]string{")
+	for _, name := range names {
+		fmt.Fprintf(out, "\t%q: %q,\n", name, flagTypes[name])
+	}
+	fmt.Fprintln(out, "}")
+	source, err := format.Source(out.Bytes())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Stdout.Write(source)
+}
@@ -0,0 +1,275 @@
+package certinventory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/api/features"
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	configclientv1 "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
+	configinformers "github.com/openshift/client-go/config/informers/externalversions"
+	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/certrotationcontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// inventoryOpts holds values to drive the cert-inventory command.
+type inventoryOpts struct {
+	kubeconfig    string
+	mustGatherDir string
+	output        string
+	restConfig    *rest.Config
+	objectSource  objectSource
+	out           io.Writer
+	now           func() time.Time
+}
+
+// NewCertInventoryCommand creates a cert-inventory command.
+func NewCertInventoryCommand() *cobra.Command {
+	opts := inventoryOpts{
+		output: outputTable,
+		out:    os.Stdout,
+		now:    time.Now,
+	}
+	cmd := &cobra.Command{
+		Use:   "cert-inventory",
+		Short: "List every certificate the operator rotates, optionally with its state in a cluster or must-gather",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := opts.Validate(); err != nil {
+				klog.Fatal(err)
+			}
+			if err := opts.Complete(); err != nil {
+				klog.Fatal(err)
+			}
+			if err := opts.Run(cmd.Context()); err != nil {
+				klog.Fatal(err)
+			}
+		},
+	}
+
+	opts.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (o *inventoryOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.kubeconfig, "kubeconfig", o.kubeconfig, "Read the current certificates from the cluster of this kubeconfig")
+	fs.StringVar(&o.mustGatherDir, "must-gather-dir", o.mustGatherDir, "Read the current certificates from this must-gather directory, the one containing namespaces/")
+	fs.StringVarP(&o.output, "output", "o", o.output, "Output format, one of: table, json")
+}
+
+// Validate verifies the inputs.
+func (o *inventoryOpts) Validate() error {
+	if o.output != outputTable && o.output != outputJSON {
+		return fmt.Errorf("unsupported output format %q, must be one of: table, json", o.output)
+	}
+	if len(o.kubeconfig) > 0 && len(o.mustGatherDir) > 0 {
+		return fmt.Errorf("--kubeconfig and --must-gather-dir are mutually exclusive")
+	}
+	return nil
+}
+
+// Complete fills in missing values before command execution.
+func (o *inventoryOpts) Complete() error {
+	switch {
+	case len(o.kubeconfig) > 0:
+		restConfig, err := clientcmd.BuildConfigFromFlags("", o.kubeconfig)
+		if err != nil {
+			return err
+		}
+		kubeClient, err := kubernetes.NewForConfig(restConfig)
+		if err != nil {
+			return err
+		}
+		configClient, err := configclientv1.NewForConfig(restConfig)
+		if err != nil {
+			return err
+		}
+		o.objectSource = &clusterSource{kubeClient: kubeClient, configClient: configClient}
+	case len(o.mustGatherDir) > 0:
+		o.objectSource = &mustGatherSource{dir: o.mustGatherDir}
+	}
+
+	return nil
+}
+
+// Run contains the logic of the cert-inventory command.
+func (o *inventoryOpts) Run(ctx context.Context) error {
+	var featureGates featuregates.FeatureGate
+	var rotationProfile *certrotationcontroller.RotationProfile
+	if o.objectSource != nil {
+		var err error
+		featureGates, err = o.objectSource.getFeatureGates(ctx)
+		if err != nil {
+			return err
+		}
+		configMap, err := o.objectSource.getConfigMap(ctx, operatorconfig.Namespace, operatorconfig.Name)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		rotationProfile, err = certrotationcontroller.RotationProfileFromConfigMap(configMap)
+		if err != nil {
+			// The operator ignores invalid profiles as well.
+			klog.Warningf("Ignoring the certificate rotation profile: %v", err)
+		}
+	}
+
+	inventory, err := ManagedCertificates(featureGates, rotationProfile)
+	if err != nil {
+		return err
+	}
+
+	entries := make([]inventoryEntry, 0, len(inventory))
+	for _, cert := range inventory {
+		entry := newInventoryEntry(cert)
+		if o.objectSource != nil {
+			entry.Status = certificateStatusFor(ctx, o.objectSource, cert, o.now())
+		}
+		entries = append(entries, entry)
+	}
+
+	if o.output == outputJSON {
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(o.out, string(data))
+		return err
+	}
+	return writeTable(o.out, entries)
+}
+
+// ManagedCertificates builds the cert rotation controller the way the operator
+// does and returns what its rotators manage. Without feature gates, the
+// rotation periods are the defaults.
+func ManagedCertificates(featureGates featuregates.FeatureGate, rotationProfile *certrotationcontroller.RotationProfile) ([]certrotationcontroller.ManagedCertificate, error) {
+	// The rotators are only built to read their configuration, their clients
+	// are never used.
+	restConfig := &rest.Config{}
+	kubeClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	configClient, err := configclient.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if featureGates == nil {
+		featureGates = featuregates.NewFeatureGate(nil, []configv1.FeatureGateName{features.FeatureShortCertRotation, features.FeatureGateConfigurablePKI})
+	}
+	controller, err := certrotationcontroller.NewCertRotationController(
+		kubeClient,
+		v1helpers.NewFakeStaticPodOperatorClient(nil, nil, nil, nil),
+		configinformers.NewSharedInformerFactory(configClient, 0),
+		v1helpers.NewKubeInformersForNamespaces(
+			kubeClient,
+			operatorclient.GlobalMachineSpecifiedConfigNamespace,
+			operatorclient.GlobalUserSpecifiedConfigNamespace,
+			operatorclient.OperatorNamespace,
+			operatorclient.TargetNamespace,
+		),
+		events.NewInMemoryRecorder("cert-inventory", clock.RealClock{}),
+		featureGates,
+		rotationProfile,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return controller.Inventory(), nil
+}
+
+type inventoryEntry struct {
+	Kind            string   `json:"kind"`
+	Namespace       string   `json:"namespace"`
+	Name            string   `json:"name"`
+	Rotators        []string `json:"rotators"`
+	CertificateName string   `json:"certificateName,omitempty"`
+	Validity        string   `json:"validity,omitempty"`
+	Refresh         string   `json:"refresh,omitempty"`
+	JiraComponent   string   `json:"jiraComponent,omitempty"`
+	Description     string   `json:"description,omitempty"`
+
+	Status *certificateStatus `json:"status,omitempty"`
+}
+
+func newInventoryEntry(cert certrotationcontroller.ManagedCertificate) inventoryEntry {
+	entry := inventoryEntry{
+		Kind:            cert.Kind,
+		Namespace:       cert.Namespace,
+		Name:            cert.Name,
+		Rotators:        cert.Rotators,
+		CertificateName: cert.CertificateName,
+		JiraComponent:   cert.JiraComponent,
+		Description:     cert.Description,
+	}
+	// CA bundles only collect the signers, they have no lifetime of their own.
+	if cert.Kind != certrotationcontroller.ManagedCertificateKindCABundle {
+		entry.Validity = cert.Validity.String()
+		entry.Refresh = cert.Refresh.String()
+		if cert.RefreshOnlyWhenExpired {
+			entry.Refresh = "when expired"
+		}
+	}
+	return entry
+}
+
+func writeTable(out io.Writer, entries []inventoryEntry) error {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAMESPACE\tNAME\tVALIDITY\tREFRESH\tNOT AFTER\tNEXT REFRESH\tROTATORS")
+	for _, entry := range entries {
+		notAfter, nextRefresh := "-", "-"
+		if entry.Status != nil {
+			switch {
+			case entry.Status.Missing:
+				notAfter = "missing"
+			case len(entry.Status.Error) > 0:
+				notAfter = entry.Status.Error
+			case entry.Status.NotAfter != nil:
+				notAfter = entry.Status.NotAfter.Format(time.RFC3339)
+			}
+			if entry.Status.NextRefresh != nil {
+				nextRefresh = entry.Status.NextRefresh.Format(time.RFC3339)
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			entry.Kind, entry.Namespace, entry.Name,
+			valueOrDash(entry.Validity), valueOrDash(entry.Refresh),
+			notAfter, nextRefresh, strings.Join(entry.Rotators, ","),
+		)
+	}
+	return w.Flush()
+}
+
+func valueOrDash(s string) string {
+	if len(s) == 0 {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,211 @@
+package certinventory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/api/features"
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/certrotationcontroller"
+)
+
+func TestManagedCertificates(t *testing.T) {
+	inventory, err := ManagedCertificates(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var signer *certrotationcontroller.ManagedCertificate
+	seen := map[string]bool{}
+	for i, cert := range inventory {
+		key := cert.Kind + "/" + cert.Namespace + "/" + cert.Name
+		if seen[key] {
+			t.Errorf("%s is listed more than once", key)
+		}
+		seen[key] = true
+
+		if cert.Kind == certrotationcontroller.ManagedCertificateKindSigner && cert.Name == "loadbalancer-serving-signer" {
+			signer = &inventory[i]
+		}
+	}
+
+	if signer == nil {
+		t.Fatal("loadbalancer-serving-signer is missing")
+	}
+	if diff := cmp.Diff([]string{"ExternalLoadBalancerServing", "InternalLoadBalancerServing"}, signer.Rotators); diff != "" {
+		t.Errorf("unexpected rotators (-want +got):\n%s", diff)
+	}
+}
+
+func TestMustGatherStatus(t *testing.T) {
+	dir := t.TempDir()
+	writeList(t, dir, "openshift-kube-apiserver", "secrets.yaml", &corev1.SecretList{
+		Items: []corev1.Secret{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "kubelet-client",
+				Annotations: map[string]string{
+					certrotation.CertificateNotBeforeAnnotation: "2026-10-01T00:00:00Z",
+					certrotation.CertificateNotAfterAnnotation:  "2026-10-31T00:00:00Z",
+					certrotation.CertificateIssuer:              "openshift-kube-apiserver-operator_kube-apiserver-to-kubelet-signer@1",
+					"openshift.io/owning-component":             "kube-apiserver",
+				},
+			},
+		}},
+	})
+
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	source := &mustGatherSource{dir: dir}
+
+	status := certificateStatusFor(context.Background(), source, certrotationcontroller.ManagedCertificate{
+		Kind:      certrotationcontroller.ManagedCertificateKindTarget,
+		Namespace: "openshift-kube-apiserver",
+		Name:      "kubelet-client",
+		Validity:  30 * 24 * time.Hour,
+		Refresh:   15 * 24 * time.Hour,
+	}, now)
+
+	notBefore := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)
+	nextRefresh := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	expected := &certificateStatus{
+		NotBefore:     &notBefore,
+		NotAfter:      &notAfter,
+		Issuer:        "openshift-kube-apiserver-operator_kube-apiserver-to-kubelet-signer@1",
+		JiraComponent: "kube-apiserver",
+		NextRefresh:   &nextRefresh,
+	}
+	if diff := cmp.Diff(expected, status); diff != "" {
+		t.Errorf("unexpected status (-want +got):\n%s", diff)
+	}
+
+	missing := certificateStatusFor(context.Background(), source, certrotationcontroller.ManagedCertificate{
+		Kind:      certrotationcontroller.ManagedCertificateKindCABundle,
+		Namespace: "openshift-kube-apiserver-operator",
+		Name:      "kube-apiserver-to-kubelet-client-ca",
+	}, now)
+	if diff := cmp.Diff(&certificateStatus{Missing: true, NextRefresh: &now}, missing); diff != "" {
+		t.Errorf("unexpected status (-want +got):\n%s", diff)
+	}
+}
+
+func TestNextRefresh(t *testing.T) {
+	notBefore := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := notBefore.Add(100 * time.Hour)
+
+	for _, tt := range []struct {
+		name                   string
+		refresh                time.Duration
+		refreshOnlyWhenExpired bool
+		expected               time.Time
+	}{
+		{
+			name:     "refresh period first",
+			refresh:  50 * time.Hour,
+			expected: notBefore.Add(50 * time.Hour),
+		},
+		{
+			name:     "80% of validity first",
+			refresh:  90 * time.Hour,
+			expected: notBefore.Add(80 * time.Hour),
+		},
+		{
+			name:                   "only when expired",
+			refresh:                50 * time.Hour,
+			refreshOnlyWhenExpired: true,
+			expected:               notAfter,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextRefresh(notBefore, notAfter, tt.refresh, tt.refreshOnlyWhenExpired); !got.Equal(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMustGatherFeatureGates(t *testing.T) {
+	dir := t.TempDir()
+	source := &mustGatherSource{dir: dir}
+
+	featureGates, err := source.getFeatureGates(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if featureGates != nil {
+		t.Fatalf("expected no feature gates without featuregates/cluster.yaml, got %v", featureGates)
+	}
+
+	writeClusterScoped(t, dir, "config.openshift.io", "featuregates", "cluster", &configv1.FeatureGate{
+		Status: configv1.FeatureGateStatus{FeatureGates: []configv1.FeatureGateDetails{
+			{Version: "4.21.0", Disabled: []configv1.FeatureGateAttributes{{Name: features.FeatureShortCertRotation}}},
+			{
+				Version:  "4.22.0",
+				Enabled:  []configv1.FeatureGateAttributes{{Name: features.FeatureShortCertRotation}},
+				Disabled: []configv1.FeatureGateAttributes{{Name: features.FeatureGateConfigurablePKI}},
+			},
+		}},
+	})
+	writeClusterScoped(t, dir, "config.openshift.io", "clusterversions", "version", &configv1.ClusterVersion{
+		Status: configv1.ClusterVersionStatus{Desired: configv1.Release{Version: "4.22.0"}},
+	})
+	featureGates, err = source.getFeatureGates(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inventory, err := ManagedCertificates(featureGates, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, cert := range inventory {
+		if cert.Kind == certrotationcontroller.ManagedCertificateKindTarget && cert.Name == "kubelet-client" {
+			if cert.Validity != 2*time.Hour || cert.Refresh != time.Hour {
+				t.Errorf("expected the short rotation periods of the enabled feature gate, got validity %v, refresh %v", cert.Validity, cert.Refresh)
+			}
+			return
+		}
+	}
+	t.Fatal("kubelet-client is missing")
+}
+
+func writeClusterScoped(t *testing.T, dir, group, resource, name string, obj interface{}) {
+	t.Helper()
+
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "cluster-scoped-resources", group, resource)
+	if err := os.MkdirAll(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(path, name+".yaml"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeList(t *testing.T, dir, namespace, file string, list interface{}) {
+	t.Helper()
+
+	data, err := yaml.Marshal(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "namespaces", namespace, "core")
+	if err := os.MkdirAll(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(path, file), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
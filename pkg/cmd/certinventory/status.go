@@ -0,0 +1,282 @@
+package certinventory
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/cert"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/api/annotations"
+	configv1 "github.com/openshift/api/config/v1"
+	configclientv1 "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/certrotationcontroller"
+)
+
+// certificateStatus is what is actually found in the cluster for a managed
+// certificate.
+type certificateStatus struct {
+	Missing bool   `json:"missing,omitempty"`
+	Error   string `json:"error,omitempty"`
+
+	NotBefore *time.Time `json:"notBefore,omitempty"`
+	NotAfter  *time.Time `json:"notAfter,omitempty"`
+	Issuer    string     `json:"issuer,omitempty"`
+	// Certificates is the number of certificates in a CA bundle.
+	Certificates int `json:"certificates,omitempty"`
+
+	JiraComponent string `json:"jiraComponent,omitempty"`
+	Description   string `json:"description,omitempty"`
+
+	// NextRefresh is when the rotator replaces the certificate next, at the
+	// latest. It is in the past if the rotator has not caught up yet.
+	NextRefresh *time.Time `json:"nextRefresh,omitempty"`
+}
+
+// objectSource reads the secrets and config maps the rotators manage, and the
+// operator config map and the feature gates that set the rotation periods.
+type objectSource interface {
+	// getFeatureGates returns nil when the feature gates are not found.
+	getFeatureGates(ctx context.Context) (featuregates.FeatureGate, error)
+	getSecret(ctx context.Context, namespace, name string) (*corev1.Secret, error)
+	getConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error)
+}
+
+type clusterSource struct {
+	kubeClient   kubernetes.Interface
+	configClient configclientv1.ConfigV1Interface
+}
+
+func (s *clusterSource) getFeatureGates(ctx context.Context) (featuregates.FeatureGate, error) {
+	featureGate, err := s.configClient.FeatureGates().Get(ctx, "cluster", metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	clusterVersion, err := s.configClient.ClusterVersions().Get(ctx, "version", metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return featureGatesFor(featureGate, clusterVersion)
+}
+
+func (s *clusterSource) getSecret(ctx context.Context, namespace, name string) (*corev1.Secret, error) {
+	return s.kubeClient.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+func (s *clusterSource) getConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error) {
+	return s.kubeClient.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// mustGatherSource reads the lists a must-gather stores per namespace in
+// namespaces/<namespace>/core/{secrets,configmaps}.yaml. Secret data is
+// usually elided there, the certificate annotations are kept though.
+type mustGatherSource struct {
+	dir string
+}
+
+func (s *mustGatherSource) getFeatureGates(_ context.Context) (featuregates.FeatureGate, error) {
+	featureGate := &configv1.FeatureGate{}
+	found, err := s.readClusterScoped("config.openshift.io", "featuregates", "cluster", featureGate)
+	if !found || err != nil {
+		return nil, err
+	}
+	clusterVersion := &configv1.ClusterVersion{}
+	found, err = s.readClusterScoped("config.openshift.io", "clusterversions", "version", clusterVersion)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("clusterversions.config.openshift.io/version is missing from the must-gather")
+	}
+	return featureGatesFor(featureGate, clusterVersion)
+}
+
+func (s *mustGatherSource) getSecret(_ context.Context, namespace, name string) (*corev1.Secret, error) {
+	list := &corev1.SecretList{}
+	if err := s.readList(namespace, "secrets.yaml", list); err != nil {
+		return nil, err
+	}
+	for i := range list.Items {
+		if list.Items[i].Name == name {
+			return &list.Items[i], nil
+		}
+	}
+	return nil, apierrors.NewNotFound(corev1.Resource("secrets"), name)
+}
+
+func (s *mustGatherSource) getConfigMap(_ context.Context, namespace, name string) (*corev1.ConfigMap, error) {
+	list := &corev1.ConfigMapList{}
+	if err := s.readList(namespace, "configmaps.yaml", list); err != nil {
+		return nil, err
+	}
+	for i := range list.Items {
+		if list.Items[i].Name == name {
+			return &list.Items[i], nil
+		}
+	}
+	return nil, apierrors.NewNotFound(corev1.Resource("configmaps"), name)
+}
+
+func (s *mustGatherSource) readClusterScoped(group, resource, name string, into interface{}) (bool, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, "cluster-scoped-resources", group, resource, name+".yaml"))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, yaml.Unmarshal(data, into)
+}
+
+func (s *mustGatherSource) readList(namespace, file string, into interface{}) error {
+	data, err := os.ReadFile(filepath.Join(s.dir, "namespaces", namespace, "core", file))
+	if os.IsNotExist(err) {
+		// Namespaces that were not gathered look the same as empty ones.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(data, into)
+}
+
+// featureGatesFor returns the feature gates of the version the cluster is
+// updating to, the operator reads the same.
+func featureGatesFor(featureGate *configv1.FeatureGate, clusterVersion *configv1.ClusterVersion) (featuregates.FeatureGate, error) {
+	access, err := featuregates.NewHardcodedFeatureGateAccessFromFeatureGate(featureGate, clusterVersion.Status.Desired.Version)
+	if err != nil {
+		return nil, err
+	}
+	return access.CurrentFeatureGates()
+}
+
+func certificateStatusFor(ctx context.Context, source objectSource, managed certrotationcontroller.ManagedCertificate, now time.Time) *certificateStatus {
+	status := &certificateStatus{}
+
+	var err error
+	if managed.Kind == certrotationcontroller.ManagedCertificateKindCABundle {
+		err = status.fromConfigMap(ctx, source, managed)
+	} else {
+		err = status.fromSecret(ctx, source, managed)
+	}
+	switch {
+	case apierrors.IsNotFound(err):
+		status.Missing = true
+		// The rotator creates missing certificates right away.
+		status.NextRefresh = &now
+	case err != nil:
+		status.Error = err.Error()
+	}
+
+	return status
+}
+
+func (s *certificateStatus) fromSecret(ctx context.Context, source objectSource, managed certrotationcontroller.ManagedCertificate) error {
+	secret, err := source.getSecret(ctx, managed.Namespace, managed.Name)
+	if err != nil {
+		return err
+	}
+	s.JiraComponent = secret.Annotations[annotations.OpenShiftComponent]
+	s.Description = secret.Annotations[annotations.OpenShiftDescription]
+
+	if certPEM := secret.Data[corev1.TLSCertKey]; len(certPEM) > 0 {
+		certs, err := cert.ParseCertsPEM(certPEM)
+		if err != nil {
+			return fmt.Errorf("unable to parse %s: %w", corev1.TLSCertKey, err)
+		}
+		s.NotBefore = &certs[0].NotBefore
+		s.NotAfter = &certs[0].NotAfter
+		s.Issuer = certs[0].Issuer.CommonName
+	} else {
+		// The rotators keep the validity in annotations, which survive when
+		// the secret data is elided.
+		if err := s.fromAnnotations(secret.Annotations); err != nil {
+			return err
+		}
+	}
+
+	if s.NotBefore != nil && s.NotAfter != nil {
+		nextRefresh := nextRefresh(*s.NotBefore, *s.NotAfter, managed.Refresh, managed.RefreshOnlyWhenExpired)
+		s.NextRefresh = &nextRefresh
+	}
+	return nil
+}
+
+func (s *certificateStatus) fromAnnotations(secretAnnotations map[string]string) error {
+	s.Issuer = secretAnnotations[certrotation.CertificateIssuer]
+	for annotation, into := range map[string]**time.Time{
+		certrotation.CertificateNotBeforeAnnotation: &s.NotBefore,
+		certrotation.CertificateNotAfterAnnotation:  &s.NotAfter,
+	} {
+		value, ok := secretAnnotations[annotation]
+		if !ok {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return fmt.Errorf("unable to parse %s: %w", annotation, err)
+		}
+		*into = &t
+	}
+	return nil
+}
+
+// fromConfigMap reports the earliest expiry in a CA bundle, that is when the
+// bundle stops trusting a signer.
+func (s *certificateStatus) fromConfigMap(ctx context.Context, source objectSource, managed certrotationcontroller.ManagedCertificate) error {
+	configMap, err := source.getConfigMap(ctx, managed.Namespace, managed.Name)
+	if err != nil {
+		return err
+	}
+	s.JiraComponent = configMap.Annotations[annotations.OpenShiftComponent]
+	s.Description = configMap.Annotations[annotations.OpenShiftDescription]
+
+	bundle := configMap.Data["ca-bundle.crt"]
+	if len(bundle) == 0 {
+		return nil
+	}
+	certs, err := cert.ParseCertsPEM([]byte(bundle))
+	if err != nil {
+		return fmt.Errorf("unable to parse ca-bundle.crt: %w", err)
+	}
+	s.Certificates = len(certs)
+
+	var earliest *x509.Certificate
+	for _, caCert := range certs {
+		if earliest == nil || caCert.NotAfter.Before(earliest.NotAfter) {
+			earliest = caCert
+		}
+	}
+	s.NotBefore = &earliest.NotBefore
+	s.NotAfter = &earliest.NotAfter
+	return nil
+}
+
+// nextRefresh mirrors the library-go rotators: a certificate is replaced once
+// it expires, or when either 80% of its validity or the refresh period since
+// it was issued has passed, whatever comes first.
+func nextRefresh(notBefore, notAfter time.Time, refresh time.Duration, refreshOnlyWhenExpired bool) time.Time {
+	if refreshOnlyWhenExpired {
+		return notAfter
+	}
+
+	at80Percent := notAfter.Add(-notAfter.Sub(notBefore) / 5)
+	if refreshTime := notBefore.Add(refresh); refresh > 0 && refreshTime.Before(at80Percent) {
+		return refreshTime
+	}
+	return at80Percent
+}
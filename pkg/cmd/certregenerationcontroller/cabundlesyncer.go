@@ -3,9 +3,11 @@ package certregenerationcontroller
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
@@ -13,6 +15,7 @@ import (
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
+	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 
@@ -30,6 +33,7 @@ const workQueueKey = "key"
 type CABundleController struct {
 	configMapGetter corev1client.ConfigMapsGetter
 	configMapLister corev1listers.ConfigMapLister
+	secretLister    corev1listers.SecretLister
 
 	eventRecorder events.Recorder
 
@@ -47,6 +51,7 @@ func NewCABundleController(
 	c := &CABundleController{
 		configMapGetter: configMapGetter,
 		configMapLister: kubeInformersForNamespaces.ConfigMapLister(),
+		secretLister:    kubeInformersForNamespaces.SecretLister(),
 		eventRecorder:   eventRecorder.WithComponentSuffix("manage-client-ca-bundle-recovery-controller"),
 		queue:           workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "CABundleRecoveryController"),
 	}
@@ -70,15 +75,27 @@ func NewCABundleController(
 		c.cachesToSync = append(c.cachesToSync, informers.Core().V1().ConfigMaps().Informer().HasSynced)
 	}
 
+	// CAs that issued a valid client certificate are kept in the bundle
+	secretNamespaces, secretNames := sets.New[string](), sets.New[string]()
+	for _, target := range targetconfigcontroller.ClientCATargets {
+		secretNamespaces.Insert(target.Namespace)
+		secretNames.Insert(target.Name)
+	}
+	for _, namespace := range sets.List(secretNamespaces) {
+		informers := kubeInformersForNamespaces.InformersFor(namespace)
+		informers.Core().V1().Secrets().Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+			FilterFunc: factory.NamesFilter(sets.List(secretNames)...),
+			Handler:    handler,
+		})
+		c.cachesToSync = append(c.cachesToSync, informers.Core().V1().Secrets().Informer().HasSynced)
+	}
+
 	return c, nil
 }
 
 func (c *CABundleController) Run(ctx context.Context) {
 	defer utilruntime.HandleCrash()
 
-	// FIXME: These are missing a wait group to track goroutines and handle graceful termination
-	// (@deads2k wants time to think it through)
-
 	klog.Info("Starting CA bundle controller")
 	defer func() {
 		klog.Info("Shutting down CA bundle controller")
@@ -90,11 +107,17 @@ func (c *CABundleController) Run(ctx context.Context) {
 		return
 	}
 
+	var wg sync.WaitGroup
+	wg.Add(1)
 	go func() {
+		defer wg.Done()
 		wait.UntilWithContext(ctx, c.runWorker, time.Second)
 	}()
 
 	<-ctx.Done()
+	// the worker blocks on the queue until it is shut down
+	c.queue.ShutDown()
+	wg.Wait()
 }
 
 func (c *CABundleController) runWorker(ctx context.Context) {
@@ -132,7 +155,7 @@ func (c *CABundleController) sync(ctx context.Context) error {
 		return nil
 	}
 
-	_, changed, err := targetconfigcontroller.ManageClientCABundle(ctx, c.configMapLister, c.configMapGetter, c.eventRecorder)
+	_, changed, err := targetconfigcontroller.ManageClientCABundle(ctx, c.configMapLister, c.secretLister, c.configMapGetter, c.eventRecorder)
 	if err != nil {
 		return err
 	}
@@ -3,6 +3,7 @@ package certregenerationcontroller
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -28,10 +29,14 @@ import (
 
 type Options struct {
 	controllerContext *controllercmd.ControllerContext
+
+	Serving *ServingOptions
 }
 
 func NewCertRegenerationControllerCommand(ctx context.Context) *cobra.Command {
-	o := &Options{}
+	o := &Options{
+		Serving: NewServingOptions(),
+	}
 	c := clock.RealClock{}
 
 	ccc := controllercmd.NewControllerCommandConfig("cert-regeneration-controller", version.Get(), func(ctx context.Context, controllerContext *controllercmd.ControllerContext) error {
@@ -55,21 +60,22 @@ func NewCertRegenerationControllerCommand(ctx context.Context) *cobra.Command {
 		return nil
 	}, c)
 
-	// Disable serving for recovery as it introduces a dependency on kube-system::extension-apiserver-authentication
-	// configmap which prevents it to start as the CA bundle is expired.
-	// TODO: Remove when the internal logic can start serving without extension-apiserver-authentication
-	//  	 and live reload extension-apiserver-authentication after it is available
+	// The default serving introduces a dependency on kube-system::extension-apiserver-authentication
+	// configmap which prevents it to start as the CA bundle is expired. The controller serves
+	// healthz and metrics on its own instead, see ServingOptions.
 	ccc.DisableServing = true
 
 	cmd := ccc.NewCommandWithContext(ctx)
 	cmd.Use = "cert-regeneration-controller"
 	cmd.Short = "Start the Cluster Certificate Regeneration Controller"
 
+	o.Serving.AddFlags(cmd.Flags())
+
 	return cmd
 }
 
 func (o *Options) Validate(ctx context.Context) error {
-	return nil
+	return o.Serving.Validate()
 }
 
 func (o *Options) Complete(ctx context.Context) error {
@@ -130,6 +136,14 @@ func (o *Options) Run(ctx context.Context, clock clock.Clock) error {
 		return fmt.Errorf("timed out waiting for FeatureGate detection")
 	}
 
+	// Regenerated certificates get the same lifetime the operator would give
+	// them. The operator config map may not be readable while certificates are
+	// expired, the default lifetime applies then.
+	rotationProfile, err := certrotationcontroller.CurrentRotationProfile(ctx, kubeClient.CoreV1())
+	if err != nil {
+		klog.Warningf("Unable to read the certificate rotation profile, using the defaults: %v", err)
+	}
+
 	kubeAPIServerCertRotationController, err := certrotationcontroller.NewCertRotationControllerOnlyWhenExpired(
 		kubeClient,
 		operatorClient,
@@ -137,6 +151,7 @@ func (o *Options) Run(ctx context.Context, clock clock.Clock) error {
 		kubeAPIServerInformersForNamespaces,
 		o.controllerContext.EventRecorder,
 		featureGates,
+		rotationProfile,
 	)
 	if err != nil {
 		return err
@@ -151,23 +166,44 @@ func (o *Options) Run(ctx context.Context, clock clock.Clock) error {
 		return err
 	}
 
+	// The container's liveness probe checks the healthz endpoint, failing to
+	// serve it would only get the container restarted later.
+	server, err := o.Serving.NewServer(kubeClient)
+	if err != nil {
+		return fmt.Errorf("unable to serve healthz and metrics: %w", err)
+	}
+
 	// We can't start informers until after the resources have been requested. Now is the time.
 	kubeAPIServerInformersForNamespaces.Start(ctx.Done())
 	dynamicInformers.Start(ctx.Done())
 	configInformers.Start(ctx.Done())
 
-	// FIXME: These are missing a wait group to track goroutines and handle graceful termination
-	// (@deads2k wants time to think it through)
+	var wg sync.WaitGroup
+
+	if server != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := server.PrepareRun().RunWithContext(ctx); err != nil {
+				klog.Errorf("Unable to serve healthz and metrics: %v", err)
+			}
+		}()
+	}
 
+	wg.Add(1)
 	go func() {
+		defer wg.Done()
 		kubeAPIServerCertRotationController.Run(ctx, 1)
 	}()
 
+	wg.Add(1)
 	go func() {
+		defer wg.Done()
 		caBundleController.Run(ctx)
 	}()
 
 	<-ctx.Done()
+	wg.Wait()
 
 	return nil
 }
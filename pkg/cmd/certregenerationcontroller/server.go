@@ -0,0 +1,294 @@
+package certregenerationcontroller
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apiserver/pkg/apis/apiserver"
+	"k8s.io/apiserver/pkg/authentication/authenticatorfactory"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizerfactory"
+	"k8s.io/apiserver/pkg/authorization/path"
+	"k8s.io/apiserver/pkg/authorization/union"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/apiserver/pkg/server/dynamiccertificates"
+	genericapiserveroptions "k8s.io/apiserver/pkg/server/options"
+	"k8s.io/apiserver/pkg/util/compatibility"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	netutils "k8s.io/utils/net"
+
+	"github.com/openshift/library-go/pkg/authorization/hardcodedauthorizer"
+)
+
+const (
+	// extension-apiserver-authentication is where delegated client certificate
+	// authentication normally gets its client CA from.
+	authenticationConfigMapNamespace = metav1.NamespaceSystem
+	authenticationConfigMapName      = "extension-apiserver-authentication"
+
+	// the platform generally uses 30s for /metrics scraping, avoid API request for every other /metrics request to the component
+	authCacheTTL = 35 * time.Second
+)
+
+// ServingOptions configure the healthz and metrics endpoint of the cert
+// regeneration controller.
+//
+// The controller runs exactly when certificates may be expired, so the
+// endpoint can't be set up the usual way that requires a valid
+// extension-apiserver-authentication configmap before serving starts.
+// Instead client certificates are verified against a local client CA bundle
+// right away and against the client CA of extension-apiserver-authentication
+// as soon as it can be read, both are reloaded when they change. Bearer tokens
+// and authorization are delegated to the kube-apiserver per request, so they
+// start working once it is reachable again. Health checks are always allowed.
+type ServingOptions struct {
+	SecureServing *genericapiserveroptions.SecureServingOptionsWithLoopback
+
+	// ClientCAFile is the local client CA bundle, usually the one of the
+	// kube-apiserver.
+	ClientCAFile string
+}
+
+func NewServingOptions() *ServingOptions {
+	secureServing := genericapiserveroptions.NewSecureServingOptions()
+	// serving is off unless a port is set
+	secureServing.BindPort = 0
+	// without a serving certificate, a self-signed one is generated in memory
+	secureServing.ServerCert.CertDirectory = ""
+	secureServing.ServerCert.PairName = "cert-regeneration-controller"
+
+	return &ServingOptions{
+		SecureServing: secureServing.WithLoopback(),
+	}
+}
+
+func (o *ServingOptions) AddFlags(fs *pflag.FlagSet) {
+	o.SecureServing.AddFlags(fs)
+	// the pod template shares the bind address with the check-endpoints
+	// listen address, which brackets IPv6 addresses
+	fs.Lookup("bind-address").Value = &bindAddressValue{ip: &o.SecureServing.BindAddress}
+	fs.StringVar(&o.ClientCAFile, "client-ca-file", o.ClientCAFile, "A local client CA bundle to verify client certificates with, in addition to the client CA in kube-system/extension-apiserver-authentication once that can be read.")
+}
+
+func (o *ServingOptions) Validate() error {
+	return utilerrors.NewAggregate(o.SecureServing.Validate())
+}
+
+// NewServer returns the server for the healthz and metrics endpoint, or nil if
+// serving is off.
+func (o *ServingOptions) NewServer(kubeClient kubernetes.Interface) (*genericapiserver.GenericAPIServer, error) {
+	if o.SecureServing.BindPort <= 0 {
+		return nil, nil
+	}
+
+	if err := o.SecureServing.MaybeDefaultWithSelfSignedCerts("localhost", nil, []net.IP{netutils.ParseIPSloppy("127.0.0.1")}); err != nil {
+		return nil, fmt.Errorf("failed to create a self-signed serving certificate: %w", err)
+	}
+
+	scheme := runtime.NewScheme()
+	metav1.AddToGroupVersion(scheme, metav1.SchemeGroupVersion)
+	config := genericapiserver.NewConfig(serializer.NewCodecFactory(scheme))
+	config.EffectiveVersion = compatibility.DefaultBuildEffectiveVersion()
+
+	if err := o.SecureServing.ApplyTo(&config.SecureServing, &config.LoopbackClientConfig); err != nil {
+		return nil, err
+	}
+
+	clientCA, err := o.clientCA(kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.Authentication.ApplyClientCert(clientCA, config.SecureServing); err != nil {
+		return nil, err
+	}
+	authenticatorConfig := authenticatorfactory.DelegatingAuthenticatorConfig{
+		Anonymous:                          &apiserver.AnonymousAuthConfig{Enabled: true},
+		TokenAccessReviewClient:            kubeClient.AuthenticationV1(),
+		TokenAccessReviewTimeout:           10 * time.Second,
+		WebhookRetryBackoff:                genericapiserveroptions.DefaultAuthWebhookRetryBackoff(),
+		CacheTTL:                           authCacheTTL,
+		ClientCertificateCAContentProvider: clientCA,
+	}
+	config.Authentication.Authenticator, _, err = authenticatorConfig.New()
+	if err != nil {
+		return nil, err
+	}
+
+	healthPaths, err := path.NewAuthorizer([]string{"/healthz", "/readyz", "/livez"})
+	if err != nil {
+		return nil, err
+	}
+	authorizerConfig := authorizerfactory.DelegatingAuthorizerConfig{
+		SubjectAccessReviewClient: kubeClient.AuthorizationV1(),
+		AllowCacheTTL:             authCacheTTL,
+		DenyCacheTTL:              10 * time.Second,
+		WebhookRetryBackoff:       genericapiserveroptions.DefaultAuthWebhookRetryBackoff(),
+	}
+	delegatedAuthorizer, err := authorizerConfig.New()
+	if err != nil {
+		return nil, err
+	}
+	config.Authorization.Authorizer = union.New(
+		// the permissions for metrics scraping are well known, openshift RBAC policy will always allow this user to read metrics.
+		hardcodedauthorizer.NewHardCodedMetricsAuthorizer(),
+		authorizerfactory.NewPrivilegedGroups(user.SystemPrivilegedGroup),
+		healthPaths,
+		delegatedAuthorizer,
+	)
+
+	return config.Complete(nil).New("cert-regeneration-controller", genericapiserver.NewEmptyDelegate())
+}
+
+// clientCA returns the union of the local client CA bundle and the client CA of
+// extension-apiserver-authentication. The server loads both when it starts
+// serving and keeps them up to date, failing to load either one is not fatal.
+func (o *ServingOptions) clientCA(kubeClient kubernetes.Interface) (dynamiccertificates.CAContentProvider, error) {
+	var providers []dynamiccertificates.CAContentProvider
+
+	if len(o.ClientCAFile) > 0 {
+		providers = append(providers, &fileCAContent{name: "client-ca-file", file: o.ClientCAFile, retryInterval: time.Minute})
+	}
+
+	delegatedCA, err := dynamiccertificates.NewDynamicCAFromConfigMapController("client-ca", authenticationConfigMapNamespace, authenticationConfigMapName, "client-ca-file", kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	providers = append(providers, delegatedCA)
+
+	return dynamiccertificates.NewUnionCAContentProvider(providers...), nil
+}
+
+// fileCAContent is the local client CA bundle. Unlike a plain
+// dynamiccertificates.DynamicFileCAContent it starts without the file when that
+// can't be loaded yet and retries until it can, then keeps it up to date.
+type fileCAContent struct {
+	name          string
+	file          string
+	retryInterval time.Duration
+
+	lock      sync.Mutex
+	loaded    *dynamiccertificates.DynamicFileCAContent
+	listeners []dynamiccertificates.Listener
+}
+
+var _ dynamiccertificates.CAContentProvider = &fileCAContent{}
+var _ dynamiccertificates.ControllerRunner = &fileCAContent{}
+
+func (c *fileCAContent) Name() string {
+	return c.name
+}
+
+func (c *fileCAContent) current() *dynamiccertificates.DynamicFileCAContent {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.loaded
+}
+
+func (c *fileCAContent) CurrentCABundleContent() []byte {
+	if loaded := c.current(); loaded != nil {
+		return loaded.CurrentCABundleContent()
+	}
+	return nil
+}
+
+func (c *fileCAContent) VerifyOptions() (x509.VerifyOptions, bool) {
+	if loaded := c.current(); loaded != nil {
+		return loaded.VerifyOptions()
+	}
+	return x509.VerifyOptions{}, false
+}
+
+func (c *fileCAContent) AddListener(listener dynamiccertificates.Listener) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.listeners = append(c.listeners, listener)
+	if c.loaded != nil {
+		c.loaded.AddListener(listener)
+	}
+}
+
+// load loads the file unless it is loaded already. The listeners are notified
+// when it gets loaded.
+func (c *fileCAContent) load() (*dynamiccertificates.DynamicFileCAContent, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.loaded != nil {
+		return c.loaded, nil
+	}
+
+	loaded, err := dynamiccertificates.NewDynamicCAContentFromFile(c.name, c.file)
+	if err != nil {
+		return nil, err
+	}
+	klog.Infof("Loaded the client CA bundle %s", c.file)
+	for _, listener := range c.listeners {
+		loaded.AddListener(listener)
+		listener.Enqueue()
+	}
+	c.loaded = loaded
+	return loaded, nil
+}
+
+func (c *fileCAContent) RunOnce(ctx context.Context) error {
+	loaded, err := c.load()
+	if err != nil {
+		klog.Warningf("Unable to load the client CA bundle %s, it is not trusted until it can be loaded: %v", c.file, err)
+		return nil
+	}
+	return loaded.RunOnce(ctx)
+}
+
+func (c *fileCAContent) Run(ctx context.Context, workers int) {
+	var loaded *dynamiccertificates.DynamicFileCAContent
+	err := wait.PollUntilContextCancel(ctx, c.retryInterval, true, func(ctx context.Context) (bool, error) {
+		var err error
+		if loaded, err = c.load(); err != nil {
+			klog.V(2).Infof("Unable to load the client CA bundle %s, retrying: %v", c.file, err)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return
+	}
+	loaded.Run(ctx, workers)
+}
+
+// bindAddressValue is a bind address flag that also takes an IPv6 address in
+// brackets.
+type bindAddressValue struct {
+	ip *net.IP
+}
+
+func (v *bindAddressValue) String() string {
+	if v.ip == nil || *v.ip == nil {
+		return ""
+	}
+	return v.ip.String()
+}
+
+func (v *bindAddressValue) Set(value string) error {
+	ip := netutils.ParseIPSloppy(strings.TrimSuffix(strings.TrimPrefix(value, "["), "]"))
+	if ip == nil {
+		return fmt.Errorf("invalid IP address %q", value)
+	}
+	*v.ip = ip
+	return nil
+}
+
+func (v *bindAddressValue) Type() string {
+	return "ip"
+}
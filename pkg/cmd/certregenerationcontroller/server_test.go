@@ -0,0 +1,149 @@
+package certregenerationcontroller
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	genericapiserveroptions "k8s.io/apiserver/pkg/server/options"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/openshift/library-go/pkg/crypto"
+)
+
+func TestServerWithoutAuthenticationConfigMap(t *testing.T) {
+	listener, port, err := genericapiserveroptions.CreateListener("tcp", "127.0.0.1:0", net.ListenConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := NewServingOptions()
+	o.SecureServing.Listener = listener
+	o.SecureServing.BindPort = port
+
+	// the kube-apiserver is unreachable, healthz must be served regardless
+	kubeClient, err := kubernetes.NewForConfig(&rest.Config{Host: "https://127.0.0.1:1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := o.NewServer(kubeClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := server.PrepareRun().RunWithContext(ctx); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	get := func(path string) (int, error) {
+		resp, err := client.Get(fmt.Sprintf("https://127.0.0.1:%d%s", port, path))
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+		return resp.StatusCode, nil
+	}
+
+	var status int
+	for start := time.Now(); time.Since(start) < 30*time.Second; time.Sleep(100 * time.Millisecond) {
+		if status, err = get("/healthz"); err == nil && status == http.StatusOK {
+			break
+		}
+	}
+	if status != http.StatusOK {
+		t.Fatalf("expected /healthz to be served, got status %d: %v", status, err)
+	}
+
+	if status, err = get("/metrics"); err != nil {
+		t.Fatal(err)
+	}
+	if status == http.StatusOK {
+		t.Errorf("expected anonymous /metrics requests to be refused")
+	}
+}
+
+type fakeListener struct {
+	enqueued int
+}
+
+func (l *fakeListener) Enqueue() {
+	l.enqueued++
+}
+
+func TestFileCAContentRetriesLoading(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "ca-bundle.crt")
+	caContent := &fileCAContent{name: "client-ca-file", file: file, retryInterval: time.Minute}
+	listener := &fakeListener{}
+	caContent.AddListener(listener)
+
+	if err := caContent.RunOnce(context.Background()); err != nil {
+		t.Fatalf("expected a missing client CA bundle not to fail, got %v", err)
+	}
+	if content := caContent.CurrentCABundleContent(); len(content) != 0 {
+		t.Errorf("expected no client CA bundle, got %q", content)
+	}
+	if _, ok := caContent.VerifyOptions(); ok {
+		t.Errorf("expected no verify options without a client CA bundle")
+	}
+
+	ca, err := crypto.MakeSelfSignedCAConfigForDuration("client-ca", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caBundle, _, err := ca.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, caBundle, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := caContent.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if content := caContent.CurrentCABundleContent(); string(content) != string(caBundle) {
+		t.Errorf("expected the client CA bundle to be loaded, got %q", content)
+	}
+	if _, ok := caContent.VerifyOptions(); !ok {
+		t.Errorf("expected verify options for the loaded client CA bundle")
+	}
+	if listener.enqueued == 0 {
+		t.Errorf("expected the listener to be notified of the loaded client CA bundle")
+	}
+}
+
+func TestBindAddressFlag(t *testing.T) {
+	for value, expected := range map[string]string{
+		"0.0.0.0": "0.0.0.0",
+		"[::]":    "::",
+		"::1":     "::1",
+	} {
+		o := NewServingOptions()
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		o.AddFlags(fs)
+		if err := fs.Parse([]string{"--bind-address=" + value}); err != nil {
+			t.Errorf("%s: %v", value, err)
+			continue
+		}
+		if actual := o.SecureServing.BindAddress.String(); actual != expected {
+			t.Errorf("%s: expected bind address %s, got %s", value, expected, actual)
+		}
+	}
+}
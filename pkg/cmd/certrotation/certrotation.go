@@ -0,0 +1,176 @@
+package certrotation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/certinventory"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/certrotationcontroller"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// NewCertRotationCommand creates the cert-rotation command.
+func NewCertRotationCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cert-rotation",
+		Short: "Inspect the certificate rotation of the operator",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+			os.Exit(1)
+		},
+	}
+
+	cmd.AddCommand(NewSimulateCommand())
+
+	return cmd
+}
+
+// simulateOpts holds values to drive the cert-rotation simulate command.
+type simulateOpts struct {
+	from                string
+	days                int
+	step                time.Duration
+	rotationProfileFile string
+	output              string
+
+	start           time.Time
+	rotationProfile *certrotationcontroller.RotationProfile
+	out             io.Writer
+}
+
+// NewSimulateCommand creates the cert-rotation simulate command.
+func NewSimulateCommand() *cobra.Command {
+	opts := simulateOpts{
+		days:   30,
+		step:   time.Minute,
+		output: outputTable,
+		out:    os.Stdout,
+	}
+	cmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "Print when the operator would rotate its signers, CA bundles and certificates and roll out kube-apiserver revisions",
+		Long: `Print when the operator would rotate its signers, CA bundles and certificates and roll out kube-apiserver revisions.
+
+The simulation starts without any certificate at --from, as on a new cluster, and
+syncs every rotator once per --step of a fake clock.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := opts.Validate(); err != nil {
+				klog.Fatal(err)
+			}
+			if err := opts.Complete(); err != nil {
+				klog.Fatal(err)
+			}
+			if err := opts.Run(cmd.Context()); err != nil {
+				klog.Fatal(err)
+			}
+		},
+	}
+
+	opts.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (o *simulateOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.from, "from", o.from, "Start of the simulation, as a date (2006-01-02) or in RFC3339. Defaults to now")
+	fs.IntVar(&o.days, "days", o.days, "Number of days to simulate")
+	fs.DurationVar(&o.step, "step", o.step, "Interval in which the rotators sync")
+	fs.StringVar(&o.rotationProfileFile, "rotation-profile", o.rotationProfileFile, "File with the certificate rotation profile to simulate, in the format of the certRotationProfile key of configmaps/kube-apiserver-operator-config in openshift-config")
+	fs.StringVarP(&o.output, "output", "o", o.output, "Output format, one of: table, json")
+}
+
+// Validate verifies the inputs.
+func (o *simulateOpts) Validate() error {
+	if o.days <= 0 {
+		return fmt.Errorf("--days must be positive")
+	}
+	if o.step <= 0 {
+		return fmt.Errorf("--step must be positive")
+	}
+	if o.output != outputTable && o.output != outputJSON {
+		return fmt.Errorf("unsupported output format %q, must be one of: table, json", o.output)
+	}
+	return nil
+}
+
+// Complete fills in missing values before command execution.
+func (o *simulateOpts) Complete() error {
+	switch {
+	case len(o.from) == 0:
+		o.start = time.Now().UTC()
+	default:
+		start, err := time.Parse(time.RFC3339, o.from)
+		if err != nil {
+			if start, err = time.Parse(time.DateOnly, o.from); err != nil {
+				return fmt.Errorf("--from %q is neither a date nor in RFC3339", o.from)
+			}
+		}
+		o.start = start
+	}
+
+	if len(o.rotationProfileFile) > 0 {
+		data, err := os.ReadFile(o.rotationProfileFile)
+		if err != nil {
+			return err
+		}
+		profile := &certrotationcontroller.RotationProfile{}
+		if err := yaml.Unmarshal(data, profile); err != nil {
+			return fmt.Errorf("failed to read %s: %w", o.rotationProfileFile, err)
+		}
+		if err := profile.Validate(); err != nil {
+			return fmt.Errorf("invalid rotation profile in %s: %w", o.rotationProfileFile, err)
+		}
+		o.rotationProfile = profile
+	}
+
+	return nil
+}
+
+// Run contains the logic of the cert-rotation simulate command.
+func (o *simulateOpts) Run(ctx context.Context) error {
+	inventory, err := certinventory.ManagedCertificates(nil, o.rotationProfile)
+	if err != nil {
+		return err
+	}
+
+	s := newSimulator(inventory, clocktesting.NewFakeClock(o.start))
+	s.run(o.start.AddDate(0, 0, o.days), o.step)
+
+	if o.output == outputJSON {
+		data, err := json.MarshalIndent(s.events, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(o.out, string(data))
+		return err
+	}
+	return writeTable(o.out, s.events)
+}
+
+func writeTable(out io.Writer, events []timelineEvent) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tKIND\tOBJECT\tREASON\tDETAIL")
+	for _, event := range events {
+		object := "kube-apiserver"
+		if len(event.Name) > 0 {
+			object = event.Namespace + "/" + event.Name
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", formatTime(event.Time), event.Kind, object, event.Reason, event.Detail)
+	}
+	return w.Flush()
+}
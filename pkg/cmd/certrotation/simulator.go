@@ -0,0 +1,302 @@
+package certrotation
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/certrotationcontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/targetconfigcontroller"
+)
+
+const eventKindRevision = "Revision"
+
+// timelineEvent is a single change the simulated rotators make.
+type timelineEvent struct {
+	Time      time.Time `json:"time"`
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Reason    string    `json:"reason"`
+	Detail    string    `json:"detail,omitempty"`
+}
+
+// simulatedCert is a generated signer or cert key pair.
+type simulatedCert struct {
+	commonName string
+	notBefore  time.Time
+	notAfter   time.Time
+	issuer     *simulatedCert
+}
+
+// rotator is what a single library-go cert rotation controller manages.
+type rotator struct {
+	name   string
+	signer certrotationcontroller.ManagedCertificate
+	bundle certrotationcontroller.ManagedCertificate
+	target certrotationcontroller.ManagedCertificate
+}
+
+// simulator replays the decisions of the library-go cert rotators against a
+// fake clock. The rotators themselves read the wall clock, so their rules are
+// mirrored here:
+//
+//   - a signer is regenerated once it expired, at 80% of its validity or after
+//     its refresh period, whatever comes first,
+//   - a CA bundle adds the current signer and drops expired ones,
+//   - a cert key pair is regenerated once it expired, at 80% of its validity,
+//     after its refresh period if its signer is at least a tenth of that
+//     period old, or when its issuer left the CA bundle,
+//   - a cert key pair is never valid past its signer.
+//
+// Signers and cert key pairs that only refresh when expired skip all but the
+// first rule.
+type simulator struct {
+	clock    *clocktesting.FakeClock
+	rotators []rotator
+
+	signers map[string]*simulatedCert
+	bundles map[string][]*simulatedCert
+	targets map[string]*simulatedCert
+
+	// revisionTriggers are the config maps and secrets that end up in a
+	// kube-apiserver revision, keyed by kind/namespace/name.
+	revisionTriggers sets.Set[string]
+	revision         int
+
+	events []timelineEvent
+}
+
+func newSimulator(inventory []certrotationcontroller.ManagedCertificate, clock *clocktesting.FakeClock) *simulator {
+	s := &simulator{
+		clock:            clock,
+		signers:          map[string]*simulatedCert{},
+		bundles:          map[string][]*simulatedCert{},
+		targets:          map[string]*simulatedCert{},
+		revisionTriggers: revisionTriggers(),
+	}
+
+	indexes := map[string]int{}
+	for _, managed := range inventory {
+		for _, name := range managed.Rotators {
+			i, ok := indexes[name]
+			if !ok {
+				i = len(s.rotators)
+				indexes[name] = i
+				s.rotators = append(s.rotators, rotator{name: name})
+			}
+			r := &s.rotators[i]
+			switch managed.Kind {
+			case certrotationcontroller.ManagedCertificateKindSigner:
+				r.signer = managed
+			case certrotationcontroller.ManagedCertificateKindCABundle:
+				r.bundle = managed
+			case certrotationcontroller.ManagedCertificateKindTarget:
+				r.target = managed
+			}
+		}
+	}
+	return s
+}
+
+// revisionTriggers returns the rotated objects whose changes roll out a new
+// kube-apiserver revision: the revisioned resources of the operand and the CA
+// bundles combined into kube-apiserver-server-ca.
+func revisionTriggers() sets.Set[string] {
+	triggers := sets.New[string]()
+	for _, resource := range operator.RevisionConfigMaps {
+		triggers.Insert(key(certrotationcontroller.ManagedCertificateKindCABundle, operatorclient.TargetNamespace, resource.Name))
+	}
+	for _, resource := range operator.RevisionSecrets {
+		triggers.Insert(key(certrotationcontroller.ManagedCertificateKindSigner, operatorclient.TargetNamespace, resource.Name))
+		triggers.Insert(key(certrotationcontroller.ManagedCertificateKindTarget, operatorclient.TargetNamespace, resource.Name))
+	}
+	for _, source := range targetconfigcontroller.KubeAPIServerServerCASources {
+		triggers.Insert(key(certrotationcontroller.ManagedCertificateKindCABundle, source.Namespace, source.Name))
+	}
+	return triggers
+}
+
+func key(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+// run syncs every rotator each step until the clock passes until.
+func (s *simulator) run(until time.Time, step time.Duration) {
+	for !s.clock.Now().After(until) {
+		s.sync()
+		s.clock.Step(step)
+	}
+}
+
+// sync runs every rotator once and records a new revision if any of them
+// changed a revisioned object.
+func (s *simulator) sync() {
+	var changed []string
+	for _, r := range s.rotators {
+		signer, signerChanged := s.ensureSigner(r.signer)
+		if signerChanged && s.revisionTriggers.Has(key(r.signer.Kind, r.signer.Namespace, r.signer.Name)) {
+			changed = append(changed, r.signer.Namespace+"/"+r.signer.Name)
+		}
+		bundle, bundleChanged := s.ensureBundle(r.bundle, signer)
+		if bundleChanged && s.revisionTriggers.Has(key(r.bundle.Kind, r.bundle.Namespace, r.bundle.Name)) {
+			changed = append(changed, r.bundle.Namespace+"/"+r.bundle.Name)
+		}
+		if s.ensureTarget(r.target, signer, bundle) && s.revisionTriggers.Has(key(r.target.Kind, r.target.Namespace, r.target.Name)) {
+			changed = append(changed, r.target.Namespace+"/"+r.target.Name)
+		}
+	}
+
+	if len(changed) > 0 {
+		s.revision++
+		s.record(timelineEvent{
+			Kind:   eventKindRevision,
+			Reason: fmt.Sprintf("revision %d", s.revision),
+			Detail: "triggered by " + strings.Join(sets.List(sets.New(changed...)), ", "),
+		})
+	}
+}
+
+func (s *simulator) ensureSigner(managed certrotationcontroller.ManagedCertificate) (*simulatedCert, bool) {
+	signerKey := managed.Namespace + "/" + managed.Name
+	current := s.signers[signerKey]
+	reason := s.needsRotation(current, managed.Refresh, managed.RefreshOnlyWhenExpired, nil)
+	if len(reason) == 0 {
+		return current, false
+	}
+
+	now := s.clock.Now()
+	signer := &simulatedCert{
+		commonName: fmt.Sprintf("%s_%s@%d", managed.Namespace, managed.Name, now.Unix()),
+		notBefore:  now,
+		notAfter:   now.Add(managed.Validity),
+	}
+	s.signers[signerKey] = signer
+	s.record(timelineEvent{
+		Kind:      managed.Kind,
+		Namespace: managed.Namespace,
+		Name:      managed.Name,
+		Reason:    reason,
+		Detail:    fmt.Sprintf("%s valid until %s", signer.commonName, formatTime(signer.notAfter)),
+	})
+	return signer, true
+}
+
+func (s *simulator) ensureBundle(managed certrotationcontroller.ManagedCertificate, signer *simulatedCert) ([]*simulatedCert, bool) {
+	bundleKey := managed.Namespace + "/" + managed.Name
+	now := s.clock.Now()
+
+	var added, expired []string
+	bundle := []*simulatedCert{}
+	for _, existing := range s.bundles[bundleKey] {
+		if now.After(existing.notAfter) {
+			expired = append(expired, existing.commonName)
+			continue
+		}
+		bundle = append(bundle, existing)
+	}
+	found := false
+	for _, existing := range bundle {
+		found = found || existing == signer
+	}
+	if !found {
+		bundle = append(bundle, signer)
+		added = append(added, signer.commonName)
+	}
+	s.bundles[bundleKey] = bundle
+
+	if len(added) == 0 && len(expired) == 0 {
+		return bundle, false
+	}
+	var reasons, details []string
+	if len(added) > 0 {
+		reasons = append(reasons, "signer added")
+		details = append(details, "added "+strings.Join(added, ", "))
+	}
+	if len(expired) > 0 {
+		reasons = append(reasons, "expired signer removed")
+		details = append(details, "removed "+strings.Join(expired, ", "))
+	}
+	s.record(timelineEvent{
+		Kind:      managed.Kind,
+		Namespace: managed.Namespace,
+		Name:      managed.Name,
+		Reason:    strings.Join(reasons, ", "),
+		Detail:    fmt.Sprintf("%s; %d signers", strings.Join(details, "; "), len(bundle)),
+	})
+	return bundle, true
+}
+
+func (s *simulator) ensureTarget(managed certrotationcontroller.ManagedCertificate, signer *simulatedCert, bundle []*simulatedCert) bool {
+	targetKey := managed.Namespace + "/" + managed.Name
+	current := s.targets[targetKey]
+	reason := s.needsRotation(current, managed.Refresh, managed.RefreshOnlyWhenExpired, signer)
+	if len(reason) == 0 && !managed.RefreshOnlyWhenExpired {
+		trusted := false
+		for _, caCert := range bundle {
+			trusted = trusted || caCert == current.issuer
+		}
+		if !trusted {
+			reason = "issuer not in CA bundle"
+		}
+	}
+	if len(reason) == 0 {
+		return false
+	}
+
+	now := s.clock.Now()
+	notAfter := now.Add(managed.Validity)
+	if signer.notAfter.Before(notAfter) {
+		notAfter = signer.notAfter
+	}
+	target := &simulatedCert{
+		notBefore: now,
+		notAfter:  notAfter,
+		issuer:    signer,
+	}
+	s.targets[targetKey] = target
+	s.record(timelineEvent{
+		Kind:      managed.Kind,
+		Namespace: managed.Namespace,
+		Name:      managed.Name,
+		Reason:    reason,
+		Detail:    fmt.Sprintf("signed by %s, valid until %s", signer.commonName, formatTime(target.notAfter)),
+	})
+	return true
+}
+
+// needsRotation returns why a certificate has to be regenerated, if it has
+// to. The signer is only set for cert key pairs.
+func (s *simulator) needsRotation(current *simulatedCert, refresh time.Duration, refreshOnlyWhenExpired bool, signer *simulatedCert) string {
+	now := s.clock.Now()
+	switch {
+	case current == nil:
+		return "created"
+	case now.After(current.notAfter):
+		return "expired"
+	case refreshOnlyWhenExpired:
+		return ""
+	case now.After(current.notAfter.Add(-current.notAfter.Sub(current.notBefore) / 5)):
+		return "80% of validity"
+	case now.After(current.notBefore.Add(refresh)):
+		if signer != nil && !now.After(signer.notBefore.Add(refresh/10)) {
+			return ""
+		}
+		return "refresh period"
+	}
+	return ""
+}
+
+func (s *simulator) record(event timelineEvent) {
+	event.Time = s.clock.Now()
+	s.events = append(s.events, event)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
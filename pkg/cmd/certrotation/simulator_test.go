@@ -0,0 +1,136 @@
+package certrotation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/cmd/certinventory"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/certrotationcontroller"
+)
+
+func TestSimulator(t *testing.T) {
+	start := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	inventory := []certrotationcontroller.ManagedCertificate{
+		{
+			Kind:      certrotationcontroller.ManagedCertificateKindSigner,
+			Namespace: "openshift-kube-apiserver-operator",
+			Name:      "localhost-serving-signer",
+			Rotators:  []string{"LocalhostServing"},
+			Validity:  10 * time.Hour,
+			Refresh:   5 * time.Hour,
+		},
+		{
+			Kind:      certrotationcontroller.ManagedCertificateKindCABundle,
+			Namespace: "openshift-kube-apiserver-operator",
+			Name:      "localhost-serving-ca",
+			Rotators:  []string{"LocalhostServing"},
+		},
+		{
+			Kind:      certrotationcontroller.ManagedCertificateKindTarget,
+			Namespace: "openshift-kube-apiserver",
+			Name:      "localhost-serving-cert-certkey",
+			Rotators:  []string{"LocalhostServing"},
+			Validity:  8 * time.Hour,
+			Refresh:   6 * time.Hour,
+		},
+	}
+
+	s := newSimulator(inventory, clocktesting.NewFakeClock(start))
+	s.run(start.Add(12*time.Hour), 10*time.Minute)
+
+	type event struct {
+		Time   time.Duration
+		Kind   string
+		Reason string
+	}
+	var got []event
+	for _, e := range s.events {
+		got = append(got, event{Time: e.Time.Sub(start), Kind: e.Kind, Reason: e.Reason})
+	}
+	expected := []event{
+		{0, certrotationcontroller.ManagedCertificateKindSigner, "created"},
+		{0, certrotationcontroller.ManagedCertificateKindCABundle, "signer added"},
+		{0, certrotationcontroller.ManagedCertificateKindTarget, "created"},
+		{0, eventKindRevision, "revision 1"},
+		// The signer refreshes strictly after its refresh period.
+		{5*time.Hour + 10*time.Minute, certrotationcontroller.ManagedCertificateKindSigner, "refresh period"},
+		{5*time.Hour + 10*time.Minute, certrotationcontroller.ManagedCertificateKindCABundle, "signer added"},
+		{5*time.Hour + 10*time.Minute, eventKindRevision, "revision 2"},
+		// The target waits a tenth of its refresh period for the new signer
+		// to be trusted, even though its own refresh period passed.
+		{6*time.Hour + 10*time.Minute, certrotationcontroller.ManagedCertificateKindTarget, "refresh period"},
+		{10*time.Hour + 10*time.Minute, certrotationcontroller.ManagedCertificateKindCABundle, "expired signer removed"},
+		{10*time.Hour + 10*time.Minute, eventKindRevision, "revision 3"},
+		{10*time.Hour + 20*time.Minute, certrotationcontroller.ManagedCertificateKindSigner, "refresh period"},
+		{10*time.Hour + 20*time.Minute, certrotationcontroller.ManagedCertificateKindCABundle, "signer added"},
+		{10*time.Hour + 20*time.Minute, eventKindRevision, "revision 4"},
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("unexpected timeline (-want +got):\n%s", diff)
+	}
+}
+
+func TestSimulatorCapsTargetValidityAtSigner(t *testing.T) {
+	start := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	inventory := []certrotationcontroller.ManagedCertificate{
+		{
+			Kind:      certrotationcontroller.ManagedCertificateKindSigner,
+			Namespace: "openshift-kube-apiserver-operator",
+			Name:      "localhost-serving-signer",
+			Rotators:  []string{"LocalhostServing"},
+			Validity:  10 * time.Hour,
+			Refresh:   8 * time.Hour,
+		},
+		{
+			Kind:      certrotationcontroller.ManagedCertificateKindCABundle,
+			Namespace: "openshift-kube-apiserver-operator",
+			Name:      "localhost-serving-ca",
+			Rotators:  []string{"LocalhostServing"},
+		},
+		{
+			Kind:      certrotationcontroller.ManagedCertificateKindTarget,
+			Namespace: "openshift-kube-apiserver",
+			Name:      "localhost-serving-cert-certkey",
+			Rotators:  []string{"LocalhostServing"},
+			Validity:  24 * time.Hour,
+			Refresh:   20 * time.Hour,
+		},
+	}
+
+	s := newSimulator(inventory, clocktesting.NewFakeClock(start))
+	s.run(start, time.Minute)
+
+	target := s.targets["openshift-kube-apiserver/localhost-serving-cert-certkey"]
+	if expected := start.Add(10 * time.Hour); !target.notAfter.Equal(expected) {
+		t.Errorf("expected the target to be valid until its signer expires at %s, got %s", formatTime(expected), formatTime(target.notAfter))
+	}
+}
+
+func TestSimulatorWithOperatorRotators(t *testing.T) {
+	inventory, err := certinventory.ManagedCertificates(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	s := newSimulator(inventory, clocktesting.NewFakeClock(start))
+	s.run(start.AddDate(0, 0, 7), time.Minute)
+
+	// Every rotator must generate its signer and target and keep them valid.
+	for _, r := range s.rotators {
+		signer := s.signers[r.signer.Namespace+"/"+r.signer.Name]
+		target := s.targets[r.target.Namespace+"/"+r.target.Name]
+		if signer == nil || target == nil {
+			t.Fatalf("rotator %s did not generate its certificates", r.name)
+		}
+		if !target.notAfter.After(s.clock.Now()) {
+			t.Errorf("rotator %s let %s/%s expire", r.name, r.target.Namespace, r.target.Name)
+		}
+	}
+	if s.revision == 0 {
+		t.Errorf("expected kube-apiserver revisions")
+	}
+}
@@ -0,0 +1,222 @@
+package explainconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	operatorclientv1 "github.com/openshift/client-go/operator/clientset/versioned/typed/operator/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/externaljwtsigner"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// explainOpts holds values to drive the explain-config command.
+type explainOpts struct {
+	kubeconfig                     string
+	operatorConfigFile             string
+	observedConfigFile             string
+	unsupportedConfigOverridesFile string
+	output                         string
+	pathPrefix                     string
+	operatorClient                 operatorclientv1.OperatorV1Interface
+	kubeClient                     kubernetes.Interface
+	out                            io.Writer
+}
+
+// NewExplainConfigCommand creates an explain-config command.
+func NewExplainConfigCommand() *cobra.Command {
+	opts := explainOpts{
+		output: outputTable,
+		out:    os.Stdout,
+	}
+	cmd := &cobra.Command{
+		Use:   "explain-config [path-prefix]",
+		Short: "Show which layer, and which config observer, sets each value of the kube-apiserver config.yaml",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) > 0 {
+				opts.pathPrefix = args[0]
+			}
+			if err := opts.Validate(); err != nil {
+				klog.Fatal(err)
+			}
+			if err := opts.Complete(); err != nil {
+				klog.Fatal(err)
+			}
+			if err := opts.Run(cmd.Context()); err != nil {
+				klog.Fatal(err)
+			}
+		},
+	}
+
+	opts.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (o *explainOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.kubeconfig, "kubeconfig", o.kubeconfig, "Read the operator config of the cluster of this kubeconfig")
+	fs.StringVar(&o.operatorConfigFile, "operator-config", o.operatorConfigFile, "Read the operator config from this KubeAPIServer manifest")
+	fs.StringVar(&o.observedConfigFile, "observed-config", o.observedConfigFile, "Use the observedConfig of this file instead of the one of the operator config")
+	fs.StringVar(&o.unsupportedConfigOverridesFile, "unsupported-config-overrides", o.unsupportedConfigOverridesFile, "Use the unsupportedConfigOverrides of this file instead of the ones of the operator config")
+	fs.StringVarP(&o.output, "output", "o", o.output, "Output format, one of: table, json")
+}
+
+// Validate verifies the inputs.
+func (o *explainOpts) Validate() error {
+	if o.output != outputTable && o.output != outputJSON {
+		return fmt.Errorf("unsupported output format %q, must be one of: table, json", o.output)
+	}
+	if len(o.kubeconfig) > 0 && len(o.operatorConfigFile) > 0 {
+		return fmt.Errorf("--kubeconfig and --operator-config are mutually exclusive")
+	}
+	return nil
+}
+
+// Complete fills in missing values before command execution.
+func (o *explainOpts) Complete() error {
+	if len(o.kubeconfig) == 0 {
+		return nil
+	}
+	restConfig, err := clientcmd.BuildConfigFromFlags("", o.kubeconfig)
+	if err != nil {
+		return err
+	}
+	o.operatorClient, err = operatorclientv1.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	o.kubeClient, err = kubernetes.NewForConfig(restConfig)
+	return err
+}
+
+// Run contains the logic of the explain-config command.
+func (o *explainOpts) Run(ctx context.Context) error {
+	operatorSpec, err := o.operatorSpec(ctx)
+	if err != nil {
+		return err
+	}
+
+	signer, err := o.externalJWTSigner(ctx)
+	if err != nil {
+		return err
+	}
+
+	values, err := Explain(operatorSpec, signer)
+	if err != nil {
+		return err
+	}
+	if len(o.pathPrefix) > 0 {
+		filtered := values[:0]
+		for _, value := range values {
+			if strings.HasPrefix(value.Path, o.pathPrefix) {
+				filtered = append(filtered, value)
+			}
+		}
+		values = filtered
+	}
+
+	if o.output == outputJSON {
+		data, err := json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(o.out, string(data))
+		return err
+	}
+	return writeTable(o.out, values)
+}
+
+// operatorSpec returns the operator spec to explain, the one of the cluster or
+// of the operator config file, with the config files given on top.
+func (o *explainOpts) operatorSpec(ctx context.Context) (*operatorv1.StaticPodOperatorSpec, error) {
+	kubeAPIServer := &operatorv1.KubeAPIServer{}
+	switch {
+	case o.operatorClient != nil:
+		var err error
+		kubeAPIServer, err = o.operatorClient.KubeAPIServers().Get(ctx, "cluster", metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+	case len(o.operatorConfigFile) > 0:
+		data, err := os.ReadFile(o.operatorConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(data, kubeAPIServer); err != nil {
+			return nil, fmt.Errorf("invalid operator config %s: %w", o.operatorConfigFile, err)
+		}
+	}
+	operatorSpec := &kubeAPIServer.Spec.StaticPodOperatorSpec
+
+	for file, raw := range map[string]*[]byte{
+		o.observedConfigFile:             &operatorSpec.ObservedConfig.Raw,
+		o.unsupportedConfigOverridesFile: &operatorSpec.UnsupportedConfigOverrides.Raw,
+	} {
+		if len(file) == 0 {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if *raw, err = yaml.YAMLToJSON(data); err != nil {
+			return nil, fmt.Errorf("invalid config %s: %w", file, err)
+		}
+	}
+	return operatorSpec, nil
+}
+
+// externalJWTSigner returns the external JWT signer of the cluster. The
+// operator config files don't hold one.
+func (o *explainOpts) externalJWTSigner(ctx context.Context) (*externaljwtsigner.Config, error) {
+	if o.kubeClient == nil {
+		return nil, nil
+	}
+	configMap, err := operatorconfig.GetLive(ctx, o.kubeClient.CoreV1())
+	if err != nil {
+		return nil, err
+	}
+	signer, err := externaljwtsigner.ConfigFromConfigMap(configMap)
+	if err != nil {
+		klog.Warningf("Ignoring the external JWT signer: %v", err)
+		return nil, nil
+	}
+	return signer, nil
+}
+
+func writeTable(out io.Writer, values []ConfigValue) error {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tVALUE\tLAYER\tOBSERVER")
+	for _, value := range values {
+		data, err := json.Marshal(value.Value)
+		if err != nil {
+			return err
+		}
+		observer := value.Observer
+		if len(observer) == 0 {
+			observer = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", value.Path, data, strings.Join(value.Layers, ","), observer)
+	}
+	return w.Flush()
+}
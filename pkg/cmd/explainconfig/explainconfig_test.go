@@ -0,0 +1,102 @@
+package explainconfig
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+func TestExplain(t *testing.T) {
+	operatorSpec := &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+		ObservedConfig: runtime.RawExtension{Raw: []byte(`{
+  "apiServerArguments": {
+    "etcd-servers": ["https://10.0.0.1:2379"],
+    "enable-admission-plugins": ["CertificateApproval", "example.com/Restricted"]
+  },
+  "admission": {"pluginConfig": {"network.openshift.io/ExternalIPRanger": {"configuration": {"allowIngressIP": true}}}}
+}`)},
+		UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(`{"apiServerArguments":{"etcd-servers":["https://10.0.0.9:2379"],"v":["4"]}}`)},
+	}}
+
+	values, err := Explain(operatorSpec, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	explained := map[string]ConfigValue{}
+	for _, value := range values {
+		key := value.Path
+		if s, ok := value.Value.(string); ok && strings.HasSuffix(key, "admission-plugins") {
+			key += "/" + s
+		}
+		explained[key] = value
+	}
+
+	for key, expected := range map[string]ConfigValue{
+		".apiServerArguments.etcd-servers": {
+			Path:     ".apiServerArguments.etcd-servers",
+			Value:    []interface{}{"https://10.0.0.1:2379"},
+			Layers:   []string{"observedConfig"},
+			Observer: "etcdendpoints.ObserveStorageURLs",
+		},
+		".apiServerArguments.v": {
+			Path:   ".apiServerArguments.v",
+			Value:  []interface{}{"4"},
+			Layers: []string{"unsupportedConfigOverrides"},
+		},
+		".apiServerArguments.service-account-signing-key-file": {
+			Path:   ".apiServerArguments.service-account-signing-key-file",
+			Value:  []interface{}{"/etc/kubernetes/static-pod-certs/secrets/bound-service-account-signing-key/service-account.key"},
+			Layers: []string{"config-overrides.yaml"},
+		},
+		".apiServerArguments.authorization-mode": {
+			Path:   ".apiServerArguments.authorization-mode",
+			Value:  explained[".apiServerArguments.authorization-mode"].Value,
+			Layers: []string{"authorization-mode"},
+		},
+		".apiServerArguments.enable-admission-plugins/CertificateApproval": {
+			Path:     ".apiServerArguments.enable-admission-plugins",
+			Value:    "CertificateApproval",
+			Layers:   []string{"defaultconfig.yaml", "observedConfig"},
+			Observer: "apiserver.ObserveAdmissionPlugins",
+		},
+		".apiServerArguments.enable-admission-plugins/example.com/Restricted": {
+			Path:     ".apiServerArguments.enable-admission-plugins",
+			Value:    "example.com/Restricted",
+			Layers:   []string{"observedConfig"},
+			Observer: "apiserver.ObserveAdmissionPlugins",
+		},
+		`.admission.pluginConfig."network.openshift.io/ExternalIPRanger".configuration.allowIngressIP`: {
+			Path:     `.admission.pluginConfig."network.openshift.io/ExternalIPRanger".configuration.allowIngressIP`,
+			Value:    true,
+			Layers:   []string{"observedConfig"},
+			Observer: "network.ObserveExternalIPPolicy",
+		},
+	} {
+		if diff := cmp.Diff(expected, explained[key]); diff != "" {
+			t.Errorf("unexpected explanation of %s (-want +got):\n%s", key, diff)
+		}
+	}
+	for _, value := range values {
+		if value.Layers[0] == unknownLayer {
+			t.Errorf("no layer found for %s", value.Path)
+		}
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	out := &bytes.Buffer{}
+	if err := writeTable(out, []ConfigValue{{Path: ".apiServerArguments.v", Value: []interface{}{"4"}, Layers: []string{"unsupportedConfigOverrides"}}}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `PATH                   VALUE  LAYER                       OBSERVER
+.apiServerArguments.v  ["4"]  unsupportedConfigOverrides  -
+`
+	if diff := cmp.Diff(expected, out.String()); diff != "" {
+		t.Errorf("unexpected table (-want +got):\n%s", diff)
+	}
+}
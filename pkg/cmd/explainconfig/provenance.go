@@ -0,0 +1,179 @@
+package explainconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation/configobservercontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/externaljwtsigner"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/targetconfigcontroller"
+)
+
+// unknownLayer is reported for the values no layer sets, which would be a bug
+// of the explanation.
+const unknownLayer = "unknown"
+
+// ConfigValue is a leaf of the rendered kube-apiserver config along with the
+// layers it comes from. The elements of the merged slices are listed one by one.
+type ConfigValue struct {
+	Path     string      `json:"path"`
+	Value    interface{} `json:"value"`
+	Layers   []string    `json:"layers"`
+	Observer string      `json:"observer,omitempty"`
+}
+
+type parsedLayer struct {
+	name   string
+	config map[string]interface{}
+}
+
+// Explain renders the kube-apiserver config for the given operator spec and
+// external JWT signer the way the TargetConfigController does, and returns
+// where each of its values comes from.
+func Explain(operatorSpec *operatorv1.StaticPodOperatorSpec, signer *externaljwtsigner.Config) ([]ConfigValue, error) {
+	rendered, err := targetconfigcontroller.RenderKubeAPIServerConfig(operatorSpec, signer)
+	if err != nil {
+		return nil, err
+	}
+	config := map[string]interface{}{}
+	if err := json.Unmarshal(rendered, &config); err != nil {
+		return nil, err
+	}
+
+	configLayers, err := targetconfigcontroller.KubeAPIServerConfigLayers(operatorSpec)
+	if err != nil {
+		return nil, err
+	}
+	layers := make([]parsedLayer, 0, len(configLayers))
+	for _, layer := range configLayers {
+		if len(layer.Config) == 0 {
+			continue
+		}
+		layerJSON, err := yaml.YAMLToJSON(layer.Config)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", layer.Name, err)
+		}
+		parsed := map[string]interface{}{}
+		if err := json.Unmarshal(layerJSON, &parsed); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", layer.Name, err)
+		}
+		layers = append(layers, parsedLayer{name: layer.Name, config: parsed})
+	}
+	ignoredOverrides, err := targetconfigcontroller.IgnoredConfigOverrides(operatorSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []ConfigValue
+	walkLeaves(nil, config, func(path []string, value interface{}) {
+		mergePath := "." + strings.Join(path, ".")
+		// the unsupportedConfigOverrides of the protected paths are dropped
+		candidates := layers
+		if hasPathPrefix(mergePath, ignoredOverrides) {
+			candidates = nil
+			for _, layer := range layers {
+				if layer.name != targetconfigcontroller.UnsupportedConfigOverridesLayer {
+					candidates = append(candidates, layer)
+				}
+			}
+		}
+
+		if elements, ok := value.([]interface{}); ok && hasPathPrefix(mergePath, targetconfigcontroller.SliceMergedConfigPaths) {
+			for _, element := range elements {
+				var from []string
+				for _, layer := range candidates {
+					if layerValue, found, _ := unstructured.NestedFieldNoCopy(layer.config, path...); found && sliceContains(layerValue, element) {
+						from = append(from, layer.name)
+					}
+				}
+				values = append(values, newConfigValue(path, element, from))
+			}
+			return
+		}
+
+		var from []string
+		for i := len(candidates) - 1; i >= 0; i-- {
+			if _, found, _ := unstructured.NestedFieldNoCopy(candidates[i].config, path...); found {
+				from = []string{candidates[i].name}
+				break
+			}
+		}
+		values = append(values, newConfigValue(path, value, from))
+	})
+	return values, nil
+}
+
+func newConfigValue(path []string, value interface{}, layers []string) ConfigValue {
+	configValue := ConfigValue{Path: displayPath(path), Value: value, Layers: layers}
+	if len(layers) == 0 {
+		configValue.Layers = []string{unknownLayer}
+	}
+	for _, layer := range layers {
+		if layer == targetconfigcontroller.ObservedConfigLayer {
+			configValue.Observer = configobservercontroller.ObserverFor(path)
+		}
+	}
+	return configValue
+}
+
+// walkLeaves calls fn for every value of config that isn't a non-empty map, in
+// path order.
+func walkLeaves(path []string, config map[string]interface{}, fn func(path []string, value interface{})) {
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		childPath := append(append([]string{}, path...), key)
+		if child, ok := config[key].(map[string]interface{}); ok && len(child) > 0 {
+			walkLeaves(childPath, child, fn)
+			continue
+		}
+		fn(childPath, config[key])
+	}
+}
+
+// displayPath joins the path the way the merge rules name it, quoting the keys
+// that contain dots, like the admission plugin names.
+func displayPath(path []string) string {
+	var b strings.Builder
+	for _, key := range path {
+		b.WriteString(".")
+		if strings.Contains(key, ".") {
+			b.WriteString(fmt.Sprintf("%q", key))
+			continue
+		}
+		b.WriteString(key)
+	}
+	return b.String()
+}
+
+func hasPathPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func sliceContains(slice, element interface{}) bool {
+	elements, ok := slice.([]interface{})
+	if !ok {
+		return false
+	}
+	for _, e := range elements {
+		if reflect.DeepEqual(e, element) {
+			return true
+		}
+	}
+	return false
+}
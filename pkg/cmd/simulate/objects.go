@@ -0,0 +1,300 @@
+package simulate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	kyaml "k8s.io/apimachinery/pkg/util/yaml"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/resourcesynccontroller"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+var (
+	scheme = runtime.NewScheme()
+	codecs = serializer.NewCodecFactory(scheme)
+)
+
+func init() {
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(configv1.Install(scheme))
+	utilruntime.Must(operatorv1.Install(scheme))
+}
+
+// objectStore holds the objects loaded from the manifests, one indexer per
+// kind the config observers and the target config list.
+type objectStore struct {
+	apiServers      cache.Indexer
+	authentications cache.Indexer
+	featureGates    cache.Indexer
+	images          cache.Indexer
+	infrastructures cache.Indexer
+	networks        cache.Indexer
+	nodes           cache.Indexer
+	proxies         cache.Indexer
+	schedulers      cache.Indexer
+	kubeAPIServers  cache.Indexer
+	kubeNodes       cache.Indexer
+	configMaps      cache.Indexer
+	secrets         cache.Indexer
+}
+
+func newObjectStore() *objectStore {
+	clusterScoped := func() cache.Indexer {
+		return cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	}
+	namespaced := func() cache.Indexer {
+		return cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	}
+	return &objectStore{
+		apiServers:      clusterScoped(),
+		authentications: clusterScoped(),
+		featureGates:    clusterScoped(),
+		images:          clusterScoped(),
+		infrastructures: clusterScoped(),
+		networks:        clusterScoped(),
+		nodes:           clusterScoped(),
+		proxies:         clusterScoped(),
+		schedulers:      clusterScoped(),
+		kubeAPIServers:  clusterScoped(),
+		kubeNodes:       clusterScoped(),
+		configMaps:      namespaced(),
+		secrets:         namespaced(),
+	}
+}
+
+// loadPath loads the manifests of the given file, or of the .yaml, .yml and
+// .json files of the given directory tree.
+func (s *objectStore) loadPath(path string) error {
+	return filepath.WalkDir(path, func(file string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		if file != path {
+			switch strings.ToLower(filepath.Ext(file)) {
+			case ".yaml", ".yml", ".json":
+			default:
+				return nil
+			}
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if err := s.loadManifests(data); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		return nil
+	})
+}
+
+// loadManifests loads every object of the given YAML or JSON documents, lists
+// included. Objects of kinds the simulation doesn't use are skipped.
+func (s *objectStore) loadManifests(data []byte) error {
+	decoder := kyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		raw := runtime.RawExtension{}
+		if err := decoder.Decode(&raw); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if len(bytes.TrimSpace(raw.Raw)) == 0 || string(bytes.TrimSpace(raw.Raw)) == "null" {
+			continue
+		}
+		if err := s.loadObject(raw.Raw); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *objectStore) loadObject(data []byte) error {
+	obj, gvk, err := codecs.UniversalDeserializer().Decode(data, nil, nil)
+	if runtime.IsNotRegisteredError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if meta.IsListType(obj) {
+		return meta.EachListItem(obj, func(item runtime.Object) error {
+			if unknown, ok := item.(*runtime.Unknown); ok {
+				return s.loadObject(unknown.Raw)
+			}
+			return s.add(item)
+		})
+	}
+	if err := s.add(obj); err != nil {
+		return fmt.Errorf("%s: %w", gvk.Kind, err)
+	}
+	return nil
+}
+
+func (s *objectStore) add(obj runtime.Object) error {
+	var indexer cache.Indexer
+	switch obj.(type) {
+	case *configv1.APIServer:
+		indexer = s.apiServers
+	case *configv1.Authentication:
+		indexer = s.authentications
+	case *configv1.FeatureGate:
+		indexer = s.featureGates
+	case *configv1.Image:
+		indexer = s.images
+	case *configv1.Infrastructure:
+		indexer = s.infrastructures
+	case *configv1.Network:
+		indexer = s.networks
+	case *configv1.Node:
+		indexer = s.nodes
+	case *configv1.Proxy:
+		indexer = s.proxies
+	case *configv1.Scheduler:
+		indexer = s.schedulers
+	case *operatorv1.KubeAPIServer:
+		indexer = s.kubeAPIServers
+	case *corev1.Node:
+		indexer = s.kubeNodes
+	case *corev1.ConfigMap:
+		indexer = s.configMaps
+	case *corev1.Secret:
+		indexer = s.secrets
+	default:
+		return nil
+	}
+	// later manifests replace earlier ones, so proposed changes can be
+	// layered on top of a dump of the cluster
+	return indexer.Update(obj)
+}
+
+// listers returns the listers the config observers get, backed by the
+// loaded objects.
+func (s *objectStore) listers(resourceSyncer resourcesynccontroller.ResourceSyncer) configobservation.Listers {
+	secretLister := corev1listers.NewSecretLister(s.secrets)
+	return configobservation.Listers{
+		APIServerLister_:      configv1listers.NewAPIServerLister(s.apiServers),
+		AuthConfigLister:      configv1listers.NewAuthenticationLister(s.authentications),
+		FeatureGateLister_:    configv1listers.NewFeatureGateLister(s.featureGates),
+		ImageConfigLister:     configv1listers.NewImageLister(s.images),
+		InfrastructureLister_: configv1listers.NewInfrastructureLister(s.infrastructures),
+		NetworkLister:         configv1listers.NewNetworkLister(s.networks),
+		NodeLister_:           configv1listers.NewNodeLister(s.nodes),
+		ProxyLister_:          configv1listers.NewProxyLister(s.proxies),
+		SchedulerLister:       configv1listers.NewSchedulerLister(s.schedulers),
+
+		SecretLister_:       secretLister,
+		ConfigSecretLister_: secretLister,
+		ConfigmapLister_:    corev1listers.NewConfigMapLister(s.configMaps),
+		KubeNodeLister:      corev1listers.NewNodeLister(s.kubeNodes),
+
+		KubeAPIServerOperatorLister_: operatorv1listers.NewKubeAPIServerLister(s.kubeAPIServers),
+
+		ResourceSync: resourceSyncer,
+	}
+}
+
+// kubeAPIServer returns the loaded KubeAPIServer, or an empty managed one.
+func (s *objectStore) kubeAPIServer() *operatorv1.KubeAPIServer {
+	obj, exists, _ := s.kubeAPIServers.GetByKey("cluster")
+	if !exists {
+		kubeAPIServer := &operatorv1.KubeAPIServer{}
+		kubeAPIServer.Name = "cluster"
+		kubeAPIServer.Spec.ManagementState = operatorv1.Managed
+		return kubeAPIServer
+	}
+	return obj.(*operatorv1.KubeAPIServer).DeepCopy()
+}
+
+// targetConfigMap returns the given ConfigMap of the target namespace, or nil.
+func (s *objectStore) targetConfigMap(name string) *corev1.ConfigMap {
+	obj, exists, _ := s.configMaps.GetByKey(operatorclient.TargetNamespace + "/" + name)
+	if !exists {
+		return nil
+	}
+	return obj.(*corev1.ConfigMap)
+}
+
+// resourceSync is a sync of a ConfigMap or a Secret requested by a config
+// observer.
+type resourceSync struct {
+	kind                string
+	destination, source resourcesynccontroller.ResourceLocation
+}
+
+func (r resourceSync) String() string {
+	if len(r.source.Name) == 0 {
+		return fmt.Sprintf("delete %s %s/%s", r.kind, r.destination.Namespace, r.destination.Name)
+	}
+	return fmt.Sprintf("copy %s %s/%s to %s/%s", r.kind, r.source.Namespace, r.source.Name, r.destination.Namespace, r.destination.Name)
+}
+
+// recordingResourceSyncer records the syncs the config observers request
+// instead of performing them.
+type recordingResourceSyncer struct {
+	syncs []resourceSync
+}
+
+func (r *recordingResourceSyncer) SyncConfigMap(destination, source resourcesynccontroller.ResourceLocation) error {
+	r.syncs = append(r.syncs, resourceSync{kind: "configmap", destination: destination, source: source})
+	return nil
+}
+
+func (r *recordingResourceSyncer) SyncSecret(destination, source resourcesynccontroller.ResourceLocation) error {
+	r.syncs = append(r.syncs, resourceSync{kind: "secret", destination: destination, source: source})
+	return nil
+}
+
+// syncedData returns the data of the ConfigMap or the Secret at the given
+// location, or nil when it isn't among the manifests.
+func (s *objectStore) syncedData(kind string, location resourcesynccontroller.ResourceLocation) map[string]string {
+	if len(location.Name) == 0 {
+		return nil
+	}
+	indexer := s.configMaps
+	if kind == "secret" {
+		indexer = s.secrets
+	}
+	obj, exists, _ := indexer.GetByKey(location.Namespace + "/" + location.Name)
+	if !exists {
+		return nil
+	}
+	data := map[string]string{}
+	switch obj := obj.(type) {
+	case *corev1.ConfigMap:
+		for key, value := range obj.Data {
+			data[key] = value
+		}
+		for key, value := range obj.BinaryData {
+			data[key] = string(value)
+		}
+	case *corev1.Secret:
+		for key, value := range obj.Data {
+			data[key] = string(value)
+		}
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return data
+}
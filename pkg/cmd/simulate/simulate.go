@@ -0,0 +1,467 @@
+package simulate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/google/go-cmp/cmp"
+	"github.com/imdario/mergo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/yaml"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation/apienablement"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation/configobservercontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/startupmonitorreadiness"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/targetconfigcontroller"
+)
+
+// simulateOpts holds values to drive the simulate command.
+type simulateOpts struct {
+	manifests            []string
+	version              string
+	kubernetesVersion    string
+	image                string
+	operatorImage        string
+	operatorImageVersion string
+	out                  io.Writer
+}
+
+// simulation is the outcome of the config observers and the target config for
+// the loaded objects, along with the current state it replaces.
+type simulation struct {
+	currentObservedConfig map[string]interface{}
+	observedConfig        map[string]interface{}
+	currentConfig         *corev1.ConfigMap
+	config                *corev1.ConfigMap
+	currentPod            *corev1.ConfigMap
+	pod                   *corev1.ConfigMap
+	resourceSyncs         []resourceSync
+	// the revisioned resources of the target namespace the resource syncs
+	// change
+	changedRevisionResources []string
+	observerErrors           []error
+	invalidArguments         []string
+}
+
+// NewSimulateCommand creates a simulate command.
+func NewSimulateCommand() *cobra.Command {
+	opts := simulateOpts{
+		out: os.Stdout,
+	}
+	cmd := &cobra.Command{
+		Use:   "simulate -f <manifests>...",
+		Short: "Replay the config observers and the target config offline on cluster manifests, and show the resulting kube-apiserver config and pod changes",
+		Long: `Replay the config observers and the target config offline on cluster manifests, and show the resulting kube-apiserver config and pod changes.
+
+The manifests are the config.openshift.io objects, the KubeAPIServer, the ConfigMaps and the Secrets
+the operator reads, e.g. from a must-gather or "oc get -o yaml". Later manifests replace earlier ones,
+so a proposed change can be passed after a dump of the cluster. The current state is the one of the
+"config" and "kube-apiserver-pod" ConfigMaps of openshift-kube-apiserver among the manifests, and the
+one of the ConfigMaps and the Secrets the resource syncs copy into openshift-kube-apiserver.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := opts.Validate(); err != nil {
+				klog.Fatal(err)
+			}
+			if err := opts.Run(cmd.Context()); err != nil {
+				klog.Fatal(err)
+			}
+		},
+	}
+
+	opts.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (o *simulateOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringSliceVarP(&o.manifests, "filename", "f", o.manifests, "Manifest files or directories to load, in order")
+	fs.StringVar(&o.version, "version", o.version, "Cluster version whose feature gates apply, required when the FeatureGate lists several")
+	fs.StringVar(&o.kubernetesVersion, "kubernetes-version", o.kubernetesVersion, "Kubernetes version of the kube-apiserver, selecting the APIs enabled by feature gates")
+	fs.StringVar(&o.image, "image", o.image, "kube-apiserver image, defaults to the one of the current pod")
+	fs.StringVar(&o.operatorImage, "operator-image", o.operatorImage, "Operator image, defaults to the one of the current pod")
+	fs.StringVar(&o.operatorImageVersion, "operator-image-version", o.operatorImageVersion, "Operator image version, defaults to the one of the current pod")
+}
+
+// Validate verifies the inputs.
+func (o *simulateOpts) Validate() error {
+	if len(o.manifests) == 0 {
+		return fmt.Errorf("at least one manifest file or directory is required")
+	}
+	if len(o.kubernetesVersion) > 0 {
+		if _, err := semver.Parse(o.kubernetesVersion); err != nil {
+			return fmt.Errorf("invalid --kubernetes-version: %w", err)
+		}
+	}
+	return nil
+}
+
+// Run contains the logic of the simulate command.
+func (o *simulateOpts) Run(ctx context.Context) error {
+	store := newObjectStore()
+	for _, path := range o.manifests {
+		if err := store.loadPath(path); err != nil {
+			return err
+		}
+	}
+
+	result, err := o.simulate(ctx, store)
+	if err != nil {
+		return err
+	}
+	return writeReport(o.out, result)
+}
+
+// simulate runs the config observers the way the ConfigObserver does, then
+// renders the config and the pod the way the TargetConfigController does.
+func (o *simulateOpts) simulate(ctx context.Context, store *objectStore) (*simulation, error) {
+	kubeAPIServer := store.kubeAPIServer()
+	operatorSpec := &kubeAPIServer.Spec.StaticPodOperatorSpec
+	operatorClient := v1helpers.NewFakeStaticPodOperatorClient(operatorSpec.DeepCopy(), kubeAPIServer.Status.StaticPodOperatorStatus.DeepCopy(), nil, nil)
+
+	featureGateAccessor, err := o.featureGateAccessor(store)
+	if err != nil {
+		return nil, err
+	}
+	var kubernetesVersion semver.Version
+	if len(o.kubernetesVersion) > 0 {
+		if kubernetesVersion, err = semver.Parse(o.kubernetesVersion); err != nil {
+			return nil, err
+		}
+	}
+	groupVersionsByFeatureGate, err := apienablement.GetDefaultGroupVersionByFeatureGate(kubernetesVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &simulation{
+		currentObservedConfig: map[string]interface{}{},
+		observedConfig:        map[string]interface{}{},
+		currentConfig:         store.targetConfigMap("config"),
+		currentPod:            store.targetConfigMap("kube-apiserver-pod"),
+	}
+	// like the ConfigObserver, start over when the existing config can't be decoded
+	if err := json.Unmarshal(operatorSpec.ObservedConfig.Raw, &result.currentObservedConfig); err != nil {
+		klog.V(4).Infof("decode of existing config failed with error: %v", err)
+	}
+
+	resourceSyncer := &recordingResourceSyncer{}
+	listers := store.listers(resourceSyncer)
+	recorder := events.NewInMemoryRecorder("simulate", clock.RealClock{})
+	for _, observe := range configobservercontroller.Observers(operatorClient, listers.ConfigMapLister(), featureGateAccessor, groupVersionsByFeatureGate) {
+		observedConfig, errs := observe(listers, recorder, runtime.DeepCopyJSON(result.currentObservedConfig))
+		result.observerErrors = append(result.observerErrors, errs...)
+		if err := mergo.Merge(&result.observedConfig, observedConfig); err != nil {
+			result.observerErrors = append(result.observerErrors, fmt.Errorf("merging observed config failed: %w", err))
+		}
+	}
+	result.resourceSyncs = resourceSyncer.syncs
+	result.changedRevisionResources = changedRevisionResources(store, resourceSyncer.syncs)
+
+	operatorSpec.ObservedConfig.Raw, err = json.Marshal(result.observedConfig)
+	if err != nil {
+		return nil, err
+	}
+	// compare with the current observed config as it's stored
+	result.observedConfig = map[string]interface{}{}
+	if err := json.Unmarshal(operatorSpec.ObservedConfig.Raw, &result.observedConfig); err != nil {
+		return nil, err
+	}
+	signer, err := targetconfigcontroller.ExternalJWTSigner(listers.ConfigMapLister())
+	if err != nil {
+		return nil, err
+	}
+	podResources, err := targetconfigcontroller.ConfiguredPodResources(listers.ConfigMapLister())
+	if err != nil {
+		return nil, err
+	}
+	config, err := targetconfigcontroller.RenderKubeAPIServerConfig(operatorSpec, signer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the config: %w", err)
+	}
+	result.config = &corev1.ConfigMap{Data: map[string]string{"config.yaml": string(config)}}
+	result.invalidArguments, err = targetconfigcontroller.ValidateKubeAPIServerConfig(operatorSpec, signer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate the config: %w", err)
+	}
+
+	image, operatorImage, operatorImageVersion := imagesFromPod(result.currentPod)
+	if len(o.image) > 0 {
+		image = o.image
+	}
+	if len(o.operatorImage) > 0 {
+		operatorImage = o.operatorImage
+	}
+	if len(o.operatorImageVersion) > 0 {
+		operatorImageVersion = o.operatorImageVersion
+	}
+	var secrets []runtime.Object
+	for _, secret := range store.secrets.List() {
+		secrets = append(secrets, secret.(runtime.Object))
+	}
+	result.pod, err = targetconfigcontroller.RenderKubeAPIServerPodConfigMap(
+		ctx,
+		fake.NewSimpleClientset(secrets...).CoreV1(),
+		featureGateAccessor,
+		startupmonitorreadiness.IsStartupMonitorEnabledFunction(listers.InfrastructureLister(), operatorClient),
+		operatorSpec,
+		signer,
+		podResources,
+		image, operatorImage, operatorImageVersion,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the pod: %w", err)
+	}
+	if result.currentPod != nil {
+		// the version is the one of the binary running the simulation, not of
+		// the operator in the cluster
+		result.pod.Data["version"] = result.currentPod.Data["version"]
+	}
+
+	return result, nil
+}
+
+// changedRevisionResources returns the ConfigMaps and the Secrets of the
+// operator revisions that the given resource syncs change, as kind/name.
+func changedRevisionResources(store *objectStore, syncs []resourceSync) []string {
+	var changed []string
+	for _, sync := range syncs {
+		if sync.destination.Namespace != operatorclient.TargetNamespace || !isRevisionResource(sync.kind, sync.destination.Name) {
+			continue
+		}
+		if reflect.DeepEqual(store.syncedData(sync.kind, sync.source), store.syncedData(sync.kind, sync.destination)) {
+			continue
+		}
+		changed = append(changed, fmt.Sprintf("%s/%s", sync.kind, sync.destination.Name))
+	}
+	return changed
+}
+
+// isRevisionResource returns whether the operator copies the given ConfigMap
+// or Secret of the target namespace into its revisions, so that a change
+// rolls out a new revision.
+func isRevisionResource(kind, name string) bool {
+	resources := operator.RevisionConfigMaps
+	if kind == "secret" {
+		resources = operator.RevisionSecrets
+	}
+	for _, resource := range resources {
+		if resource.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// featureGateAccessor returns the feature gates of the loaded FeatureGate for
+// the cluster version.
+func (o *simulateOpts) featureGateAccessor(store *objectStore) (featuregates.FeatureGateAccess, error) {
+	obj, exists, _ := store.featureGates.GetByKey("cluster")
+	if !exists {
+		return nil, fmt.Errorf("the manifests must include the FeatureGate named cluster")
+	}
+	featureGate := obj.(*configv1.FeatureGate)
+	version := o.version
+	if len(version) == 0 {
+		if len(featureGate.Status.FeatureGates) != 1 {
+			return nil, fmt.Errorf("the FeatureGate lists the feature gates of %d versions, pick one with --version", len(featureGate.Status.FeatureGates))
+		}
+		version = featureGate.Status.FeatureGates[0].Version
+	}
+	return featuregates.NewHardcodedFeatureGateAccessFromFeatureGate(featureGate, version)
+}
+
+// imagesFromPod returns the images the given kube-apiserver-pod ConfigMap was
+// rendered with.
+func imagesFromPod(podConfigMap *corev1.ConfigMap) (image, operatorImage, operatorImageVersion string) {
+	if podConfigMap == nil {
+		return "", "", ""
+	}
+	pod := &corev1.Pod{}
+	if err := yaml.Unmarshal([]byte(podConfigMap.Data["pod.yaml"]), pod); err != nil {
+		klog.Warningf("Ignoring the images of the current pod: %v", err)
+		return "", "", ""
+	}
+	for _, container := range pod.Spec.Containers {
+		switch container.Name {
+		case "kube-apiserver":
+			image = container.Image
+		case "kube-apiserver-check-endpoints":
+			operatorImage = container.Image
+		}
+		for _, env := range container.Env {
+			if env.Name == "OPERATOR_IMAGE_VERSION" {
+				operatorImageVersion = env.Value
+			}
+		}
+	}
+	return image, operatorImage, operatorImageVersion
+}
+
+// changedKeys returns the keys whose value differs between the current and
+// the simulated ConfigMap, with the diff of their normalized YAML.
+func changedKeys(current, simulated *corev1.ConfigMap) ([]string, map[string]string, error) {
+	currentData := map[string]string{}
+	if current != nil {
+		currentData = current.Data
+	}
+	keys := map[string]bool{}
+	for key := range currentData {
+		keys[key] = true
+	}
+	for key := range simulated.Data {
+		keys[key] = true
+	}
+
+	var changed []string
+	diffs := map[string]string{}
+	for key := range keys {
+		currentYAML, err := normalizeYAML(currentData[key])
+		if err != nil {
+			return nil, nil, fmt.Errorf("current %s: %w", key, err)
+		}
+		simulatedYAML, err := normalizeYAML(simulated.Data[key])
+		if err != nil {
+			return nil, nil, fmt.Errorf("simulated %s: %w", key, err)
+		}
+		if diff := diffDocuments(currentYAML, simulatedYAML); len(diff) > 0 {
+			changed = append(changed, key)
+			diffs[key] = diff
+		}
+	}
+	sort.Strings(changed)
+	return changed, diffs, nil
+}
+
+// normalizeYAML returns the given YAML or JSON document as YAML with sorted
+// keys, so that documents only differing in their encoding compare equal.
+func normalizeYAML(document string) (string, error) {
+	if len(strings.TrimSpace(document)) == 0 {
+		return "", nil
+	}
+	documentJSON, err := yaml.YAMLToJSON([]byte(document))
+	if err != nil {
+		return "", err
+	}
+	documentYAML, err := yaml.JSONToYAML(documentJSON)
+	if err != nil {
+		return "", err
+	}
+	return string(documentYAML), nil
+}
+
+// diffDocuments returns the line diff between the given documents, or an
+// empty string when they are equal.
+func diffDocuments(current, simulated string) string {
+	if current == simulated {
+		return ""
+	}
+	if len(current) == 0 {
+		// cmp elides long strings that have no line in common
+		return "+ " + strings.ReplaceAll(strings.TrimSuffix(simulated, "\n"), "\n", "\n+ ") + "\n"
+	}
+	return cmp.Diff(current, simulated)
+}
+
+func writeReport(out io.Writer, result *simulation) error {
+	var observedConfigYAML [2]string
+	for i, observedConfig := range []map[string]interface{}{result.currentObservedConfig, result.observedConfig} {
+		if len(observedConfig) == 0 {
+			continue
+		}
+		observedConfigJSON, err := json.Marshal(observedConfig)
+		if err != nil {
+			return err
+		}
+		if observedConfigYAML[i], err = normalizeYAML(string(observedConfigJSON)); err != nil {
+			return err
+		}
+	}
+	if diff := diffDocuments(observedConfigYAML[0], observedConfigYAML[1]); len(diff) > 0 {
+		fmt.Fprintf(out, "observedConfig (-current +simulated):\n%s\n", diff)
+	} else {
+		fmt.Fprintf(out, "observedConfig: unchanged\n\n")
+	}
+
+	var revisionReasons []string
+	for _, configMap := range []struct {
+		name               string
+		current, simulated *corev1.ConfigMap
+	}{
+		{"config", result.currentConfig, result.config},
+		{"kube-apiserver-pod", result.currentPod, result.pod},
+	} {
+		if configMap.current == nil {
+			fmt.Fprintf(out, "configmap/%s: no current state among the manifests, comparing with an empty one\n", configMap.name)
+		}
+		changed, diffs, err := changedKeys(configMap.current, configMap.simulated)
+		if err != nil {
+			return fmt.Errorf("configmap/%s: %w", configMap.name, err)
+		}
+		if len(changed) == 0 {
+			fmt.Fprintf(out, "configmap/%s: unchanged\n\n", configMap.name)
+			continue
+		}
+		for _, key := range changed {
+			fmt.Fprintf(out, "configmap/%s %s (-current +simulated):\n%s\n", configMap.name, key, diffs[key])
+		}
+		if configMap.name == "config" && len(result.invalidArguments) > 0 {
+			// withheld by the operator
+			continue
+		}
+		if isRevisionResource("configmap", configMap.name) {
+			revisionReasons = append(revisionReasons, fmt.Sprintf("configmap/%s changed", configMap.name))
+		}
+	}
+	for _, resource := range result.changedRevisionResources {
+		revisionReasons = append(revisionReasons, fmt.Sprintf("%s changed by a resource sync", resource))
+	}
+
+	if len(result.resourceSyncs) > 0 {
+		fmt.Fprintln(out, "Resource syncs requested by the config observers:")
+		for _, sync := range result.resourceSyncs {
+			fmt.Fprintf(out, "  %s\n", sync)
+		}
+		fmt.Fprintln(out)
+	}
+	if len(result.observerErrors) > 0 {
+		fmt.Fprintln(out, "Config observer errors, the operator would report ConfigObservationDegraded:")
+		for _, err := range result.observerErrors {
+			fmt.Fprintf(out, "  %v\n", err)
+		}
+		fmt.Fprintln(out)
+	}
+	if len(result.invalidArguments) > 0 {
+		fmt.Fprintln(out, "Invalid apiServerArguments, the operator would report APIServerArgumentsDegraded and keep the current configmap/config:")
+		for _, invalid := range result.invalidArguments {
+			fmt.Fprintf(out, "  %s\n", invalid)
+		}
+		fmt.Fprintln(out)
+	}
+
+	if len(revisionReasons) > 0 {
+		_, err := fmt.Fprintf(out, "A new revision would be rolled out: %s\n", strings.Join(revisionReasons, ", "))
+		return err
+	}
+	_, err := fmt.Fprintln(out, "No new revision would be rolled out")
+	return err
+}
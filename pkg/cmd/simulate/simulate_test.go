@@ -0,0 +1,231 @@
+package simulate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/api/features"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/resourcesynccontroller"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+func TestSimulate(t *testing.T) {
+	dir := t.TempDir()
+	writeManifests(t, filepath.Join(dir, "cluster.yaml"), defaultFeatureGate(), &configv1.Infrastructure{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "config.openshift.io/v1", Kind: "Infrastructure"},
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status: configv1.InfrastructureStatus{
+			APIServerURL:           "https://api.example.com:6443",
+			ControlPlaneTopology:   configv1.HighlyAvailableTopologyMode,
+			InfrastructureTopology: configv1.HighlyAvailableTopologyMode,
+		},
+	}, &configv1.Network{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "config.openshift.io/v1", Kind: "Network"},
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status:     configv1.NetworkStatus{ServiceNetwork: []string{"172.30.0.0/16"}},
+	}, &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-etcd", Name: "etcd-endpoints"},
+		Data:       map[string]string{"member-1": "10.0.0.1"},
+	})
+	// the proposed change
+	writeManifests(t, filepath.Join(dir, "proposed.yaml"), &configv1.APIServer{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "config.openshift.io/v1", Kind: "APIServer"},
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: configv1.APIServerSpec{TLSSecurityProfile: &configv1.TLSSecurityProfile{
+			Type:   configv1.TLSProfileModernType,
+			Modern: &configv1.ModernTLSProfile{},
+		}},
+	})
+
+	opts := &simulateOpts{
+		manifests: []string{filepath.Join(dir, "cluster.yaml"), filepath.Join(dir, "proposed.yaml")},
+		image:     "kube-apiserver:test",
+	}
+	store := newObjectStore()
+	for _, path := range opts.manifests {
+		if err := store.loadPath(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result, err := opts.simulate(context.Background(), store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result.config.Data["config.yaml"], `"minTLSVersion":"VersionTLS13"`) {
+		t.Errorf("expected the Modern TLS profile in the config, got %s", result.config.Data["config.yaml"])
+	}
+	if !strings.Contains(result.config.Data["config.yaml"], `"https://10.0.0.1:2379"`) {
+		t.Errorf("expected the etcd endpoints in the config, got %s", result.config.Data["config.yaml"])
+	}
+	out := &bytes.Buffer{}
+	if err := writeReport(out, result); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "A new revision would be rolled out: configmap/config changed, configmap/kube-apiserver-pod changed") {
+		t.Errorf("expected a new revision, got:\n%s", out.String())
+	}
+
+	// once the operator applied the simulated state, simulating again changes
+	// nothing
+	observedConfig, err := json.Marshal(result.observedConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result.config.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"}
+	result.config.ObjectMeta = metav1.ObjectMeta{Namespace: operatorclient.TargetNamespace, Name: "config"}
+	result.pod.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"}
+	writeManifests(t, filepath.Join(dir, "current.yaml"), &operatorv1.KubeAPIServer{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "operator.openshift.io/v1", Kind: "KubeAPIServer"},
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: operatorv1.KubeAPIServerSpec{StaticPodOperatorSpec: operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+			ManagementState: operatorv1.Managed,
+			ObservedConfig:  runtime.RawExtension{Raw: observedConfig},
+		}}},
+	}, result.config, result.pod)
+
+	opts.manifests = append(opts.manifests, filepath.Join(dir, "current.yaml"))
+	opts.image = ""
+	out.Reset()
+	opts.out = out
+	if err := opts.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"observedConfig: unchanged", "configmap/config: unchanged", "configmap/kube-apiserver-pod: unchanged", "No new revision would be rolled out"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q, got:\n%s", expected, out.String())
+		}
+	}
+}
+
+func TestFeatureGateAccessor(t *testing.T) {
+	featureGate := defaultFeatureGate()
+	featureGate.Status.FeatureGates = append(featureGate.Status.FeatureGates, configv1.FeatureGateDetails{Version: "4.23.0"})
+	store := newObjectStore()
+	if err := store.add(featureGate); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := (&simulateOpts{}).featureGateAccessor(store); err == nil {
+		t.Errorf("expected an error without a version when the FeatureGate lists several")
+	}
+	if _, err := (&simulateOpts{version: "4.22.0"}).featureGateAccessor(store); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := (&simulateOpts{version: "4.21.0"}).featureGateAccessor(store); err == nil {
+		t.Errorf("expected an error for a version the FeatureGate doesn't list")
+	}
+}
+
+func defaultFeatureGate() *configv1.FeatureGate {
+	details := configv1.FeatureGateDetails{Version: "4.22.0"}
+	featureSet := features.FeatureSets(4, features.SelfManaged, configv1.Default)
+	for _, enabled := range featureSet.Enabled {
+		details.Enabled = append(details.Enabled, enabled.FeatureGateAttributes)
+	}
+	for _, disabled := range featureSet.Disabled {
+		details.Disabled = append(details.Disabled, disabled.FeatureGateAttributes)
+	}
+	return &configv1.FeatureGate{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "config.openshift.io/v1", Kind: "FeatureGate"},
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status:     configv1.FeatureGateStatus{FeatureGates: []configv1.FeatureGateDetails{details}},
+	}
+}
+
+func writeManifests(t *testing.T, file string, objs ...runtime.Object) {
+	t.Helper()
+	var documents []string
+	for _, obj := range objs {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		documents = append(documents, string(data))
+	}
+	if err := os.WriteFile(file, []byte(strings.Join(documents, "---\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChangedRevisionResources(t *testing.T) {
+	store := newObjectStore()
+	for _, obj := range []runtime.Object{
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config-managed", Name: "encryption-config-openshift-kube-apiserver"},
+			Data:       map[string][]byte{"encryption-config": []byte("new")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.TargetNamespace, Name: "encryption-config"},
+			Data:       map[string][]byte{"encryption-config": []byte("old")},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.TargetNamespace, Name: "cloud-config"},
+			Data:       map[string]string{"config": "old"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "user-ca"},
+			Data:       map[string]string{"ca-bundle.crt": "new"},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "webhook"},
+			Data:       map[string][]byte{"kubeConfig": []byte("same")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.TargetNamespace, Name: "webhook-authenticator"},
+			Data:       map[string][]byte{"kubeConfig": []byte("same")},
+		},
+	} {
+		if err := store.add(obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+	location := func(namespace, name string) resourcesynccontroller.ResourceLocation {
+		return resourcesynccontroller.ResourceLocation{Namespace: namespace, Name: name}
+	}
+	syncs := []resourceSync{
+		{kind: "secret", destination: location(operatorclient.TargetNamespace, "encryption-config"), source: location("openshift-config-managed", "encryption-config-openshift-kube-apiserver")},
+		{kind: "configmap", destination: location(operatorclient.TargetNamespace, "cloud-config")},
+		// not copied into the revisions
+		{kind: "configmap", destination: location(operatorclient.TargetNamespace, "user-client-ca"), source: location("openshift-config", "user-ca")},
+		// unchanged
+		{kind: "secret", destination: location(operatorclient.TargetNamespace, "webhook-authenticator"), source: location("openshift-config", "webhook")},
+		// already deleted
+		{kind: "secret", destination: location(operatorclient.TargetNamespace, "encryption-config-unused")},
+	}
+
+	changed := changedRevisionResources(store, syncs)
+	expected := []string{"secret/encryption-config", "configmap/cloud-config"}
+	if !reflect.DeepEqual(expected, changed) {
+		t.Errorf("expected %v, got %v", expected, changed)
+	}
+
+	out := &bytes.Buffer{}
+	if err := writeReport(out, &simulation{
+		config:                   &corev1.ConfigMap{},
+		pod:                      &corev1.ConfigMap{},
+		currentConfig:            &corev1.ConfigMap{},
+		currentPod:               &corev1.ConfigMap{},
+		resourceSyncs:            syncs,
+		changedRevisionResources: changed,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "A new revision would be rolled out: secret/encryption-config changed by a resource sync, configmap/cloud-config changed by a resource sync") {
+		t.Errorf("expected a new revision, got:\n%s", out.String())
+	}
+}
@@ -3,11 +3,9 @@ package boundsatokensignercontroller
 import (
 	"bytes"
 	"context"
-	"crypto/rand"
-	"crypto/rsa"
-	"crypto/x509"
-	"encoding/pem"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -16,24 +14,27 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	errorsutil "k8s.io/apimachinery/pkg/util/errors"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
-	"k8s.io/client-go/util/keyutil"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 
+	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/externaljwtsigner"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
 )
 
 const (
 	operatorNamespace = operatorclient.OperatorNamespace
 	targetNamespace   = operatorclient.TargetNamespace
 
-	keySize = 2048
 	// A new keypair will first be written to this secret in the operator namespace...
 	NextSigningKeySecretName = "next-bound-service-account-signing-key"
 	// ...and will copied to this secret in the operand namespace once
@@ -43,14 +44,27 @@ const (
 	PublicKeyKey         = "service-account.pub"
 
 	PublicKeyConfigMapName = "bound-sa-token-signing-certs"
+
+	// signingKeyRotationConditionType is true while a new signing key is on its
+	// way to the nodes. It doesn't end in Progressing, a routine rotation is
+	// not a change of the operator version.
+	signingKeyRotationConditionType = "BoundSATokenSigningKeyRotating"
 )
 
 // BoundSATokenSignerController manages the keypair used to sign bound
-// tokens and the key bundle used to verify them.
+// tokens and the key bundle used to verify them. With an external JWT signer,
+// it publishes the public keys of the signer in the key bundle instead.
 type BoundSATokenSignerController struct {
 	operatorClient  v1helpers.StaticPodOperatorClient
 	secretClient    corev1client.SecretsGetter
 	configMapClient corev1client.ConfigMapsGetter
+	// operatorConfigLister reads the signing key rotation and the external
+	// JWT signer settings.
+	operatorConfigLister corev1listers.ConfigMapLister
+	// keyFetcher returns the public keys of the external JWT signer.
+	keyFetcher externaljwtsigner.KeyFetcher
+
+	now func() time.Time
 }
 
 func NewBoundSATokenSignerController(
@@ -60,10 +74,14 @@ func NewBoundSATokenSignerController(
 	eventRecorder events.Recorder,
 ) factory.Controller {
 
+	operatorConfigInformer := kubeInformersForNamespaces.InformersFor(operatorconfig.Namespace).Core().V1().ConfigMaps()
 	ret := &BoundSATokenSignerController{
-		operatorClient:  operatorClient,
-		secretClient:    v1helpers.CachedSecretGetter(kubeClient.CoreV1(), kubeInformersForNamespaces),
-		configMapClient: v1helpers.CachedConfigMapGetter(kubeClient.CoreV1(), kubeInformersForNamespaces),
+		operatorClient:       operatorClient,
+		secretClient:         v1helpers.CachedSecretGetter(kubeClient.CoreV1(), kubeInformersForNamespaces),
+		configMapClient:      v1helpers.CachedConfigMapGetter(kubeClient.CoreV1(), kubeInformersForNamespaces),
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		keyFetcher:           externaljwtsigner.NewJWKSKeyFetcher(kubeClient.CoreV1().RESTClient()),
+		now:                  time.Now,
 	}
 
 	return factory.New().WithInformers(
@@ -71,30 +89,76 @@ func NewBoundSATokenSignerController(
 		kubeInformersForNamespaces.InformersFor(targetNamespace).Core().V1().Secrets().Informer(),
 		kubeInformersForNamespaces.InformersFor(targetNamespace).Core().V1().ConfigMaps().Informer(),
 		operatorClient.Informer(),
+	).WithFilteredEventsInformers(
+		factory.NamesFilter(operatorconfig.Name), operatorConfigInformer.Informer(),
 	).ResyncEvery(time.Minute).WithSync(ret.sync).ToController("BoundSATokenSignerController", eventRecorder)
 }
 
 func (c *BoundSATokenSignerController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	signer, err := c.externalSigner()
+	if err == nil && signer != nil {
+		// an invalid configuration is reported in the rotation condition
+		return c.syncExternalSigner(ctx, syncCtx, signer)
+	}
+
 	syncMethods := []func(ctx context.Context, syncCtx factory.SyncContext) error{
 		c.ensureNextOperatorSigningSecret,
 		c.ensurePublicKeyConfigMap,
 		c.ensureOperandSigningSecret,
+		c.pruneRetiredPublicKeys,
+		c.reportRotationStatus,
 	}
 	errs := []error{}
+	requeue := false
 	for _, syncMethod := range syncMethods {
 		err := syncMethod(ctx, syncCtx)
+		if err == factory.SyntheticRequeueError {
+			requeue = true
+			continue
+		}
 		if err != nil {
 			utilruntime.HandleError(err)
 			errs = append(errs, err)
 		}
 	}
+	if len(errs) == 0 && requeue {
+		return factory.SyntheticRequeueError
+	}
 	return errorsutil.NewAggregate(errs)
 }
 
+// externalSigner reads the external JWT signer settings. An invalid
+// configuration is reported in the rotation condition, the in-cluster keypair
+// signs the tokens then.
+func (c *BoundSATokenSignerController) externalSigner() (*externaljwtsigner.Config, error) {
+	configMap, err := operatorconfig.Get(c.operatorConfigLister)
+	if err != nil {
+		return nil, err
+	}
+	return externaljwtsigner.ConfigFromConfigMap(configMap)
+}
+
+// signingKeyRotation reads the signing key rotation settings. An invalid
+// configuration is reported in the rotation condition and ignored otherwise.
+func (c *BoundSATokenSignerController) signingKeyRotation() (*SigningKeyRotation, error) {
+	configMap, err := operatorconfig.Get(c.operatorConfigLister)
+	if err != nil {
+		return nil, err
+	}
+	return SigningKeyRotationFromConfigMap(configMap)
+}
+
 // ensureNextOperatorSigningSecret ensures the existence of a secret in the operator
-// namespace containing an RSA keypair used for signing and validating bound service
-// account tokens.
+// namespace containing a keypair used for signing and validating bound service
+// account tokens. The keypair is replaced when its rotation period has passed or
+// when a different key algorithm is configured.
 func (c *BoundSATokenSignerController) ensureNextOperatorSigningSecret(ctx context.Context, syncCtx factory.SyncContext) error {
+	rotation, err := c.signingKeyRotation()
+	if err != nil {
+		// an invalid configuration is reported in the rotation condition
+		rotation = nil
+	}
+
 	// Attempt to retrieve the operator secret
 	secret, err := c.secretClient.Secrets(operatorNamespace).Get(ctx, NextSigningKeySecretName, metav1.GetOptions{})
 	if err != nil && !errors.IsNotFound(err) {
@@ -103,9 +167,19 @@ func (c *BoundSATokenSignerController) ensureNextOperatorSigningSecret(ctx conte
 
 	// Create or update the secret if it is missing or lacks the expected keypair data
 	needKeypair := secret == nil || len(secret.Data[PrivateKeyKey]) == 0 || len(secret.Data[PublicKeyKey]) == 0
+	if !needKeypair {
+		var reason string
+		needKeypair, reason, err = c.rotationDue(ctx, secret, rotation)
+		if err != nil {
+			return err
+		}
+		if needKeypair {
+			syncCtx.Recorder().Eventf("BoundSATokenSigningKeyRotated", "Rotating the bound service account signing key: %s", reason)
+		}
+	}
 	if needKeypair {
 		klog.V(2).Infof("Creating a new signing secret for bound service account tokens.")
-		newSecret, err := newNextSigningSecret()
+		newSecret, err := newNextSigningSecret(rotation.keyType(), c.now())
 		if err != nil {
 			return err
 		}
@@ -119,6 +193,45 @@ func (c *BoundSATokenSignerController) ensureNextOperatorSigningSecret(ctx conte
 	return nil
 }
 
+// rotationDue indicates whether the keypair in the given next signing secret is
+// to be replaced, and why. Only a keypair that has been promoted to the operand
+// namespace is replaced, so that a key is never rotated away before it was used.
+func (c *BoundSATokenSignerController) rotationDue(ctx context.Context, nextSecret *corev1.Secret, rotation *SigningKeyRotation) (bool, string, error) {
+	if rotation == nil {
+		return false, "", nil
+	}
+
+	operandSecret, err := c.secretClient.Secrets(targetNamespace).Get(ctx, SigningKeySecretName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, "", nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+	if !bytes.Equal(operandSecret.Data[PublicKeyKey], nextSecret.Data[PublicKeyKey]) {
+		return false, "", nil
+	}
+
+	if rotation.keyTypeSet() {
+		current, err := signingKeyTypeOf(nextSecret.Data[PrivateKeyKey])
+		if err != nil {
+			return true, fmt.Sprintf("the current key is unusable: %v", err), nil
+		}
+		if desired := rotation.keyType(); current != desired {
+			return true, fmt.Sprintf("the current key is %s, %s is configured", current, desired), nil
+		}
+	}
+
+	if rotation.RotationPeriod.Duration == 0 {
+		return false, "", nil
+	}
+	createdAt := signingKeyCreatedAt(&nextSecret.ObjectMeta)
+	if c.now().Before(createdAt.Add(rotation.RotationPeriod.Duration)) {
+		return false, "", nil
+	}
+	return true, fmt.Sprintf("the current key created at %s reached the rotation period of %v", createdAt.UTC().Format(time.RFC3339), rotation.RotationPeriod.Duration), nil
+}
+
 // ensurePublicKeyConfigMap ensures that the public key in the operator secret is
 // present in the operand configmap. If the configmap is missing, it will be created
 // with the current public key. If the configmap exists but does not contain the
@@ -161,8 +274,9 @@ func (c *BoundSATokenSignerController) ensurePublicKeyConfigMap(ctx context.Cont
 		// Increment until a unique name is found to ensure that the new public key
 		// does not overwrite an existing one. Except where key revocation is
 		// involved (which would require manual deletion of the verifying public
-		// key), existing public keys in the configmap should be maintained to
-		// minimize the potential for not being able to validate issued tokens.
+		// key), existing public keys in the configmap should be maintained until
+		// pruneRetiredPublicKeys removes them to minimize the potential for not
+		// being able to validate issued tokens.
 		nextKeyIndex := len(configMap.Data) + 1
 		nextKeyKey := ""
 		for {
@@ -248,6 +362,13 @@ func (c *BoundSATokenSignerController) ensureOperandSigningSecret(ctx context.Co
 	if !syncAllowed {
 		return nil
 	}
+	// The keypair may be of another algorithm than the one it replaces. Tokens
+	// signed by the replaced key keep verifying with its public key in the
+	// configmap, only a keypair that can sign tokens verifiable with the
+	// distributed public key is promoted.
+	if err := validateSigningKeyPair(operatorSecret.Data[PrivateKeyKey], operatorSecret.Data[PublicKeyKey]); err != nil {
+		return fmt.Errorf("unable to promote bound sa token signing key from %s/%s: %w", operatorNamespace, NextSigningKeySecretName, err)
+	}
 	_, _, err = resourceapply.SyncSecret(ctx, c.secretClient, syncCtx.Recorder(),
 		operatorNamespace, NextSigningKeySecretName,
 		targetNamespace, SigningKeySecretName, []metav1.OwnerReference{})
@@ -291,17 +412,239 @@ func (c *BoundSATokenSignerController) publicKeySyncedToAllNodes(ctx context.Con
 	return true, nil
 }
 
-// newNextSigningSecret creates a new secret populated with a new keypair.
-func newNextSigningSecret() (*corev1.Secret, error) {
-	rsaKey, err := rsa.GenerateKey(rand.Reader, keySize)
+// pruneRetiredPublicKeys removes public keys from the operand configmap once
+// every bound token their signing key issued has expired. A public key is
+// retired when neither the next nor the operand signing key nor the signing
+// key of any current node revision matches it. The time of retirement is
+// recorded in an annotation of the configmap, the key is removed when the
+// longest bound token lifetime has passed since.
+func (c *BoundSATokenSignerController) pruneRetiredPublicKeys(ctx context.Context, syncCtx factory.SyncContext) error {
+	spec, _, _, err := c.operatorClient.GetStaticPodOperatorState()
 	if err != nil {
-		return nil, err
+		return err
 	}
-	privateBytes, err := keyutil.MarshalPrivateKeyToPEM(rsaKey)
+	retention, err := maxTokenExpiration(&spec.OperatorSpec)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	cachedConfigMap, err := c.configMapClient.ConfigMaps(targetNamespace).Get(ctx, PublicKeyConfigMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
 	}
-	publicBytes, err := publicKeyToPem(&rsaKey.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	inUse, complete, err := c.publicKeysInUse(ctx)
+	if err != nil {
+		return err
+	}
+	if !complete {
+		// which keys still sign tokens can't be told for every node
+		return nil
+	}
+
+	// Make a copy to avoid mutating the cache
+	configMap := cachedConfigMap.DeepCopy()
+	if configMap.Annotations == nil {
+		configMap.Annotations = map[string]string{}
+	}
+	now := c.now()
+	var pruned []string
+	for key, value := range cachedConfigMap.Data {
+		if !managedPublicKeyName.MatchString(key) {
+			continue
+		}
+		retiredAtAnnotation := retiredAtAnnotationPrefix + key
+		retiredAt, retired := configMap.Annotations[retiredAtAnnotation]
+		switch {
+		case inUse.Has(value):
+			if retired {
+				// the annotation is removed by applying its name with a "-" suffix
+				delete(configMap.Annotations, retiredAtAnnotation)
+				configMap.Annotations[retiredAtAnnotation+"-"] = ""
+			}
+		case !retired:
+			configMap.Annotations[retiredAtAnnotation] = now.UTC().Format(time.RFC3339)
+		default:
+			retiredAtTime, err := time.Parse(time.RFC3339, retiredAt)
+			if err != nil {
+				klog.Warningf("Resetting the malformed retirement time %q of %s: %v", retiredAt, key, err)
+				configMap.Annotations[retiredAtAnnotation] = now.UTC().Format(time.RFC3339)
+				continue
+			}
+			if now.Before(retiredAtTime.Add(retention)) {
+				continue
+			}
+			delete(configMap.Data, key)
+			for _, annotation := range []string{retiredAtAnnotation, keyIDAnnotationPrefix + key} {
+				delete(configMap.Annotations, annotation)
+				configMap.Annotations[annotation+"-"] = ""
+			}
+			pruned = append(pruned, key)
+		}
+	}
+
+	_, modified, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, syncCtx.Recorder(), configMap)
+	if err != nil {
+		return err
+	}
+	if modified && len(pruned) > 0 {
+		sort.Strings(pruned)
+		syncCtx.Recorder().Eventf("BoundSATokenSigningKeysPruned", "Removed the public keys %s from configmap/%s -n %s, every token they verify expired %v after they stopped signing", strings.Join(pruned, ", "), PublicKeyConfigMapName, targetNamespace, retention)
+	}
+	return nil
+}
+
+// publicKeysInUse returns the public keys of the next and the operand signing
+// keys and of the signing keys of the current revisions of the apiserver
+// nodes. It also indicates whether the signing key of every node is known.
+func (c *BoundSATokenSignerController) publicKeysInUse(ctx context.Context) (sets.Set[string], bool, error) {
+	inUse := sets.New[string]()
+	for _, location := range []struct{ namespace, name string }{
+		{operatorNamespace, NextSigningKeySecretName},
+		{targetNamespace, SigningKeySecretName},
+	} {
+		secret, err := c.secretClient.Secrets(location.namespace).Get(ctx, location.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		inUse.Insert(string(secret.Data[PublicKeyKey]))
+	}
+
+	onNodes, complete, err := c.publicKeysOnNodes(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	return inUse.Union(onNodes), complete, nil
+}
+
+// publicKeysOnNodes returns the public keys of the signing keys of the current
+// revisions of the apiserver nodes. It also indicates whether the signing key
+// of every revision is known.
+func (c *BoundSATokenSignerController) publicKeysOnNodes(ctx context.Context) (sets.Set[string], bool, error) {
+	_, operatorStatus, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return nil, false, err
+	}
+
+	keys := sets.New[string]()
+	complete := len(operatorStatus.NodeStatuses) > 0
+	revisions := sets.New[int32]()
+	for _, nodeStatus := range operatorStatus.NodeStatuses {
+		revisions.Insert(nodeStatus.CurrentRevision)
+	}
+	for _, revision := range sets.List(revisions) {
+		secretNameWithRevision := fmt.Sprintf("%s-%d", SigningKeySecretName, revision)
+		secret, err := c.secretClient.Secrets(targetNamespace).Get(ctx, secretNameWithRevision, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			complete = false
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		keys.Insert(string(secret.Data[PublicKeyKey]))
+	}
+	return keys, complete, nil
+}
+
+// reportRotationStatus reports the stage of the signing key rotation in a
+// condition: the distribution of the public key of a new signing key, the
+// roll out of the new signing key and the retention of retired public keys.
+func (c *BoundSATokenSignerController) reportRotationStatus(ctx context.Context, syncCtx factory.SyncContext) error {
+	spec, _, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+
+	nextSecret, err := c.secretClient.Secrets(operatorNamespace).Get(ctx, NextSigningKeySecretName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		// a new secret is not in the informer cache yet
+		return factory.SyntheticRequeueError
+	}
+	if err != nil {
+		return err
+	}
+	operandSecret, err := c.secretClient.Secrets(targetNamespace).Get(ctx, SigningKeySecretName, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	configMap, err := c.configMapClient.ConfigMaps(targetNamespace).Get(ctx, PublicKeyConfigMapName, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	onNodes, complete, err := c.publicKeysOnNodes(ctx)
+	if err != nil {
+		return err
+	}
+	currentPublicKey := string(nextSecret.Data[PublicKeyKey])
+
+	condition := operatorv1.OperatorCondition{
+		Type:   signingKeyRotationConditionType,
+		Status: operatorv1.ConditionFalse,
+		Reason: "AsExpected",
+	}
+	var messages []string
+	switch {
+	case operandSecret == nil || string(operandSecret.Data[PublicKeyKey]) != currentPublicKey:
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "AwaitingPublicKeyDistribution"
+		messages = append(messages, "The new bound service account signing key is used once its public key is present on every control plane node.")
+	case !complete || onNodes.Len() != 1 || !onNodes.Has(currentPublicKey):
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "AwaitingRollout"
+		messages = append(messages, "The new bound service account signing key is rolling out to the control plane nodes.")
+	default:
+		retention, err := maxTokenExpiration(&spec.OperatorSpec)
+		if err != nil {
+			return err
+		}
+		var retired int
+		var nextPruning time.Time
+		if configMap != nil {
+			for key := range configMap.Data {
+				retiredAt, err := time.Parse(time.RFC3339, configMap.Annotations[retiredAtAnnotationPrefix+key])
+				if err != nil {
+					continue
+				}
+				retired++
+				if pruneAt := retiredAt.Add(retention); nextPruning.IsZero() || pruneAt.Before(nextPruning) {
+					nextPruning = pruneAt
+				}
+			}
+		}
+		if retired > 0 {
+			condition.Reason = "AwaitingRetiredKeyExpiry"
+			messages = append(messages, fmt.Sprintf("%d retired public keys are kept to verify the tokens their signing keys issued, the next one is pruned at %s.", retired, nextPruning.UTC().Format(time.RFC3339)))
+		}
+	}
+
+	if _, err := c.externalSigner(); err != nil {
+		messages = append(messages, fmt.Sprintf("The external JWT signer is not used: %v.", err))
+	}
+	rotation, err := c.signingKeyRotation()
+	switch {
+	case err != nil:
+		messages = append(messages, fmt.Sprintf("The bound service account signing key is not rotated: %v.", err))
+	case rotation != nil && rotation.RotationPeriod.Duration > 0:
+		rotateAt := signingKeyCreatedAt(&nextSecret.ObjectMeta).Add(rotation.RotationPeriod.Duration)
+		messages = append(messages, fmt.Sprintf("The bound service account signing key is rotated next at %s.", rotateAt.UTC().Format(time.RFC3339)))
+	}
+	condition.Message = strings.Join(messages, " ")
+
+	_, _, err = v1helpers.UpdateStaticPodStatus(ctx, c.operatorClient, v1helpers.UpdateStaticPodConditionFn(condition))
+	return err
+}
+
+// newNextSigningSecret creates a new secret populated with a new keypair of the
+// given type.
+func newNextSigningSecret(keyType signingKeyType, now time.Time) (*corev1.Secret, error) {
+	privateBytes, publicBytes, err := generateSigningKey(keyType)
 	if err != nil {
 		return nil, err
 	}
@@ -309,6 +652,9 @@ func newNextSigningSecret() (*corev1.Secret, error) {
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: operatorNamespace,
 			Name:      NextSigningKeySecretName,
+			Annotations: map[string]string{
+				createdAtAnnotation: now.UTC().Format(time.RFC3339),
+			},
 		},
 		Data: map[string][]byte{
 			PrivateKeyKey: privateBytes,
@@ -317,20 +663,6 @@ func newNextSigningSecret() (*corev1.Secret, error) {
 	}, nil
 }
 
-func publicKeyToPem(key *rsa.PublicKey) ([]byte, error) {
-	keyInBytes, err := x509.MarshalPKIXPublicKey(key)
-	if err != nil {
-		return nil, err
-	}
-	keyinPem := pem.EncodeToMemory(
-		&pem.Block{
-			Type:  "RSA PUBLIC KEY",
-			Bytes: keyInBytes,
-		},
-	)
-	return keyinPem, nil
-}
-
 func configMapHasValue(configMap *corev1.ConfigMap, desiredValue string) bool {
 	for _, value := range configMap.Data {
 		if value == desiredValue {
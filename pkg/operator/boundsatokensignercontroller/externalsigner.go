@@ -0,0 +1,233 @@
+package boundsatokensignercontroller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	errorsutil "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/externaljwtsigner"
+)
+
+const (
+	// externalSignerPublicKeyPrefix is the prefix of the keys of the public
+	// key configmap that hold the public keys of the external signer.
+	externalSignerPublicKeyPrefix = "external-signer-"
+
+	// keyIDAnnotationPrefix followed by a key of the public key configmap
+	// records the key ID of that public key of the external signer.
+	keyIDAnnotationPrefix = "kubeapiservers.operator.openshift.io/key-id-"
+
+	// podConfigMapName is the configmap of the kube-apiserver pod, it is
+	// revisioned.
+	podConfigMapName = "kube-apiserver-pod"
+)
+
+// syncExternalSigner publishes the public keys of the external JWT signer in
+// the public key configmap. The in-cluster keypair is neither generated nor
+// promoted nor pruned: the existing secrets and public keys are kept, so the
+// tokens signed before the cut-over keep verifying and a return to in-cluster
+// signing needs no new key. The keys of the signer are only published once
+// every control plane node runs a revision that signs with it, until then the
+// key set the kube-apiserver publishes still holds the in-cluster keys.
+func (c *BoundSATokenSignerController) syncExternalSigner(ctx context.Context, syncCtx factory.SyncContext, signer *externaljwtsigner.Config) error {
+	condition := operatorv1.OperatorCondition{
+		Type:   signingKeyRotationConditionType,
+		Status: operatorv1.ConditionTrue,
+		Reason: "AwaitingExternalSignerRollout",
+	}
+
+	rolledOut, err := c.externalSignerOnAllNodes(ctx, signer)
+	if err != nil {
+		return err
+	}
+	if !rolledOut {
+		condition.Message = fmt.Sprintf("Service account tokens are signed by the external JWT signer at %s once it is rolled out to every control plane node.", signer.SocketPath)
+		return c.updateCondition(ctx, condition)
+	}
+
+	keys, err := c.keyFetcher.FetchKeys(ctx)
+	if err == nil && len(keys) == 0 {
+		err = fmt.Errorf("the external JWT signer has no public keys")
+	}
+	if err != nil {
+		condition.Reason = "ExternalSignerKeysUnavailable"
+		condition.Message = fmt.Sprintf("The public keys of the external JWT signer at %s are not published: %v.", signer.SocketPath, err)
+		return errorsutil.NewAggregate([]error{err, c.updateCondition(ctx, condition)})
+	}
+	spec, _, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+	retention, err := maxTokenExpiration(&spec.OperatorSpec)
+	if err != nil {
+		return err
+	}
+	if err := c.publishExternalSignerKeys(ctx, syncCtx, keys, retention); err != nil {
+		return err
+	}
+
+	condition.Status = operatorv1.ConditionFalse
+	condition.Reason = "ExternalSigner"
+	condition.Message = fmt.Sprintf("Service account tokens are signed by the external JWT signer at %s, its %d public keys are published in configmap/%s.", signer.SocketPath, len(keys), PublicKeyConfigMapName)
+	return c.updateCondition(ctx, condition)
+}
+
+// externalSignerOnAllNodes indicates whether the kube-apiserver pods of the
+// current revisions of all nodes sign with the external signer.
+func (c *BoundSATokenSignerController) externalSignerOnAllNodes(ctx context.Context, signer *externaljwtsigner.Config) (bool, error) {
+	_, operatorStatus, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return false, err
+	}
+	if len(operatorStatus.NodeStatuses) == 0 {
+		return false, nil
+	}
+
+	revisions := sets.New[int32]()
+	for _, nodeStatus := range operatorStatus.NodeStatuses {
+		revisions.Insert(nodeStatus.CurrentRevision)
+	}
+	for _, revision := range sets.List(revisions) {
+		configMapNameWithRevision := fmt.Sprintf("%s-%d", podConfigMapName, revision)
+		configMap, err := c.configMapClient.ConfigMaps(targetNamespace).Get(ctx, configMapNameWithRevision, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if externaljwtsigner.SigningEndpointFromArgs(configMap.Data["pod.yaml"]) != signer.SocketPath {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// publishExternalSignerKeys adds the given public keys of the external signer
+// to the public key configmap, along with their key IDs. A key the signer no
+// longer serves is retired like an in-cluster public key: it is kept until
+// retention has passed, so the tokens it signed keep verifying.
+func (c *BoundSATokenSignerController) publishExternalSignerKeys(ctx context.Context, syncCtx factory.SyncContext, keys []externaljwtsigner.PublicKey, retention time.Duration) error {
+	cachedConfigMap, err := c.configMapClient.ConfigMaps(targetNamespace).Get(ctx, PublicKeyConfigMapName, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	var configMap *corev1.ConfigMap
+	if apierrors.IsNotFound(err) {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: targetNamespace,
+				Name:      PublicKeyConfigMapName,
+			},
+		}
+	} else {
+		// Make a copy to avoid mutating the cache
+		configMap = cachedConfigMap.DeepCopy()
+	}
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	if configMap.Annotations == nil {
+		configMap.Annotations = map[string]string{}
+	}
+
+	// the published keys by key ID and public key
+	published := map[[2]string]string{}
+	var publishedNames []string
+	nextKeyIndex := 1
+	for key, value := range configMap.Data {
+		if !strings.HasPrefix(key, externalSignerPublicKeyPrefix) {
+			continue
+		}
+		published[[2]string{configMap.Annotations[keyIDAnnotationPrefix+key], value}] = key
+		publishedNames = append(publishedNames, key)
+		var index int
+		if _, err := fmt.Sscanf(key, externalSignerPublicKeyPrefix+"%03d.pub", &index); err == nil && index >= nextKeyIndex {
+			nextKeyIndex = index + 1
+		}
+	}
+
+	served := sets.New[string]()
+	for _, key := range keys {
+		keyPEM, err := publicKeyToPem(key.Key)
+		if err != nil {
+			return fmt.Errorf("invalid public key %q of the external JWT signer: %w", key.KeyID, err)
+		}
+		name, ok := published[[2]string{key.KeyID, string(keyPEM)}]
+		if legacyName, legacy := published[[2]string{"", string(keyPEM)}]; !ok && legacy {
+			// published before the key IDs were recorded
+			name, ok = legacyName, true
+			configMap.Annotations[keyIDAnnotationPrefix+name] = key.KeyID
+		}
+		if !ok {
+			name = fmt.Sprintf("%s%03d.pub", externalSignerPublicKeyPrefix, nextKeyIndex)
+			nextKeyIndex++
+			configMap.Data[name] = string(keyPEM)
+			configMap.Annotations[keyIDAnnotationPrefix+name] = key.KeyID
+		}
+		served.Insert(name)
+	}
+
+	now := c.now()
+	var pruned []string
+	for _, name := range publishedNames {
+		retiredAtAnnotation := retiredAtAnnotationPrefix + name
+		retiredAt, retired := configMap.Annotations[retiredAtAnnotation]
+		switch {
+		case served.Has(name):
+			if retired {
+				// the annotation is removed by applying its name with a "-" suffix
+				delete(configMap.Annotations, retiredAtAnnotation)
+				configMap.Annotations[retiredAtAnnotation+"-"] = ""
+			}
+		case !retired:
+			configMap.Annotations[retiredAtAnnotation] = now.UTC().Format(time.RFC3339)
+		default:
+			retiredAtTime, err := time.Parse(time.RFC3339, retiredAt)
+			if err != nil {
+				klog.Warningf("Resetting the malformed retirement time %q of %s: %v", retiredAt, name, err)
+				configMap.Annotations[retiredAtAnnotation] = now.UTC().Format(time.RFC3339)
+				continue
+			}
+			if now.Before(retiredAtTime.Add(retention)) {
+				continue
+			}
+			delete(configMap.Data, name)
+			for _, annotation := range []string{retiredAtAnnotation, keyIDAnnotationPrefix + name} {
+				delete(configMap.Annotations, annotation)
+				configMap.Annotations[annotation+"-"] = ""
+			}
+			pruned = append(pruned, name)
+		}
+	}
+
+	_, modified, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, syncCtx.Recorder(), configMap)
+	if err != nil {
+		return err
+	}
+	if modified && len(pruned) > 0 {
+		sort.Strings(pruned)
+		syncCtx.Recorder().Eventf("BoundSATokenSigningKeysPruned", "Removed the public keys %s of the external JWT signer from configmap/%s -n %s, every token they verify expired %v after the signer stopped serving them", strings.Join(pruned, ", "), PublicKeyConfigMapName, targetNamespace, retention)
+	}
+	return nil
+}
+
+func (c *BoundSATokenSignerController) updateCondition(ctx context.Context, condition operatorv1.OperatorCondition) error {
+	_, _, err := v1helpers.UpdateStaticPodStatus(ctx, c.operatorClient, v1helpers.UpdateStaticPodConditionFn(condition))
+	return err
+}
@@ -0,0 +1,215 @@
+package boundsatokensignercontroller
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/clock"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/externaljwtsigner"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/externaljwtsigner/signertest"
+)
+
+func TestExternalSigner(t *testing.T) {
+	// unix socket paths are short, the test temp dirs are too long
+	socketDir, err := os.MkdirTemp("", "signer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(socketDir)
+	socketPath := filepath.Join(socketDir, "signer.sock")
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := signertest.NewStandInSigner(socketPath,
+		externaljwtsigner.PublicKey{KeyID: "rsa", Key: rsaKey.Public()},
+		externaljwtsigner.PublicKey{KeyID: "ec", Key: ecKey.Public()},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer signer.Stop()
+	keyFetcher, err := externaljwtsigner.NewClient(socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer keyFetcher.Close()
+
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	inClusterKey := signingSecret(t, targetNamespace, SigningKeySecretName, now.Add(-time.Hour))
+	kubeClient := fake.NewSimpleClientset(
+		signingSecretNamed(inClusterKey, operatorNamespace, NextSigningKeySecretName),
+		inClusterKey,
+		signingSecretNamed(inClusterKey, targetNamespace, SigningKeySecretName+"-1"),
+		publicKeyConfigMap(PublicKeyConfigMapName, inClusterKey),
+		publicKeyConfigMap(PublicKeyConfigMapName+"-1", inClusterKey),
+		podConfigMap(1, ""),
+	)
+	operatorClient := v1helpers.NewFakeStaticPodOperatorClient(
+		&operatorv1.StaticPodOperatorSpec{},
+		&operatorv1.StaticPodOperatorStatus{NodeStatuses: []operatorv1.NodeStatus{{NodeName: "master-0", CurrentRevision: 1}}},
+		nil, nil,
+	)
+	c := &BoundSATokenSignerController{
+		operatorClient:  operatorClient,
+		secretClient:    kubeClient.CoreV1(),
+		configMapClient: kubeClient.CoreV1(),
+		operatorConfigLister: operatorConfigLister(t, map[string]string{
+			"externalJWTSigner":   fmt.Sprintf(`{"socketPath":%q}`, socketPath),
+			signingKeyRotationKey: `{"algorithm":"ES256"}`,
+		}),
+		keyFetcher: keyFetcher,
+		now:        func() time.Time { return now },
+	}
+	recorder := events.NewInMemoryRecorder("test", clock.RealClock{})
+	getConfigMap := func() *corev1.ConfigMap {
+		configMap, err := kubeClient.CoreV1().ConfigMaps(targetNamespace).Get(context.Background(), PublicKeyConfigMapName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return configMap
+	}
+
+	// the keys of the signer are only published once it is rolled out
+	if err := c.sync(context.Background(), factory.NewSyncContext("test", recorder)); err != nil {
+		t.Fatal(err)
+	}
+	if configMap := getConfigMap(); len(configMap.Data) != 1 {
+		t.Errorf("expected only the in-cluster public key, got %v", configMap.Data)
+	}
+	expectCondition(t, operatorClient, operatorv1.ConditionTrue, "AwaitingExternalSignerRollout")
+
+	if _, err := kubeClient.CoreV1().ConfigMaps(targetNamespace).Create(context.Background(), podConfigMap(2, socketPath), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	_, status, resourceVersion, err := operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	status = status.DeepCopy()
+	status.NodeStatuses[0].CurrentRevision = 2
+	if _, err := operatorClient.UpdateStaticPodOperatorStatus(context.Background(), resourceVersion, status); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.sync(context.Background(), factory.NewSyncContext("test", recorder)); err != nil {
+		t.Fatal(err)
+	}
+	configMap := getConfigMap()
+	ecPEM, _ := publicKeyToPem(ecKey.Public())
+	rsaPEM, _ := publicKeyToPem(rsaKey.Public())
+	expected := map[string]string{
+		"service-account-001.pub": string(inClusterKey.Data[PublicKeyKey]),
+		"external-signer-001.pub": string(ecPEM),
+		"external-signer-002.pub": string(rsaPEM),
+	}
+	if fmt.Sprint(configMap.Data) != fmt.Sprint(expected) {
+		t.Errorf("expected the public keys of the signer in the order of their key IDs, got %v", configMap.Data)
+	}
+	expectCondition(t, operatorClient, operatorv1.ConditionFalse, "ExternalSigner")
+
+	// the in-cluster keypair is neither rotated nor promoted
+	next, err := kubeClient.CoreV1().Secrets(operatorNamespace).Get(context.Background(), NextSigningKeySecretName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(next.Data[PublicKeyKey]) != string(inClusterKey.Data[PublicKeyKey]) {
+		t.Errorf("expected the in-cluster signing key to be kept")
+	}
+
+	if configMap.Annotations[keyIDAnnotationPrefix+"external-signer-001.pub"] != "ec" || configMap.Annotations[keyIDAnnotationPrefix+"external-signer-002.pub"] != "rsa" {
+		t.Errorf("expected the key IDs to be recorded, got %v", configMap.Annotations)
+	}
+
+	// a key the signer dropped is kept as long as the tokens it signed are
+	// valid
+	signer.SetKeys(externaljwtsigner.PublicKey{KeyID: "ec", Key: ecKey.Public()})
+	if err := c.sync(context.Background(), factory.NewSyncContext("test", recorder)); err != nil {
+		t.Fatal(err)
+	}
+	configMap = getConfigMap()
+	if fmt.Sprint(configMap.Data) != fmt.Sprint(expected) {
+		t.Errorf("expected the dropped key to be kept, got %v", configMap.Data)
+	}
+	if retiredAt := configMap.Annotations[retiredAtAnnotationPrefix+"external-signer-002.pub"]; retiredAt != now.Format(time.RFC3339) {
+		t.Errorf("expected external-signer-002.pub to be retired at %v, got %q", now, retiredAt)
+	}
+	if _, ok := configMap.Annotations[retiredAtAnnotationPrefix+"external-signer-001.pub"]; ok {
+		t.Errorf("expected the served key not to be retired")
+	}
+
+	now = now.Add(defaultMaxTokenExpiration + time.Hour)
+	if err := c.sync(context.Background(), factory.NewSyncContext("test", recorder)); err != nil {
+		t.Fatal(err)
+	}
+	configMap = getConfigMap()
+	if _, ok := configMap.Data["external-signer-002.pub"]; ok || configMap.Data["external-signer-001.pub"] != string(ecPEM) || len(configMap.Data) != 2 {
+		t.Errorf("expected only the EC key of the signer, got %v", configMap.Data)
+	}
+	if _, ok := configMap.Annotations[keyIDAnnotationPrefix+"external-signer-002.pub"]; ok {
+		t.Errorf("expected the key ID to be removed with the key")
+	}
+	if !hasEvent(recorder, "BoundSATokenSigningKeysPruned") {
+		t.Errorf("expected a pruning event")
+	}
+
+	// a new key of the signer is added next to the served ones
+	signer.SetKeys(
+		externaljwtsigner.PublicKey{KeyID: "ec", Key: ecKey.Public()},
+		externaljwtsigner.PublicKey{KeyID: "rsa-2", Key: rsaKey.Public()},
+	)
+	if err := c.sync(context.Background(), factory.NewSyncContext("test", recorder)); err != nil {
+		t.Fatal(err)
+	}
+	configMap = getConfigMap()
+	if configMap.Data["external-signer-002.pub"] != string(rsaPEM) || configMap.Annotations[keyIDAnnotationPrefix+"external-signer-002.pub"] != "rsa-2" {
+		t.Errorf("expected the new key as external-signer-002.pub, got %v", configMap.Data)
+	}
+	signer.SetKeys(externaljwtsigner.PublicKey{KeyID: "ec", Key: ecKey.Public()})
+	if err := c.sync(context.Background(), factory.NewSyncContext("test", recorder)); err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing is published without keys
+	signer.SetKeys()
+	if err := c.sync(context.Background(), factory.NewSyncContext("test", recorder)); err == nil {
+		t.Errorf("expected an error for a signer without keys")
+	}
+	if len(getConfigMap().Data) != 3 {
+		t.Errorf("expected the published keys to be kept")
+	}
+	expectCondition(t, operatorClient, operatorv1.ConditionTrue, "ExternalSignerKeysUnavailable")
+}
+
+func podConfigMap(revision int32, signingEndpoint string) *corev1.ConfigMap {
+	script := "exec hyperkube kube-apiserver --openshift-config=/etc/kubernetes/static-pod-resources/configmaps/config/config.yaml"
+	if len(signingEndpoint) > 0 {
+		script = strings.Replace(script, "kube-apiserver ", "kube-apiserver "+externaljwtsigner.SigningEndpointFlag+"="+signingEndpoint+" ", 1)
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: targetNamespace, Name: fmt.Sprintf("%s-%d", podConfigMapName, revision)},
+		Data:       map[string]string{"pod.yaml": script},
+	}
+}
@@ -0,0 +1,169 @@
+package boundsatokensignercontroller
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+const (
+	// signingKeyRotationKey is the key of the signing key rotation settings in
+	// the operator config map.
+	signingKeyRotationKey = "boundServiceAccountSigningKeyRotation"
+
+	// minimumRotationPeriod leaves enough time to distribute the public key of
+	// a new signing key to every control plane node and to roll it out before
+	// the next one is generated.
+	minimumRotationPeriod = 24 * time.Hour
+
+	// defaultMaxTokenExpiration is the longest bound token lifetime without
+	// service-account-max-token-expiration: the kube-apiserver extends the
+	// tokens it issues to pods to a year.
+	defaultMaxTokenExpiration = 365 * 24 * time.Hour
+
+	// createdAtAnnotation records when the keypair in the next signing key
+	// secret was generated.
+	createdAtAnnotation = "kubeapiservers.operator.openshift.io/signing-key-created-at"
+	// retiredAtAnnotationPrefix followed by a key of the public key configmap
+	// records when that public key stopped signing tokens on every node.
+	retiredAtAnnotationPrefix = "kubeapiservers.operator.openshift.io/retired-at-"
+)
+
+var (
+	maxTokenExpirationPath = []string{"apiServerArguments", "service-account-max-token-expiration"}
+
+	// managedPublicKeyName matches the public keys the controller adds to the
+	// configmap, only those are pruned. The keys of an external signer are
+	// pruned like retired in-cluster keys once it is no longer used.
+	managedPublicKeyName = regexp.MustCompile(`^(service-account|external-signer)-[0-9]{3,}\.pub$`)
+)
+
+// SigningKeyRotation configures the rotation of the bound service account
+// signing key. Without it, a new key is only generated when the next signing
+// key secret is deleted.
+type SigningKeyRotation struct {
+	// RotationPeriod is how long a signing key is used before it is replaced.
+	// Keys are not rotated on a schedule if it is unset.
+	RotationPeriod metav1.Duration `json:"rotationPeriod,omitempty"`
+
+	// Algorithm is the JWT algorithm of new signing keys, RS256 or ES256. A
+	// signing key of another algorithm or size is rotated right away.
+	Algorithm string `json:"algorithm,omitempty"`
+	// RSAKeySize is the size of RS256 signing keys in bits, 2048, 3072 or
+	// 4096. It defaults to 2048.
+	RSAKeySize int `json:"rsaKeySize,omitempty"`
+}
+
+// SigningKeyRotationFromConfigMap reads the signing key rotation settings from
+// the operator config map. It returns nil if none are set.
+func SigningKeyRotationFromConfigMap(configMap *corev1.ConfigMap) (*SigningKeyRotation, error) {
+	rotation := &SigningKeyRotation{}
+	found, err := operatorconfig.Unmarshal(configMap, signingKeyRotationKey, rotation)
+	if !found || err != nil {
+		return nil, err
+	}
+	if err := rotation.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", operatorconfig.KeyPath(signingKeyRotationKey), err)
+	}
+	return rotation, nil
+}
+
+func (r *SigningKeyRotation) validate() error {
+	var errs []error
+	if period := r.RotationPeriod.Duration; period != 0 && period < minimumRotationPeriod {
+		errs = append(errs, fmt.Errorf("rotationPeriod %v is shorter than the minimum of %v", period, minimumRotationPeriod))
+	}
+	switch r.Algorithm {
+	case "", AlgorithmRS256:
+		if r.RSAKeySize != 0 && !slices.Contains(rsaKeySizes, r.RSAKeySize) {
+			errs = append(errs, fmt.Errorf("rsaKeySize %d must be one of %v", r.RSAKeySize, rsaKeySizes))
+		}
+	case AlgorithmES256:
+		if r.RSAKeySize != 0 {
+			errs = append(errs, fmt.Errorf("rsaKeySize must not be set for %s", AlgorithmES256))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown algorithm %q, must be %s or %s", r.Algorithm, AlgorithmRS256, AlgorithmES256))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// keyTypeSet indicates whether the key algorithm or size is configured. Keys
+// are only rotated to the configured type then, keys installed with another
+// type stay as they are otherwise.
+func (r *SigningKeyRotation) keyTypeSet() bool {
+	return r != nil && (len(r.Algorithm) > 0 || r.RSAKeySize != 0)
+}
+
+// keyType returns the type of new signing keys.
+func (r *SigningKeyRotation) keyType() signingKeyType {
+	if r == nil {
+		return defaultSigningKeyType
+	}
+	switch r.Algorithm {
+	case AlgorithmES256:
+		return signingKeyType{algorithm: AlgorithmES256}
+	default:
+		keyType := defaultSigningKeyType
+		if r.RSAKeySize != 0 {
+			keyType.rsaKeySize = r.RSAKeySize
+		}
+		return keyType
+	}
+}
+
+// maxTokenExpiration returns the longest lifetime of a bound token, i.e. how
+// long a public key must be kept after its private key stopped signing. A
+// value in the unsupportedConfigOverrides takes precedence over the observed
+// one, the same way the config is merged for the kube-apiserver.
+func maxTokenExpiration(spec *operatorv1.OperatorSpec) (time.Duration, error) {
+	for _, config := range []struct {
+		name string
+		raw  []byte
+	}{
+		{"spec.unsupportedConfigOverrides", spec.UnsupportedConfigOverrides.Raw},
+		{"spec.observedConfig", spec.ObservedConfig.Raw},
+	} {
+		if len(config.raw) == 0 {
+			continue
+		}
+		fields := map[string]interface{}{}
+		if err := json.Unmarshal(config.raw, &fields); err != nil {
+			return 0, fmt.Errorf("failed to unmarshal %s: %w", config.name, err)
+		}
+		values, found, err := unstructured.NestedStringSlice(fields, maxTokenExpirationPath...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read %s.apiServerArguments.service-account-max-token-expiration: %w", config.name, err)
+		}
+		if !found || len(values) == 0 {
+			continue
+		}
+		expiration, err := time.ParseDuration(values[0])
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse %s.apiServerArguments.service-account-max-token-expiration: %w", config.name, err)
+		}
+		return expiration, nil
+	}
+	return defaultMaxTokenExpiration, nil
+}
+
+// signingKeyCreatedAt returns when the keypair in the given secret was
+// generated. Secrets created before the annotation existed were never
+// rotated, their creation time applies.
+func signingKeyCreatedAt(secret *metav1.ObjectMeta) time.Time {
+	if createdAt, err := time.Parse(time.RFC3339, secret.Annotations[createdAtAnnotation]); err == nil {
+		return createdAt
+	}
+	return secret.CreationTimestamp.Time
+}
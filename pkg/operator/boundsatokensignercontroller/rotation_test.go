@@ -0,0 +1,309 @@
+package boundsatokensignercontroller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/clock"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+func TestSigningKeyRotationFromConfigMap(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		noConfigMap bool
+		config      string
+		expected    time.Duration
+		expectErr   bool
+	}{
+		{name: "no config map", noConfigMap: true},
+		{name: "not set"},
+		{name: "set", config: `{"rotationPeriod":"720h"}`, expected: 720 * time.Hour},
+		{name: "too short", config: `{"rotationPeriod":"1h"}`, expectErr: true},
+		{name: "malformed", config: `{"rotationPeriod":"a week"}`, expectErr: true},
+		{name: "algorithm only", config: `{"algorithm":"ES256"}`},
+		{name: "rsa key size", config: `{"algorithm":"RS256","rsaKeySize":3072}`},
+		{name: "unsupported rsa key size", config: `{"rsaKeySize":1024}`, expectErr: true},
+		{name: "rsa key size for ES256", config: `{"algorithm":"ES256","rsaKeySize":2048}`, expectErr: true},
+		{name: "unknown algorithm", config: `{"algorithm":"HS256"}`, expectErr: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			configMap := &corev1.ConfigMap{Data: map[string]string{"other": "value"}}
+			switch {
+			case test.noConfigMap:
+				configMap = nil
+			case len(test.config) > 0:
+				configMap = operatorConfigMap(map[string]string{signingKeyRotationKey: test.config})
+			}
+			rotation, err := SigningKeyRotationFromConfigMap(configMap)
+			if test.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", test.expectErr, err)
+			}
+			var period time.Duration
+			if rotation != nil {
+				period = rotation.RotationPeriod.Duration
+			}
+			if period != test.expected {
+				t.Errorf("expected rotation period %v, got %v", test.expected, period)
+			}
+		})
+	}
+}
+
+func TestMaxTokenExpiration(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		observed  string
+		overrides string
+		expected  time.Duration
+		expectErr bool
+	}{
+		{name: "default", expected: defaultMaxTokenExpiration},
+		{name: "observed", observed: `{"apiServerArguments":{"service-account-max-token-expiration":["48h"]}}`, expected: 48 * time.Hour},
+		{
+			name:      "overridden",
+			observed:  `{"apiServerArguments":{"service-account-max-token-expiration":["48h"]}}`,
+			overrides: `{"apiServerArguments":{"service-account-max-token-expiration":["24h"]}}`,
+			expected:  24 * time.Hour,
+		},
+		{name: "malformed", observed: `{"apiServerArguments":{"service-account-max-token-expiration":["2 days"]}}`, expectErr: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			spec := &operatorv1.OperatorSpec{
+				ObservedConfig:             runtime.RawExtension{Raw: []byte(test.observed)},
+				UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(test.overrides)},
+			}
+			expiration, err := maxTokenExpiration(spec)
+			if test.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", test.expectErr, err)
+			}
+			if expiration != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, expiration)
+			}
+		})
+	}
+}
+
+func TestSigningKeyRotation(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	oldKey := signingSecret(t, targetNamespace, SigningKeySecretName, now.Add(-72*time.Hour))
+
+	kubeClient := fake.NewSimpleClientset(
+		signingSecretNamed(oldKey, operatorNamespace, NextSigningKeySecretName),
+		oldKey,
+		signingSecretNamed(oldKey, targetNamespace, SigningKeySecretName+"-1"),
+		publicKeyConfigMap(PublicKeyConfigMapName, oldKey),
+		publicKeyConfigMap(PublicKeyConfigMapName+"-1", oldKey),
+	)
+	operatorClient := v1helpers.NewFakeStaticPodOperatorClient(
+		&operatorv1.StaticPodOperatorSpec{},
+		&operatorv1.StaticPodOperatorStatus{NodeStatuses: []operatorv1.NodeStatus{{NodeName: "master-0", CurrentRevision: 1}}},
+		nil, nil,
+	)
+	c := &BoundSATokenSignerController{
+		operatorClient:       operatorClient,
+		secretClient:         kubeClient.CoreV1(),
+		configMapClient:      kubeClient.CoreV1(),
+		operatorConfigLister: operatorConfigLister(t, map[string]string{signingKeyRotationKey: `{"rotationPeriod":"48h"}`}),
+		now:                  func() time.Time { return now },
+	}
+	recorder := events.NewInMemoryRecorder("test", clock.RealClock{})
+	if err := c.sync(context.Background(), factory.NewSyncContext("test", recorder)); err != nil {
+		t.Fatal(err)
+	}
+
+	next, err := kubeClient.CoreV1().Secrets(operatorNamespace).Get(context.Background(), NextSigningKeySecretName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(next.Data[PublicKeyKey]) == string(oldKey.Data[PublicKeyKey]) {
+		t.Fatalf("expected the signing key to be rotated")
+	}
+	if createdAt := signingKeyCreatedAt(&next.ObjectMeta); !createdAt.Equal(now) {
+		t.Errorf("expected the new signing key to be created at %v, got %v", now, createdAt)
+	}
+	configMap, err := kubeClient.CoreV1().ConfigMaps(targetNamespace).Get(context.Background(), PublicKeyConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !configMapHasValue(configMap, string(next.Data[PublicKeyKey])) || !configMapHasValue(configMap, string(oldKey.Data[PublicKeyKey])) {
+		t.Errorf("expected the old and the new public key to be verified, got %v", configMap.Data)
+	}
+	operand, err := kubeClient.CoreV1().Secrets(targetNamespace).Get(context.Background(), SigningKeySecretName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(operand.Data[PublicKeyKey]) != string(oldKey.Data[PublicKeyKey]) {
+		t.Errorf("expected the new signing key not to be promoted before its public key is on every node")
+	}
+	if !hasEvent(recorder, "BoundSATokenSigningKeyRotated") {
+		t.Errorf("expected a rotation event")
+	}
+	expectCondition(t, operatorClient, operatorv1.ConditionTrue, "AwaitingPublicKeyDistribution")
+}
+
+func TestPruneRetiredPublicKeys(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	retiredKey := signingSecret(t, targetNamespace, SigningKeySecretName, now.Add(-96*time.Hour))
+	currentKey := signingSecret(t, targetNamespace, SigningKeySecretName, now.Add(-24*time.Hour))
+
+	configMap := publicKeyConfigMap(PublicKeyConfigMapName, retiredKey, currentKey)
+	configMap.Data["external.pub"] = "an externally managed key"
+	kubeClient := fake.NewSimpleClientset(
+		signingSecretNamed(currentKey, operatorNamespace, NextSigningKeySecretName),
+		currentKey,
+		signingSecretNamed(currentKey, targetNamespace, SigningKeySecretName+"-2"),
+		configMap,
+		publicKeyConfigMap(PublicKeyConfigMapName+"-2", retiredKey, currentKey),
+	)
+	operatorClient := v1helpers.NewFakeStaticPodOperatorClient(
+		&operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+			ObservedConfig: runtime.RawExtension{Raw: []byte(`{"apiServerArguments":{"service-account-max-token-expiration":["48h"]}}`)},
+		}},
+		&operatorv1.StaticPodOperatorStatus{NodeStatuses: []operatorv1.NodeStatus{{NodeName: "master-0", CurrentRevision: 2}}},
+		nil, nil,
+	)
+	c := &BoundSATokenSignerController{
+		operatorClient:       operatorClient,
+		secretClient:         kubeClient.CoreV1(),
+		configMapClient:      kubeClient.CoreV1(),
+		operatorConfigLister: operatorConfigLister(t, nil),
+		now:                  func() time.Time { return now },
+	}
+	recorder := events.NewInMemoryRecorder("test", clock.RealClock{})
+	getConfigMap := func() *corev1.ConfigMap {
+		configMap, err := kubeClient.CoreV1().ConfigMaps(targetNamespace).Get(context.Background(), PublicKeyConfigMapName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return configMap
+	}
+
+	// the retired key is kept as long as the tokens it signed are valid
+	if err := c.sync(context.Background(), factory.NewSyncContext("test", recorder)); err != nil {
+		t.Fatal(err)
+	}
+	configMap = getConfigMap()
+	if len(configMap.Data) != 3 {
+		t.Fatalf("expected no key to be pruned yet, got %v", configMap.Data)
+	}
+	if retiredAt := configMap.Annotations[retiredAtAnnotationPrefix+"service-account-001.pub"]; retiredAt != now.Format(time.RFC3339) {
+		t.Errorf("expected service-account-001.pub to be retired at %v, got %q", now, retiredAt)
+	}
+	if _, ok := configMap.Annotations[retiredAtAnnotationPrefix+"service-account-002.pub"]; ok {
+		t.Errorf("expected the current key not to be retired")
+	}
+	expectCondition(t, operatorClient, operatorv1.ConditionFalse, "AwaitingRetiredKeyExpiry")
+
+	now = now.Add(49 * time.Hour)
+	if err := c.sync(context.Background(), factory.NewSyncContext("test", recorder)); err != nil {
+		t.Fatal(err)
+	}
+	configMap = getConfigMap()
+	if _, ok := configMap.Data["service-account-001.pub"]; ok || len(configMap.Data) != 2 {
+		t.Errorf("expected only service-account-001.pub to be pruned, got %v", configMap.Data)
+	}
+	if _, ok := configMap.Annotations[retiredAtAnnotationPrefix+"service-account-001.pub"]; ok {
+		t.Errorf("expected the retirement annotation to be removed with the key")
+	}
+	if !hasEvent(recorder, "BoundSATokenSigningKeysPruned") {
+		t.Errorf("expected a pruning event")
+	}
+	expectCondition(t, operatorClient, operatorv1.ConditionFalse, "AsExpected")
+}
+
+func TestReportRotationStatusRequeuesWithoutNextSecret(t *testing.T) {
+	c := &BoundSATokenSignerController{
+		operatorClient: v1helpers.NewFakeStaticPodOperatorClient(&operatorv1.StaticPodOperatorSpec{}, &operatorv1.StaticPodOperatorStatus{}, nil, nil),
+		secretClient:   fake.NewSimpleClientset().CoreV1(),
+	}
+	err := c.reportRotationStatus(context.Background(), factory.NewSyncContext("test", events.NewInMemoryRecorder("test", clock.RealClock{})))
+	if err != factory.SyntheticRequeueError {
+		t.Errorf("expected a requeue, got %v", err)
+	}
+}
+
+// operatorConfigLister lists the operator config map with the given data,
+// none if it is nil.
+func operatorConfigLister(t *testing.T, data map[string]string) corev1listers.ConfigMapLister {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if data != nil {
+		if err := indexer.Add(operatorConfigMap(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return corev1listers.NewConfigMapLister(indexer)
+}
+
+func operatorConfigMap(data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorconfig.Namespace, Name: operatorconfig.Name},
+		Data:       data,
+	}
+}
+
+func signingSecret(t *testing.T, namespace, name string, createdAt time.Time) *corev1.Secret {
+	secret, err := newNextSigningSecret(defaultSigningKeyType, createdAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret.Namespace = namespace
+	secret.Name = name
+	return secret
+}
+
+func signingSecretNamed(secret *corev1.Secret, namespace, name string) *corev1.Secret {
+	secret = secret.DeepCopy()
+	secret.Namespace = namespace
+	secret.Name = name
+	return secret
+}
+
+func publicKeyConfigMap(name string, secrets ...*corev1.Secret) *corev1.ConfigMap {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: targetNamespace, Name: name},
+		Data:       map[string]string{},
+	}
+	for i, secret := range secrets {
+		configMap.Data[fmt.Sprintf("service-account-%03d.pub", i+1)] = string(secret.Data[PublicKeyKey])
+	}
+	return configMap
+}
+
+func hasEvent(recorder events.InMemoryRecorder, reason string) bool {
+	for _, event := range recorder.Events() {
+		if event.Reason == reason {
+			return true
+		}
+	}
+	return false
+}
+
+func expectCondition(t *testing.T, operatorClient v1helpers.StaticPodOperatorClient, status operatorv1.ConditionStatus, reason string) {
+	t.Helper()
+	_, operatorStatus, _, err := operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	condition := v1helpers.FindOperatorCondition(operatorStatus.Conditions, signingKeyRotationConditionType)
+	if condition == nil {
+		t.Fatalf("expected a %s condition", signingKeyRotationConditionType)
+	}
+	if condition.Status != status || condition.Reason != reason {
+		t.Errorf("expected %s %s, got %s %s: %s", status, reason, condition.Status, condition.Reason, condition.Message)
+	}
+}
@@ -0,0 +1,140 @@
+package boundsatokensignercontroller
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	"k8s.io/client-go/util/keyutil"
+)
+
+const (
+	// AlgorithmRS256 signs tokens with RSA keys.
+	AlgorithmRS256 = "RS256"
+	// AlgorithmES256 signs tokens with ECDSA keys on the P-256 curve.
+	AlgorithmES256 = "ES256"
+)
+
+// rsaKeySizes are the supported sizes of RS256 signing keys.
+var rsaKeySizes = []int{2048, 3072, 4096}
+
+// signingKeyType is the JWT algorithm of a signing key and, for RS256, its
+// size in bits.
+type signingKeyType struct {
+	algorithm  string
+	rsaKeySize int
+}
+
+// defaultSigningKeyType is what the signing keys have always been.
+var defaultSigningKeyType = signingKeyType{algorithm: AlgorithmRS256, rsaKeySize: 2048}
+
+func (t signingKeyType) String() string {
+	if t.algorithm == AlgorithmRS256 {
+		return fmt.Sprintf("%s (%d bits)", t.algorithm, t.rsaKeySize)
+	}
+	return t.algorithm
+}
+
+// signingKeyTypeOf returns the type of the given PEM encoded private key.
+func signingKeyTypeOf(privateKeyPEM []byte) (signingKeyType, error) {
+	key, err := keyutil.ParsePrivateKeyPEM(privateKeyPEM)
+	if err != nil {
+		return signingKeyType{}, err
+	}
+	return signingKeyTypeOfKey(key)
+}
+
+// signingKeyTypeOfKey returns the type of the given parsed private key.
+func signingKeyTypeOfKey(key interface{}) (signingKeyType, error) {
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return signingKeyType{algorithm: AlgorithmRS256, rsaKeySize: key.N.BitLen()}, nil
+	case *ecdsa.PrivateKey:
+		if key.Curve != elliptic.P256() {
+			return signingKeyType{}, fmt.Errorf("unsupported ECDSA curve %s, only P-256 is supported", key.Curve.Params().Name)
+		}
+		return signingKeyType{algorithm: AlgorithmES256}, nil
+	default:
+		return signingKeyType{}, fmt.Errorf("unsupported private key type %T", key)
+	}
+}
+
+// generateSigningKey returns a new PEM encoded keypair of the given type.
+func generateSigningKey(keyType signingKeyType) (privateKeyPEM, publicKeyPEM []byte, err error) {
+	var privateKey crypto.Signer
+	switch keyType.algorithm {
+	case AlgorithmRS256:
+		privateKey, err = rsa.GenerateKey(rand.Reader, keyType.rsaKeySize)
+	case AlgorithmES256:
+		privateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	default:
+		err = fmt.Errorf("unsupported signing key algorithm %q", keyType.algorithm)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	privateKeyPEM, err = keyutil.MarshalPrivateKeyToPEM(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	publicKeyPEM, err = publicKeyToPem(privateKey.Public())
+	if err != nil {
+		return nil, nil, err
+	}
+	return privateKeyPEM, publicKeyPEM, nil
+}
+
+// validateSigningKeyPair ensures that the private key is of a supported type
+// and that the public key belongs to it, whatever the algorithm.
+func validateSigningKeyPair(privateKeyPEM, publicKeyPEM []byte) error {
+	privateKey, err := keyutil.ParsePrivateKeyPEM(privateKeyPEM)
+	if err != nil {
+		return fmt.Errorf("invalid private key: %w", err)
+	}
+	if _, err := signingKeyTypeOfKey(privateKey); err != nil {
+		return fmt.Errorf("invalid private key: %w", err)
+	}
+	publicKeys, err := keyutil.ParsePublicKeysPEM(publicKeyPEM)
+	if err != nil {
+		return fmt.Errorf("invalid public key: %w", err)
+	}
+	expected, err := x509.MarshalPKIXPublicKey(privateKey.(crypto.Signer).Public())
+	if err != nil {
+		return err
+	}
+	actual, err := x509.MarshalPKIXPublicKey(publicKeys[0])
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(expected, actual) {
+		return fmt.Errorf("the public key does not belong to the private key")
+	}
+	return nil
+}
+
+func publicKeyToPem(key crypto.PublicKey) ([]byte, error) {
+	keyInBytes, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	// RSA keys have always been written with this type, keep it so that the
+	// existing public keys stay byte for byte the same.
+	blockType := "PUBLIC KEY"
+	if _, ok := key.(*rsa.PublicKey); ok {
+		blockType = "RSA PUBLIC KEY"
+	}
+	keyinPem := pem.EncodeToMemory(
+		&pem.Block{
+			Type:  blockType,
+			Bytes: keyInBytes,
+		},
+	)
+	return keyinPem, nil
+}
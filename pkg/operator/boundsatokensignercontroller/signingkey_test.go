@@ -0,0 +1,171 @@
+package boundsatokensignercontroller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/util/keyutil"
+	"k8s.io/utils/clock"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestGenerateSigningKey(t *testing.T) {
+	for _, keyType := range []signingKeyType{
+		defaultSigningKeyType,
+		{algorithm: AlgorithmRS256, rsaKeySize: 3072},
+		{algorithm: AlgorithmES256},
+	} {
+		t.Run(keyType.String(), func(t *testing.T) {
+			privateKeyPEM, publicKeyPEM, err := generateSigningKey(keyType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, err := signingKeyTypeOf(privateKeyPEM)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != keyType {
+				t.Errorf("expected a %s key, got %s", keyType, actual)
+			}
+			if err := validateSigningKeyPair(privateKeyPEM, publicKeyPEM); err != nil {
+				t.Error(err)
+			}
+			// the kube-apiserver reads the public keys from one file per configmap key
+			if _, err := keyutil.ParsePublicKeysPEM(publicKeyPEM); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	rsaPrivateKeyPEM, _, err := generateSigningKey(defaultSigningKeyType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ecdsaPublicKeyPEM, err := generateSigningKey(signingKeyType{algorithm: AlgorithmES256})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validateSigningKeyPair(rsaPrivateKeyPEM, ecdsaPublicKeyPEM); err == nil {
+		t.Errorf("expected a keypair of mismatched keys to be refused")
+	}
+}
+
+func TestSigningKeyAlgorithmChange(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	rsaKey := signingSecret(t, targetNamespace, SigningKeySecretName, now.Add(-time.Hour))
+
+	kubeClient := fake.NewSimpleClientset(
+		signingSecretNamed(rsaKey, operatorNamespace, NextSigningKeySecretName),
+		rsaKey,
+		signingSecretNamed(rsaKey, targetNamespace, SigningKeySecretName+"-1"),
+		publicKeyConfigMap(PublicKeyConfigMapName, rsaKey),
+		publicKeyConfigMap(PublicKeyConfigMapName+"-1", rsaKey),
+	)
+	operatorClient := v1helpers.NewFakeStaticPodOperatorClient(
+		&operatorv1.StaticPodOperatorSpec{},
+		&operatorv1.StaticPodOperatorStatus{NodeStatuses: []operatorv1.NodeStatus{{NodeName: "master-0", CurrentRevision: 1}}},
+		nil, nil,
+	)
+	c := &BoundSATokenSignerController{
+		operatorClient:       operatorClient,
+		secretClient:         kubeClient.CoreV1(),
+		configMapClient:      kubeClient.CoreV1(),
+		operatorConfigLister: operatorConfigLister(t, map[string]string{signingKeyRotationKey: `{"algorithm":"ES256"}`}),
+		now:                  func() time.Time { return now },
+	}
+	recorder := events.NewInMemoryRecorder("test", clock.RealClock{})
+
+	// the RSA key is replaced right away
+	if err := c.sync(context.Background(), factory.NewSyncContext("test", recorder)); err != nil {
+		t.Fatal(err)
+	}
+	next, err := kubeClient.CoreV1().Secrets(operatorNamespace).Get(context.Background(), NextSigningKeySecretName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keyType, err := signingKeyTypeOf(next.Data[PrivateKeyKey]); err != nil || keyType.algorithm != AlgorithmES256 {
+		t.Fatalf("expected an ES256 signing key, got %v: %v", keyType, err)
+	}
+
+	// once both public keys are on every node, the ES256 key is promoted and
+	// tokens signed with the RSA key keep verifying
+	configMap, err := kubeClient.CoreV1().ConfigMaps(targetNamespace).Get(context.Background(), PublicKeyConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	revisioned := configMap.DeepCopy()
+	revisioned.Name = PublicKeyConfigMapName + "-2"
+	revisioned.ResourceVersion = ""
+	if _, err := kubeClient.CoreV1().ConfigMaps(targetNamespace).Create(context.Background(), revisioned, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kubeClient.CoreV1().Secrets(targetNamespace).Create(context.Background(), signingSecretNamed(rsaKey, targetNamespace, SigningKeySecretName+"-2"), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	_, status, resourceVersion, err := operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	status = status.DeepCopy()
+	status.NodeStatuses[0].CurrentRevision = 2
+	if _, err := operatorClient.UpdateStaticPodOperatorStatus(context.Background(), resourceVersion, status); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.sync(context.Background(), factory.NewSyncContext("test", recorder)); err != nil {
+		t.Fatal(err)
+	}
+	operand, err := kubeClient.CoreV1().Secrets(targetNamespace).Get(context.Background(), SigningKeySecretName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(operand.Data[PublicKeyKey]) != string(next.Data[PublicKeyKey]) {
+		t.Errorf("expected the ES256 signing key to be promoted")
+	}
+	if !configMapHasValue(revisioned, string(rsaKey.Data[PublicKeyKey])) {
+		t.Errorf("expected the RSA public key to be kept")
+	}
+}
+
+func TestSigningKeyAlgorithmNotReadFromOverrides(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	rsaKey := signingSecret(t, targetNamespace, SigningKeySecretName, now.Add(-time.Hour))
+
+	kubeClient := fake.NewSimpleClientset(
+		signingSecretNamed(rsaKey, operatorNamespace, NextSigningKeySecretName),
+		rsaKey,
+		signingSecretNamed(rsaKey, targetNamespace, SigningKeySecretName+"-1"),
+		publicKeyConfigMap(PublicKeyConfigMapName, rsaKey),
+		publicKeyConfigMap(PublicKeyConfigMapName+"-1", rsaKey),
+	)
+	c := &BoundSATokenSignerController{
+		operatorClient: v1helpers.NewFakeStaticPodOperatorClient(
+			&operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+				UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(`{"boundServiceAccountSigningKeyRotation":{"algorithm":"ES256"}}`)},
+			}},
+			&operatorv1.StaticPodOperatorStatus{NodeStatuses: []operatorv1.NodeStatus{{NodeName: "master-0", CurrentRevision: 1}}},
+			nil, nil,
+		),
+		secretClient:         kubeClient.CoreV1(),
+		configMapClient:      kubeClient.CoreV1(),
+		operatorConfigLister: operatorConfigLister(t, nil),
+		now:                  func() time.Time { return now },
+	}
+	if err := c.sync(context.Background(), factory.NewSyncContext("test", events.NewInMemoryRecorder("test", clock.RealClock{}))); err != nil {
+		t.Fatal(err)
+	}
+	next, err := kubeClient.CoreV1().Secrets(operatorNamespace).Get(context.Background(), NextSigningKeySecretName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(next.Data[PublicKeyKey]) != string(rsaKey.Data[PublicKeyKey]) {
+		t.Errorf("expected the algorithm in the unsupportedConfigOverrides to be ignored")
+	}
+}
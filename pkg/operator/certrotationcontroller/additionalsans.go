@@ -0,0 +1,88 @@
+package certrotationcontroller
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// additionalLoadBalancerSANsKey is the key of the additional load balancer
+// SANs in the operator config map.
+const additionalLoadBalancerSANsKey = "additionalLoadBalancerSANs"
+
+// AdditionalLoadBalancerSANs are hostnames and IP addresses the load balancer
+// serving certificates cover in addition to the hostname of the API server
+// URL in the infrastructure status, e.g. DNS aliases or a VIP address in
+// front of the API.
+type AdditionalLoadBalancerSANs struct {
+	// External applies to the certificate for status.apiServerURL.
+	External []string `json:"external,omitempty"`
+	// Internal applies to the certificate for status.apiServerInternalURL.
+	Internal []string `json:"internal,omitempty"`
+}
+
+// AdditionalLoadBalancerSANsFromConfigMap reads the additional load balancer
+// SANs from the operator config map. It returns nil if none are set.
+func AdditionalLoadBalancerSANsFromConfigMap(configMap *corev1.ConfigMap) (*AdditionalLoadBalancerSANs, error) {
+	sans := &AdditionalLoadBalancerSANs{}
+	if ok, err := operatorconfig.Unmarshal(configMap, additionalLoadBalancerSANsKey, sans); !ok || err != nil {
+		return nil, err
+	}
+	return sans, nil
+}
+
+// validSANs returns the names that are IP addresses or DNS subdomains,
+// optionally with a leading wildcard label, and an error for every other one.
+func validSANs(names []string) ([]string, []error) {
+	var valid []string
+	var errs []error
+	for _, name := range names {
+		if net.ParseIP(name) != nil {
+			valid = append(valid, name)
+			continue
+		}
+		var msgs []string
+		if strings.HasPrefix(name, "*.") {
+			msgs = validation.IsWildcardDNS1123Subdomain(name)
+		} else {
+			msgs = validation.IsDNS1123Subdomain(name)
+		}
+		if len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("%q is neither an IP address nor a valid hostname: %s", name, strings.Join(msgs, ", ")))
+			continue
+		}
+		valid = append(valid, name)
+	}
+	return valid, errs
+}
+
+// additionalLoadBalancerSANs returns the valid additional SANs the given
+// function picks for a load balancer. Like an invalid rotation profile,
+// invalid additional SANs are ignored rather than failing the sync, so that
+// they cannot keep the rotation from starting.
+func (c *CertRotationController) additionalLoadBalancerSANs(pick func(*AdditionalLoadBalancerSANs) []string) ([]string, error) {
+	configMap, err := operatorconfig.Get(c.operatorConfigLister)
+	if err != nil {
+		return nil, err
+	}
+
+	sans, err := AdditionalLoadBalancerSANsFromConfigMap(configMap)
+	if err != nil {
+		klog.Warningf("Ignoring the additional load balancer SANs: %v", err)
+		return nil, nil
+	}
+	if sans == nil {
+		return nil, nil
+	}
+	valid, errs := validSANs(pick(sans))
+	for _, err := range errs {
+		klog.Warningf("Ignoring additional load balancer SAN %v", err)
+	}
+	return valid, nil
+}
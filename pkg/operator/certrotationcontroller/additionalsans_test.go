@@ -0,0 +1,83 @@
+package certrotationcontroller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+func TestLoadBalancerHostnamesWithAdditionalSANs(t *testing.T) {
+	scenarios := []struct {
+		name             string
+		sans             string
+		expectedExternal []string
+		expectedInternal []string
+	}{
+		{
+			name:             "no additional SANs",
+			expectedExternal: []string{"api.example.com"},
+			expectedInternal: []string{"api-int.example.com"},
+		},
+		{
+			name: "additional hostnames and IPs",
+			sans: `external: ["api.alias.com", "*.api.example.com", "192.0.2.10", "2001:db8::10"]
+internal: ["10.0.0.5"]`,
+			expectedExternal: []string{"*.api.example.com", "192.0.2.10", "2001:db8::10", "api.alias.com", "api.example.com"},
+			expectedInternal: []string{"10.0.0.5", "api-int.example.com"},
+		},
+		{
+			name:             "invalid names are skipped",
+			sans:             `external: ["api.alias.com", "Not A Hostname", "api.example.com"]`,
+			expectedExternal: []string{"api.alias.com", "api.example.com"},
+			expectedInternal: []string{"api-int.example.com"},
+		},
+		{
+			name:             "malformed SANs are ignored",
+			sans:             `external: api.alias.com`,
+			expectedExternal: []string{"api.example.com"},
+			expectedInternal: []string{"api-int.example.com"},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			require.NoError(t, indexer.Add(&configv1.Infrastructure{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Status: configv1.InfrastructureStatus{
+					APIServerURL:         "https://api.example.com:6443",
+					APIServerInternalURL: "https://api-int.example.com:6443",
+				},
+			}))
+			configMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: operatorconfig.Namespace, Name: operatorconfig.Name},
+				Data:       map[string]string{"certRotationProfile": "{}"},
+			}
+			if len(scenario.sans) > 0 {
+				configMap.Data[additionalLoadBalancerSANsKey] = scenario.sans
+			}
+			require.NoError(t, configMapIndexer.Add(configMap))
+			controller := CertRotationController{
+				operatorConfigLister: corev1listers.NewConfigMapLister(configMapIndexer),
+				infrastructureLister: configv1listers.NewInfrastructureLister(indexer),
+				externalLoadBalancer: &DynamicServingRotation{hostnamesChanged: make(chan struct{}, 10)},
+				internalLoadBalancer: &DynamicServingRotation{hostnamesChanged: make(chan struct{}, 10)},
+			}
+
+			require.NoError(t, controller.syncExternalLoadBalancerHostnames())
+			require.NoError(t, controller.syncInternalLoadBalancerHostnames())
+			require.Equal(t, scenario.expectedExternal, controller.externalLoadBalancer.GetHostnames())
+			require.Equal(t, scenario.expectedInternal, controller.internalLoadBalancer.GetHostnames())
+		})
+	}
+}
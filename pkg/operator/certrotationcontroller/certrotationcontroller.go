@@ -3,6 +3,7 @@ package certrotationcontroller
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
@@ -10,6 +11,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
@@ -18,6 +20,7 @@ import (
 	configinformers "github.com/openshift/client-go/config/informers/externalversions"
 	configlisterv1 "github.com/openshift/client-go/config/listers/config/v1"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
 
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/certrotation"
@@ -27,11 +30,30 @@ import (
 	"github.com/openshift/library-go/pkg/pki"
 )
 
+const rotationDay = 24 * time.Hour
+
 type CertRotationController struct {
-	certRotators []factory.Controller
+	// rotatorsLock guards certRotators, their event handlers and inventory,
+	// which are rebuilt when the rotation profile changes.
+	rotatorsLock  sync.Mutex
+	certRotators  []factory.Controller
+	eventHandlers eventHandlers
+	inventory     []ManagedCertificate
+
+	// rotationProfileChanged hands a changed rotation profile to Run.
+	rotationProfileChanged chan *RotationProfile
+
+	// used to rebuild the cert rotators
+	kubeClient                 kubernetes.Interface
+	configInformer             configinformers.SharedInformerFactory
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces
+	featureGates               featuregates.FeatureGate
+	refreshOnlyWhenExpired     bool
 
+	operatorClient       v1helpers.StaticPodOperatorClient
 	networkLister        configlisterv1.NetworkLister
 	infrastructureLister configlisterv1.InfrastructureLister
+	operatorConfigLister corev1listers.ConfigMapLister
 
 	serviceNetwork        *DynamicServingRotation
 	serviceHostnamesQueue workqueue.RateLimitingInterface
@@ -54,6 +76,7 @@ func NewCertRotationController(
 	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
 	eventRecorder events.Recorder,
 	featureGates featuregates.FeatureGate,
+	rotationProfile *RotationProfile,
 ) (*CertRotationController, error) {
 	return newCertRotationController(
 		kubeClient,
@@ -62,6 +85,7 @@ func NewCertRotationController(
 		kubeInformersForNamespaces,
 		eventRecorder,
 		featureGates,
+		rotationProfile,
 		false,
 	)
 }
@@ -73,7 +97,9 @@ func NewCertRotationControllerOnlyWhenExpired(
 	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
 	eventRecorder events.Recorder,
 	featureGates featuregates.FeatureGate,
+	rotationProfile *RotationProfile,
 ) (*CertRotationController, error) {
+	RegisterRegenerationMetrics()
 	return newCertRotationController(
 		kubeClient,
 		operatorClient,
@@ -81,6 +107,7 @@ func NewCertRotationControllerOnlyWhenExpired(
 		kubeInformersForNamespaces,
 		eventRecorder,
 		featureGates,
+		rotationProfile,
 		true,
 	)
 }
@@ -92,11 +119,23 @@ func newCertRotationController(
 	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
 	eventRecorder events.Recorder,
 	featureGates featuregates.FeatureGate,
+	rotationProfile *RotationProfile,
 	refreshOnlyWhenExpired bool,
 ) (*CertRotationController, error) {
+	operatorConfigInformer := kubeInformersForNamespaces.InformersFor(operatorconfig.Namespace).Core().V1().ConfigMaps()
 	ret := &CertRotationController{
+		rotationProfileChanged: make(chan *RotationProfile, 1),
+
+		kubeClient:                 kubeClient,
+		configInformer:             configInformer,
+		kubeInformersForNamespaces: kubeInformersForNamespaces,
+		featureGates:               featureGates,
+		refreshOnlyWhenExpired:     refreshOnlyWhenExpired,
+
+		operatorClient:       operatorClient,
 		networkLister:        configInformer.Config().V1().Networks().Lister(),
 		infrastructureLister: configInformer.Config().V1().Infrastructures().Lister(),
+		operatorConfigLister: operatorConfigInformer.Lister(),
 
 		serviceHostnamesQueue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "ServiceHostnames"),
 		serviceNetwork:        &DynamicServingRotation{hostnamesChanged: make(chan struct{}, 10)},
@@ -111,20 +150,45 @@ func newCertRotationController(
 		cachesToSync: []cache.InformerSynced{
 			configInformer.Config().V1().Networks().Informer().HasSynced,
 			configInformer.Config().V1().Infrastructures().Informer().HasSynced,
+			operatorClient.Informer().HasSynced,
+			operatorConfigInformer.Informer().HasSynced,
 		},
 	}
 
 	configInformer.Config().V1().Networks().Informer().AddEventHandler(ret.serviceHostnameEventHandler())
 	configInformer.Config().V1().Infrastructures().Informer().AddEventHandler(ret.externalLoadBalancerHostnameEventHandler())
+	// the additional load balancer SANs are read from the operator config map
+	operatorConfigInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: factory.NamesFilter(operatorconfig.Name),
+		Handler:    ret.externalLoadBalancerHostnameEventHandler(),
+	})
+	operatorConfigInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: factory.NamesFilter(operatorconfig.Name),
+		Handler:    ret.internalLoadBalancerHostnameEventHandler(),
+	})
+
+	if featureGates != nil && featureGates.Enabled(features.FeatureGateConfigurablePKI) {
+		ret.cachesToSync = append(ret.cachesToSync, configInformer.Config().V1alpha1().PKIs().Informer().HasSynced)
+	}
+
+	ret.addCertRotators(rotationProfile)
+	return ret, nil
+}
+
+// addCertRotators creates the cert rotators with the rotation periods of the
+// profile and the feature gates.
+func (c *CertRotationController) addCertRotators(rotationProfile *RotationProfile) {
+	kubeClient := c.kubeClient
+	operatorClient := c.operatorClient
+	configInformer := c.configInformer
+	kubeInformersForNamespaces := c.kubeInformersForNamespaces
+	eventRecorder := c.recorder
+	featureGates := c.featureGates
+	refreshOnlyWhenExpired := c.refreshOnlyWhenExpired
 
 	foreverPeriod := 10 * 365 * 24 * time.Hour
 	foreverRefreshPeriod := 8 * 365 * 24 * time.Hour
 
-	rotationDay := 24 * time.Hour
-	// for the development cycle, make the rotation 60 times faster (every twelve hours or so).
-	// This must be reverted before we ship
-	rotationDay = rotationDay / 60
-
 	// Some certificates should not be affected by development cycle rotation
 	devRotationExceptionDay := 24 * time.Hour
 
@@ -146,13 +210,31 @@ func newCertRotationController(
 	}
 	klog.Infof("Setting monthPeriod to %v, yearPeriod to %v, tenMonthPeriod to %v", monthPeriod, yearPeriod, tenMonthPeriod)
 
+	serving := rotationPeriods{validity: monthPeriod, refresh: monthPeriod / 2}
+	client := rotationPeriods{validity: monthPeriod, refresh: monthPeriod / 2}
+	aggregatorSigner := rotationPeriods{validity: monthPeriod, refresh: monthPeriod / 2}
+	controlPlaneSigner := rotationPeriods{validity: 2 * devRotationExceptionMonth, refresh: devRotationExceptionMonth}
+
+	// Short rotation is for testing rotation itself, it wins over any profile.
+	if rotationProfile != nil && !featureGates.Enabled(features.FeatureShortCertRotation) {
+		serving = serving.override(rotationProfile.Serving)
+		client = client.override(rotationProfile.Client)
+		aggregatorSigner = aggregatorSigner.override(rotationProfile.Signer)
+		controlPlaneSigner = controlPlaneSigner.override(rotationProfile.Signer)
+		klog.Infof("Using certificate rotation profile: serving %v/%v, client %v/%v, signer %v/%v",
+			serving.validity, serving.refresh, client.validity, client.refresh, aggregatorSigner.validity, aggregatorSigner.refresh)
+	}
+
 	var pkiProvider pki.PKIProfileProvider
 	if featureGates != nil && featureGates.Enabled(features.FeatureGateConfigurablePKI) {
-		ret.cachesToSync = append(ret.cachesToSync, configInformer.Config().V1alpha1().PKIs().Informer().HasSynced)
 		pkiProvider = pki.NewClusterPKIProfileProvider(configInformer.Config().V1alpha1().PKIs().Lister())
 	}
+	// An explicit key algorithm in the rotation profile wins over the PKI configuration.
+	if rotationProfile != nil {
+		pkiProvider = newKeyProfileProvider(pkiProvider, rotationProfile.Keys)
+	}
 
-	certRotator := certrotation.NewCertRotationController(
+	c.addCertRotator(
 		"AggregatorProxyClientCert",
 		certrotation.RotatedSigningCASecret{
 			Namespace: operatorclient.OperatorNamespace,
@@ -163,8 +245,8 @@ func newCertRotationController(
 				TestName:                         "[sig-cli] oc adm new-project [apigroup:project.openshift.io][apigroup:authorization.openshift.io] [Suite:openshift/conformance/parallel]",
 				AutoRegenerateAfterOfflineExpiry: "https://github.com/openshift/cluster-kube-apiserver-operator/pull/1631",
 			},
-			Validity:               monthPeriod,
-			Refresh:                monthPeriod / 2,
+			Validity:               aggregatorSigner.validity,
+			Refresh:                aggregatorSigner.refresh,
 			RefreshOnlyWhenExpired: refreshOnlyWhenExpired,
 			CertificateName:        "kube-apiserver.aggregator-front-proxy-signer",
 			PKIProfileProvider:     pkiProvider,
@@ -197,8 +279,8 @@ func newCertRotationController(
 				TestName:                         "[sig-cli] oc adm new-project [apigroup:project.openshift.io][apigroup:authorization.openshift.io] [Suite:openshift/conformance/parallel]",
 				AutoRegenerateAfterOfflineExpiry: "https://github.com/openshift/cluster-kube-apiserver-operator/pull/1631",
 			},
-			Validity:               monthPeriod,
-			Refresh:                monthPeriod / 2,
+			Validity:               client.validity,
+			Refresh:                client.refresh,
 			RefreshOnlyWhenExpired: refreshOnlyWhenExpired,
 			CertCreator: &certrotation.ClientRotation{
 				UserInfo: &user.DefaultInfo{Name: "system:openshift-aggregator"},
@@ -213,9 +295,8 @@ func newCertRotationController(
 		eventRecorder,
 		&certrotation.StaticPodConditionStatusReporter{OperatorClient: operatorClient},
 	)
-	ret.certRotators = append(ret.certRotators, certRotator)
 
-	certRotator = certrotation.NewCertRotationController(
+	c.addCertRotator(
 		"KubeAPIServerToKubeletClientCert",
 		certrotation.RotatedSigningCASecret{
 			Namespace: operatorclient.OperatorNamespace,
@@ -262,8 +343,8 @@ func newCertRotationController(
 				TestName:                         "[sig-cli] Kubectl logs logs should be able to retrieve and filter logs  [Conformance] [Suite:openshift/conformance/parallel/minimal] [Suite:k8s]",
 				AutoRegenerateAfterOfflineExpiry: "https://github.com/openshift/cluster-kube-apiserver-operator/pull/1631",
 			},
-			Validity:               monthPeriod,
-			Refresh:                monthPeriod / 2,
+			Validity:               client.validity,
+			Refresh:                client.refresh,
 			RefreshOnlyWhenExpired: refreshOnlyWhenExpired,
 			CertCreator: &certrotation.ClientRotation{
 				UserInfo: &user.DefaultInfo{Name: "system:kube-apiserver", Groups: []string{"kube-master"}},
@@ -278,9 +359,8 @@ func newCertRotationController(
 		eventRecorder,
 		&certrotation.StaticPodConditionStatusReporter{OperatorClient: operatorClient},
 	)
-	ret.certRotators = append(ret.certRotators, certRotator)
 
-	certRotator = certrotation.NewCertRotationController(
+	c.addCertRotator(
 		"LocalhostServing",
 		certrotation.RotatedSigningCASecret{
 			Namespace: operatorclient.OperatorNamespace,
@@ -333,8 +413,8 @@ func newCertRotationController(
 				TestName:                         "[Conformance][sig-api-machinery][Feature:APIServer] local kubeconfig \"localhost.kubeconfig\" should be present on all masters and work [apigroup:config.openshift.io] [Suite:openshift/conformance/parallel/minimal]",
 				AutoRegenerateAfterOfflineExpiry: "https://github.com/openshift/cluster-kube-apiserver-operator/pull/1631",
 			},
-			Validity:               monthPeriod,
-			Refresh:                monthPeriod / 2,
+			Validity:               serving.validity,
+			Refresh:                serving.refresh,
 			RefreshOnlyWhenExpired: refreshOnlyWhenExpired,
 			CertCreator: &certrotation.ServingRotation{
 				Hostnames: func() []string { return []string{"localhost", "127.0.0.1"} },
@@ -349,9 +429,8 @@ func newCertRotationController(
 		eventRecorder,
 		&certrotation.StaticPodConditionStatusReporter{OperatorClient: operatorClient},
 	)
-	ret.certRotators = append(ret.certRotators, certRotator)
 
-	certRotator = certrotation.NewCertRotationController(
+	c.addCertRotator(
 		"ServiceNetworkServing",
 		certrotation.RotatedSigningCASecret{
 			Namespace: operatorclient.OperatorNamespace,
@@ -404,12 +483,12 @@ func newCertRotationController(
 				TestName:                         "[Conformance][sig-api-machinery][Feature:APIServer] kube-apiserver should be accessible via service network endpoint [Suite:openshift/conformance/parallel/minimal]",
 				AutoRegenerateAfterOfflineExpiry: "https://github.com/openshift/cluster-kube-apiserver-operator/pull/1631",
 			},
-			Validity:               monthPeriod,
-			Refresh:                monthPeriod / 2,
+			Validity:               serving.validity,
+			Refresh:                serving.refresh,
 			RefreshOnlyWhenExpired: refreshOnlyWhenExpired,
 			CertCreator: &certrotation.ServingRotation{
-				Hostnames:        ret.serviceNetwork.GetHostnames,
-				HostnamesChanged: ret.serviceNetwork.hostnamesChanged,
+				Hostnames:        c.serviceNetwork.GetHostnames,
+				HostnamesChanged: c.serviceNetwork.hostnamesChanged,
 			},
 			CertificateName:    "kube-apiserver.service-network-serving",
 			PKIProfileProvider: pkiProvider,
@@ -421,9 +500,8 @@ func newCertRotationController(
 		eventRecorder,
 		&certrotation.StaticPodConditionStatusReporter{OperatorClient: operatorClient},
 	)
-	ret.certRotators = append(ret.certRotators, certRotator)
 
-	certRotator = certrotation.NewCertRotationController(
+	c.addCertRotator(
 		"ExternalLoadBalancerServing",
 		certrotation.RotatedSigningCASecret{
 			Namespace: operatorclient.OperatorNamespace,
@@ -476,12 +554,12 @@ func newCertRotationController(
 				TestName:                         "[sig-apps] Deployment RollingUpdateDeployment should delete old pods and create new ones [Conformance] [Suite:openshift/conformance/parallel/minimal] [Suite:k8s]",
 				AutoRegenerateAfterOfflineExpiry: "https://github.com/openshift/cluster-kube-apiserver-operator/pull/1631",
 			},
-			Validity:               monthPeriod,
-			Refresh:                monthPeriod / 2,
+			Validity:               serving.validity,
+			Refresh:                serving.refresh,
 			RefreshOnlyWhenExpired: refreshOnlyWhenExpired,
 			CertCreator: &certrotation.ServingRotation{
-				Hostnames:        ret.externalLoadBalancer.GetHostnames,
-				HostnamesChanged: ret.externalLoadBalancer.hostnamesChanged,
+				Hostnames:        c.externalLoadBalancer.GetHostnames,
+				HostnamesChanged: c.externalLoadBalancer.hostnamesChanged,
 			},
 			CertificateName:    "kube-apiserver.external-loadbalancer-serving",
 			PKIProfileProvider: pkiProvider,
@@ -493,9 +571,8 @@ func newCertRotationController(
 		eventRecorder,
 		&certrotation.StaticPodConditionStatusReporter{OperatorClient: operatorClient},
 	)
-	ret.certRotators = append(ret.certRotators, certRotator)
 
-	certRotator = certrotation.NewCertRotationController(
+	c.addCertRotator(
 		"InternalLoadBalancerServing",
 		certrotation.RotatedSigningCASecret{
 			Namespace: operatorclient.OperatorNamespace,
@@ -548,12 +625,12 @@ func newCertRotationController(
 				TestName:                         "[Conformance][sig-api-machinery][Feature:APIServer] kube-apiserver should be accessible via api-int endpoint [Suite:openshift/conformance/parallel/minimal]",
 				AutoRegenerateAfterOfflineExpiry: "https://github.com/openshift/cluster-kube-apiserver-operator/pull/1631",
 			},
-			Validity:               monthPeriod,
-			Refresh:                monthPeriod / 2,
+			Validity:               serving.validity,
+			Refresh:                serving.refresh,
 			RefreshOnlyWhenExpired: refreshOnlyWhenExpired,
 			CertCreator: &certrotation.ServingRotation{
-				Hostnames:        ret.internalLoadBalancer.GetHostnames,
-				HostnamesChanged: ret.internalLoadBalancer.hostnamesChanged,
+				Hostnames:        c.internalLoadBalancer.GetHostnames,
+				HostnamesChanged: c.internalLoadBalancer.hostnamesChanged,
 			},
 			CertificateName:    "kube-apiserver.internal-loadbalancer-serving",
 			PKIProfileProvider: pkiProvider,
@@ -565,9 +642,8 @@ func newCertRotationController(
 		eventRecorder,
 		&certrotation.StaticPodConditionStatusReporter{OperatorClient: operatorClient},
 	)
-	ret.certRotators = append(ret.certRotators, certRotator)
 
-	certRotator = certrotation.NewCertRotationController(
+	c.addCertRotator(
 		"LocalhostRecoveryServing",
 		certrotation.RotatedSigningCASecret{
 			Namespace: operatorclient.OperatorNamespace,
@@ -641,9 +717,8 @@ func newCertRotationController(
 		eventRecorder,
 		&certrotation.StaticPodConditionStatusReporter{OperatorClient: operatorClient},
 	)
-	ret.certRotators = append(ret.certRotators, certRotator)
 
-	certRotator = certrotation.NewCertRotationController(
+	c.addCertRotator(
 		"KubeControllerManagerClient",
 		certrotation.RotatedSigningCASecret{
 			Namespace: operatorclient.OperatorNamespace,
@@ -654,8 +729,8 @@ func newCertRotationController(
 				TestName:                         "[sig-apps] Deployment RollingUpdateDeployment should delete old pods and create new ones [Conformance] [Suite:openshift/conformance/parallel/minimal] [Suite:k8s]",
 				AutoRegenerateAfterOfflineExpiry: "https://github.com/openshift/cluster-kube-apiserver-operator/pull/1631",
 			},
-			Validity:               2 * devRotationExceptionMonth,
-			Refresh:                devRotationExceptionMonth,
+			Validity:               controlPlaneSigner.validity,
+			Refresh:                controlPlaneSigner.refresh,
 			RefreshOnlyWhenExpired: refreshOnlyWhenExpired,
 			CertificateName:        "kube-apiserver.control-plane-client-signer",
 			PKIProfileProvider:     pkiProvider,
@@ -688,8 +763,8 @@ func newCertRotationController(
 				TestName:                         "[sig-apps] Deployment RollingUpdateDeployment should delete old pods and create new ones [Conformance] [Suite:openshift/conformance/parallel/minimal] [Suite:k8s]",
 				AutoRegenerateAfterOfflineExpiry: "https://github.com/openshift/cluster-kube-apiserver-operator/pull/1631",
 			},
-			Validity:               monthPeriod,
-			Refresh:                monthPeriod / 2,
+			Validity:               client.validity,
+			Refresh:                client.refresh,
 			RefreshOnlyWhenExpired: refreshOnlyWhenExpired,
 			CertCreator: &certrotation.ClientRotation{
 				UserInfo: &user.DefaultInfo{Name: "system:kube-controller-manager"},
@@ -704,9 +779,8 @@ func newCertRotationController(
 		eventRecorder,
 		&certrotation.StaticPodConditionStatusReporter{OperatorClient: operatorClient},
 	)
-	ret.certRotators = append(ret.certRotators, certRotator)
 
-	certRotator = certrotation.NewCertRotationController(
+	c.addCertRotator(
 		"KubeSchedulerClient",
 		certrotation.RotatedSigningCASecret{
 			Namespace: operatorclient.OperatorNamespace,
@@ -717,8 +791,8 @@ func newCertRotationController(
 				TestName:                         "[sig-apps] Deployment RollingUpdateDeployment should delete old pods and create new ones [Conformance] [Suite:openshift/conformance/parallel/minimal] [Suite:k8s]",
 				AutoRegenerateAfterOfflineExpiry: "https://github.com/openshift/cluster-kube-apiserver-operator/pull/1631",
 			},
-			Validity:               2 * devRotationExceptionMonth,
-			Refresh:                devRotationExceptionMonth,
+			Validity:               controlPlaneSigner.validity,
+			Refresh:                controlPlaneSigner.refresh,
 			RefreshOnlyWhenExpired: refreshOnlyWhenExpired,
 			CertificateName:        "kube-apiserver.control-plane-client-signer",
 			PKIProfileProvider:     pkiProvider,
@@ -751,8 +825,8 @@ func newCertRotationController(
 				TestName:                         "[sig-apps] Deployment RollingUpdateDeployment should delete old pods and create new ones [Conformance] [Suite:openshift/conformance/parallel/minimal] [Suite:k8s]",
 				AutoRegenerateAfterOfflineExpiry: "https://github.com/openshift/cluster-kube-apiserver-operator/pull/1631",
 			},
-			Validity:               monthPeriod,
-			Refresh:                monthPeriod / 2,
+			Validity:               client.validity,
+			Refresh:                client.refresh,
 			RefreshOnlyWhenExpired: refreshOnlyWhenExpired,
 			CertCreator: &certrotation.ClientRotation{
 				UserInfo: &user.DefaultInfo{Name: "system:kube-scheduler"},
@@ -767,9 +841,8 @@ func newCertRotationController(
 		eventRecorder,
 		&certrotation.StaticPodConditionStatusReporter{OperatorClient: operatorClient},
 	)
-	ret.certRotators = append(ret.certRotators, certRotator)
 
-	certRotator = certrotation.NewCertRotationController(
+	c.addCertRotator(
 		"ControlPlaneNodeAdminClient",
 		certrotation.RotatedSigningCASecret{
 			Namespace: operatorclient.OperatorNamespace,
@@ -780,8 +853,8 @@ func newCertRotationController(
 				TestName:                         "[sig-apps] Deployment RollingUpdateDeployment should delete old pods and create new ones [Conformance] [Suite:openshift/conformance/parallel/minimal] [Suite:k8s]",
 				AutoRegenerateAfterOfflineExpiry: "https://github.com/openshift/cluster-kube-apiserver-operator/pull/1631",
 			},
-			Validity:               2 * devRotationExceptionMonth,
-			Refresh:                devRotationExceptionMonth,
+			Validity:               controlPlaneSigner.validity,
+			Refresh:                controlPlaneSigner.refresh,
 			RefreshOnlyWhenExpired: refreshOnlyWhenExpired,
 			CertificateName:        "kube-apiserver.control-plane-client-signer",
 			PKIProfileProvider:     pkiProvider,
@@ -814,8 +887,8 @@ func newCertRotationController(
 				TestName:                         "[Conformance][sig-api-machinery][Feature:APIServer] local kubeconfig \"control-plane-node.kubeconfig\" should be present in all kube-apiserver containers [Suite:openshift/conformance/parallel/minimal]",
 				AutoRegenerateAfterOfflineExpiry: "https://github.com/openshift/cluster-kube-apiserver-operator/pull/1631",
 			},
-			Validity:               monthPeriod,
-			Refresh:                monthPeriod / 2,
+			Validity:               client.validity,
+			Refresh:                client.refresh,
 			RefreshOnlyWhenExpired: refreshOnlyWhenExpired,
 			CertCreator: &certrotation.ClientRotation{
 				UserInfo: &user.DefaultInfo{Name: "system:control-plane-node-admin", Groups: []string{"system:masters"}},
@@ -830,9 +903,8 @@ func newCertRotationController(
 		eventRecorder,
 		&certrotation.StaticPodConditionStatusReporter{OperatorClient: operatorClient},
 	)
-	ret.certRotators = append(ret.certRotators, certRotator)
 
-	certRotator = certrotation.NewCertRotationController(
+	c.addCertRotator(
 		"CheckEndpointsClient",
 		certrotation.RotatedSigningCASecret{
 			Namespace: operatorclient.OperatorNamespace,
@@ -843,8 +915,8 @@ func newCertRotationController(
 				TestName:                         "[sig-apps] Deployment RollingUpdateDeployment should delete old pods and create new ones [Conformance] [Suite:openshift/conformance/parallel/minimal] [Suite:k8s]",
 				AutoRegenerateAfterOfflineExpiry: "https://github.com/openshift/cluster-kube-apiserver-operator/pull/1631",
 			},
-			Validity:               2 * devRotationExceptionMonth,
-			Refresh:                devRotationExceptionMonth,
+			Validity:               controlPlaneSigner.validity,
+			Refresh:                controlPlaneSigner.refresh,
 			RefreshOnlyWhenExpired: refreshOnlyWhenExpired,
 			CertificateName:        "kube-apiserver.control-plane-client-signer",
 			PKIProfileProvider:     pkiProvider,
@@ -877,8 +949,8 @@ func newCertRotationController(
 				TestName:                         "[Conformance][sig-api-machinery][Feature:APIServer] local kubeconfig \"check-endpoints.kubeconfig\" should be present in all kube-apiserver containers [Suite:openshift/conformance/parallel/minimal]",
 				AutoRegenerateAfterOfflineExpiry: "https://github.com/openshift/cluster-kube-apiserver-operator/pull/1631",
 			},
-			Validity:               monthPeriod,
-			Refresh:                monthPeriod / 2,
+			Validity:               client.validity,
+			Refresh:                client.refresh,
 			RefreshOnlyWhenExpired: refreshOnlyWhenExpired,
 			CertCreator: &certrotation.ClientRotation{
 				UserInfo: &user.DefaultInfo{Name: "system:serviceaccount:openshift-kube-apiserver:check-endpoints"},
@@ -893,9 +965,8 @@ func newCertRotationController(
 		eventRecorder,
 		&certrotation.StaticPodConditionStatusReporter{OperatorClient: operatorClient},
 	)
-	ret.certRotators = append(ret.certRotators, certRotator)
 
-	certRotator = certrotation.NewCertRotationController(
+	c.addCertRotator(
 		"NodeSystemAdminClient",
 		certrotation.RotatedSigningCASecret{
 			Namespace: operatorclient.OperatorNamespace,
@@ -965,9 +1036,6 @@ func newCertRotationController(
 		eventRecorder,
 		&certrotation.StaticPodConditionStatusReporter{OperatorClient: operatorClient},
 	)
-	ret.certRotators = append(ret.certRotators, certRotator)
-
-	return ret, nil
 }
 
 func (c *CertRotationController) WaitForReady(stopCh <-chan struct{}) {
@@ -996,7 +1064,7 @@ func (c *CertRotationController) WaitForReady(stopCh <-chan struct{}) {
 func (c *CertRotationController) RunOnce() error {
 	errlist := []error{}
 	runOnceCtx := context.WithValue(context.Background(), certrotation.RunOnceContextKey, true)
-	for _, certRotator := range c.certRotators {
+	for _, certRotator := range c.currentCertRotators() {
 		if err := certRotator.Sync(runOnceCtx, factory.NewSyncContext("CertRotationController", c.recorder)); err != nil {
 			errlist = append(errlist, err)
 		}
@@ -1005,18 +1073,83 @@ func (c *CertRotationController) RunOnce() error {
 	return utilerrors.NewAggregate(errlist)
 }
 
+// SetRotationProfile makes Run replace the cert rotators with ones using the
+// rotation periods of the profile. Only the latest profile is kept when Run
+// has not picked up the previous one yet.
+func (c *CertRotationController) SetRotationProfile(profile *RotationProfile) {
+	for {
+		select {
+		case c.rotationProfileChanged <- profile:
+			return
+		default:
+		}
+		select {
+		case <-c.rotationProfileChanged:
+		default:
+		}
+	}
+}
+
+func (c *CertRotationController) currentCertRotators() []factory.Controller {
+	c.rotatorsLock.Lock()
+	defer c.rotatorsLock.Unlock()
+	return c.certRotators
+}
+
+// replaceCertRotators rebuilds the cert rotators and the inventory. The event
+// handlers of the replaced rotators are removed from the shared informers.
+func (c *CertRotationController) replaceCertRotators(profile *RotationProfile) {
+	c.rotatorsLock.Lock()
+	defer c.rotatorsLock.Unlock()
+	c.eventHandlers.removeAll()
+	c.certRotators = nil
+	c.inventory = nil
+	c.addCertRotators(profile)
+}
+
 func (c *CertRotationController) Run(ctx context.Context, workers int) {
 	klog.Infof("Starting CertRotation")
 	defer klog.Infof("Shutting down CertRotation")
 	c.WaitForReady(ctx.Done())
 
-	go wait.Until(c.runServiceHostnames, time.Second, ctx.Done())
-	go wait.Until(c.runExternalLoadBalancerHostnames, time.Second, ctx.Done())
-	go wait.Until(c.runInternalLoadBalancerHostnames, time.Second, ctx.Done())
+	var wg sync.WaitGroup
+	for _, run := range []func(){c.runServiceHostnames, c.runExternalLoadBalancerHostnames, c.runInternalLoadBalancerHostnames} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wait.Until(run, time.Second, ctx.Done())
+		}()
+	}
+
+	for {
+		rotatorsCtx, stopRotators := context.WithCancel(ctx)
+		var rotatorsWG sync.WaitGroup
+		for _, certRotator := range c.currentCertRotators() {
+			rotatorsWG.Add(1)
+			go func() {
+				defer rotatorsWG.Done()
+				certRotator.Run(rotatorsCtx, workers)
+			}()
+		}
+
+		var profile *RotationProfile
+		select {
+		case <-ctx.Done():
+		case profile = <-c.rotationProfileChanged:
+		}
+		stopRotators()
+		rotatorsWG.Wait()
+		if ctx.Err() != nil {
+			break
+		}
 
-	for _, certRotator := range c.certRotators {
-		go certRotator.Run(ctx, workers)
+		klog.Infof("Restarting the cert rotators with the changed certificate rotation profile")
+		c.replaceCertRotators(profile)
 	}
 
-	<-ctx.Done()
+	// the hostname workers block on their queues until these are shut down
+	c.serviceHostnamesQueue.ShutDown()
+	c.externalLoadBalancerHostnamesQueue.ShutDown()
+	c.internalLoadBalancerHostnamesQueue.ShutDown()
+	wg.Wait()
 }
@@ -0,0 +1,80 @@
+package certrotationcontroller
+
+import (
+	"time"
+
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// eventHandler is an event handler a cert rotator registered on a shared
+// informer.
+type eventHandler struct {
+	informer     cache.SharedIndexInformer
+	registration cache.ResourceEventHandlerRegistration
+}
+
+// eventHandlers records the event handlers the cert rotators register, so
+// that they can be removed from the shared informers when the rotators are
+// replaced.
+type eventHandlers struct {
+	handlers []eventHandler
+}
+
+func (h *eventHandlers) secretInformer(informer corev1informers.SecretInformer) corev1informers.SecretInformer {
+	return trackedSecretInformer{SecretInformer: informer, handlers: h}
+}
+
+func (h *eventHandlers) configMapInformer(informer corev1informers.ConfigMapInformer) corev1informers.ConfigMapInformer {
+	return trackedConfigMapInformer{ConfigMapInformer: informer, handlers: h}
+}
+
+// removeAll removes the recorded event handlers from their informers.
+func (h *eventHandlers) removeAll() {
+	for _, handler := range h.handlers {
+		if err := handler.informer.RemoveEventHandler(handler.registration); err != nil {
+			klog.Warningf("Failed to remove the event handler of a replaced cert rotator: %v", err)
+		}
+	}
+	h.handlers = nil
+}
+
+type trackedSecretInformer struct {
+	corev1informers.SecretInformer
+	handlers *eventHandlers
+}
+
+func (i trackedSecretInformer) Informer() cache.SharedIndexInformer {
+	return trackedInformer{SharedIndexInformer: i.SecretInformer.Informer(), handlers: i.handlers}
+}
+
+type trackedConfigMapInformer struct {
+	corev1informers.ConfigMapInformer
+	handlers *eventHandlers
+}
+
+func (i trackedConfigMapInformer) Informer() cache.SharedIndexInformer {
+	return trackedInformer{SharedIndexInformer: i.ConfigMapInformer.Informer(), handlers: i.handlers}
+}
+
+type trackedInformer struct {
+	cache.SharedIndexInformer
+	handlers *eventHandlers
+}
+
+func (i trackedInformer) AddEventHandler(handler cache.ResourceEventHandler) (cache.ResourceEventHandlerRegistration, error) {
+	registration, err := i.SharedIndexInformer.AddEventHandler(handler)
+	if err == nil {
+		i.handlers.handlers = append(i.handlers.handlers, eventHandler{informer: i.SharedIndexInformer, registration: registration})
+	}
+	return registration, err
+}
+
+func (i trackedInformer) AddEventHandlerWithResyncPeriod(handler cache.ResourceEventHandler, resyncPeriod time.Duration) (cache.ResourceEventHandlerRegistration, error) {
+	registration, err := i.SharedIndexInformer.AddEventHandlerWithResyncPeriod(handler, resyncPeriod)
+	if err == nil {
+		i.handlers.handlers = append(i.handlers.handlers, eventHandler{informer: i.SharedIndexInformer, registration: registration})
+	}
+	return registration, err
+}
@@ -7,6 +7,7 @@ import (
 	"k8s.io/klog/v2"
 
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/cache"
 )
 
@@ -24,8 +25,14 @@ func (c *CertRotationController) syncExternalLoadBalancerHostnames() error {
 	hostname_arr := strings.Split(hostname, ":")
 	hostname = hostname_arr[0]
 
-	klog.V(2).Infof("syncing external loadbalancer hostnames: %v", hostname)
-	c.externalLoadBalancer.setHostnames([]string{hostname})
+	additional, err := c.additionalLoadBalancerSANs(func(sans *AdditionalLoadBalancerSANs) []string { return sans.External })
+	if err != nil {
+		return err
+	}
+	hostnames := sets.NewString(hostname).Insert(additional...).List()
+
+	klog.V(2).Infof("syncing external loadbalancer hostnames: %v", hostnames)
+	c.externalLoadBalancer.setHostnames(hostnames)
 	return nil
 }
 
@@ -0,0 +1,449 @@
+package certrotationcontroller
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/util/cert"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+const (
+	// ForcedRotationAnnotationPrefix on the KubeAPIServer CR requests the
+	// rotation of the signer or cert key pair named by the rest of the key. The
+	// value is the time of the request in RFC3339, a later time requests
+	// another rotation, e.g.
+	//
+	//   force-rotation.kubeapiservers.operator.openshift.io/kube-apiserver-to-kubelet-signer: "2026-10-18T10:00:00Z"
+	ForcedRotationAnnotationPrefix = "force-rotation.kubeapiservers.operator.openshift.io/"
+
+	// forcedRotationRequestedAtAnnotation marks a secret as rotated for the
+	// request of the given time.
+	forcedRotationRequestedAtAnnotation = "kubeapiservers.operator.openshift.io/forced-rotation-requested-at"
+	// forcedRotationReplacedIssuerAnnotation is the common name of the signer
+	// a forced rotation replaced. It is removed from the CA bundles once no
+	// cert key pair is signed by it anymore.
+	forcedRotationReplacedIssuerAnnotation = "kubeapiservers.operator.openshift.io/forced-rotation-replaced-issuer"
+
+	forcedRotationProgressingConditionType = "ForcedCertRotationProgressing"
+	// forcedRotationInvalidRequestsConditionType lists the requests that are
+	// ignored. It doesn't degrade the operator, a typo in an annotation doesn't
+	// affect the cluster.
+	forcedRotationInvalidRequestsConditionType = "ForcedCertRotationInvalidRequests"
+)
+
+// ForcedRotationController rotates signers and cert key pairs on request,
+// e.g. when a key might have leaked. The rotators regenerate a secret once
+// its validity annotations are gone, so the controller only removes them in
+// the right order:
+//
+//  1. the requested secret is regenerated,
+//  2. the CA bundles of a signer pick up the new signer,
+//  3. the cert key pairs signed by the replaced signer are regenerated,
+//  4. every node runs a revision with the regenerated revisioned cert key pairs,
+//  5. the replaced signer is removed from the CA bundles.
+//
+// Progress is reported through the ForcedCertRotationProgressing condition.
+type ForcedRotationController struct {
+	operatorClient  v1helpers.StaticPodOperatorClient
+	inventory       []ManagedCertificate
+	secretClient    corev1client.SecretsGetter
+	configMapClient corev1client.ConfigMapsGetter
+	secretLister    corev1listers.SecretLister
+	configMapLister corev1listers.ConfigMapLister
+
+	// revisionedSecrets are the secrets of revisionNamespace the kube-apiserver
+	// reads from its revision, as <name>-<revision>.
+	revisionNamespace string
+	revisionedSecrets sets.Set[string]
+}
+
+func NewForcedRotationController(
+	operatorClient v1helpers.StaticPodOperatorClient,
+	inventory []ManagedCertificate,
+	revisionedSecrets sets.Set[string],
+	kubeClient corev1client.CoreV1Interface,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	recorder events.Recorder,
+) factory.Controller {
+	c := &ForcedRotationController{
+		operatorClient:    operatorClient,
+		inventory:         inventory,
+		secretClient:      kubeClient,
+		configMapClient:   kubeClient,
+		secretLister:      kubeInformersForNamespaces.SecretLister(),
+		configMapLister:   kubeInformersForNamespaces.ConfigMapLister(),
+		revisionNamespace: operatorclient.TargetNamespace,
+		revisionedSecrets: revisionedSecrets,
+	}
+
+	namespaces := sets.New(operatorclient.TargetNamespace)
+	for _, managed := range inventory {
+		namespaces.Insert(managed.Namespace)
+	}
+	informers := []factory.Informer{operatorClient.Informer()}
+	for _, namespace := range sets.List(namespaces) {
+		informers = append(informers,
+			kubeInformersForNamespaces.InformersFor(namespace).Core().V1().Secrets().Informer(),
+			kubeInformersForNamespaces.InformersFor(namespace).Core().V1().ConfigMaps().Informer(),
+		)
+	}
+
+	return factory.New().
+		WithInformers(informers...).
+		ResyncEvery(time.Minute).
+		WithSync(c.sync).
+		ToController("ForcedCertRotationController", recorder.WithComponentSuffix("forced-cert-rotation-controller"))
+}
+
+func (c *ForcedRotationController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	meta, err := c.operatorClient.GetObjectMeta()
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for key := range meta.Annotations {
+		if name, ok := strings.CutPrefix(key, ForcedRotationAnnotationPrefix); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var errs []error
+	var invalid, inProgress []string
+	for _, name := range names {
+		requestedAt := meta.Annotations[ForcedRotationAnnotationPrefix+name]
+		if _, err := time.Parse(time.RFC3339, requestedAt); err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: the request time %q is not in RFC3339 format", name, requestedAt))
+			continue
+		}
+		managed, err := c.lookup(name)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+
+		progress, err := c.rotate(ctx, syncCtx.Recorder(), managed, requestedAt)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			inProgress = append(inProgress, fmt.Sprintf("%s: %v", name, err))
+		case len(progress) > 0:
+			inProgress = append(inProgress, fmt.Sprintf("%s: %s", name, progress))
+		}
+	}
+
+	progressing := operatorv1.OperatorCondition{
+		Type:   forcedRotationProgressingConditionType,
+		Status: operatorv1.ConditionFalse,
+	}
+	if len(inProgress) > 0 {
+		progressing.Status = operatorv1.ConditionTrue
+		progressing.Reason = "RotationInProgress"
+		progressing.Message = strings.Join(inProgress, "\n")
+	}
+	invalidRequests := operatorv1.OperatorCondition{
+		Type:   forcedRotationInvalidRequestsConditionType,
+		Status: operatorv1.ConditionFalse,
+	}
+	if len(invalid) > 0 {
+		invalidRequests.Status = operatorv1.ConditionTrue
+		invalidRequests.Reason = "InvalidRequest"
+		invalidRequests.Message = strings.Join(invalid, "\n")
+	}
+	_, status, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+	previous := v1helpers.FindOperatorCondition(status.Conditions, forcedRotationInvalidRequestsConditionType)
+	if len(invalid) > 0 && (previous == nil || previous.Message != invalidRequests.Message) {
+		syncCtx.Recorder().Warningf("ForcedCertRotationInvalidRequest", "Ignoring the forced rotation requests %s", strings.Join(invalid, "; "))
+	}
+	if _, _, err := v1helpers.UpdateStatus(ctx, c.operatorClient, v1helpers.UpdateConditionFn(progressing), v1helpers.UpdateConditionFn(invalidRequests)); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// rotate advances the forced rotation of the named secret by one step. It
+// returns what the rotation waits for, or an empty string once it is done.
+func (c *ForcedRotationController) rotate(ctx context.Context, recorder events.Recorder, managed ManagedCertificate, requestedAt string) (string, error) {
+	secret, err := c.secretLister.Secrets(managed.Namespace).Get(managed.Name)
+	if apierrors.IsNotFound(err) {
+		return fmt.Sprintf("waiting for %s/%s to be created", managed.Namespace, managed.Name), nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if secret.Annotations[forcedRotationRequestedAtAnnotation] != requestedAt {
+		replacedIssuer := ""
+		if managed.Kind == ManagedCertificateKindSigner {
+			if replacedIssuer, err = commonName(secret); err != nil {
+				return "", err
+			}
+		}
+		if err := c.forceRegeneration(ctx, recorder, secret, requestedAt, replacedIssuer); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("waiting for %s/%s to be regenerated", managed.Namespace, managed.Name), nil
+	}
+	if len(secret.Annotations[certrotation.CertificateNotAfterAnnotation]) == 0 {
+		return fmt.Sprintf("waiting for %s/%s to be regenerated", managed.Namespace, managed.Name), nil
+	}
+	if managed.Kind == ManagedCertificateKindTarget {
+		return "", nil
+	}
+
+	return c.replaceSigner(ctx, recorder, managed, secret, requestedAt)
+}
+
+// replaceSigner rolls the cert key pairs signed by the replaced signer and
+// prunes it from the CA bundles, once they trust the new signer and every node
+// runs the new cert key pairs.
+func (c *ForcedRotationController) replaceSigner(ctx context.Context, recorder events.Recorder, signer ManagedCertificate, secret *corev1.Secret, requestedAt string) (string, error) {
+	replacedIssuer := secret.Annotations[forcedRotationReplacedIssuerAnnotation]
+	currentIssuer, err := commonName(secret)
+	if err != nil {
+		return "", err
+	}
+	if currentIssuer == replacedIssuer {
+		return fmt.Sprintf("waiting for %s/%s to be regenerated", signer.Namespace, signer.Name), nil
+	}
+
+	bundles := c.related(signer, ManagedCertificateKindCABundle)
+	for _, bundle := range bundles {
+		configMap, err := c.configMapLister.ConfigMaps(bundle.Namespace).Get(bundle.Name)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return "", err
+		}
+		if configMap == nil || !bundleContains(configMap, currentIssuer) {
+			return fmt.Sprintf("waiting for the CA bundle %s/%s to include the new signer", bundle.Namespace, bundle.Name), nil
+		}
+	}
+
+	var pending []string
+	for _, target := range c.related(signer, ManagedCertificateKindTarget) {
+		targetSecret, err := c.secretLister.Secrets(target.Namespace).Get(target.Name)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if targetSecret.Annotations[certrotation.CertificateIssuer] != replacedIssuer {
+			continue
+		}
+		pending = append(pending, target.Namespace+"/"+target.Name)
+		if targetSecret.Annotations[forcedRotationRequestedAtAnnotation] == requestedAt {
+			continue
+		}
+		if err := c.forceRegeneration(ctx, recorder, targetSecret, requestedAt, ""); err != nil {
+			return "", err
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Sprintf("waiting for %s to be signed by the new signer", strings.Join(pending, ", ")), nil
+	}
+
+	// A node still running a revision with a replaced cert key pair would stop
+	// being trusted once its signer leaves the CA bundles.
+	if progress, err := c.waitForNodeRevisions(signer, replacedIssuer); err != nil || len(progress) > 0 {
+		return progress, err
+	}
+
+	for _, bundle := range bundles {
+		if err := c.pruneBundle(ctx, recorder, bundle, replacedIssuer); err != nil {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// waitForNodeRevisions returns what the rotation waits for until the current
+// revision of every node has copies of the revisioned cert key pairs of the
+// signer that are not signed by the replaced issuer anymore.
+func (c *ForcedRotationController) waitForNodeRevisions(signer ManagedCertificate, replacedIssuer string) (string, error) {
+	var revisioned []ManagedCertificate
+	for _, target := range c.related(signer, ManagedCertificateKindTarget) {
+		if target.Namespace == c.revisionNamespace && c.revisionedSecrets.Has(target.Name) {
+			revisioned = append(revisioned, target)
+		}
+	}
+	if len(revisioned) == 0 {
+		return "", nil
+	}
+
+	_, status, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return "", err
+	}
+	var pending []string
+	for _, node := range status.NodeStatuses {
+		for _, target := range revisioned {
+			name := fmt.Sprintf("%s-%d", target.Name, node.CurrentRevision)
+			copied, err := c.secretLister.Secrets(target.Namespace).Get(name)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return "", err
+			}
+			if copied == nil {
+				pending = append(pending, fmt.Sprintf("%s at revision %d", node.NodeName, node.CurrentRevision))
+				break
+			}
+			if issuer, err := issuerCommonName(copied); err != nil || issuer == replacedIssuer {
+				pending = append(pending, fmt.Sprintf("%s at revision %d", node.NodeName, node.CurrentRevision))
+				break
+			}
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Sprintf("waiting for the new cert key pairs to be rolled out to %s", strings.Join(pending, ", ")), nil
+	}
+	return "", nil
+}
+
+// forceRegeneration drops the validity annotations, which makes the rotator
+// regenerate the secret on its next sync.
+func (c *ForcedRotationController) forceRegeneration(ctx context.Context, recorder events.Recorder, secret *corev1.Secret, requestedAt, replacedIssuer string) error {
+	secret = secret.DeepCopy()
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	delete(secret.Annotations, certrotation.CertificateNotBeforeAnnotation)
+	delete(secret.Annotations, certrotation.CertificateNotAfterAnnotation)
+	secret.Annotations[forcedRotationRequestedAtAnnotation] = requestedAt
+	if len(replacedIssuer) > 0 {
+		secret.Annotations[forcedRotationReplacedIssuerAnnotation] = replacedIssuer
+	}
+
+	if _, err := c.secretClient.Secrets(secret.Namespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	recorder.Eventf("ForcedCertRotation", "Forced the rotation of %s/%s requested at %s", secret.Namespace, secret.Name, requestedAt)
+	return nil
+}
+
+func (c *ForcedRotationController) pruneBundle(ctx context.Context, recorder events.Recorder, bundle ManagedCertificate, issuer string) error {
+	configMap, err := c.configMapLister.ConfigMaps(bundle.Namespace).Get(bundle.Name)
+	if err != nil {
+		return err
+	}
+	certificates, err := cert.ParseCertsPEM([]byte(configMap.Data["ca-bundle.crt"]))
+	if err != nil {
+		return err
+	}
+
+	var remaining []*x509.Certificate
+	for _, certificate := range certificates {
+		if certificate.Subject.CommonName != issuer {
+			remaining = append(remaining, certificate)
+		}
+	}
+	if len(remaining) == len(certificates) {
+		return nil
+	}
+
+	caBytes, err := crypto.EncodeCertificates(remaining...)
+	if err != nil {
+		return err
+	}
+	configMap = configMap.DeepCopy()
+	configMap.Data["ca-bundle.crt"] = string(caBytes)
+	if _, err := c.configMapClient.ConfigMaps(configMap.Namespace).Update(ctx, configMap, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	recorder.Eventf("ForcedCertRotationPrunedSigner", "Removed the replaced signer %q from %s/%s", issuer, bundle.Namespace, bundle.Name)
+	return nil
+}
+
+// lookup finds the signer or cert key pair with the given name, optionally
+// qualified with its namespace as namespace/name.
+func (c *ForcedRotationController) lookup(name string) (ManagedCertificate, error) {
+	namespace, name, qualified := strings.Cut(name, "/")
+	if !qualified {
+		namespace, name = "", namespace
+	}
+
+	var matches []ManagedCertificate
+	for _, managed := range c.inventory {
+		if managed.Kind == ManagedCertificateKindCABundle || managed.Name != name {
+			continue
+		}
+		if qualified && managed.Namespace != namespace {
+			continue
+		}
+		matches = append(matches, managed)
+	}
+
+	switch len(matches) {
+	case 0:
+		return ManagedCertificate{}, fmt.Errorf("no signer or cert key pair of this name is managed by the operator")
+	case 1:
+		return matches[0], nil
+	default:
+		return ManagedCertificate{}, fmt.Errorf("the name is ambiguous, qualify it with the namespace")
+	}
+}
+
+// related returns the managed objects of the given kind that share a rotator
+// with the signer.
+func (c *ForcedRotationController) related(signer ManagedCertificate, kind string) []ManagedCertificate {
+	rotators := sets.New(signer.Rotators...)
+
+	var related []ManagedCertificate
+	for _, managed := range c.inventory {
+		if managed.Kind == kind && rotators.HasAny(managed.Rotators...) {
+			related = append(related, managed)
+		}
+	}
+	return related
+}
+
+func commonName(secret *corev1.Secret) (string, error) {
+	certificates, err := cert.ParseCertsPEM(secret.Data["tls.crt"])
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s/%s: %w", secret.Namespace, secret.Name, err)
+	}
+	return certificates[0].Subject.CommonName, nil
+}
+
+func issuerCommonName(secret *corev1.Secret) (string, error) {
+	certificates, err := cert.ParseCertsPEM(secret.Data["tls.crt"])
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s/%s: %w", secret.Namespace, secret.Name, err)
+	}
+	return certificates[0].Issuer.CommonName, nil
+}
+
+func bundleContains(configMap *corev1.ConfigMap, issuer string) bool {
+	certificates, err := cert.ParseCertsPEM([]byte(configMap.Data["ca-bundle.crt"]))
+	if err != nil {
+		return false
+	}
+	for _, certificate := range certificates {
+		if certificate.Subject.CommonName == issuer {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,283 @@
+package certrotationcontroller
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/cert"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestForcedRotationController(t *testing.T) {
+	const requestedAt = "2026-10-18T10:00:00Z"
+
+	scenarios := []struct {
+		name                string
+		request             string
+		expectSignerRotated bool
+		expectTargetRotated bool
+		expectedInvalid     string
+	}{
+		{
+			name:                "signer",
+			request:             "signer",
+			expectSignerRotated: true,
+			expectTargetRotated: true,
+		},
+		{
+			name:                "cert key pair qualified with the namespace",
+			request:             "ns/serving",
+			expectTargetRotated: true,
+		},
+		{
+			name:            "CA bundles are not rotated",
+			request:         "ca-bundle",
+			expectedInvalid: "ca-bundle: no signer or cert key pair of this name is managed by the operator",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			ctx := context.Background()
+			kube := newFakeKube(t)
+			recorder := events.NewInMemoryRecorder("test", clocktesting.NewFakePassiveClock(time.Now()))
+
+			ensure := func() {
+				signer, _, err := certrotation.RotatedSigningCASecret{
+					Namespace:     "ns",
+					Name:          "signer",
+					Validity:      24 * time.Hour,
+					Refresh:       12 * time.Hour,
+					Lister:        kube.secretLister(),
+					Client:        kube.client.CoreV1(),
+					EventRecorder: recorder,
+				}.EnsureSigningCertKeyPair(ctx)
+				require.NoError(t, err)
+				kube.sync()
+
+				bundle, err := certrotation.CABundleConfigMap{
+					Namespace:     "ns",
+					Name:          "ca-bundle",
+					Lister:        kube.configMapLister(),
+					Client:        kube.client.CoreV1(),
+					EventRecorder: recorder,
+				}.EnsureConfigMapCABundle(ctx, signer, "ns/signer")
+				require.NoError(t, err)
+				kube.sync()
+
+				_, err = certrotation.RotatedSelfSignedCertKeySecret{
+					Namespace:     "ns",
+					Name:          "serving",
+					Validity:      12 * time.Hour,
+					Refresh:       6 * time.Hour,
+					CertCreator:   &certrotation.ServingRotation{Hostnames: func() []string { return []string{"localhost"} }},
+					Lister:        kube.secretLister(),
+					Client:        kube.client.CoreV1(),
+					EventRecorder: recorder,
+				}.EnsureTargetCertKeyPair(ctx, signer, bundle)
+				require.NoError(t, err)
+				kube.sync()
+			}
+			ensure()
+			oldSigner, oldTarget := kube.certificate("signer"), kube.certificate("serving")
+			// Signers are named after the second they are created in.
+			time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+
+			// The node runs revision 1, with the current serving cert.
+			rollOut := func(revision int32) {
+				serving, err := kube.client.CoreV1().Secrets("ns").Get(ctx, "serving", metav1.GetOptions{})
+				require.NoError(t, err)
+				copied := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: fmt.Sprintf("serving-%d", revision)},
+					Data:       serving.Data,
+				}
+				_, err = kube.client.CoreV1().Secrets("ns").Create(ctx, copied, metav1.CreateOptions{})
+				require.NoError(t, err)
+				kube.sync()
+			}
+			rollOut(1)
+			staticPodClient := v1helpers.NewFakeStaticPodOperatorClient(
+				&operatorv1.StaticPodOperatorSpec{},
+				&operatorv1.StaticPodOperatorStatus{NodeStatuses: []operatorv1.NodeStatus{{NodeName: "master-0", CurrentRevision: 1}}},
+				nil,
+				nil,
+			)
+			operatorClient := &fakeStaticPodOperatorClientWithMeta{
+				StaticPodOperatorClient: staticPodClient,
+				meta:                    &metav1.ObjectMeta{Annotations: map[string]string{ForcedRotationAnnotationPrefix + scenario.request: requestedAt}},
+			}
+			c := &ForcedRotationController{
+				operatorClient:    operatorClient,
+				inventory:         testInventory(),
+				secretClient:      kube.client.CoreV1(),
+				configMapClient:   kube.client.CoreV1(),
+				secretLister:      kube.secretLister(),
+				configMapLister:   kube.configMapLister(),
+				revisionNamespace: "ns",
+				revisionedSecrets: sets.New("serving"),
+			}
+			syncCtx := factory.NewSyncContext("test", recorder)
+
+			// Every round the controller takes one step and the rotators
+			// catch up.
+			rounds := func() *operatorv1.StaticPodOperatorStatus {
+				var status *operatorv1.StaticPodOperatorStatus
+				for round := 0; round < 10; round++ {
+					require.NoError(t, c.sync(ctx, syncCtx))
+					kube.sync()
+					ensure()
+
+					var err error
+					_, status, _, err = operatorClient.GetStaticPodOperatorState()
+					require.NoError(t, err)
+					if v1helpers.IsOperatorConditionFalse(status.Conditions, forcedRotationProgressingConditionType) {
+						break
+					}
+				}
+				return status
+			}
+			status := rounds()
+			if scenario.expectSignerRotated {
+				// The replaced signer stays trusted while the node runs the
+				// replaced serving cert.
+				progressing := v1helpers.FindOperatorCondition(status.Conditions, forcedRotationProgressingConditionType)
+				require.Equal(t, operatorv1.ConditionTrue, progressing.Status)
+				require.Equal(t, "signer: waiting for the new cert key pairs to be rolled out to master-0 at revision 1", progressing.Message)
+
+				rollOut(2)
+				_, current, resourceVersion, err := staticPodClient.GetStaticPodOperatorState()
+				require.NoError(t, err)
+				rolledOut := current.DeepCopy()
+				rolledOut.NodeStatuses[0].CurrentRevision = 2
+				_, err = staticPodClient.UpdateStaticPodOperatorStatus(ctx, resourceVersion, rolledOut)
+				require.NoError(t, err)
+				status = rounds()
+			}
+			require.True(t, v1helpers.IsOperatorConditionFalse(status.Conditions, forcedRotationProgressingConditionType), "the forced rotation did not finish: %v", status.Conditions)
+
+			invalid := v1helpers.FindOperatorCondition(status.Conditions, forcedRotationInvalidRequestsConditionType)
+			require.NotNil(t, invalid)
+			if len(scenario.expectedInvalid) > 0 {
+				require.Equal(t, operatorv1.ConditionTrue, invalid.Status)
+				require.Equal(t, scenario.expectedInvalid, invalid.Message)
+			} else {
+				require.Equal(t, operatorv1.ConditionFalse, invalid.Status)
+			}
+			var warnings int
+			for _, event := range recorder.Events() {
+				if event.Reason == "ForcedCertRotationInvalidRequest" {
+					warnings++
+				}
+			}
+			require.Equal(t, len(scenario.expectedInvalid) > 0, warnings == 1, "expected a single warning about the invalid request, got %d", warnings)
+
+			newSigner, newTarget := kube.certificate("signer"), kube.certificate("serving")
+			require.Equal(t, scenario.expectSignerRotated, newSigner.SerialNumber.Cmp(oldSigner.SerialNumber) != 0, "signer rotated")
+			require.Equal(t, scenario.expectTargetRotated, newTarget.SerialNumber.Cmp(oldTarget.SerialNumber) != 0, "target rotated")
+			require.Equal(t, newSigner.Subject.CommonName, newTarget.Issuer.CommonName)
+
+			bundle, err := kube.client.CoreV1().ConfigMaps("ns").Get(ctx, "ca-bundle", metav1.GetOptions{})
+			require.NoError(t, err)
+			bundleCerts, err := cert.ParseCertsPEM([]byte(bundle.Data["ca-bundle.crt"]))
+			require.NoError(t, err)
+			require.Len(t, bundleCerts, 1, "the replaced signer must leave the CA bundle")
+			require.Equal(t, newSigner.Subject.CommonName, bundleCerts[0].Subject.CommonName)
+
+			// A finished request is not repeated.
+			require.NoError(t, c.sync(ctx, syncCtx))
+			kube.sync()
+			ensure()
+			require.Equal(t, newSigner.SerialNumber, kube.certificate("signer").SerialNumber)
+			require.Equal(t, newTarget.SerialNumber, kube.certificate("serving").SerialNumber)
+		})
+	}
+}
+
+// fakeStaticPodOperatorClientWithMeta serves the object meta the fake static
+// pod operator client doesn't support.
+type fakeStaticPodOperatorClientWithMeta struct {
+	v1helpers.StaticPodOperatorClient
+	meta *metav1.ObjectMeta
+}
+
+func (c *fakeStaticPodOperatorClientWithMeta) GetObjectMeta() (*metav1.ObjectMeta, error) {
+	return c.meta, nil
+}
+
+func testInventory() []ManagedCertificate {
+	return []ManagedCertificate{
+		{Kind: ManagedCertificateKindSigner, Namespace: "ns", Name: "signer", Rotators: []string{"Serving"}},
+		{Kind: ManagedCertificateKindCABundle, Namespace: "ns", Name: "ca-bundle", Rotators: []string{"Serving"}},
+		{Kind: ManagedCertificateKindTarget, Namespace: "ns", Name: "serving", Rotators: []string{"Serving"}},
+	}
+}
+
+// fakeKube backs listers with a fake clientset. Unlike informers, the listers
+// only change on sync, which keeps the order of events under test control.
+type fakeKube struct {
+	t          *testing.T
+	client     *fake.Clientset
+	secrets    cache.Indexer
+	configMaps cache.Indexer
+}
+
+func newFakeKube(t *testing.T) *fakeKube {
+	return &fakeKube{
+		t:          t,
+		client:     fake.NewSimpleClientset(),
+		secrets:    cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}),
+		configMaps: cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}),
+	}
+}
+
+func (k *fakeKube) sync() {
+	ctx := context.Background()
+
+	secretList, err := k.client.CoreV1().Secrets(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	require.NoError(k.t, err)
+	var secrets []interface{}
+	for i := range secretList.Items {
+		secrets = append(secrets, &secretList.Items[i])
+	}
+	require.NoError(k.t, k.secrets.Replace(secrets, ""))
+
+	configMapList, err := k.client.CoreV1().ConfigMaps(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	require.NoError(k.t, err)
+	var configMaps []interface{}
+	for i := range configMapList.Items {
+		configMaps = append(configMaps, &configMapList.Items[i])
+	}
+	require.NoError(k.t, k.configMaps.Replace(configMaps, ""))
+}
+
+func (k *fakeKube) secretLister() corev1listers.SecretLister {
+	return corev1listers.NewSecretLister(k.secrets)
+}
+
+func (k *fakeKube) configMapLister() corev1listers.ConfigMapLister {
+	return corev1listers.NewConfigMapLister(k.configMaps)
+}
+
+func (k *fakeKube) certificate(name string) *x509.Certificate {
+	secret, err := k.client.CoreV1().Secrets("ns").Get(context.Background(), name, metav1.GetOptions{})
+	require.NoError(k.t, err)
+	certificates, err := cert.ParseCertsPEM(secret.Data["tls.crt"])
+	require.NoError(k.t, err)
+	return certificates[0]
+}
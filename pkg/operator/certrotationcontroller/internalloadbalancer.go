@@ -7,6 +7,7 @@ import (
 	"k8s.io/klog/v2"
 
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/cache"
 )
 
@@ -24,8 +25,14 @@ func (c *CertRotationController) syncInternalLoadBalancerHostnames() error {
 	hostname = strings.Replace(hostname, "https://", "", 1)
 	hostname = hostname[0:strings.LastIndex(hostname, ":")]
 
-	klog.V(2).Infof("syncing internal loadbalancer hostnames: %v", hostname)
-	c.internalLoadBalancer.setHostnames([]string{hostname})
+	additional, err := c.additionalLoadBalancerSANs(func(sans *AdditionalLoadBalancerSANs) []string { return sans.Internal })
+	if err != nil {
+		return err
+	}
+	hostnames := sets.NewString(hostname).Insert(additional...).List()
+
+	klog.V(2).Infof("syncing internal loadbalancer hostnames: %v", hostnames)
+	c.internalLoadBalancer.setHostnames(hostnames)
 	return nil
 }
 
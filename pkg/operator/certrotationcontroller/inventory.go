@@ -0,0 +1,115 @@
+package certrotationcontroller
+
+import (
+	"time"
+
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+const (
+	ManagedCertificateKindSigner   = "SigningCA"
+	ManagedCertificateKindCABundle = "CABundle"
+	ManagedCertificateKindTarget   = "CertKeyPair"
+)
+
+// ManagedCertificate describes a single secret or config map that a cert
+// rotator maintains. Signers and CA bundles are often shared between rotators,
+// Rotators lists all of them.
+type ManagedCertificate struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Rotators  []string
+	// CertificateName is the logical name used for PKI profile resolution.
+	CertificateName string
+
+	// Validity and Refresh are not set for CA bundles, they only collect the
+	// signers.
+	Validity               time.Duration
+	Refresh                time.Duration
+	RefreshOnlyWhenExpired bool
+
+	JiraComponent string
+	Description   string
+}
+
+// addCertRotator creates a cert rotator and records what it manages, so that
+// the inventory stays in sync with the rotators that are actually run.
+func (c *CertRotationController) addCertRotator(
+	name string,
+	signer certrotation.RotatedSigningCASecret,
+	caBundle certrotation.CABundleConfigMap,
+	target certrotation.RotatedSelfSignedCertKeySecret,
+	recorder events.Recorder,
+	reporter certrotation.StatusReporter,
+) {
+	if signer.RefreshOnlyWhenExpired {
+		metricsReporter := &regenerationMetricsReporter{StatusReporter: reporter, name: name, now: time.Now}
+		signer.EventRecorder = metricsReporter.recorderFor(signer.EventRecorder)
+		caBundle.EventRecorder = metricsReporter.recorderFor(caBundle.EventRecorder)
+		target.EventRecorder = metricsReporter.recorderFor(target.EventRecorder)
+		reporter = metricsReporter
+	}
+	signer.Informer = c.eventHandlers.secretInformer(signer.Informer)
+	caBundle.Informer = c.eventHandlers.configMapInformer(caBundle.Informer)
+	target.Informer = c.eventHandlers.secretInformer(target.Informer)
+	c.certRotators = append(c.certRotators, certrotation.NewCertRotationController(name, signer, caBundle, target, recorder, reporter))
+
+	c.recordManagedCertificate(name, ManagedCertificate{
+		Kind:                   ManagedCertificateKindSigner,
+		Namespace:              signer.Namespace,
+		Name:                   signer.Name,
+		CertificateName:        signer.CertificateName,
+		Validity:               signer.Validity,
+		Refresh:                signer.Refresh,
+		RefreshOnlyWhenExpired: signer.RefreshOnlyWhenExpired,
+		JiraComponent:          signer.AdditionalAnnotations.JiraComponent,
+		Description:            signer.AdditionalAnnotations.Description,
+	})
+	c.recordManagedCertificate(name, ManagedCertificate{
+		Kind:                   ManagedCertificateKindCABundle,
+		Namespace:              caBundle.Namespace,
+		Name:                   caBundle.Name,
+		RefreshOnlyWhenExpired: caBundle.RefreshOnlyWhenExpired,
+		JiraComponent:          caBundle.AdditionalAnnotations.JiraComponent,
+		Description:            caBundle.AdditionalAnnotations.Description,
+	})
+	c.recordManagedCertificate(name, ManagedCertificate{
+		Kind:                   ManagedCertificateKindTarget,
+		Namespace:              target.Namespace,
+		Name:                   target.Name,
+		CertificateName:        target.CertificateName,
+		Validity:               target.Validity,
+		Refresh:                target.Refresh,
+		RefreshOnlyWhenExpired: target.RefreshOnlyWhenExpired,
+		JiraComponent:          target.AdditionalAnnotations.JiraComponent,
+		Description:            target.AdditionalAnnotations.Description,
+	})
+}
+
+func (c *CertRotationController) recordManagedCertificate(rotator string, cert ManagedCertificate) {
+	for i := range c.inventory {
+		existing := &c.inventory[i]
+		if existing.Kind == cert.Kind && existing.Namespace == cert.Namespace && existing.Name == cert.Name {
+			existing.Rotators = append(existing.Rotators, rotator)
+			return
+		}
+	}
+
+	cert.Rotators = []string{rotator}
+	c.inventory = append(c.inventory, cert)
+}
+
+// Inventory returns every secret and config map the cert rotators manage, in
+// the order the rotators are created.
+func (c *CertRotationController) Inventory() []ManagedCertificate {
+	c.rotatorsLock.Lock()
+	defer c.rotatorsLock.Unlock()
+	inventory := make([]ManagedCertificate, 0, len(c.inventory))
+	for _, cert := range c.inventory {
+		cert.Rotators = append([]string(nil), cert.Rotators...)
+		inventory = append(inventory, cert)
+	}
+	return inventory
+}
@@ -0,0 +1,104 @@
+package certrotationcontroller
+
+import (
+	"fmt"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	configv1alpha1 "github.com/openshift/api/config/v1alpha1"
+	"github.com/openshift/library-go/pkg/pki"
+)
+
+// KeyProfile selects the key algorithm per class of certificate. Classes that
+// are not set keep the key the PKI configuration gives them, or RSA-2048
+// without one. Certificates keep their key until they are rotated next.
+type KeyProfile struct {
+	Serving *configv1alpha1.KeyConfig `json:"serving,omitempty"`
+	Client  *configv1alpha1.KeyConfig `json:"client,omitempty"`
+	Signer  *configv1alpha1.KeyConfig `json:"signer,omitempty"`
+}
+
+// legacyKeyConfig is what the rotators generate without a PKI profile.
+var legacyKeyConfig = configv1alpha1.KeyConfig{
+	Algorithm: configv1alpha1.KeyAlgorithmRSA,
+	RSA:       configv1alpha1.RSAKeyConfig{KeySize: 2048},
+}
+
+func (p *KeyProfile) validate() error {
+	var errs []error
+	for _, class := range []struct {
+		name string
+		key  *configv1alpha1.KeyConfig
+	}{
+		{"serving", p.Serving},
+		{"client", p.Client},
+		{"signer", p.Signer},
+	} {
+		if class.key == nil {
+			continue
+		}
+		if err := validateKeyConfig(*class.key); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", class.name, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func validateKeyConfig(key configv1alpha1.KeyConfig) error {
+	switch key.Algorithm {
+	case configv1alpha1.KeyAlgorithmRSA:
+		if size := key.RSA.KeySize; size < 2048 || size > 8192 || size%1024 != 0 {
+			return fmt.Errorf("RSA key size %d must be a multiple of 1024 from 2048 to 8192", size)
+		}
+	case configv1alpha1.KeyAlgorithmECDSA:
+		if _, err := pki.KeyPairGeneratorFromAPI(key); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown key algorithm %q, must be RSA or ECDSA", key.Algorithm)
+	}
+	return nil
+}
+
+// keyProfileProvider applies the key profile on top of the PKI profile of the
+// ConfigurablePKI feature, if any.
+type keyProfileProvider struct {
+	base pki.PKIProfileProvider
+	keys *KeyProfile
+}
+
+// newKeyProfileProvider returns the base provider if there is no key profile.
+// The base provider may be nil.
+func newKeyProfileProvider(base pki.PKIProfileProvider, keys *KeyProfile) pki.PKIProfileProvider {
+	if keys == nil {
+		return base
+	}
+	return &keyProfileProvider{base: base, keys: keys}
+}
+
+func (p *keyProfileProvider) PKIProfile() (*configv1alpha1.PKIProfile, error) {
+	profile := configv1alpha1.PKIProfile{
+		Defaults: configv1alpha1.DefaultCertificateConfig{Key: legacyKeyConfig},
+	}
+	if p.base != nil {
+		baseProfile, err := p.base.PKIProfile()
+		if err != nil {
+			return nil, err
+		}
+		// Unmanaged PKI leaves the defaults to the rotators.
+		if baseProfile != nil {
+			profile = *baseProfile
+		}
+	}
+
+	if p.keys.Serving != nil {
+		profile.ServingCertificates.Key = *p.keys.Serving
+	}
+	if p.keys.Client != nil {
+		profile.ClientCertificates.Key = *p.keys.Client
+	}
+	if p.keys.Signer != nil {
+		profile.SignerCertificates.Key = *p.keys.Signer
+	}
+	return &profile, nil
+}
@@ -0,0 +1,216 @@
+package certrotationcontroller
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/cert"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	configv1alpha1 "github.com/openshift/api/config/v1alpha1"
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/pki"
+)
+
+var (
+	rsaKey   = configv1alpha1.KeyConfig{Algorithm: configv1alpha1.KeyAlgorithmRSA, RSA: configv1alpha1.RSAKeyConfig{KeySize: 2048}}
+	p256Key  = configv1alpha1.KeyConfig{Algorithm: configv1alpha1.KeyAlgorithmECDSA, ECDSA: configv1alpha1.ECDSAKeyConfig{Curve: configv1alpha1.ECDSACurveP256}}
+	rsa4kKey = configv1alpha1.KeyConfig{Algorithm: configv1alpha1.KeyAlgorithmRSA, RSA: configv1alpha1.RSAKeyConfig{KeySize: 4096}}
+)
+
+func TestKeyProfileProvider(t *testing.T) {
+	defaultProfile := pki.DefaultPKIProfile()
+
+	scenarios := []struct {
+		name     string
+		base     pki.PKIProfileProvider
+		keys     *KeyProfile
+		expected map[pki.CertificateType]crypto.KeyPairGenerator
+	}{
+		{
+			name: "no key profile keeps the legacy keys",
+			expected: map[pki.CertificateType]crypto.KeyPairGenerator{
+				pki.CertificateTypeServing: nil,
+				pki.CertificateTypeClient:  nil,
+				pki.CertificateTypeSigner:  nil,
+			},
+		},
+		{
+			name: "ECDSA serving certificates only",
+			keys: &KeyProfile{Serving: &p256Key},
+			expected: map[pki.CertificateType]crypto.KeyPairGenerator{
+				pki.CertificateTypeServing: crypto.ECDSAKeyPairGenerator{Curve: crypto.P256},
+				pki.CertificateTypeClient:  crypto.RSAKeyPairGenerator{Bits: 2048},
+				pki.CertificateTypeSigner:  crypto.RSAKeyPairGenerator{Bits: 2048},
+			},
+		},
+		{
+			name: "key profile on top of the PKI configuration",
+			base: pki.NewStaticPKIProfileProvider(&defaultProfile),
+			keys: &KeyProfile{Signer: &rsa4kKey},
+			expected: map[pki.CertificateType]crypto.KeyPairGenerator{
+				pki.CertificateTypeServing: crypto.ECDSAKeyPairGenerator{Curve: crypto.P256},
+				pki.CertificateTypeClient:  crypto.ECDSAKeyPairGenerator{Curve: crypto.P256},
+				pki.CertificateTypeSigner:  crypto.RSAKeyPairGenerator{Bits: 4096},
+			},
+		},
+		{
+			name: "unmanaged PKI configuration",
+			base: pki.NewStaticPKIProfileProvider(nil),
+			keys: &KeyProfile{Client: &p256Key},
+			expected: map[pki.CertificateType]crypto.KeyPairGenerator{
+				pki.CertificateTypeServing: crypto.RSAKeyPairGenerator{Bits: 2048},
+				pki.CertificateTypeClient:  crypto.ECDSAKeyPairGenerator{Curve: crypto.P256},
+				pki.CertificateTypeSigner:  crypto.RSAKeyPairGenerator{Bits: 2048},
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			provider := newKeyProfileProvider(scenario.base, scenario.keys)
+			for certType, expected := range scenario.expected {
+				if provider == nil {
+					require.Nil(t, expected, "%s", certType)
+					continue
+				}
+				cfg, err := pki.ResolveCertificateConfig(provider, certType, "test")
+				require.NoError(t, err)
+				require.Equal(t, expected, cfg.Key, "%s", certType)
+			}
+		})
+	}
+}
+
+func TestKeyProfileValidate(t *testing.T) {
+	require.NoError(t, (&KeyProfile{Serving: &p256Key, Client: &rsaKey, Signer: &rsa4kKey}).validate())
+
+	err := (&KeyProfile{
+		Serving: &configv1alpha1.KeyConfig{Algorithm: configv1alpha1.KeyAlgorithmRSA, RSA: configv1alpha1.RSAKeyConfig{KeySize: 1024}},
+		Client:  &configv1alpha1.KeyConfig{Algorithm: configv1alpha1.KeyAlgorithmECDSA, ECDSA: configv1alpha1.ECDSAKeyConfig{Curve: "P224"}},
+		Signer:  &configv1alpha1.KeyConfig{Algorithm: "DSA"},
+	}).validate()
+	require.ErrorContains(t, err, "serving: RSA key size 1024 must be a multiple of 1024 from 2048 to 8192")
+	require.ErrorContains(t, err, "client: ")
+	require.ErrorContains(t, err, `signer: unknown key algorithm "DSA"`)
+}
+
+// TestMixedAlgorithmRotation switches a signer and its serving certificate
+// from RSA to ECDSA. Until the old serving certificate is rotated, the CA
+// bundle holds both signers and both certificates keep validating.
+func TestMixedAlgorithmRotation(t *testing.T) {
+	ctx := context.Background()
+	kubeClient := fake.NewSimpleClientset()
+	secrets := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	configMaps := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	syncListers := func() {
+		secretList, err := kubeClient.CoreV1().Secrets("ns").List(ctx, metav1.ListOptions{})
+		require.NoError(t, err)
+		var secretObjs []interface{}
+		for i := range secretList.Items {
+			secretObjs = append(secretObjs, &secretList.Items[i])
+		}
+		require.NoError(t, secrets.Replace(secretObjs, ""))
+
+		configMapList, err := kubeClient.CoreV1().ConfigMaps("ns").List(ctx, metav1.ListOptions{})
+		require.NoError(t, err)
+		var configMapObjs []interface{}
+		for i := range configMapList.Items {
+			configMapObjs = append(configMapObjs, &configMapList.Items[i])
+		}
+		require.NoError(t, configMaps.Replace(configMapObjs, ""))
+	}
+	recorder := events.NewInMemoryRecorder("test", clocktesting.NewFakePassiveClock(time.Now()))
+
+	rotate := func(keys *KeyProfile) (*x509.Certificate, []*x509.Certificate) {
+		provider := newKeyProfileProvider(nil, keys)
+
+		signer, _, err := certrotation.RotatedSigningCASecret{
+			Namespace:          "ns",
+			Name:               "signer",
+			Validity:           24 * time.Hour,
+			Refresh:            12 * time.Hour,
+			PKIProfileProvider: provider,
+			Lister:             corev1listers.NewSecretLister(secrets),
+			Client:             kubeClient.CoreV1(),
+			EventRecorder:      recorder,
+		}.EnsureSigningCertKeyPair(ctx)
+		require.NoError(t, err)
+		syncListers()
+
+		bundle, err := certrotation.CABundleConfigMap{
+			Namespace:     "ns",
+			Name:          "ca-bundle",
+			Lister:        corev1listers.NewConfigMapLister(configMaps),
+			Client:        kubeClient.CoreV1(),
+			EventRecorder: recorder,
+		}.EnsureConfigMapCABundle(ctx, signer, "ns/signer")
+		require.NoError(t, err)
+		syncListers()
+
+		target, err := certrotation.RotatedSelfSignedCertKeySecret{
+			Namespace:          "ns",
+			Name:               "serving",
+			Validity:           12 * time.Hour,
+			Refresh:            6 * time.Hour,
+			CertCreator:        &certrotation.ServingRotation{Hostnames: func() []string { return []string{"localhost"} }},
+			PKIProfileProvider: provider,
+			Lister:             corev1listers.NewSecretLister(secrets),
+			Client:             kubeClient.CoreV1(),
+			EventRecorder:      recorder,
+		}.EnsureTargetCertKeyPair(ctx, signer, bundle)
+		require.NoError(t, err)
+		syncListers()
+
+		targetCerts, err := cert.ParseCertsPEM(target.Data["tls.crt"])
+		require.NoError(t, err)
+		return targetCerts[0], bundle
+	}
+
+	verify := func(target *x509.Certificate, bundle []*x509.Certificate) {
+		pool := x509.NewCertPool()
+		for _, caCert := range bundle {
+			pool.AddCert(caCert)
+		}
+		_, err := target.Verify(x509.VerifyOptions{DNSName: "localhost", Roots: pool})
+		require.NoError(t, err)
+	}
+
+	rsaTarget, bundle := rotate(&KeyProfile{Serving: &rsaKey, Signer: &rsaKey})
+	require.IsType(t, &rsa.PublicKey{}, rsaTarget.PublicKey)
+	require.Len(t, bundle, 1)
+	verify(rsaTarget, bundle)
+
+	// The signer is due, e.g. after its refresh period.
+	require.NoError(t, kubeClient.CoreV1().Secrets("ns").Delete(ctx, "signer", metav1.DeleteOptions{}))
+	syncListers()
+
+	ecdsaKeys := &KeyProfile{Serving: &p256Key, Signer: &p256Key}
+	stillRSATarget, bundle := rotate(ecdsaKeys)
+	require.Equal(t, rsaTarget.SerialNumber, stillRSATarget.SerialNumber, "the serving certificate must not rotate before it is due")
+	require.Len(t, bundle, 2)
+	require.IsType(t, &ecdsa.PublicKey{}, bundle[0].PublicKey)
+	require.IsType(t, &rsa.PublicKey{}, bundle[1].PublicKey)
+	verify(rsaTarget, bundle)
+
+	// The serving certificate is due as well.
+	require.NoError(t, kubeClient.CoreV1().Secrets("ns").Delete(ctx, "serving", metav1.DeleteOptions{}))
+	syncListers()
+
+	ecdsaTarget, bundle := rotate(ecdsaKeys)
+	require.IsType(t, &ecdsa.PublicKey{}, ecdsaTarget.PublicKey)
+	require.Len(t, bundle, 2)
+	verify(ecdsaTarget, bundle)
+	verify(rsaTarget, bundle)
+}
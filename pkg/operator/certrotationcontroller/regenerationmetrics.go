@@ -0,0 +1,88 @@
+package certrotationcontroller
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+var (
+	registerRegenerationMetrics sync.Once
+
+	certRegenerationLastRegeneratedGauge = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Name: "openshift_kube_apiserver_cert_regeneration_last_regenerated_timestamp_seconds",
+		Help: "Reports the time of the last cert regeneration sync of a cert rotator that regenerated its expired signer, CA bundle or certificate",
+	}, []string{"name"})
+
+	certRegenerationErrorsCounter = metrics.NewCounterVec(&metrics.CounterOpts{
+		Name: "openshift_kube_apiserver_cert_regeneration_errors_total",
+		Help: "Counts the cert regeneration syncs of a cert rotator that failed",
+	}, []string{"name"})
+)
+
+// RegisterRegenerationMetrics registers the metrics of the cert rotators
+// that only refresh expired certificates.
+func RegisterRegenerationMetrics() {
+	registerRegenerationMetrics.Do(func() {
+		legacyregistry.MustRegister(certRegenerationLastRegeneratedGauge)
+		legacyregistry.MustRegister(certRegenerationErrorsCounter)
+	})
+}
+
+// regenerationReasons are the events the signer, CA bundle and target of a
+// cert rotator emit right before they regenerate their secret or config map.
+var regenerationReasons = sets.New("SignerUpdateRequired", "CABundleUpdateRequired", "TargetUpdateRequired")
+
+// regenerationMetricsReporter records the result of every sync of a cert
+// rotator before reporting it with the wrapped status reporter. It is only
+// used by the cert-regeneration-controller, whose rotators sync while the
+// certificates may be expired and the operator status possibly can't be
+// written. Whether a sync regenerated anything is learned from the events
+// recorded through recorderFor.
+type regenerationMetricsReporter struct {
+	certrotation.StatusReporter
+
+	name string
+	now  func() time.Time
+
+	// regenerated is set once the rotator regenerates something and cleared
+	// by the next successful sync.
+	regenerated atomic.Bool
+}
+
+func (r *regenerationMetricsReporter) Report(ctx context.Context, controllerName string, syncErr error) (bool, error) {
+	if syncErr != nil {
+		certRegenerationErrorsCounter.WithLabelValues(r.name).Inc()
+	} else if r.regenerated.Swap(false) {
+		certRegenerationLastRegeneratedGauge.WithLabelValues(r.name).Set(float64(r.now().Unix()))
+	}
+	return r.StatusReporter.Report(ctx, controllerName, syncErr)
+}
+
+// recorderFor returns the recorder to give the signer, CA bundle and target
+// of the rotator.
+func (r *regenerationMetricsReporter) recorderFor(recorder events.Recorder) events.Recorder {
+	return &regenerationRecorder{Recorder: recorder, reporter: r}
+}
+
+// regenerationRecorder notes the regenerations in the reporter.
+type regenerationRecorder struct {
+	events.Recorder
+
+	reporter *regenerationMetricsReporter
+}
+
+func (r *regenerationRecorder) Eventf(reason, messageFmt string, args ...interface{}) {
+	if regenerationReasons.Has(reason) {
+		r.reporter.regenerated.Store(true)
+	}
+	r.Recorder.Eventf(reason, messageFmt, args...)
+}
@@ -0,0 +1,82 @@
+package certrotationcontroller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/component-base/metrics/testutil"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+type fakeStatusReporter struct {
+	reported []error
+}
+
+func (r *fakeStatusReporter) Report(ctx context.Context, controllerName string, syncErr error) (bool, error) {
+	r.reported = append(r.reported, syncErr)
+	return true, nil
+}
+
+func TestRegenerationMetricsReporter(t *testing.T) {
+	RegisterRegenerationMetrics()
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	delegate := &fakeStatusReporter{}
+	reporter := &regenerationMetricsReporter{StatusReporter: delegate, name: "TestRotator", now: func() time.Time { return now }}
+	recorder := reporter.recorderFor(events.NewInMemoryRecorder("test", clocktesting.NewFakePassiveClock(now)))
+
+	lastRegenerated := func() float64 {
+		value, err := testutil.GetGaugeMetricValue(certRegenerationLastRegeneratedGauge.WithLabelValues("TestRotator"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+	report := func(err error) {
+		if _, reportErr := reporter.Report(context.Background(), "TestRotator", err); reportErr != nil {
+			t.Fatal(reportErr)
+		}
+	}
+
+	// a sync that left everything as it was
+	report(nil)
+	if value := lastRegenerated(); value != 0 {
+		t.Errorf("expected no regeneration to be recorded, got %v", value)
+	}
+
+	// a regeneration that failed half way, then succeeded
+	syncErr := errors.New("signer expired")
+	recorder.Eventf("SignerUpdateRequired", "%q in %q requires a new signing cert/key pair: %v", "signer", "ns", "already expired")
+	report(syncErr)
+	report(syncErr)
+	if value := lastRegenerated(); value != 0 {
+		t.Errorf("expected no regeneration to be recorded before a successful sync, got %v", value)
+	}
+	report(nil)
+	if value := lastRegenerated(); value != float64(now.Unix()) {
+		t.Errorf("expected the regeneration at %d, got %v", now.Unix(), value)
+	}
+
+	// later syncs without regeneration keep the time
+	now = now.Add(time.Hour)
+	recorder.Eventf("SecretUpdated", "Updated Secret/signer -n ns because it changed")
+	report(nil)
+	if value := lastRegenerated(); value != float64(now.Add(-time.Hour).Unix()) {
+		t.Errorf("expected the regeneration at %d to be kept, got %v", now.Add(-time.Hour).Unix(), value)
+	}
+
+	if len(delegate.reported) != 5 || delegate.reported[0] != nil || delegate.reported[2] != syncErr {
+		t.Errorf("expected every sync to be reported, got %v", delegate.reported)
+	}
+	errorCount, err := testutil.GetCounterMetricValue(certRegenerationErrorsCounter.WithLabelValues("TestRotator"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errorCount != 2 {
+		t.Errorf("expected 2 errors, got %v", errorCount)
+	}
+}
@@ -0,0 +1,153 @@
+package certrotationcontroller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+const (
+	// rotationProfileKey is the key of the rotation profile in the operator
+	// config map.
+	rotationProfileKey = "certRotationProfile"
+
+	// minimumRefresh keeps certificates from rotating in the middle of an
+	// upgrade. Rolling out a new release to every control plane node takes a
+	// few hours, and a node that is down at that time must not miss more than
+	// a single rotation.
+	minimumRefresh = 6 * time.Hour
+)
+
+// RotationProfile overrides the lifetime and the key algorithm of the
+// certificates the operator rotates, per class of certificate. Classes that
+// are not set keep their defaults. Lifetimes of signers provided by the
+// installer and of the break-glass node-system-admin client certificate are
+// not covered.
+type RotationProfile struct {
+	// Serving applies to the kube-apiserver serving certificates for
+	// localhost, the service network and the load balancers.
+	Serving *RotationPeriods `json:"serving,omitempty"`
+	// Client applies to the client certificates the operator issues, e.g.
+	// for the kubelet and the aggregator front proxy.
+	Client *RotationPeriods `json:"client,omitempty"`
+	// Signer applies to the signers the operator creates for the client
+	// certificates.
+	Signer *RotationPeriods `json:"signer,omitempty"`
+
+	// Keys applies to every certificate the operator rotates.
+	Keys *KeyProfile `json:"keys,omitempty"`
+}
+
+type RotationPeriods struct {
+	Validity metav1.Duration `json:"validity"`
+	Refresh  metav1.Duration `json:"refresh"`
+}
+
+// RotationProfileFromConfigMap reads the rotation profile from the operator
+// config map. It returns nil if no profile is set.
+func RotationProfileFromConfigMap(configMap *corev1.ConfigMap) (*RotationProfile, error) {
+	profile := &RotationProfile{}
+	found, err := operatorconfig.Unmarshal(configMap, rotationProfileKey, profile)
+	if !found || err != nil {
+		return nil, err
+	}
+	if err := profile.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", operatorconfig.KeyPath(rotationProfileKey), err)
+	}
+	return profile, nil
+}
+
+// CurrentRotationProfile reads the rotation profile from the server. An
+// invalid profile is ignored, the defaults apply until it is fixed; the
+// RotationProfileController reports it.
+func CurrentRotationProfile(ctx context.Context, client corev1client.ConfigMapsGetter) (*RotationProfile, error) {
+	configMap, err := operatorconfig.GetLive(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := RotationProfileFromConfigMap(configMap)
+	if err != nil {
+		klog.Warningf("Ignoring the certificate rotation profile: %v", err)
+		return nil, nil
+	}
+	return profile, nil
+}
+
+// Validate refuses profiles that would break trust or rotate too often.
+func (p *RotationProfile) Validate() error {
+	var errs []error
+	for _, class := range []struct {
+		name    string
+		periods *RotationPeriods
+	}{
+		{"serving", p.Serving},
+		{"client", p.Client},
+		{"signer", p.Signer},
+	} {
+		if class.periods == nil {
+			continue
+		}
+		if err := class.periods.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", class.name, err))
+		}
+	}
+
+	if p.Keys != nil {
+		if err := p.Keys.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("keys: %w", err))
+		}
+	}
+
+	// A client certificate must not outlive the signer that issued it, the CA
+	// bundle drops the signer once it expires. Without a signer override the
+	// shortest lived default signer, the aggregator front proxy one, counts.
+	if p.Client != nil {
+		signerValidity := defaultRotationPeriods().validity
+		if p.Signer != nil {
+			signerValidity = p.Signer.Validity.Duration
+		}
+		if p.Client.Validity.Duration > signerValidity {
+			errs = append(errs, fmt.Errorf("client: validity %v exceeds the signer validity %v", p.Client.Validity.Duration, signerValidity))
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func (p *RotationPeriods) validate() error {
+	switch {
+	case p.Refresh.Duration < minimumRefresh:
+		return fmt.Errorf("refresh %v is shorter than the minimum of %v", p.Refresh.Duration, minimumRefresh)
+	case p.Refresh.Duration >= p.Validity.Duration:
+		return fmt.Errorf("refresh %v must be shorter than the validity %v", p.Refresh.Duration, p.Validity.Duration)
+	}
+	return nil
+}
+
+type rotationPeriods struct {
+	validity time.Duration
+	refresh  time.Duration
+}
+
+// defaultRotationPeriods is the lifetime of serving and client certificates
+// when no profile is set.
+func defaultRotationPeriods() rotationPeriods {
+	monthPeriod := 30 * rotationDay
+	return rotationPeriods{validity: monthPeriod, refresh: monthPeriod / 2}
+}
+
+func (p rotationPeriods) override(periods *RotationPeriods) rotationPeriods {
+	if periods == nil {
+		return p
+	}
+	return rotationPeriods{validity: periods.Validity.Duration, refresh: periods.Refresh.Duration}
+}
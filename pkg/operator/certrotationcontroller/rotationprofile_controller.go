@@ -0,0 +1,77 @@
+package certrotationcontroller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+const rotationProfileDegradedConditionType = "CertRotationProfileDegraded"
+
+// RotationProfileController reports invalid rotation profiles and hands a
+// valid profile that differs from the active one to onProfileChange, which
+// rebuilds the cert rotators with it.
+type RotationProfileController struct {
+	configMapLister corev1listers.ConfigMapLister
+	operatorClient  v1helpers.OperatorClient
+	activeProfile   *RotationProfile
+	onProfileChange func(*RotationProfile)
+}
+
+func NewRotationProfileController(
+	operatorClient v1helpers.OperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	activeProfile *RotationProfile,
+	onProfileChange func(*RotationProfile),
+	recorder events.Recorder,
+) factory.Controller {
+	configMapInformer := kubeInformersForNamespaces.InformersFor(operatorconfig.Namespace).Core().V1().ConfigMaps()
+	c := &RotationProfileController{
+		configMapLister: configMapInformer.Lister(),
+		operatorClient:  operatorClient,
+		activeProfile:   activeProfile,
+		onProfileChange: onProfileChange,
+	}
+
+	return factory.New().
+		WithInformers(operatorClient.Informer()).
+		WithFilteredEventsInformers(factory.NamesFilter(operatorconfig.Name), configMapInformer.Informer()).
+		WithSync(c.sync).
+		ToController("CertRotationProfileController", recorder.WithComponentSuffix("cert-rotation-profile-controller"))
+}
+
+func (c *RotationProfileController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	configMap, err := operatorconfig.Get(c.configMapLister)
+	if err != nil {
+		return err
+	}
+
+	condition := operatorv1.OperatorCondition{
+		Type:   rotationProfileDegradedConditionType,
+		Status: operatorv1.ConditionFalse,
+	}
+	profile, profileErr := RotationProfileFromConfigMap(configMap)
+	if profileErr != nil {
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "InvalidProfile"
+		condition.Message = profileErr.Error() + ", the previous rotation periods stay in effect"
+	}
+	if _, _, err := v1helpers.UpdateStatus(ctx, c.operatorClient, v1helpers.UpdateConditionFn(condition)); err != nil {
+		return err
+	}
+
+	if profileErr == nil && !equality.Semantic.DeepEqual(profile, c.activeProfile) {
+		syncCtx.Recorder().Eventf("CertRotationProfileChanged", "Applying the changed certificate rotation profile")
+		c.onProfileChange(profile)
+		c.activeProfile = profile
+	}
+	return nil
+}
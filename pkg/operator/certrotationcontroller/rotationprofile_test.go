@@ -0,0 +1,221 @@
+package certrotationcontroller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/api/features"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	configinformers "github.com/openshift/client-go/config/informers/externalversions"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+func operatorConfigMap(profile string) *corev1.ConfigMap {
+	if len(profile) == 0 {
+		return nil
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorconfig.Namespace, Name: operatorconfig.Name},
+		Data:       map[string]string{rotationProfileKey: profile},
+	}
+}
+
+func TestRotationProfileFromConfigMap(t *testing.T) {
+	scenarios := []struct {
+		name            string
+		profile         string
+		expectedProfile *RotationProfile
+		expectedError   string
+	}{
+		{
+			name: "no config map",
+		},
+		{
+			name: "longer serving certificates, in YAML",
+			profile: `
+serving:
+  validity: 2160h
+  refresh: 720h
+`,
+			expectedProfile: &RotationProfile{
+				Serving: &RotationPeriods{Validity: metav1.Duration{Duration: 2160 * time.Hour}, Refresh: metav1.Duration{Duration: 720 * time.Hour}},
+			},
+		},
+		{
+			name:    "longer client certificates with a longer signer",
+			profile: `{"client":{"validity":"1440h","refresh":"720h"},"signer":{"validity":"2160h","refresh":"1080h"}}`,
+			expectedProfile: &RotationProfile{
+				Client: &RotationPeriods{Validity: metav1.Duration{Duration: 1440 * time.Hour}, Refresh: metav1.Duration{Duration: 720 * time.Hour}},
+				Signer: &RotationPeriods{Validity: metav1.Duration{Duration: 2160 * time.Hour}, Refresh: metav1.Duration{Duration: 1080 * time.Hour}},
+			},
+		},
+		{
+			name:          "refresh shorter than an upgrade",
+			profile:       `{"serving":{"validity":"4h","refresh":"2h"}}`,
+			expectedError: "serving: refresh 2h0m0s is shorter than the minimum of 6h0m0s",
+		},
+		{
+			name:          "refresh not shorter than validity",
+			profile:       `{"serving":{"validity":"24h","refresh":"24h"}}`,
+			expectedError: "serving: refresh 24h0m0s must be shorter than the validity 24h0m0s",
+		},
+		{
+			name:          "client outlives the default signer",
+			profile:       `{"client":{"validity":"1440h","refresh":"720h"}}`,
+			expectedError: "client: validity 1440h0m0s exceeds the signer validity 720h0m0s",
+		},
+		{
+			name:          "invalid duration",
+			profile:       `{"serving":{"validity":"a month","refresh":"360h"}}`,
+			expectedError: "failed to unmarshal configmaps/kube-apiserver-operator-config in openshift-config, key certRotationProfile",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			profile, err := RotationProfileFromConfigMap(operatorConfigMap(scenario.profile))
+			if len(scenario.expectedError) > 0 {
+				require.ErrorContains(t, err, scenario.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, scenario.expectedProfile, profile)
+		})
+	}
+}
+
+func TestRotationProfileController(t *testing.T) {
+	validProfile := `{"serving":{"validity":"2160h","refresh":"720h"}}`
+	invalidProfile := `{"serving":{"validity":"4h","refresh":"2h"}}`
+
+	scenarios := []struct {
+		name              string
+		profile           string
+		activeProfile     *RotationProfile
+		expectedCondition operatorv1.ConditionStatus
+		expectedChange    bool
+	}{
+		{
+			name:              "no profile",
+			expectedCondition: operatorv1.ConditionFalse,
+		},
+		{
+			name:              "new profile",
+			profile:           validProfile,
+			expectedCondition: operatorv1.ConditionFalse,
+			expectedChange:    true,
+		},
+		{
+			name:    "active profile",
+			profile: validProfile,
+			activeProfile: &RotationProfile{
+				Serving: &RotationPeriods{Validity: metav1.Duration{Duration: 2160 * time.Hour}, Refresh: metav1.Duration{Duration: 720 * time.Hour}},
+			},
+			expectedCondition: operatorv1.ConditionFalse,
+		},
+		{
+			name:              "invalid profile",
+			profile:           invalidProfile,
+			expectedCondition: operatorv1.ConditionTrue,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if configMap := operatorConfigMap(scenario.profile); configMap != nil {
+				require.NoError(t, indexer.Add(configMap))
+			}
+			operatorClient := v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{}, nil)
+			var changedTo *RotationProfile
+			c := &RotationProfileController{
+				configMapLister: corev1listers.NewConfigMapLister(indexer),
+				operatorClient:  operatorClient,
+				activeProfile:   scenario.activeProfile,
+				onProfileChange: func(profile *RotationProfile) { changedTo = profile },
+			}
+
+			recorder := events.NewInMemoryRecorder("test", clocktesting.NewFakePassiveClock(time.Now()))
+			require.NoError(t, c.sync(context.Background(), factory.NewSyncContext("test", recorder)))
+			require.Equal(t, scenario.expectedChange, changedTo != nil)
+			if scenario.expectedChange {
+				require.Equal(t, changedTo, c.activeProfile)
+				// applied once only
+				changedTo = nil
+				require.NoError(t, c.sync(context.Background(), factory.NewSyncContext("test", recorder)))
+				require.Nil(t, changedTo)
+			}
+
+			_, status, _, err := operatorClient.GetOperatorState()
+			require.NoError(t, err)
+			condition := v1helpers.FindOperatorCondition(status.Conditions, rotationProfileDegradedConditionType)
+			require.NotNil(t, condition)
+			require.Equal(t, scenario.expectedCondition, condition.Status)
+		})
+	}
+}
+
+func TestSetRotationProfileReplacesCertRotators(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset()
+	// the config informers are never started
+	configClient, err := configclient.NewForConfig(&rest.Config{})
+	require.NoError(t, err)
+	c, err := NewCertRotationController(
+		kubeClient,
+		v1helpers.NewFakeStaticPodOperatorClient(&operatorv1.StaticPodOperatorSpec{}, &operatorv1.StaticPodOperatorStatus{}, nil, nil),
+		configinformers.NewSharedInformerFactory(configClient, 0),
+		v1helpers.NewKubeInformersForNamespaces(kubeClient, operatorclient.GlobalUserSpecifiedConfigNamespace, operatorclient.GlobalMachineSpecifiedConfigNamespace, operatorclient.OperatorNamespace, operatorclient.TargetNamespace),
+		events.NewInMemoryRecorder("test", clocktesting.NewFakePassiveClock(time.Now())),
+		featuregates.NewFeatureGate(nil, []configv1.FeatureGateName{features.FeatureShortCertRotation, features.FeatureGateConfigurablePKI}),
+		nil,
+	)
+	require.NoError(t, err)
+	servingValidity := func() time.Duration {
+		for _, cert := range c.Inventory() {
+			if cert.Kind == ManagedCertificateKindTarget && cert.Name == "localhost-serving-cert-certkey" {
+				return cert.Validity
+			}
+		}
+		t.Fatal("localhost-serving-cert-certkey is missing")
+		return 0
+	}
+	require.Equal(t, 30*24*time.Hour, servingValidity())
+	rotators := len(c.currentCertRotators())
+	require.GreaterOrEqual(t, len(c.eventHandlers.handlers), rotators)
+	replaced := c.eventHandlers.handlers
+
+	// only the latest profile is picked up
+	c.SetRotationProfile(&RotationProfile{Serving: &RotationPeriods{Validity: metav1.Duration{Duration: 1440 * time.Hour}, Refresh: metav1.Duration{Duration: 720 * time.Hour}}})
+	c.SetRotationProfile(&RotationProfile{Serving: &RotationPeriods{Validity: metav1.Duration{Duration: 2160 * time.Hour}, Refresh: metav1.Duration{Duration: 720 * time.Hour}}})
+	profile := <-c.rotationProfileChanged
+	require.Empty(t, c.rotationProfileChanged)
+
+	c.replaceCertRotators(profile)
+	require.Equal(t, 2160*time.Hour, servingValidity())
+	require.Len(t, c.currentCertRotators(), rotators)
+	// the event handlers of the replaced rotators are removed, not added to
+	require.Len(t, c.eventHandlers.handlers, len(replaced))
+	for _, handler := range c.eventHandlers.handlers {
+		for _, replacedHandler := range replaced {
+			require.NotEqual(t, replacedHandler.registration, handler.registration)
+		}
+	}
+}
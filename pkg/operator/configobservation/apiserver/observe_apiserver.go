@@ -3,9 +3,11 @@ package apiserver
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/imdario/mergo"
 	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -17,16 +19,23 @@ import (
 	"github.com/openshift/library-go/pkg/operator/resourcesynccontroller"
 
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/namedcertificatescontroller"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/usercertificatescontroller"
 )
 
 const (
 	userServingCertPublicCertFile          = "/etc/kubernetes/static-pod-certs/secrets/user-serving-cert/tls.crt"
 	userServingCertPrivateKeyFile          = "/etc/kubernetes/static-pod-certs/secrets/user-serving-cert/tls.key"
 	namedUserServingCertResourceNameFormat = "user-serving-cert-%03d"
+	namedUserServingCertsDir               = "/etc/kubernetes/static-pod-certs/secrets/" + namedcertificatescontroller.SecretName
+	legacyNamedUserServingCertsDirPrefix   = "/etc/kubernetes/static-pod-certs/secrets/user-serving-cert-"
 )
 
-var namedUserServingCertResourceNames = []string{
+// legacyNamedUserServingCertResourceNames are the secrets that named certificates used to be copied to, one per entry.
+// All named certificates are combined in a single secret now, the legacy copies of the first entries are only kept in
+// sync until no node runs a revision that still serves from them.
+var legacyNamedUserServingCertResourceNames = []string{
 	fmt.Sprintf(namedUserServingCertResourceNameFormat, 0),
 	fmt.Sprintf(namedUserServingCertResourceNameFormat, 1),
 	fmt.Sprintf(namedUserServingCertResourceNameFormat, 2),
@@ -39,8 +48,6 @@ var namedUserServingCertResourceNames = []string{
 	fmt.Sprintf(namedUserServingCertResourceNameFormat, 9),
 }
 
-var maxUserNamedCerts = len(namedUserServingCertResourceNames)
-
 // syncActionRules rules define source resource names indexed by destination resource names.
 // Empty value means to delete the destination.
 type syncActionRules map[string]string
@@ -64,11 +71,12 @@ var ObserveUserClientCABundle configobserver.ObserveConfigFunc = (&apiServerObse
 }).observe
 
 // ObserveNamedCertificates returns an ObserveConfigFunc that observes user managed TLS cert info for serving secure
-// traffic to specific hostnames.
+// traffic to specific hostnames. The user managed secrets are combined into a single secret by the
+// NamedCertificatesController, the observer only points the kube-apiserver to their files.
 var ObserveNamedCertificates configobserver.ObserveConfigFunc = (&apiServerObserver{
 	observerFunc:  observeNamedCertificates,
 	configPaths:   [][]string{{"servingInfo", "namedCertificates"}},
-	resourceNames: namedUserServingCertResourceNames,
+	resourceNames: legacyNamedUserServingCertResourceNames,
 	resourceType:  corev1.Secret{},
 }).observe
 
@@ -79,6 +87,18 @@ func observeUserClientCABundle(apiServer *configv1.APIServer, recorder events.Re
 	if len(configMapName) == 0 {
 		return nil, nil, nil // previously observed resource (if any) should be deleted
 	}
+	configMap, err := listers.ConfigMapLister().ConfigMaps(operatorclient.GlobalUserSpecifiedConfigNamespace).Get(configMapName)
+	switch {
+	case errors.IsNotFound(err):
+	case err != nil:
+		return nil, nil, []error{err}
+	default:
+		// refuse to propagate a broken bundle, the user certificates controller reports it
+		if err := usercertificatescontroller.ValidateClientCABundle(configMap, time.Now()); err != nil {
+			return nil, nil, []error{&invalidUserCertificateError{fmt.Errorf("configmap %s/%s: %w", operatorclient.GlobalUserSpecifiedConfigNamespace, configMapName, err)}}
+		}
+	}
+
 	// The user managed client CA bundle will be combined with other operator managed client CA bundles (by the target
 	// config controller) into a common client CA bundle managed by the operator. As such, since the user managed client
 	// CA bundle is never explicitly referenced in the kube-apiserver config, the returned observed config will always
@@ -93,16 +113,10 @@ func observeNamedCertificates(apiServer *configv1.APIServer, recorder events.Rec
 	observedConfig := map[string]interface{}{}
 
 	namedCertificates := apiServer.Spec.ServingCerts.NamedCertificates
-	if len(namedCertificates) > maxUserNamedCerts {
-		// TODO: This should be validation error, not operator error/event.
-		err := fmt.Errorf("spec.servingCerts.namedCertificates cannot have more than %d entries", maxUserNamedCerts)
-		recorder.Warningf("ObserveNamedCertificatesFailed", "%v", err)
-		return previouslyObservedConfig, nil, append(errs, err)
-	}
+	namesBySecret := usercertificatescontroller.ServingCertificateNames(apiServer)
 
 	// add the named cert info to the observed config. return the previously observed config on any error.
 	namedCertificatesPath := []string{"servingInfo", "namedCertificates"}
-	resourceSyncRules := syncActionRules{}
 	var observedNamedCertificates []interface{}
 
 	// these are always present in the config because we mint and rotate them ourselves.
@@ -144,22 +158,22 @@ func observeNamedCertificates(apiServer *configv1.APIServer, recorder events.Rec
 		}
 
 		// check that secret exists and readable by operator
-		if _, err := listers.ConfigSecretLister().Secrets(operatorclient.GlobalUserSpecifiedConfigNamespace).Get(namedCertificate.ServingCertificate.Name); err != nil {
+		secret, err := listers.ConfigSecretLister().Secrets(operatorclient.GlobalUserSpecifiedConfigNamespace).Get(namedCertificate.ServingCertificate.Name)
+		if err != nil {
 			return previouslyObservedConfig, nil, append(errs, err)
 		}
-		// pick one of the available target resource names
-		targetSecretName := fmt.Sprintf(namedUserServingCertResourceNameFormat, index)
-
-		// add a sync rule to copy the user specified secret to the operator namespace
-		resourceSyncRules[targetSecretName] = sourceSecretName
+		// refuse to propagate a broken key pair, the user certificates controller reports it
+		if err := usercertificatescontroller.ValidateServingCertificate(secret, namesBySecret[sourceSecretName], time.Now()); err != nil {
+			return previouslyObservedConfig, nil, append(errs, &invalidUserCertificateError{fmt.Errorf("secret %s/%s: %w", operatorclient.GlobalUserSpecifiedConfigNamespace, sourceSecretName, err)})
+		}
 
-		// add the named certificate to the observed config
-		certFile := fmt.Sprintf("/etc/kubernetes/static-pod-certs/secrets/%s/tls.crt", targetSecretName)
+		// add the named certificate to the observed config, pointing to its copy in the combined secret
+		certFile := fmt.Sprintf("%s/%s", namedUserServingCertsDir, namedcertificatescontroller.CertFileKey(sourceSecretName))
 		if err := unstructured.SetNestedField(observedNamedCertificate, certFile, "certFile"); err != nil {
 			return previouslyObservedConfig, nil, append(errs, err)
 		}
 
-		keyFile := fmt.Sprintf("/etc/kubernetes/static-pod-certs/secrets/%s/tls.key", targetSecretName)
+		keyFile := fmt.Sprintf("%s/%s", namedUserServingCertsDir, namedcertificatescontroller.KeyFileKey(sourceSecretName))
 		if err := unstructured.SetNestedField(observedNamedCertificate, keyFile, "keyFile"); err != nil {
 			return previouslyObservedConfig, nil, append(errs, err)
 		}
@@ -180,9 +194,68 @@ func observeNamedCertificates(apiServer *configv1.APIServer, recorder events.Rec
 		}
 	}
 
+	// keep the legacy per entry copies while a node still serves from them, they are deleted afterwards
+	resourceSyncRules := syncActionRules{}
+	legacyInUse, err := legacyNamedCertificatesInUse(listers)
+	if err != nil {
+		return previouslyObservedConfig, nil, append(errs, err)
+	}
+	if legacyInUse {
+		for index, namedCertificate := range namedCertificates {
+			if index >= len(legacyNamedUserServingCertResourceNames) {
+				break
+			}
+			resourceSyncRules[legacyNamedUserServingCertResourceNames[index]] = namedCertificate.ServingCertificate.Name
+		}
+	}
+
 	return observedConfig, resourceSyncRules, errs
 }
 
+// legacyNamedCertificatesInUse returns whether the current revision of any node points the kube-apiserver to the
+// legacy per entry copies of the named certificates.
+func legacyNamedCertificatesInUse(listers *configobservation.Listers) (bool, error) {
+	kubeAPIServer, err := listers.KubeAPIServerOperatorLister().Get("cluster")
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	for _, nodeStatus := range kubeAPIServer.Status.NodeStatuses {
+		if nodeStatus.CurrentRevision == 0 {
+			continue
+		}
+		configMap, err := listers.ConfigMapLister().ConfigMaps(operatorclient.TargetNamespace).Get(fmt.Sprintf("config-%d", nodeStatus.CurrentRevision))
+		if errors.IsNotFound(err) {
+			// the revision cannot be inspected, keep the legacy copies to be safe
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		config := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(configMap.Data["config.yaml"]), &config); err != nil {
+			return false, fmt.Errorf("configmap %s/%s: %w", configMap.Namespace, configMap.Name, err)
+		}
+		namedCertificates, _, err := unstructured.NestedSlice(config, "servingInfo", "namedCertificates")
+		if err != nil {
+			return false, fmt.Errorf("configmap %s/%s: %w", configMap.Namespace, configMap.Name, err)
+		}
+		for _, namedCertificate := range namedCertificates {
+			namedCertificate, ok := namedCertificate.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if certFile, _, _ := unstructured.NestedString(namedCertificate, "certFile"); strings.HasPrefix(certFile, legacyNamedUserServingCertsDirPrefix) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
 type apiServerObserver struct {
 	observerFunc  observeAPIServerConfigFunc
 	configPaths   [][]string
@@ -217,9 +290,10 @@ func (o *apiServerObserver) observe(genericListers configobserver.Listers, recor
 	observedConfig, observedResources, errs := o.observerFunc(apiServer, recorder, previouslyObservedConfig, &listers)
 
 	// if we get error during observation, skip the merging and return previous config and errors.
+	// Invalid user certificates are reported by the UserCertificatesDegraded condition instead.
 	if len(errs) > 0 {
 		klog.Warningf("errors during apiservers.%s/cluster processing: %+v", configv1.GroupName, errs)
-		return previouslyObservedConfig, errs
+		return previouslyObservedConfig, withoutInvalidUserCertificateErrors(errs)
 	}
 
 	// default to deleting previous resources, and then merge in observed resources rules
@@ -233,6 +307,22 @@ func (o *apiServerObserver) observe(genericListers configobserver.Listers, recor
 	return observedConfig, errs
 }
 
+// invalidUserCertificateError is returned for user provided certificates that fail validation. Like any other error
+// it keeps the previously observed config and resources.
+type invalidUserCertificateError struct {
+	error
+}
+
+func withoutInvalidUserCertificateErrors(errs []error) []error {
+	var filtered []error
+	for _, err := range errs {
+		if _, ok := err.(*invalidUserCertificateError); !ok {
+			filtered = append(filtered, err)
+		}
+	}
+	return filtered
+}
+
 // deleteSyncRules generates resource sync rules to delete the resources, specified by names, from the
 // operator namespace.
 func deleteSyncRules(names ...string) syncActionRules {
@@ -3,18 +3,24 @@ package apiserver
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/diff"
+	"k8s.io/apimachinery/pkg/util/sets"
 	corelistersv1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/utils/clock"
 
 	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
 	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	operatorlistersv1 "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/crypto"
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/resourcesynccontroller"
 
@@ -27,6 +33,7 @@ func TestObserveUserClientCABundle(t *testing.T) {
 	testCases := []struct {
 		name           string
 		config         *configv1.APIServer
+		configMap      *corev1.ConfigMap
 		existing       map[string]interface{}
 		expected       map[string]interface{}
 		expectedSynced map[string]string
@@ -50,14 +57,24 @@ func TestObserveUserClientCABundle(t *testing.T) {
 			},
 		},
 		{
-			name:     "HappyPath",
-			config:   newAPIServerConfig(withClientCA("happy")),
-			existing: map[string]interface{}{},
-			expected: map[string]interface{}{},
+			name:      "HappyPath",
+			config:    newAPIServerConfig(withClientCA("happy")),
+			configMap: newClientCAConfigMap(t, "happy", time.Now()),
+			existing:  map[string]interface{}{},
+			expected:  map[string]interface{}{},
 			expectedSynced: map[string]string{
 				"configmap/user-client-ca.openshift-kube-apiserver": "configmap/happy.openshift-config",
 			},
 		},
+		{
+			// invalid bundles are reported by the UserCertificatesDegraded condition
+			name:           "ExpiredBundle",
+			config:         newAPIServerConfig(withClientCA("expired")),
+			configMap:      newClientCAConfigMap(t, "expired", time.Now().Add(-2*time.Hour)),
+			existing:       map[string]interface{}{},
+			expected:       map[string]interface{}{},
+			expectedSynced: map[string]string{},
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -67,9 +84,15 @@ func TestObserveUserClientCABundle(t *testing.T) {
 					t.Fatal(err)
 				}
 			}
+			if tc.configMap != nil {
+				if err := indexer.Add(tc.configMap); err != nil {
+					t.Fatal(err)
+				}
+			}
 			synced := map[string]string{}
 			listers := configobservation.Listers{
 				APIServerLister_: configlistersv1.NewAPIServerLister(indexer),
+				ConfigmapLister_: corelistersv1.NewConfigMapLister(indexer),
 				ResourceSync:     &mockResourceSyncer{t: t, synced: synced},
 			}
 			result, errs := ObserveUserClientCABundle(listers, events.NewInMemoryRecorder(t.Name(), clock.RealClock{}), tc.existing)
@@ -104,6 +127,7 @@ func TestObserveNamedCertificates(t *testing.T) {
 		name           string
 		config         *configv1.APIServer
 		missingSecret  string
+		invalidSecret  string
 		existing       map[string]interface{}
 		expected       map[string]interface{}
 		expectErrs     bool
@@ -203,15 +227,15 @@ func TestObserveNamedCertificates(t *testing.T) {
 							"keyFile":  "/etc/kubernetes/static-pod-resources/secrets/localhost-recovery-serving-certkey/tls.key",
 						},
 						map[string]interface{}{
-							"certFile": "/etc/kubernetes/static-pod-certs/secrets/user-serving-cert-000/tls.crt",
-							"keyFile":  "/etc/kubernetes/static-pod-certs/secrets/user-serving-cert-000/tls.key",
+							"certFile": "/etc/kubernetes/static-pod-certs/secrets/user-serving-certs/foo.crt",
+							"keyFile":  "/etc/kubernetes/static-pod-certs/secrets/user-serving-certs/foo.key",
 							"names":    []interface{}{"*.foo.org"},
 						},
 					},
 				},
 			},
 			expectedSynced: map[string]string{
-				"secret/user-serving-cert-000.openshift-kube-apiserver": "secret/foo.openshift-config",
+				"secret/user-serving-cert-000.openshift-kube-apiserver": "DELETE",
 				"secret/user-serving-cert-001.openshift-kube-apiserver": "DELETE",
 				"secret/user-serving-cert-002.openshift-kube-apiserver": "DELETE",
 				"secret/user-serving-cert-003.openshift-kube-apiserver": "DELETE",
@@ -275,14 +299,14 @@ func TestObserveNamedCertificates(t *testing.T) {
 							"keyFile":  "/etc/kubernetes/static-pod-resources/secrets/localhost-recovery-serving-certkey/tls.key",
 						},
 						map[string]interface{}{
-							"certFile": "/etc/kubernetes/static-pod-certs/secrets/user-serving-cert-000/tls.crt",
-							"keyFile":  "/etc/kubernetes/static-pod-certs/secrets/user-serving-cert-000/tls.key",
+							"certFile": "/etc/kubernetes/static-pod-certs/secrets/user-serving-certs/foo.crt",
+							"keyFile":  "/etc/kubernetes/static-pod-certs/secrets/user-serving-certs/foo.key",
 						},
 					},
 				},
 			},
 			expectedSynced: map[string]string{
-				"secret/user-serving-cert-000.openshift-kube-apiserver": "secret/foo.openshift-config",
+				"secret/user-serving-cert-000.openshift-kube-apiserver": "DELETE",
 				"secret/user-serving-cert-001.openshift-kube-apiserver": "DELETE",
 				"secret/user-serving-cert-002.openshift-kube-apiserver": "DELETE",
 				"secret/user-serving-cert-003.openshift-kube-apiserver": "DELETE",
@@ -329,15 +353,15 @@ func TestObserveNamedCertificates(t *testing.T) {
 							"keyFile":  "/etc/kubernetes/static-pod-resources/secrets/localhost-recovery-serving-certkey/tls.key",
 						},
 						map[string]interface{}{
-							"certFile": "/etc/kubernetes/static-pod-certs/secrets/user-serving-cert-000/tls.crt",
-							"keyFile":  "/etc/kubernetes/static-pod-certs/secrets/user-serving-cert-000/tls.key",
+							"certFile": "/etc/kubernetes/static-pod-certs/secrets/user-serving-certs/foo.crt",
+							"keyFile":  "/etc/kubernetes/static-pod-certs/secrets/user-serving-certs/foo.key",
 							"names":    []interface{}{"*.foo.org", "foo.org", "*.bar.org"},
 						},
 					},
 				},
 			},
 			expectedSynced: map[string]string{
-				"secret/user-serving-cert-000.openshift-kube-apiserver": "secret/foo.openshift-config",
+				"secret/user-serving-cert-000.openshift-kube-apiserver": "DELETE",
 				"secret/user-serving-cert-001.openshift-kube-apiserver": "DELETE",
 				"secret/user-serving-cert-002.openshift-kube-apiserver": "DELETE",
 				"secret/user-serving-cert-003.openshift-kube-apiserver": "DELETE",
@@ -361,7 +385,7 @@ func TestObserveNamedCertificates(t *testing.T) {
 				),
 				withCertificate(
 					withNames("three"),
-					withNames("tři"),
+					withNames("tri"),
 					withSecret("three"),
 				),
 			),
@@ -390,26 +414,26 @@ func TestObserveNamedCertificates(t *testing.T) {
 							"keyFile":  "/etc/kubernetes/static-pod-resources/secrets/localhost-recovery-serving-certkey/tls.key",
 						},
 						map[string]interface{}{
-							"certFile": "/etc/kubernetes/static-pod-certs/secrets/user-serving-cert-000/tls.crt",
-							"keyFile":  "/etc/kubernetes/static-pod-certs/secrets/user-serving-cert-000/tls.key",
+							"certFile": "/etc/kubernetes/static-pod-certs/secrets/user-serving-certs/one.crt",
+							"keyFile":  "/etc/kubernetes/static-pod-certs/secrets/user-serving-certs/one.key",
 							"names":    []interface{}{"one"},
 						},
 						map[string]interface{}{
-							"certFile": "/etc/kubernetes/static-pod-certs/secrets/user-serving-cert-001/tls.crt",
-							"keyFile":  "/etc/kubernetes/static-pod-certs/secrets/user-serving-cert-001/tls.key",
+							"certFile": "/etc/kubernetes/static-pod-certs/secrets/user-serving-certs/two.crt",
+							"keyFile":  "/etc/kubernetes/static-pod-certs/secrets/user-serving-certs/two.key",
 						},
 						map[string]interface{}{
-							"certFile": "/etc/kubernetes/static-pod-certs/secrets/user-serving-cert-002/tls.crt",
-							"keyFile":  "/etc/kubernetes/static-pod-certs/secrets/user-serving-cert-002/tls.key",
-							"names":    []interface{}{"three", "tři"},
+							"certFile": "/etc/kubernetes/static-pod-certs/secrets/user-serving-certs/three.crt",
+							"keyFile":  "/etc/kubernetes/static-pod-certs/secrets/user-serving-certs/three.key",
+							"names":    []interface{}{"three", "tri"},
 						},
 					},
 				},
 			},
 			expectedSynced: map[string]string{
-				"secret/user-serving-cert-000.openshift-kube-apiserver": "secret/one.openshift-config",
-				"secret/user-serving-cert-001.openshift-kube-apiserver": "secret/two.openshift-config",
-				"secret/user-serving-cert-002.openshift-kube-apiserver": "secret/three.openshift-config",
+				"secret/user-serving-cert-000.openshift-kube-apiserver": "DELETE",
+				"secret/user-serving-cert-001.openshift-kube-apiserver": "DELETE",
+				"secret/user-serving-cert-002.openshift-kube-apiserver": "DELETE",
 				"secret/user-serving-cert-003.openshift-kube-apiserver": "DELETE",
 				"secret/user-serving-cert-004.openshift-kube-apiserver": "DELETE",
 				"secret/user-serving-cert-005.openshift-kube-apiserver": "DELETE",
@@ -431,36 +455,30 @@ func TestObserveNamedCertificates(t *testing.T) {
 			expectErrs: true,
 		},
 		{
-			name: "TooManyNamedCertificates",
+			name: "NoSuchSecret",
 			config: newAPIServerConfig(
-				withCertificate(withNames("000"), withSecret("000")),
-				withCertificate(withNames("001"), withSecret("001")),
-				withCertificate(withNames("002"), withSecret("002")),
-				withCertificate(withNames("003"), withSecret("003")),
-				withCertificate(withNames("004"), withSecret("004")),
-				withCertificate(withNames("005"), withSecret("005")),
-				withCertificate(withNames("006"), withSecret("006")),
-				withCertificate(withNames("007"), withSecret("007")),
-				withCertificate(withNames("008"), withSecret("008")),
-				withCertificate(withNames("009"), withSecret("009")),
-				withCertificate(withNames("010"), withSecret("010")),
+				withCertificate(
+					withNames("*.foo.org"),
+					withSecret("foo"),
+				),
 			),
-			existing:   existingConfig,
-			expected:   existingConfig,
-			expectErrs: true,
+			missingSecret: "foo",
+			existing:      existingConfig,
+			expected:      existingConfig,
+			expectErrs:    true,
 		},
 		{
-			name: "NoSuchSecret",
+			// invalid certificates are reported by the UserCertificatesDegraded condition
+			name: "InvalidSecret",
 			config: newAPIServerConfig(
 				withCertificate(
 					withNames("*.foo.org"),
 					withSecret("foo"),
 				),
 			),
-			missingSecret: "foo",
+			invalidSecret: "foo",
 			existing:      existingConfig,
 			expected:      existingConfig,
-			expectErrs:    true,
 		},
 	}
 	for _, tc := range testCases {
@@ -478,16 +496,11 @@ func TestObserveNamedCertificates(t *testing.T) {
 					if nc.ServingCertificate.Name == tc.missingSecret {
 						continue
 					}
-					objs = append(objs, &corev1.Secret{
-						ObjectMeta: metav1.ObjectMeta{
-							Name:      nc.ServingCertificate.Name,
-							Namespace: operatorclient.GlobalUserSpecifiedConfigNamespace,
-						},
-						Data: map[string][]byte{
-							"tls.crt": []byte("FOO"),
-							"tls.key": []byte("BAR"),
-						},
-					})
+					hostnames := nc.Names
+					if nc.ServingCertificate.Name == tc.invalidSecret {
+						hostnames = []string{"other.example.com"}
+					}
+					objs = append(objs, newServingCertSecret(t, nc.ServingCertificate.Name, hostnames...))
 				}
 			}
 			for _, obj := range objs {
@@ -498,9 +511,10 @@ func TestObserveNamedCertificates(t *testing.T) {
 
 			synced := map[string]string{}
 			listers := configobservation.Listers{
-				APIServerLister_:    configlistersv1.NewAPIServerLister(indexer),
-				ResourceSync:        &mockResourceSyncer{t: t, synced: synced},
-				ConfigSecretLister_: corelistersv1.NewSecretLister(indexer),
+				APIServerLister_:             configlistersv1.NewAPIServerLister(indexer),
+				ResourceSync:                 &mockResourceSyncer{t: t, synced: synced},
+				ConfigSecretLister_:          corelistersv1.NewSecretLister(indexer),
+				KubeAPIServerOperatorLister_: operatorlistersv1.NewKubeAPIServerLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})),
 			}
 			result, errs := ObserveNamedCertificates(listers, events.NewInMemoryRecorder(t.Name(), clock.RealClock{}), tc.existing)
 			if tc.expectErrs && len(errs) == 0 {
@@ -524,6 +538,142 @@ func TestObserveNamedCertificates(t *testing.T) {
 
 }
 
+func TestObserveManyNamedCertificates(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	var builders []func(*configv1.APIServer)
+	for i := 0; i < 25; i++ {
+		name := fmt.Sprintf("cert-%d", i)
+		builders = append(builders, withCertificate(withNames(name+".example.com"), withSecret(name)))
+		if err := indexer.Add(newServingCertSecret(t, name, name+".example.com")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := indexer.Add(newAPIServerConfig(builders...)); err != nil {
+		t.Fatal(err)
+	}
+
+	synced := map[string]string{}
+	listers := configobservation.Listers{
+		APIServerLister_:             configlistersv1.NewAPIServerLister(indexer),
+		ResourceSync:                 &mockResourceSyncer{t: t, synced: synced},
+		ConfigSecretLister_:          corelistersv1.NewSecretLister(indexer),
+		KubeAPIServerOperatorLister_: operatorlistersv1.NewKubeAPIServerLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})),
+	}
+	result, errs := ObserveNamedCertificates(listers, events.NewInMemoryRecorder(t.Name(), clock.RealClock{}), map[string]interface{}{})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	namedCertificates, _, err := unstructured.NestedSlice(result, "servingInfo", "namedCertificates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// five operator managed certificates precede the user provided ones
+	if len(namedCertificates) != 5+25 {
+		t.Fatalf("expected %d named certificates, got %d", 5+25, len(namedCertificates))
+	}
+	expected := map[string]interface{}{
+		"certFile": "/etc/kubernetes/static-pod-certs/secrets/user-serving-certs/cert-24.crt",
+		"keyFile":  "/etc/kubernetes/static-pod-certs/secrets/user-serving-certs/cert-24.key",
+		"names":    []interface{}{"cert-24.example.com"},
+	}
+	if last := namedCertificates[len(namedCertificates)-1]; !equality.Semantic.DeepEqual(expected, last) {
+		t.Errorf("unexpected last named certificate: %s", diff.Diff(expected, last))
+	}
+	for to, from := range synced {
+		if from != "DELETE" {
+			t.Errorf("expected only the legacy copies to be deleted, got %s synced from %s", to, from)
+		}
+	}
+}
+
+func TestObserveNamedCertificatesKeepsLegacyCopies(t *testing.T) {
+	revisionConfig := func(revision int, certFile string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.TargetNamespace, Name: fmt.Sprintf("config-%d", revision)},
+			Data: map[string]string{
+				"config.yaml": fmt.Sprintf(`{"servingInfo":{"namedCertificates":[{"certFile":%q,"names":["*.foo.org"]}]}}`, certFile),
+			},
+		}
+	}
+	legacyDeleted := func() map[string]string {
+		synced := map[string]string{}
+		for _, name := range legacyNamedUserServingCertResourceNames {
+			synced[fmt.Sprintf("secret/%s.%s", name, operatorclient.TargetNamespace)] = "DELETE"
+		}
+		return synced
+	}
+	legacyKept := legacyDeleted()
+	legacyKept["secret/user-serving-cert-000.openshift-kube-apiserver"] = "secret/foo.openshift-config"
+	legacyKept["secret/user-serving-cert-001.openshift-kube-apiserver"] = "secret/bar.openshift-config"
+
+	testCases := []struct {
+		name           string
+		revisions      []int32
+		expectedSynced map[string]string
+	}{
+		{
+			name:           "a node runs a revision using the legacy copies",
+			revisions:      []int32{2, 1},
+			expectedSynced: legacyKept,
+		},
+		{
+			name:           "a node runs a revision that cannot be inspected",
+			revisions:      []int32{2, 3},
+			expectedSynced: legacyKept,
+		},
+		{
+			name:           "all nodes run a revision using the combined secret",
+			revisions:      []int32{2, 2},
+			expectedSynced: legacyDeleted(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			objs := []runtime.Object{
+				newAPIServerConfig(
+					withCertificate(withNames("*.foo.org"), withSecret("foo")),
+					withCertificate(withNames("*.bar.org"), withSecret("bar")),
+				),
+				newServingCertSecret(t, "foo", "*.foo.org"),
+				newServingCertSecret(t, "bar", "*.bar.org"),
+				revisionConfig(1, "/etc/kubernetes/static-pod-certs/secrets/user-serving-cert-000/tls.crt"),
+				revisionConfig(2, "/etc/kubernetes/static-pod-certs/secrets/user-serving-certs/foo.crt"),
+			}
+			for _, obj := range objs {
+				if err := indexer.Add(obj); err != nil {
+					t.Fatal(err)
+				}
+			}
+			kubeAPIServer := &operatorv1.KubeAPIServer{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}}
+			for i, revision := range tc.revisions {
+				kubeAPIServer.Status.NodeStatuses = append(kubeAPIServer.Status.NodeStatuses, operatorv1.NodeStatus{NodeName: fmt.Sprintf("master-%d", i), CurrentRevision: revision})
+			}
+			operatorIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if err := operatorIndexer.Add(kubeAPIServer); err != nil {
+				t.Fatal(err)
+			}
+
+			synced := map[string]string{}
+			listers := configobservation.Listers{
+				APIServerLister_:             configlistersv1.NewAPIServerLister(indexer),
+				ResourceSync:                 &mockResourceSyncer{t: t, synced: synced},
+				ConfigSecretLister_:          corelistersv1.NewSecretLister(indexer),
+				ConfigmapLister_:             corelistersv1.NewConfigMapLister(indexer),
+				KubeAPIServerOperatorLister_: operatorlistersv1.NewKubeAPIServerLister(operatorIndexer),
+			}
+			if _, errs := ObserveNamedCertificates(listers, events.NewInMemoryRecorder(t.Name(), clock.RealClock{}), map[string]interface{}{}); len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if !equality.Semantic.DeepEqual(tc.expectedSynced, synced) {
+				t.Errorf("unexpected synced resources: %s", diff.Diff(tc.expectedSynced, synced))
+			}
+		})
+	}
+}
+
 type mockResourceSyncer struct {
 	t      *testing.T
 	synced map[string]string
@@ -547,6 +697,46 @@ func (rs *mockResourceSyncer) SyncSecret(destination, source resourcesynccontrol
 	return nil
 }
 
+// newServingCertSecret returns a user serving certificate secret covering the given hostnames.
+func newServingCertSecret(t *testing.T, name string, hostnames ...string) *corev1.Secret {
+	if len(hostnames) == 0 {
+		hostnames = []string{name + ".example.com"}
+	}
+	caConfig, err := crypto.MakeSelfSignedCAConfigForDuration("test-ca", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := &crypto.CA{Config: caConfig, SerialGenerator: &crypto.RandomSerialGenerator{}}
+	servingConfig, err := ca.MakeServerCertForDuration(sets.New(hostnames...), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM, keyPEM, err := servingConfig.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: operatorclient.GlobalUserSpecifiedConfigNamespace},
+		Data:       map[string][]byte{"tls.crt": certPEM, "tls.key": keyPEM},
+	}
+}
+
+// newClientCAConfigMap returns a user client CA bundle with a CA valid for an hour from notBefore.
+func newClientCAConfigMap(t *testing.T, name string, notBefore time.Time) *corev1.ConfigMap {
+	caConfig, err := crypto.UnsafeMakeSelfSignedCAConfigForDurationAtTime("test-client-ca", func() time.Time { return notBefore }, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM, _, err := caConfig.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: operatorclient.GlobalUserSpecifiedConfigNamespace},
+		Data:       map[string]string{"ca-bundle.crt": string(certPEM)},
+	}
+}
+
 func newAPIServerConfig(builders ...func(*configv1.APIServer)) *configv1.APIServer {
 	config := &configv1.APIServer{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}}
 	for _, builder := range builders {
@@ -3,6 +3,8 @@ package configobservercontroller
 import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 
 	configv1 "github.com/openshift/api/config/v1"
@@ -38,7 +40,7 @@ type ConfigObserver struct {
 	factory.Controller
 }
 
-func NewConfigObserver(operatorClient v1helpers.StaticPodOperatorClient, kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces, configInformer configinformers.SharedInformerFactory, operatorInformer operatorv1informers.SharedInformerFactory, resourceSyncer resourcesynccontroller.ResourceSyncer, featureGateAccessor featuregates.FeatureGateAccess, eventRecorder events.Recorder, groupVersionsByFeatureGate map[configv1.FeatureGateName][]schema.GroupVersion) *ConfigObserver {
+func NewConfigObserver(operatorClient v1helpers.StaticPodOperatorClient, kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces, nodeInformer corev1informers.NodeInformer, configInformer configinformers.SharedInformerFactory, operatorInformer operatorv1informers.SharedInformerFactory, resourceSyncer resourcesynccontroller.ResourceSyncer, featureGateAccessor featuregates.FeatureGateAccess, eventRecorder events.Recorder, groupVersionsByFeatureGate map[configv1.FeatureGateName][]schema.GroupVersion) *ConfigObserver {
 	interestingNamespaces := []string{
 		operatorclient.GlobalUserSpecifiedConfigNamespace,
 		operatorclient.GlobalMachineSpecifiedConfigNamespace,
@@ -58,6 +60,9 @@ func NewConfigObserver(operatorClient v1helpers.StaticPodOperatorClient, kubeInf
 		operatorClient.Informer(),
 		kubeInformersForNamespaces.InformersFor("openshift-etcd").Core().V1().Endpoints().Informer(),
 		kubeInformersForNamespaces.InformersFor("openshift-etcd").Core().V1().ConfigMaps().Informer(),
+		// the informer is cluster-wide, the status updates of the worker nodes
+		// would resync the config for nothing
+		filteredInformer{Informer: nodeInformer.Informer(), filter: node.MasterNodesFilter},
 		configInformer.Config().V1().Images().Informer(),
 		configInformer.Config().V1().Infrastructures().Informer(),
 		configInformer.Config().V1().Authentications().Informer(),
@@ -91,6 +96,7 @@ func NewConfigObserver(operatorClient v1helpers.StaticPodOperatorClient, kubeInf
 				SecretLister_:       kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Secrets().Lister(),
 				ConfigSecretLister_: kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().Secrets().Lister(),
 				ConfigmapLister_:    kubeInformersForNamespaces.ConfigMapLister(),
+				KubeNodeLister:      nodeInformer.Lister(),
 
 				KubeAPIServerOperatorLister_: operatorInformer.Operator().V1().KubeAPIServers().Lister(),
 
@@ -101,6 +107,7 @@ func NewConfigObserver(operatorClient v1helpers.StaticPodOperatorClient, kubeInf
 
 					kubeInformersForNamespaces.InformersFor("openshift-etcd").Core().V1().ConfigMaps().Informer().HasSynced,
 					kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Secrets().Informer().HasSynced,
+					nodeInformer.Informer().HasSynced,
 
 					configInformer.Config().V1().APIServers().Informer().HasSynced,
 					configInformer.Config().V1().Authentications().Informer().HasSynced,
@@ -115,62 +122,85 @@ func NewConfigObserver(operatorClient v1helpers.StaticPodOperatorClient, kubeInf
 				),
 			},
 			infomers,
-			// We are disabling this because it doesn't work today and customers aren't going to be able to get the kube service network options right.
-			// Customers may only use SNI.  I'm leaving this code in case we ever come up with a way to make an SNI-like thing based on IPs.
-			//apiserver.ObserveDefaultUserServingCertificate,
-			apiserver.ObserveNamedCertificates,
-			apiserver.ObserveUserClientCABundle,
-			apiserver.ObserveAdditionalCORSAllowedOrigins,
-			apiserver.ObserveShutdownDelayDuration,
-			apiserver.ObserveGracefulTerminationDuration,
-			apiserver.ObserveSendRetryAfterWhileNotReadyOnce,
-			apiserver.ObserveGoawayChance,
-			apiserver.ObserveAdmissionPlugins,
-			apiserver.NewObserveEventTTL(featureGateAccessor),
-			libgoapiserver.ObserveTLSSecurityProfile,
-			auth.NewObserveAuthMetadata(featureGateAccessor),
-			auth.ObserveServiceAccountIssuer,
-			auth.NewObserveWebhookTokenAuthenticator(featureGateAccessor),
-			auth.NewObserveExternalOIDC(featureGateAccessor),
-			auth.NewObservePodSecurityAdmissionEnforcementFunc(featureGateAccessor),
-			encryption.NewEncryptionConfigObserver(
-				operatorclient.TargetNamespace,
-				// static path at which we expect to find the encryption config secret
-				"/etc/kubernetes/static-pod-resources/secrets/encryption-config/encryption-config",
-			),
-			etcdendpoints.ObserveStorageURLs,
-			cloudprovider.NewCloudProviderObserver(
-				"openshift-kube-apiserver", true,
-			),
-			apienablement.NewFeatureGateObserverWithRuntimeConfig(
-				nil,
-				FeatureBlacklist,
-				featureGateAccessor,
-				groupVersionsByFeatureGate,
-			),
-			network.ObserveRestrictedCIDRs,
-			network.ObserveServicesSubnet,
-			network.ObserveExternalIPPolicy,
-			network.ObserveServicesNodePortRange,
-			nodeobserver.NewLatencyProfileObserver(
-				node.LatencyConfigs,
-				[]nodeobserver.ShouldSuppressConfigUpdatesFunc{
-					nodeobserver.NewSuppressConfigUpdateUntilSameProfileFunc(
-						operatorClient,
-						kubeInformersForNamespaces.ConfigMapLister().ConfigMaps(operatorclient.TargetNamespace),
-						node.LatencyConfigs,
-					),
-				},
-			),
-			node.NewMinimumKubeletVersionObserver(featureGateAccessor),
-			node.NewAuthorizationModeObserver(featureGateAccessor),
-			proxy.NewProxyObserveFunc([]string{"targetconfigcontroller", "proxy"}),
-			images.ObserveInternalRegistryHostname,
-			images.ObserveExternalRegistryHostnames,
-			images.ObserveAllowedRegistriesForImport,
-			scheduler.ObserveDefaultNodeSelector,
+			Observers(operatorClient, kubeInformersForNamespaces.ConfigMapLister(), featureGateAccessor, groupVersionsByFeatureGate)...,
 		),
 	}
 
 	return c
 }
+
+// filteredInformer passes on the events of the objects the filter accepts only,
+// for the informers of the config observer, which registers them unfiltered.
+type filteredInformer struct {
+	factory.Informer
+	filter factory.EventFilterFunc
+}
+
+func (i filteredInformer) AddEventHandler(handler cache.ResourceEventHandler) (cache.ResourceEventHandlerRegistration, error) {
+	return i.Informer.AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: i.filter,
+		Handler:    handler,
+	})
+}
+
+// Observers returns the config observers of the kube-apiserver, the ones
+// NewConfigObserver runs and the simulate command replays offline.
+func Observers(operatorClient v1helpers.StaticPodOperatorClient, configMapLister corev1listers.ConfigMapLister, featureGateAccessor featuregates.FeatureGateAccess, groupVersionsByFeatureGate map[configv1.FeatureGateName][]schema.GroupVersion) []configobserver.ObserveConfigFunc {
+	return []configobserver.ObserveConfigFunc{
+		// We are disabling this because it doesn't work today and customers aren't going to be able to get the kube service network options right.
+		// Customers may only use SNI.  I'm leaving this code in case we ever come up with a way to make an SNI-like thing based on IPs.
+		//apiserver.ObserveDefaultUserServingCertificate,
+		apiserver.ObserveNamedCertificates,
+		apiserver.ObserveUserClientCABundle,
+		apiserver.ObserveAdditionalCORSAllowedOrigins,
+		apiserver.ObserveShutdownDelayDuration,
+		apiserver.ObserveGracefulTerminationDuration,
+		apiserver.ObserveSendRetryAfterWhileNotReadyOnce,
+		apiserver.ObserveGoawayChance,
+		apiserver.ObserveAdmissionPlugins,
+		apiserver.NewObserveEventTTL(featureGateAccessor),
+		libgoapiserver.ObserveTLSSecurityProfile,
+		auth.NewObserveAuthMetadata(featureGateAccessor),
+		auth.ObserveServiceAccountIssuer,
+		auth.NewObserveWebhookTokenAuthenticator(featureGateAccessor),
+		auth.NewObserveExternalOIDC(featureGateAccessor),
+		auth.NewObservePodSecurityAdmissionEnforcementFunc(featureGateAccessor),
+		encryption.NewEncryptionConfigObserver(
+			operatorclient.TargetNamespace,
+			// static path at which we expect to find the encryption config secret
+			"/etc/kubernetes/static-pod-resources/secrets/encryption-config/encryption-config",
+		),
+		etcdendpoints.ObserveStorageURLs,
+		cloudprovider.NewCloudProviderObserver(
+			"openshift-kube-apiserver", true,
+		),
+		apienablement.NewFeatureGateObserverWithRuntimeConfig(
+			nil,
+			FeatureBlacklist,
+			featureGateAccessor,
+			groupVersionsByFeatureGate,
+		),
+		network.ObserveRestrictedCIDRs,
+		network.ObserveServicesSubnet,
+		network.ObserveExternalIPPolicy,
+		network.ObserveServicesNodePortRange,
+		nodeobserver.NewLatencyProfileObserver(
+			node.LatencyConfigs,
+			[]nodeobserver.ShouldSuppressConfigUpdatesFunc{
+				nodeobserver.NewSuppressConfigUpdateUntilSameProfileFunc(
+					operatorClient,
+					configMapLister.ConfigMaps(operatorclient.TargetNamespace),
+					node.LatencyConfigs,
+				),
+			},
+		),
+		node.NewMinimumKubeletVersionObserver(featureGateAccessor),
+		node.NewAuthorizationModeObserver(featureGateAccessor),
+		node.ObserveControlPlaneAllocatable,
+		proxy.NewProxyObserveFunc([]string{"targetconfigcontroller", "proxy"}),
+		images.ObserveInternalRegistryHostname,
+		images.ObserveExternalRegistryHostnames,
+		images.ObserveAllowedRegistriesForImport,
+		scheduler.ObserveDefaultNodeSelector,
+	}
+}
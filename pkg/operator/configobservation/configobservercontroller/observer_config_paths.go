@@ -0,0 +1,95 @@
+package configobservercontroller
+
+// observerConfigPaths lists the paths of the observed config each one of the
+// Observers sets, in the order of Observers. Keep it in sync with the
+// observers, it is how explain-config tells which observer produced a value.
+var observerConfigPaths = []struct {
+	observer string
+	paths    [][]string
+}{
+	{"apiserver.ObserveNamedCertificates", [][]string{{"servingInfo", "namedCertificates"}}},
+	// only syncs the user client CA bundle, the TargetConfigController combines it
+	{"apiserver.ObserveUserClientCABundle", nil},
+	{"apiserver.ObserveAdditionalCORSAllowedOrigins", [][]string{{"corsAllowedOrigins"}}},
+	{"apiserver.ObserveShutdownDelayDuration", [][]string{{"apiServerArguments", "shutdown-delay-duration"}}},
+	{"apiserver.ObserveGracefulTerminationDuration", [][]string{{"gracefulTerminationDuration"}}},
+	{"apiserver.ObserveSendRetryAfterWhileNotReadyOnce", [][]string{{"apiServerArguments", "send-retry-after-while-not-ready-once"}}},
+	{"apiserver.ObserveGoawayChance", [][]string{{"apiServerArguments", "goaway-chance"}}},
+	{"apiserver.ObserveAdmissionPlugins", [][]string{
+		{"apiServerArguments", "enable-admission-plugins"},
+		{"apiServerArguments", "disable-admission-plugins"},
+	}},
+	{"apiserver.NewObserveEventTTL", [][]string{{"apiServerArguments", "event-ttl"}}},
+	{"libgoapiserver.ObserveTLSSecurityProfile", [][]string{
+		{"servingInfo", "minTLSVersion"},
+		{"servingInfo", "cipherSuites"},
+	}},
+	{"auth.NewObserveAuthMetadata", [][]string{{"authConfig", "oauthMetadataFile"}}},
+	{"auth.ObserveServiceAccountIssuer", [][]string{
+		{"apiServerArguments", "service-account-issuer"},
+		{"apiServerArguments", "api-audiences"},
+		{"apiServerArguments", "service-account-jwks-uri"},
+	}},
+	{"auth.NewObserveWebhookTokenAuthenticator", [][]string{
+		{"apiServerArguments", "authentication-token-webhook-config-file"},
+		{"apiServerArguments", "authentication-token-webhook-version"},
+	}},
+	{"auth.NewObserveExternalOIDC", [][]string{{"apiServerArguments", "authentication-config"}}},
+	{"auth.NewObservePodSecurityAdmissionEnforcementFunc", [][]string{{"admission", "pluginConfig", "PodSecurity", "configuration", "defaults"}}},
+	{"encryption.NewEncryptionConfigObserver", [][]string{{"apiServerArguments", "encryption-provider-config"}}},
+	{"etcdendpoints.ObserveStorageURLs", [][]string{
+		{"apiServerArguments", "etcd-servers"},
+		{"storageConfig", "urls"},
+	}},
+	// only removes the unused cloud-config ConfigMap
+	{"cloudprovider.NewCloudProviderObserver", nil},
+	{"apienablement.NewFeatureGateObserverWithRuntimeConfig", [][]string{
+		{"apiServerArguments", "feature-gates"},
+		{"apiServerArguments", "runtime-config"},
+	}},
+	{"network.ObserveRestrictedCIDRs", [][]string{{"admission", "pluginConfig", "network.openshift.io/RestrictedEndpointsAdmission", "configuration"}}},
+	{"network.ObserveServicesSubnet", [][]string{
+		{"servicesSubnet"},
+		{"servingInfo", "bindAddress"},
+		{"servingInfo", "bindNetwork"},
+	}},
+	{"network.ObserveExternalIPPolicy", [][]string{{"admission", "pluginConfig", "network.openshift.io/ExternalIPRanger", "configuration"}}},
+	{"network.ObserveServicesNodePortRange", [][]string{{"apiServerArguments", "service-node-port-range"}}},
+	{"nodeobserver.NewLatencyProfileObserver", [][]string{
+		{"apiServerArguments", "default-not-ready-toleration-seconds"},
+		{"apiServerArguments", "default-unreachable-toleration-seconds"},
+	}},
+	{"node.NewMinimumKubeletVersionObserver", [][]string{{"minimumKubeletVersion"}}},
+	{"node.NewAuthorizationModeObserver", [][]string{{"apiServerArguments", "authorization-mode"}}},
+	{"node.ObserveControlPlaneAllocatable", [][]string{{"targetconfigcontroller", "controlPlaneAllocatable"}}},
+	{"proxy.NewProxyObserveFunc", [][]string{{"targetconfigcontroller", "proxy"}}},
+	{"images.ObserveInternalRegistryHostname", [][]string{{"imagePolicyConfig", "internalRegistryHostname"}}},
+	{"images.ObserveExternalRegistryHostnames", [][]string{{"imagePolicyConfig", "externalRegistryHostnames"}}},
+	{"images.ObserveAllowedRegistriesForImport", [][]string{{"imagePolicyConfig", "allowedRegistriesForImport"}}},
+	{"scheduler.ObserveDefaultNodeSelector", [][]string{{"projectConfig", "defaultNodeSelector"}}},
+}
+
+// ObserverFor returns the observer that sets the given path of the observed
+// config, or an empty string when none is known to.
+func ObserverFor(path []string) string {
+	for _, entry := range observerConfigPaths {
+		for _, observerPath := range entry.paths {
+			if hasPrefix(path, observerPath) {
+				return entry.observer
+			}
+		}
+	}
+	return ""
+}
+
+func hasPrefix(path, prefix []string) bool {
+	if len(path) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,172 @@
+package configobservercontroller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/blang/semver/v4"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/clock"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/api/features"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resourcesynccontroller"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation/apienablement"
+)
+
+type noopResourceSyncer struct{}
+
+func (noopResourceSyncer) SyncConfigMap(destination, source resourcesynccontroller.ResourceLocation) error {
+	return nil
+}
+
+func (noopResourceSyncer) SyncSecret(destination, source resourcesynccontroller.ResourceLocation) error {
+	return nil
+}
+
+func indexerWith(t *testing.T, objs ...runtime.Object) cache.Indexer {
+	t.Helper()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, obj := range objs {
+		if err := indexer.Add(obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return indexer
+}
+
+// TestObserverConfigPaths runs the observers against a cluster that sets most
+// of their inputs and checks every path they write is attributed to them.
+func TestObserverConfigPaths(t *testing.T) {
+	cluster := metav1.ObjectMeta{Name: "cluster"}
+	listers := configobservation.Listers{
+		APIServerLister_: configv1listers.NewAPIServerLister(indexerWith(t, &configv1.APIServer{
+			ObjectMeta: cluster,
+			Spec: configv1.APIServerSpec{
+				AdditionalCORSAllowedOrigins: []string{"//example.com"},
+				TLSSecurityProfile:           &configv1.TLSSecurityProfile{Type: configv1.TLSProfileModernType, Modern: &configv1.ModernTLSProfile{}},
+			},
+		})),
+		AuthConfigLister: configv1listers.NewAuthenticationLister(indexerWith(t, &configv1.Authentication{
+			ObjectMeta: cluster,
+			Spec:       configv1.AuthenticationSpec{ServiceAccountIssuer: "https://issuer.example.com"},
+		})),
+		FeatureGateLister_: configv1listers.NewFeatureGateLister(indexerWith(t)),
+		ImageConfigLister: configv1listers.NewImageLister(indexerWith(t, &configv1.Image{
+			ObjectMeta: cluster,
+			Spec: configv1.ImageSpec{
+				ExternalRegistryHostnames:  []string{"registry.example.com"},
+				AllowedRegistriesForImport: []configv1.RegistryLocation{{DomainName: "quay.io"}},
+			},
+			Status: configv1.ImageStatus{InternalRegistryHostname: "image-registry.openshift-image-registry.svc:5000"},
+		})),
+		InfrastructureLister_: configv1listers.NewInfrastructureLister(indexerWith(t, &configv1.Infrastructure{
+			ObjectMeta: cluster,
+			Status: configv1.InfrastructureStatus{
+				APIServerURL:           "https://api.example.com:6443",
+				ControlPlaneTopology:   configv1.HighlyAvailableTopologyMode,
+				InfrastructureTopology: configv1.HighlyAvailableTopologyMode,
+			},
+		})),
+		NetworkLister: configv1listers.NewNetworkLister(indexerWith(t, &configv1.Network{
+			ObjectMeta: cluster,
+			Spec: configv1.NetworkSpec{
+				ExternalIP:           &configv1.ExternalIPConfig{Policy: &configv1.ExternalIPPolicy{AllowedCIDRs: []string{"10.0.0.0/8"}}},
+				ServiceNodePortRange: "30000-32767",
+			},
+			Status: configv1.NetworkStatus{ClusterNetwork: []configv1.ClusterNetworkEntry{{CIDR: "10.128.0.0/14"}}, ServiceNetwork: []string{"172.30.0.0/16"}},
+		})),
+		NodeLister_: configv1listers.NewNodeLister(indexerWith(t, &configv1.Node{
+			ObjectMeta: cluster,
+			Spec:       configv1.NodeSpec{WorkerLatencyProfile: configv1.MediumUpdateAverageReaction},
+		})),
+		ProxyLister_: configv1listers.NewProxyLister(indexerWith(t, &configv1.Proxy{
+			ObjectMeta: cluster,
+			Status:     configv1.ProxyStatus{HTTPProxy: "http://proxy.example.com", NoProxy: "example.com"},
+		})),
+		SchedulerLister: configv1listers.NewSchedulerLister(indexerWith(t, &configv1.Scheduler{
+			ObjectMeta: cluster,
+			Spec:       configv1.SchedulerSpec{DefaultNodeSelector: "node-role.kubernetes.io/worker="},
+		})),
+		ConfigmapLister_: corev1listers.NewConfigMapLister(indexerWith(t, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-etcd", Name: "etcd-endpoints"},
+			Data:       map[string]string{"member-1": "10.0.0.1"},
+		})),
+		SecretLister_:       corev1listers.NewSecretLister(indexerWith(t)),
+		ConfigSecretLister_: corev1listers.NewSecretLister(indexerWith(t)),
+		KubeNodeLister: corev1listers.NewNodeLister(indexerWith(t, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "master-0", Labels: map[string]string{"node-role.kubernetes.io/master": ""}},
+			Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+			}},
+		})),
+		KubeAPIServerOperatorLister_: operatorv1listers.NewKubeAPIServerLister(indexerWith(t, &operatorv1.KubeAPIServer{ObjectMeta: cluster})),
+		ResourceSync:                 noopResourceSyncer{},
+	}
+
+	featureSet := features.FeatureSets(4, features.SelfManaged, configv1.Default)
+	var enabled, disabled []configv1.FeatureGateName
+	for _, gate := range featureSet.Enabled {
+		enabled = append(enabled, gate.FeatureGateAttributes.Name)
+	}
+	for _, gate := range featureSet.Disabled {
+		disabled = append(disabled, gate.FeatureGateAttributes.Name)
+	}
+	featureGateAccessor := featuregates.NewHardcodedFeatureGateAccess(enabled, disabled)
+	groupVersionsByFeatureGate, err := apienablement.GetDefaultGroupVersionByFeatureGate(semver.Version{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	operatorClient := v1helpers.NewFakeStaticPodOperatorClient(&operatorv1.StaticPodOperatorSpec{}, &operatorv1.StaticPodOperatorStatus{}, nil, nil)
+
+	observers := Observers(operatorClient, listers.ConfigMapLister(), featureGateAccessor, groupVersionsByFeatureGate)
+	if len(observers) != len(observerConfigPaths) {
+		t.Fatalf("expected an observerConfigPaths entry per observer, got %d entries for %d observers", len(observerConfigPaths), len(observers))
+	}
+	recorder := events.NewInMemoryRecorder("test", clock.RealClock{})
+	written := 0
+	for i, observe := range observers {
+		entry := observerConfigPaths[i]
+		observedConfig, _ := observe(listers, recorder, map[string]interface{}{})
+		walkConfigLeaves(nil, observedConfig, func(path []string) {
+			written++
+			for _, observerPath := range entry.paths {
+				if hasPrefix(path, observerPath) {
+					return
+				}
+			}
+			t.Errorf("observer %d (%s) writes %s, which observerConfigPaths doesn't attribute to it", i, entry.observer, strings.Join(path, "."))
+		})
+	}
+	if written == 0 {
+		t.Fatal("expected the observers to write the observed config")
+	}
+}
+
+// walkConfigLeaves calls fn with the path of every value of the given config
+// that isn't an object.
+func walkConfigLeaves(path []string, config map[string]interface{}, fn func(path []string)) {
+	for key, value := range config {
+		valuePath := append(append([]string{}, path...), key)
+		if valueMap, ok := value.(map[string]interface{}); ok && len(valueMap) > 0 {
+			walkConfigLeaves(valuePath, valueMap, fn)
+			continue
+		}
+		fn(valuePath)
+	}
+}
@@ -30,6 +30,7 @@ type Listers struct {
 	ConfigmapLister_    corelistersv1.ConfigMapLister
 	SecretLister_       corelistersv1.SecretLister
 	ConfigSecretLister_ corelistersv1.SecretLister
+	KubeNodeLister      corelistersv1.NodeLister
 
 	ResourceSync       resourcesynccontroller.ResourceSyncer
 	PreRunCachesSynced []cache.InformerSynced
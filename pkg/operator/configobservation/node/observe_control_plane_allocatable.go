@@ -0,0 +1,74 @@
+package node
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/library-go/pkg/operator/configobserver"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+// ControlPlaneAllocatablePath is where the allocatable cpu and memory of the
+// smallest master node are observed, for the target config controller to size
+// the kube-apiserver pod.
+var ControlPlaneAllocatablePath = []string{"targetconfigcontroller", "controlPlaneAllocatable"}
+
+var masterNodeSelector = labels.SelectorFromSet(labels.Set{"node-role.kubernetes.io/master": ""})
+
+// MasterNodesFilter passes the events of the master nodes, the only ones
+// ObserveControlPlaneAllocatable looks at.
+func MasterNodesFilter(obj interface{}) bool {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	kubeNode, ok := obj.(*corev1.Node)
+	return ok && masterNodeSelector.Matches(labels.Set(kubeNode.Labels))
+}
+
+// ObserveControlPlaneAllocatable observes the smallest allocatable cpu and
+// memory of the master nodes. They are rounded down to whole cores and GiB, so
+// that the small variations of the allocatable resources don't roll out new
+// revisions.
+func ObserveControlPlaneAllocatable(genericListers configobserver.Listers, _ events.Recorder, existingConfig map[string]interface{}) (ret map[string]interface{}, errs []error) {
+	defer func() {
+		ret = configobserver.Pruned(ret, ControlPlaneAllocatablePath)
+	}()
+
+	listers := genericListers.(configobservation.Listers)
+	nodes, err := listers.KubeNodeLister.List(masterNodeSelector)
+	if err != nil {
+		return existingConfig, append(errs, err)
+	}
+
+	var cpu, memory *resource.Quantity
+	for _, node := range nodes {
+		nodeCPU, nodeMemory := node.Status.Allocatable[corev1.ResourceCPU], node.Status.Allocatable[corev1.ResourceMemory]
+		if nodeCPU.IsZero() || nodeMemory.IsZero() {
+			// not reported by the kubelet yet
+			continue
+		}
+		if cpu == nil || nodeCPU.Cmp(*cpu) < 0 {
+			cpu = &nodeCPU
+		}
+		if memory == nil || nodeMemory.Cmp(*memory) < 0 {
+			memory = &nodeMemory
+		}
+	}
+
+	ret = map[string]interface{}{}
+	if cpu == nil {
+		return ret, errs
+	}
+	allocatable := map[string]interface{}{
+		"cpu":    resource.NewQuantity(max(cpu.MilliValue()/1000, 1), resource.DecimalSI).String(),
+		"memory": resource.NewQuantity(max(memory.Value()>>30, 1)<<30, resource.BinarySI).String(),
+	}
+	if err := unstructured.SetNestedField(ret, allocatable, ControlPlaneAllocatablePath...); err != nil {
+		return existingConfig, append(errs, err)
+	}
+	return ret, errs
+}
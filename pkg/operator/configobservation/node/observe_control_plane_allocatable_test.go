@@ -0,0 +1,110 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelistersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestObserveControlPlaneAllocatable(t *testing.T) {
+	node := func(name string, master bool, cpu, memory string) *corev1.Node {
+		n := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}}}
+		if master {
+			n.Labels["node-role.kubernetes.io/master"] = ""
+		}
+		if len(cpu) > 0 {
+			n.Status.Allocatable = corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			}
+		}
+		return n
+	}
+
+	for _, test := range []struct {
+		name           string
+		nodes          []*corev1.Node
+		existingConfig map[string]interface{}
+		expectedConfig map[string]interface{}
+	}{
+		{
+			name:           "no master nodes",
+			nodes:          []*corev1.Node{node("worker-0", false, "64", "256Gi")},
+			expectedConfig: map[string]interface{}{},
+		},
+		{
+			name: "smallest master allocatable, rounded down",
+			nodes: []*corev1.Node{
+				node("master-0", true, "7500m", "31Gi"),
+				node("master-1", true, "15500m", "30700Mi"),
+				node("master-2", true, "", ""),
+				node("worker-0", false, "2", "4Gi"),
+			},
+			expectedConfig: map[string]interface{}{"targetconfigcontroller": map[string]interface{}{"controlPlaneAllocatable": map[string]interface{}{
+				"cpu":    "7",
+				"memory": "29Gi",
+			}}},
+		},
+		{
+			name:  "unset when the master nodes are gone",
+			nodes: []*corev1.Node{},
+			existingConfig: map[string]interface{}{"targetconfigcontroller": map[string]interface{}{"controlPlaneAllocatable": map[string]interface{}{
+				"cpu":    "7",
+				"memory": "29Gi",
+			}}},
+			expectedConfig: map[string]interface{}{},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			for _, n := range test.nodes {
+				if err := indexer.Add(n); err != nil {
+					t.Fatal(err)
+				}
+			}
+			listers := configobservation.Listers{KubeNodeLister: corelistersv1.NewNodeLister(indexer)}
+			eventRecorder := events.NewInMemoryRecorder("", clocktesting.NewFakePassiveClock(time.Now()))
+
+			actualConfig, errs := ObserveControlPlaneAllocatable(listers, eventRecorder, test.existingConfig)
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+			if diff := cmp.Diff(test.expectedConfig, actualConfig); diff != "" {
+				t.Fatalf("unexpected configuration, diff = %v", diff)
+			}
+		})
+	}
+}
+
+func TestMasterNodesFilter(t *testing.T) {
+	master := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "master-0", Labels: map[string]string{"node-role.kubernetes.io/master": ""}}}
+	worker := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-0", Labels: map[string]string{"node-role.kubernetes.io/worker": ""}}}
+
+	for _, test := range []struct {
+		name     string
+		obj      interface{}
+		expected bool
+	}{
+		{name: "master node", obj: master, expected: true},
+		{name: "worker node", obj: worker},
+		{name: "deleted master node", obj: cache.DeletedFinalStateUnknown{Key: master.Name, Obj: master}, expected: true},
+		{name: "deleted worker node", obj: cache.DeletedFinalStateUnknown{Key: worker.Name, Obj: worker}},
+		{name: "not a node", obj: &corev1.ConfigMap{ObjectMeta: master.ObjectMeta}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := MasterNodesFilter(test.obj); actual != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package externaljwtsigner
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// PublicKey is a public key that verifies the tokens the external signer
+// issues.
+type PublicKey struct {
+	// KeyID is the key ID in the header of the tokens signed with the key.
+	KeyID string
+	Key   crypto.PublicKey
+	// ExcludeFromOIDCDiscovery keeps the key out of the OIDC discovery
+	// documents of the kube-apiserver.
+	ExcludeFromOIDCDiscovery bool
+}
+
+// KeyFetcher returns the public keys of the external signer.
+type KeyFetcher interface {
+	FetchKeys(ctx context.Context) ([]PublicKey, error)
+}
+
+// Client calls the external signer on its unix socket. It only fetches keys
+// and metadata, signing tokens is left to the kube-apiserver.
+type Client struct {
+	conn *grpc.ClientConn
+}
+
+var _ KeyFetcher = &Client{}
+
+// NewClient returns a client of the external signer listening on the given
+// unix socket. The connection is established on the first call.
+func NewClient(socketPath string) (*Client, error) {
+	conn, err := grpc.NewClient("unix://"+socketPath,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(codec{})),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn}, nil
+}
+
+// FetchKeys returns the public keys of the signer, ordered by key ID.
+func (c *Client) FetchKeys(ctx context.Context) ([]PublicKey, error) {
+	response := &fetchKeysResponse{}
+	if err := c.conn.Invoke(ctx, fetchKeysMethod, &fetchKeysRequest{}, response); err != nil {
+		return nil, fmt.Errorf("failed to fetch the keys of the external JWT signer: %w", err)
+	}
+	keys := make([]PublicKey, 0, len(response.keys))
+	for _, k := range response.keys {
+		publicKey, err := x509.ParsePKIXPublicKey(k.key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q of the external JWT signer: %w", k.keyID, err)
+		}
+		keys = append(keys, PublicKey{KeyID: k.keyID, Key: publicKey, ExcludeFromOIDCDiscovery: k.excludeFromOIDCDiscovery})
+	}
+	sortKeys(keys)
+	return keys, nil
+}
+
+// Close closes the connection to the signer.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
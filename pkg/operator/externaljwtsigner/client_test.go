@@ -0,0 +1,59 @@
+package externaljwtsigner_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/externaljwtsigner"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/externaljwtsigner/signertest"
+)
+
+func TestClientWithStandInSigner(t *testing.T) {
+	// unix socket paths are short, the test temp dirs are too long
+	socketDir, err := os.MkdirTemp("", "signer")
+	require.NoError(t, err)
+	defer os.RemoveAll(socketDir)
+	socketPath := filepath.Join(socketDir, "signer.sock")
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	keys := []externaljwtsigner.PublicKey{
+		{KeyID: "b", Key: rsaKey.Public()},
+		{KeyID: "a", Key: ecKey.Public(), ExcludeFromOIDCDiscovery: true},
+	}
+
+	signer, err := signertest.NewStandInSigner(socketPath, keys...)
+	require.NoError(t, err)
+	defer signer.Stop()
+
+	client, err := externaljwtsigner.NewClient(socketPath)
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	fetched, err := client.FetchKeys(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []externaljwtsigner.PublicKey{keys[1], keys[0]}, fetched)
+
+	signer.SetKeys()
+	fetched, err = client.FetchKeys(ctx)
+	require.NoError(t, err)
+	require.Empty(t, fetched)
+
+	signer.Stop()
+	_, err = client.FetchKeys(ctx)
+	require.Error(t, err)
+}
@@ -0,0 +1,95 @@
+package externaljwtsigner
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+const (
+	// configKey is the key of the external JWT signer settings in the
+	// operator config map.
+	configKey = "externalJWTSigner"
+
+	// SigningEndpointFlag makes the kube-apiserver sign service account tokens
+	// with the external signer listening on the given unix socket instead of
+	// a private key file.
+	SigningEndpointFlag = "--service-account-signing-endpoint"
+
+	// maxSocketPathLength is the longest path of a unix socket, sun_path
+	// holds 108 bytes including the terminating NUL.
+	maxSocketPathLength = 107
+)
+
+var (
+	// socketPathPattern restricts the socket path to characters that need no
+	// quoting in the shell script that starts the kube-apiserver.
+	socketPathPattern = regexp.MustCompile(`^/[a-zA-Z0-9._/-]+$`)
+
+	signingEndpointArgPattern = regexp.MustCompile(regexp.QuoteMeta(SigningEndpointFlag) + `=(\S+)`)
+)
+
+// Config configures the external JWT signer. When it is set, the
+// kube-apiserver delegates the signing of service account tokens to the signer
+// on the node and the bound service account signing key is not used.
+type Config struct {
+	// SocketPath is the absolute path of the unix socket the signer listens on
+	// on every control plane node.
+	SocketPath string `json:"socketPath"`
+}
+
+// ConfigFromConfigMap reads the external JWT signer settings from the
+// operator config map. It returns nil if none are set.
+func ConfigFromConfigMap(configMap *corev1.ConfigMap) (*Config, error) {
+	config := &Config{}
+	found, err := operatorconfig.Unmarshal(configMap, configKey, config)
+	if !found || err != nil {
+		return nil, err
+	}
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", operatorconfig.KeyPath(configKey), err)
+	}
+	return config, nil
+}
+
+func (c *Config) validate() error {
+	switch {
+	case len(c.SocketPath) == 0:
+		return fmt.Errorf("socketPath is required")
+	case !socketPathPattern.MatchString(c.SocketPath):
+		return fmt.Errorf("socketPath %q must be an absolute path of letters, digits, '.', '_', '-' and '/'", c.SocketPath)
+	case filepath.Clean(c.SocketPath) != c.SocketPath:
+		return fmt.Errorf("socketPath %q must be a clean path", c.SocketPath)
+	case len(c.SocketPath) > maxSocketPathLength:
+		return fmt.Errorf("socketPath %q is longer than %d characters", c.SocketPath, maxSocketPathLength)
+	case c.SocketDir() == "/":
+		return fmt.Errorf("socketPath %q must not be in the root directory", c.SocketPath)
+	}
+	return nil
+}
+
+// SocketDir is the directory of the socket, it is mounted from the host into
+// the containers that talk to the signer.
+func (c *Config) SocketDir() string {
+	return filepath.Dir(c.SocketPath)
+}
+
+// Flag is the kube-apiserver flag that selects the signer.
+func (c *Config) Flag() string {
+	return SigningEndpointFlag + "=" + c.SocketPath
+}
+
+// SigningEndpointFromArgs returns the socket of the external signer that the
+// given command line selects, or "" if it selects none.
+func SigningEndpointFromArgs(args ...string) string {
+	match := signingEndpointArgPattern.FindStringSubmatch(strings.Join(args, " "))
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
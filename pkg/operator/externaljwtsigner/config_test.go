@@ -0,0 +1,103 @@
+package externaljwtsigner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestConfigFromConfigMap(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          map[string]string
+		expected      *Config
+		expectedError string
+	}{
+		{
+			name: "no config map",
+		},
+		{
+			name: "not configured",
+			data: map[string]string{"certRotationProfile": "{}"},
+		},
+		{
+			name:     "socket path",
+			data:     map[string]string{"externalJWTSigner": `{"socketPath": "/var/run/jwt-signer/signer.sock"}`},
+			expected: &Config{SocketPath: "/var/run/jwt-signer/signer.sock"},
+		},
+		{
+			name:          "missing socket path",
+			data:          map[string]string{"externalJWTSigner": `{}`},
+			expectedError: "socketPath is required",
+		},
+		{
+			name:          "relative socket path",
+			data:          map[string]string{"externalJWTSigner": `{"socketPath": "signer.sock"}`},
+			expectedError: "must be an absolute path",
+		},
+		{
+			name:          "socket path with shell characters",
+			data:          map[string]string{"externalJWTSigner": `{"socketPath": "/var/run/signer.sock; rm -rf /"}`},
+			expectedError: "must be an absolute path",
+		},
+		{
+			name:          "unclean socket path",
+			data:          map[string]string{"externalJWTSigner": `{"socketPath": "/var/run/../signer.sock"}`},
+			expectedError: "must be a clean path",
+		},
+		{
+			name:          "socket in the root directory",
+			data:          map[string]string{"externalJWTSigner": `{"socketPath": "/signer.sock"}`},
+			expectedError: "must not be in the root directory",
+		},
+		{
+			name:          "long socket path",
+			data:          map[string]string{"externalJWTSigner": `{"socketPath": "/var/run/` + strings.Repeat("a", 100) + `"}`},
+			expectedError: "longer than 107 characters",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var configMap *corev1.ConfigMap
+			if test.data != nil {
+				configMap = &corev1.ConfigMap{Data: test.data}
+			}
+			config, err := ConfigFromConfigMap(configMap)
+			if len(test.expectedError) > 0 {
+				require.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, config)
+		})
+	}
+}
+
+func TestSigningEndpointFromArgs(t *testing.T) {
+	require.Equal(t, "", SigningEndpointFromArgs("/bin/bash", "-ec", "exec hyperkube kube-apiserver --v=2"))
+	require.Equal(t, "/var/run/jwt-signer/signer.sock", SigningEndpointFromArgs("/bin/bash", "-ec", "exec hyperkube kube-apiserver --service-account-signing-endpoint=/var/run/jwt-signer/signer.sock --v=2"))
+}
+
+func TestAddToKubeAPIServerPod(t *testing.T) {
+	config := &Config{SocketPath: "/var/run/jwt-signer/signer.sock"}
+	pod := &corev1.PodSpec{
+		Containers: []corev1.Container{
+			{Name: "kube-apiserver", Args: []string{"exec watch-termination -- hyperkube kube-apiserver --openshift-config=config.yaml"}},
+			{Name: "kube-apiserver-cert-syncer"},
+		},
+	}
+
+	require.NoError(t, config.AddToKubeAPIServerPod(pod, "kube-apiserver"))
+	require.Equal(t, "exec watch-termination -- hyperkube kube-apiserver --service-account-signing-endpoint=/var/run/jwt-signer/signer.sock --openshift-config=config.yaml", pod.Containers[0].Args[0])
+	require.Equal(t, config.SocketPath, SigningEndpointFromArgs(pod.Containers[0].Args...))
+	require.Len(t, pod.Volumes, 1)
+	require.Equal(t, "/var/run/jwt-signer", pod.Volumes[0].HostPath.Path)
+	require.Equal(t, []corev1.VolumeMount{{Name: pod.Volumes[0].Name, MountPath: "/var/run/jwt-signer"}}, pod.Containers[0].VolumeMounts)
+	require.Empty(t, pod.Containers[1].VolumeMounts)
+
+	require.ErrorContains(t, config.AddToKubeAPIServerPod(pod, "kube-apiserver-cert-syncer"), "doesn't start the kube-apiserver")
+	require.ErrorContains(t, config.AddToKubeAPIServerPod(pod, "missing"), "not found")
+}
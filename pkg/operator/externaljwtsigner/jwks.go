@@ -0,0 +1,136 @@
+package externaljwtsigner
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+
+	"k8s.io/client-go/rest"
+)
+
+// jwksPath serves the keys that verify service account tokens. With an
+// external signer, these are the keys the kube-apiserver fetched from it.
+const jwksPath = "/openid/v1/jwks"
+
+// jwksKeyFetcher returns the keys of the external signer from the key set
+// the kube-apiserver publishes for OIDC discovery. The operator doesn't run
+// on the control plane nodes, it can't reach the socket of the signer itself.
+// Keys excluded from OIDC discovery aren't part of the key set.
+type jwksKeyFetcher struct {
+	client rest.Interface
+}
+
+// NewJWKSKeyFetcher returns a KeyFetcher that reads the keys of the external
+// signer from the OIDC discovery key set of the kube-apiserver.
+func NewJWKSKeyFetcher(client rest.Interface) KeyFetcher {
+	return &jwksKeyFetcher{client: client}
+}
+
+func (f *jwksKeyFetcher) FetchKeys(ctx context.Context) ([]PublicKey, error) {
+	raw, err := f.client.Get().AbsPath(jwksPath).DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", jwksPath, err)
+	}
+	keys, err := parseJWKS(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", jwksPath, err)
+	}
+	return keys, nil
+}
+
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Curve string `json:"crv"`
+	X     string `json:"x"`
+	Y     string `json:"y"`
+}
+
+// parseJWKS returns the RSA and P-256 keys of the given JSON web key set,
+// ordered by key ID.
+func parseJWKS(raw []byte) ([]PublicKey, error) {
+	keySet := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+	if err := json.Unmarshal(raw, &keySet); err != nil {
+		return nil, err
+	}
+
+	var keys []PublicKey
+	for _, jwk := range keySet.Keys {
+		var key PublicKey
+		var err error
+		switch jwk.KeyType {
+		case "RSA":
+			key, err = parseRSAKey(jwk)
+		case "EC":
+			key, err = parseECKey(jwk)
+		default:
+			err = fmt.Errorf("unsupported key type %q", jwk.KeyType)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", jwk.KeyID, err)
+		}
+		keys = append(keys, key)
+	}
+	sortKeys(keys)
+	return keys, nil
+}
+
+func parseRSAKey(jwk jsonWebKey) (PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return PublicKey{}, fmt.Errorf("invalid modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return PublicKey{}, fmt.Errorf("invalid exponent: %w", err)
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if len(n) == 0 || !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return PublicKey{}, fmt.Errorf("invalid RSA key")
+	}
+	return PublicKey{
+		KeyID: jwk.KeyID,
+		Key:   &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())},
+	}, nil
+}
+
+func parseECKey(jwk jsonWebKey) (PublicKey, error) {
+	if jwk.Curve != "P-256" {
+		return PublicKey{}, fmt.Errorf("unsupported curve %q", jwk.Curve)
+	}
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	if err != nil {
+		return PublicKey{}, fmt.Errorf("invalid x coordinate: %w", err)
+	}
+	y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+	if err != nil {
+		return PublicKey{}, fmt.Errorf("invalid y coordinate: %w", err)
+	}
+	if len(x) != 32 || len(y) != 32 {
+		return PublicKey{}, fmt.Errorf("invalid P-256 coordinates")
+	}
+	key, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), slices.Concat([]byte{4}, x, y))
+	if err != nil {
+		return PublicKey{}, err
+	}
+	return PublicKey{KeyID: jwk.KeyID, Key: key}, nil
+}
+
+func sortKeys(keys []PublicKey) {
+	slices.SortStableFunc(keys, func(a, b PublicKey) int {
+		return strings.Compare(a.KeyID, b.KeyID)
+	})
+}
@@ -0,0 +1,48 @@
+package externaljwtsigner
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecPoint, err := ecKey.PublicKey.Bytes()
+	require.NoError(t, err)
+
+	encode := base64.RawURLEncoding.EncodeToString
+	keySet := fmt.Sprintf(`{"keys": [
+		{"use": "sig", "kty": "RSA", "kid": "rsa", "alg": "RS256", "n": %q, "e": %q},
+		{"use": "sig", "kty": "EC", "kid": "ec", "alg": "ES256", "crv": "P-256", "x": %q, "y": %q}
+	]}`, encode(rsaKey.N.Bytes()), encode(big.NewInt(int64(rsaKey.E)).Bytes()), encode(ecPoint[1:33]), encode(ecPoint[33:]))
+
+	keys, err := parseJWKS([]byte(keySet))
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.Equal(t, "ec", keys[0].KeyID)
+	require.True(t, ecKey.PublicKey.Equal(keys[0].Key))
+	require.Equal(t, "rsa", keys[1].KeyID)
+	require.True(t, rsaKey.PublicKey.Equal(keys[1].Key))
+
+	for _, invalid := range []string{
+		`{"keys": [{"kty": "oct", "kid": "symmetric"}]}`,
+		`{"keys": [{"kty": "EC", "kid": "p384", "crv": "P-384"}]}`,
+		`{"keys": [{"kty": "EC", "kid": "short", "crv": "P-256", "x": "AA", "y": "AA"}]}`,
+		`{"keys": [{"kty": "RSA", "kid": "no-exponent", "n": "AQAB"}]}`,
+		`not json`,
+	} {
+		_, err := parseJWKS([]byte(invalid))
+		require.Error(t, err, invalid)
+	}
+}
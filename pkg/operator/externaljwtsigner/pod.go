@@ -0,0 +1,68 @@
+package externaljwtsigner
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	socketDirVolumeName = "external-jwt-signer-dir"
+
+	// kubeAPIServerCommand starts the kube-apiserver in the script of its
+	// container, the flag is added right after it.
+	kubeAPIServerCommand = "hyperkube kube-apiserver "
+)
+
+// AddToKubeAPIServerPod makes the kube-apiserver container of the given pod
+// sign service account tokens with the external signer: the flag is added to
+// the command line and the directory of the socket is mounted from the host.
+func (c *Config) AddToKubeAPIServerPod(pod *corev1.PodSpec, containerName string) error {
+	container, err := c.mountSocketDir(pod, containerName)
+	if err != nil {
+		return err
+	}
+	for i, arg := range container.Args {
+		if strings.Contains(arg, kubeAPIServerCommand) {
+			container.Args[i] = strings.Replace(arg, kubeAPIServerCommand, kubeAPIServerCommand+c.Flag()+" ", 1)
+			return nil
+		}
+	}
+	return fmt.Errorf("container %q doesn't start the kube-apiserver", containerName)
+}
+
+// AddToStartupMonitorPod mounts the directory of the socket into the given
+// container, so that the startup monitor can check the signer.
+func (c *Config) AddToStartupMonitorPod(pod *corev1.PodSpec, containerName string) error {
+	_, err := c.mountSocketDir(pod, containerName)
+	return err
+}
+
+func (c *Config) mountSocketDir(pod *corev1.PodSpec, containerName string) (*corev1.Container, error) {
+	var container *corev1.Container
+	for i := range pod.Containers {
+		if pod.Containers[i].Name == containerName {
+			container = &pod.Containers[i]
+			break
+		}
+	}
+	if container == nil {
+		return nil, fmt.Errorf("container %q not found", containerName)
+	}
+
+	// the signer creates the socket when it starts, the directory may not
+	// exist before
+	hostPathType := corev1.HostPathDirectoryOrCreate
+	pod.Volumes = append(pod.Volumes, corev1.Volume{
+		Name: socketDirVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			HostPath: &corev1.HostPathVolumeSource{Path: c.SocketDir(), Type: &hostPathType},
+		},
+	})
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      socketDirVolumeName,
+		MountPath: c.SocketDir(),
+	})
+	return container, nil
+}
@@ -0,0 +1,164 @@
+package externaljwtsigner
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The messages of the ExternalJWTSigner service that the operator calls, see
+// k8s.io/externaljwt/apis/v1. They are encoded by hand so that the operator
+// doesn't depend on the generated code for the few fields it needs, fields it
+// doesn't know are skipped.
+const (
+	serviceName = "v1.ExternalJWTSigner"
+
+	fetchKeysMethod = "/" + serviceName + "/FetchKeys"
+)
+
+// message is a protobuf message of the ExternalJWTSigner service.
+type message interface {
+	marshal() []byte
+	unmarshal(b []byte) error
+}
+
+type fetchKeysRequest struct{}
+
+func (*fetchKeysRequest) marshal() []byte { return nil }
+
+func (*fetchKeysRequest) unmarshal(b []byte) error { return forEachField(b, skipField) }
+
+type fetchKeysResponse struct {
+	keys               []*key
+	refreshHintSeconds int64
+}
+
+func (m *fetchKeysResponse) marshal() []byte {
+	var b []byte
+	for _, k := range m.keys {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, k.marshal())
+	}
+	if m.refreshHintSeconds != 0 {
+		b = protowire.AppendTag(b, 3, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(m.refreshHintSeconds))
+	}
+	return b
+}
+
+func (m *fetchKeysResponse) unmarshal(b []byte) error {
+	var keyErr error
+	err := forEachField(b, func(num protowire.Number, typ protowire.Type, b []byte) int {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n > 0 {
+				k := &key{}
+				if err := k.unmarshal(v); err != nil && keyErr == nil {
+					keyErr = fmt.Errorf("key %d: %w", len(m.keys), err)
+				}
+				m.keys = append(m.keys, k)
+			}
+			return n
+		case num == 3 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			m.refreshHintSeconds = int64(v)
+			return n
+		}
+		return skipField(num, typ, b)
+	})
+	if err != nil {
+		return err
+	}
+	return keyErr
+}
+
+type key struct {
+	keyID                    string
+	key                      []byte
+	excludeFromOIDCDiscovery bool
+}
+
+func (m *key) marshal() []byte {
+	var b []byte
+	if len(m.keyID) > 0 {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, m.keyID)
+	}
+	if len(m.key) > 0 {
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendBytes(b, m.key)
+	}
+	if m.excludeFromOIDCDiscovery {
+		b = protowire.AppendTag(b, 3, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(true))
+	}
+	return b
+}
+
+func (m *key) unmarshal(b []byte) error {
+	return forEachField(b, func(num protowire.Number, typ protowire.Type, b []byte) int {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeString(b)
+			m.keyID = v
+			return n
+		case num == 2 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			m.key = append([]byte(nil), v...)
+			return n
+		case num == 3 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			m.excludeFromOIDCDiscovery = protowire.DecodeBool(v)
+			return n
+		}
+		return skipField(num, typ, b)
+	})
+}
+
+// forEachField calls field for every field of the encoded message b, with the
+// encoded value of the field. field returns the length of the value or a
+// negative protowire error code.
+func forEachField(b []byte, field func(num protowire.Number, typ protowire.Type, b []byte) int) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		n = field(num, typ, b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+func skipField(num protowire.Number, typ protowire.Type, b []byte) int {
+	return protowire.ConsumeFieldValue(num, typ, b)
+}
+
+// codec encodes the messages of the ExternalJWTSigner service for gRPC.
+type codec struct{}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(message)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message type %T", v)
+	}
+	return m.marshal(), nil
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	m, ok := v.(message)
+	if !ok {
+		return fmt.Errorf("unexpected message type %T", v)
+	}
+	return m.unmarshal(data)
+}
+
+// Name is the content subtype of the messages, they are plain protobuf.
+func (codec) Name() string {
+	return "proto"
+}
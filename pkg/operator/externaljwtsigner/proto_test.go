@@ -0,0 +1,19 @@
+package externaljwtsigner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnknownFieldsAreSkipped(t *testing.T) {
+	encoded := (&fetchKeysResponse{keys: []*key{{keyID: "a", key: []byte{1, 2}}}}).marshal()
+	// data_timestamp, a google.protobuf.Timestamp the operator doesn't read
+	encoded = append(encoded, 0x12, 0x02, 0x08, 0x01)
+
+	response := &fetchKeysResponse{}
+	require.NoError(t, response.unmarshal(encoded))
+	require.Equal(t, []*key{{keyID: "a", key: []byte{1, 2}}}, response.keys)
+
+	require.Error(t, response.unmarshal([]byte{0x0a, 0x05}))
+}
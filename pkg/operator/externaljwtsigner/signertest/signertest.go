@@ -0,0 +1,126 @@
+// Package signertest provides a stand-in external JWT signer for tests.
+package signertest
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/externaljwtsigner"
+)
+
+// StandInSigner serves the keys of an external signer on a unix socket, so
+// that the operator and the startup monitor can be tested without a real one.
+// It doesn't sign tokens.
+type StandInSigner struct {
+	server *grpc.Server
+
+	lock sync.Mutex
+	keys []externaljwtsigner.PublicKey
+}
+
+// NewStandInSigner starts serving the given keys on the unix socket until
+// Stop is called.
+func NewStandInSigner(socketPath string, keys ...externaljwtsigner.PublicKey) (*StandInSigner, error) {
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+	s := &StandInSigner{
+		server: grpc.NewServer(grpc.ForceServerCodec(codec{})),
+		keys:   keys,
+	}
+	s.server.RegisterService(&serviceDesc, s)
+	go func() {
+		_ = s.server.Serve(listener)
+	}()
+	return s, nil
+}
+
+// SetKeys replaces the keys the signer serves.
+func (s *StandInSigner) SetKeys(keys ...externaljwtsigner.PublicKey) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.keys = keys
+}
+
+// Stop stops serving and removes the socket.
+func (s *StandInSigner) Stop() {
+	s.server.Stop()
+}
+
+// fetchKeys encodes a FetchKeysResponse of k8s.io/externaljwt/apis/v1.
+func (s *StandInSigner) fetchKeys() (*encoded, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var response encoded
+	for _, k := range s.keys {
+		der, err := x509.MarshalPKIXPublicKey(k.Key)
+		if err != nil {
+			return nil, err
+		}
+		var key []byte
+		key = protowire.AppendTag(key, 1, protowire.BytesType)
+		key = protowire.AppendString(key, k.KeyID)
+		key = protowire.AppendTag(key, 2, protowire.BytesType)
+		key = protowire.AppendBytes(key, der)
+		if k.ExcludeFromOIDCDiscovery {
+			key = protowire.AppendTag(key, 3, protowire.VarintType)
+			key = protowire.AppendVarint(key, protowire.EncodeBool(true))
+		}
+		response = protowire.AppendTag(response, 1, protowire.BytesType)
+		response = protowire.AppendBytes(response, key)
+	}
+	// refresh_hint_seconds
+	response = protowire.AppendTag(response, 3, protowire.VarintType)
+	response = protowire.AppendVarint(response, 60)
+	return &response, nil
+}
+
+// encoded is a protobuf message in its wire format. The stand-in doesn't read
+// the requests, they have no fields it needs.
+type encoded []byte
+
+type codec struct{}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(*encoded)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message type %T", v)
+	}
+	return *m, nil
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	m, ok := v.(*encoded)
+	if !ok {
+		return fmt.Errorf("unexpected message type %T", v)
+	}
+	*m = append((*m)[:0], data...)
+	return nil
+}
+
+func (codec) Name() string {
+	return "proto"
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: "v1.ExternalJWTSigner",
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "FetchKeys",
+			Handler: func(srv any, _ context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+				if err := dec(&encoded{}); err != nil {
+					return nil, err
+				}
+				return srv.(*StandInSigner).fetchKeys()
+			},
+		},
+	},
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/blang/semver/v4"
 	operatorv1 "github.com/openshift/api/operator/v1"
+	configv1informers "github.com/openshift/client-go/config/informers/externalversions/config/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/management"
@@ -27,6 +29,7 @@ const (
 	KubeletMinorVersionSyncedReason                 = "KubeletMinorVersionsSynced"
 	KubeletMinorVersionSupportedNextUpgradeReason   = "KubeletMinorVersionSupportedNextUpgrade"
 	KubeletMinorVersionUnsupportedNextUpgradeReason = "KubeletMinorVersionUnsupportedNextUpgrade"
+	KubeletMinorVersionUnsupportedUpgradePathReason = "KubeletMinorVersionUnsupportedUpgradePath"
 	KubeletMinorVersionUnsupportedReason            = "KubeletMinorVersionUnsupported"
 	KubeletMinorVersionAheadReason                  = "KubeletMinorVersionAhead"
 )
@@ -55,6 +58,10 @@ const (
 //
 // Note: 4.23 is the last 4.x release and is designated EUS, but
 // its supported skew is still -1 (one release from 4.22).
+//
+// When ClusterVersion requests an update more than one minor ahead, the
+// skew is evaluated against every minor on the way there, as kubelets in
+// paused pools stay behind while the control plane moves on.
 type KubeletVersionSkewController interface {
 	factory.Controller
 }
@@ -64,6 +71,7 @@ func NewKubeletVersionSkewController(
 	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
 	nodeLister corev1listers.NodeLister,
 	nodeInformer cache.SharedIndexInformer,
+	clusterVersionInformer configv1informers.ClusterVersionInformer,
 	recorder events.Recorder,
 ) *kubeletVersionSkewController {
 	openShiftVersion := semver.MustParse(status.VersionForOperatorFromEnv())
@@ -71,13 +79,15 @@ func NewKubeletVersionSkewController(
 	c := &kubeletVersionSkewController{
 		operatorClient:              operatorClient,
 		nodeLister:                  nodeLister,
+		clusterVersionLister:        clusterVersionInformer.Lister(),
 		apiServerVersion:            semver.MustParse(status.VersionForOperandFromEnv()),
+		openShiftVersion:            openShiftVersion,
 		minSupportedSkew:            minSupportedKubeletSkewForOpenShiftVersion(openShiftVersion),
 		minSupportedSkewNextVersion: minSupportedKubeletSkewForOpenShiftVersion(nextOpenShiftVersion),
 	}
 	c.Controller = factory.New().
 		WithSync(c.sync).
-		WithInformers(nodeInformer).
+		WithInformers(nodeInformer, clusterVersionInformer.Informer()).
 		ToController("KubeletVersionSkewController", recorder.WithComponentSuffix("kubelet-version-skew-controller"))
 	return c
 }
@@ -112,7 +122,9 @@ type kubeletVersionSkewController struct {
 	factory.Controller
 	operatorClient              v1helpers.OperatorClient
 	nodeLister                  corev1listers.NodeLister
+	clusterVersionLister        configv1listers.ClusterVersionLister
 	apiServerVersion            semver.Version
+	openShiftVersion            semver.Version
 	minSupportedSkew            int
 	minSupportedSkewNextVersion int
 }
@@ -132,6 +144,12 @@ func (c *kubeletVersionSkewController) sync(ctx context.Context, _ factory.SyncC
 	}
 	sort.Sort(byName(nodes))
 
+	path := c.upgradePath()
+	nextUpgrade := "in the next OpenShift version upgrade"
+	if len(path) > 1 {
+		nextUpgrade = fmt.Sprintf("on every hop of the upgrade path %s", path)
+	}
+
 	var errors nodeKubeletInfos
 	var skewedUnsupported nodeKubeletInfos
 	var skewedLimit nodeKubeletInfos
@@ -139,36 +157,41 @@ func (c *kubeletVersionSkewController) sync(ctx context.Context, _ factory.SyncC
 	var synced nodeKubeletInfos
 	var unsupported nodeKubeletInfos
 
+	var all nodeKubeletInfos
+
 	// for each node, check kubelet version
 	for _, node := range nodes {
 		kubeletVersion, err := nodeKubeletVersion(node)
 		if err != nil {
 			runtime.HandleError(fmt.Errorf("unable to determine kubelet version on node %s: %w", node.Name, err))
-			errors = append(errors, nodeKubeletInfo{node: node.Name, err: err})
+			errors = append(errors, nodeKubeletInfo{node: node.Name, pool: machineConfigPool(node), err: err})
 			continue
 		}
 		skew := int(kubeletVersion.Minor - c.apiServerVersion.Minor)
 		// Assume that an OpenShift version upgrade also bumps to the next kube version. Revisit
 		// this in the future if an OpenShift version upgrade ever skips or repeats a kube version.
-		skewNextVersion := skew - 1
+		unsupportedHop := firstUnsupportedHop(skew, path)
+		info := nodeKubeletInfo{node: node.Name, pool: machineConfigPool(node), version: &kubeletVersion, skew: skew, unsupportedHop: unsupportedHop}
+		all = append(all, info)
 		switch {
 		case skew == 0:
 			// synced
-			synced = append(synced, nodeKubeletInfo{node: node.Name, version: &kubeletVersion})
+			synced = append(synced, info)
 		case skew < c.minSupportedSkew:
 			// already in an unsupported state
-			skewedUnsupported = append(skewedUnsupported, nodeKubeletInfo{node: node.Name, version: &kubeletVersion})
-		case skewNextVersion < c.minSupportedSkewNextVersion:
-			// upgrading to next minor version of API server would result in an unsupported config
-			skewedLimit = append(skewedLimit, nodeKubeletInfo{node: node.Name, version: &kubeletVersion})
+			skewedUnsupported = append(skewedUnsupported, info)
+		case unsupportedHop >= 0:
+			// upgrading along the path of the API server would result in an unsupported config
+			skewedLimit = append(skewedLimit, info)
 		case skew < 0:
 			// behind, but upgrading to next minor version of API server is supported
-			skewedButOK = append(skewedButOK, nodeKubeletInfo{node: node.Name, version: &kubeletVersion})
+			skewedButOK = append(skewedButOK, info)
 		default:
 			// kubelet version newer than api server version. possibly in the middle of a rollback.
-			unsupported = append(unsupported, nodeKubeletInfo{node: node.Name, version: &kubeletVersion})
+			unsupported = append(unsupported, info)
 		}
 	}
+	recordNodeMetrics(all)
 
 	condition := operatorv1.OperatorCondition{Type: KubeletMinorVersionUpgradeableConditionType}
 	// use the most "severe" reason to set the condition status
@@ -182,6 +205,10 @@ func (c *kubeletVersionSkewController) sync(ctx context.Context, _ factory.SyncC
 		case 2, 3:
 			condition.Message = fmt.Sprintf("Unsupported Kubelet versions on nodes %s are too far behind the target API server version (%v).", skewedUnsupported.nodes(), c.apiServerVersion)
 		default:
+			if skewedUnsupported.hasPools() {
+				condition.Message = fmt.Sprintf("Unsupported Kubelet versions are too far behind the target API server version (%v): %s.", c.apiServerVersion, skewedUnsupported.pools())
+				break
+			}
 			condition.Message = fmt.Sprintf("Unsupported Kubelet versions on %d nodes are too far behind the target API server version (%v).", len(skewedUnsupported), c.apiServerVersion)
 		}
 	case len(unsupported) > 0:
@@ -193,6 +220,10 @@ func (c *kubeletVersionSkewController) sync(ctx context.Context, _ factory.SyncC
 		case 2, 3:
 			condition.Message = fmt.Sprintf("Unsupported Kubelet versions on nodes %s are ahead of the target API server version (%v).", unsupported.nodes(), c.apiServerVersion)
 		default:
+			if unsupported.hasPools() {
+				condition.Message = fmt.Sprintf("Unsupported Kubelet versions are ahead of the target API server version (%v): %s.", c.apiServerVersion, unsupported.pools())
+				break
+			}
 			condition.Message = fmt.Sprintf("Unsupported Kubelet versions on %d nodes are ahead of the target API server version (%v).", len(unsupported), c.apiServerVersion)
 		}
 	case len(errors) > 0:
@@ -204,8 +235,19 @@ func (c *kubeletVersionSkewController) sync(ctx context.Context, _ factory.SyncC
 		case 2, 3:
 			condition.Message = fmt.Sprintf("Unable to determine the kubelet version on nodes %s.", errors.nodes())
 		default:
+			if errors.hasPools() {
+				condition.Message = fmt.Sprintf("Unable to determine the kubelet version on %s.", errors.pools())
+				break
+			}
 			condition.Message = fmt.Sprintf("Unable to determine the kubelet version on %d nodes.", len(errors))
 		}
+	case len(skewedLimit) > 0 && len(path) > 1:
+		condition.Reason = KubeletMinorVersionUnsupportedUpgradePathReason
+		if skewedLimit.unsupportedAtHop(0) {
+			condition.Reason = KubeletMinorVersionUnsupportedNextUpgradeReason
+		}
+		condition.Status = operatorv1.ConditionFalse
+		condition.Message = fmt.Sprintf("Kubelet versions will not be supported on the upgrade path %s: %s.", path, skewedLimit.describeHops(path))
 	case len(skewedLimit) > 0:
 		condition.Reason = KubeletMinorVersionUnsupportedNextUpgradeReason
 		condition.Status = operatorv1.ConditionFalse
@@ -215,6 +257,10 @@ func (c *kubeletVersionSkewController) sync(ctx context.Context, _ factory.SyncC
 		case 2, 3:
 			condition.Message = fmt.Sprintf("Kubelet versions on nodes %s will not be supported in the next OpenShift version upgrade.", skewedLimit.nodes())
 		default:
+			if skewedLimit.hasPools() {
+				condition.Message = fmt.Sprintf("Kubelet versions will not be supported in the next OpenShift version upgrade: %s.", skewedLimit.pools())
+				break
+			}
 			condition.Message = fmt.Sprintf("Kubelet versions on %d nodes will not be supported in the next OpenShift version upgrade.", len(skewedLimit))
 		}
 	case len(skewedButOK) > 0:
@@ -222,11 +268,15 @@ func (c *kubeletVersionSkewController) sync(ctx context.Context, _ factory.SyncC
 		condition.Status = operatorv1.ConditionTrue
 		switch len(skewedButOK) {
 		case 1:
-			condition.Message = fmt.Sprintf("Kubelet version (%v) on node %s is behind the expected API server version; nevertheless, it will continue to be supported in the next OpenShift version upgrade.", skewedButOK.version(), skewedButOK.nodes())
+			condition.Message = fmt.Sprintf("Kubelet version (%v) on node %s is behind the expected API server version; nevertheless, it will continue to be supported %s.", skewedButOK.version(), skewedButOK.nodes(), nextUpgrade)
 		case 2, 3:
-			condition.Message = fmt.Sprintf("Kubelet versions on nodes %s are behind the expected API server version; nevertheless, they will continue to be supported in the next OpenShift version upgrade.", skewedButOK.nodes())
+			condition.Message = fmt.Sprintf("Kubelet versions on nodes %s are behind the expected API server version; nevertheless, they will continue to be supported %s.", skewedButOK.nodes(), nextUpgrade)
 		default:
-			condition.Message = fmt.Sprintf("Kubelet versions on %d nodes are behind the expected API server version; nevertheless, they will continue to be supported in the next OpenShift version upgrade.", len(skewedButOK))
+			if skewedButOK.hasPools() {
+				condition.Message = fmt.Sprintf("Kubelet versions are behind the expected API server version; nevertheless, they will continue to be supported %s: %s.", nextUpgrade, skewedButOK.pools())
+				break
+			}
+			condition.Message = fmt.Sprintf("Kubelet versions on %d nodes are behind the expected API server version; nevertheless, they will continue to be supported %s.", len(skewedButOK), nextUpgrade)
 		}
 	default:
 		condition.Reason = KubeletMinorVersionSyncedReason
@@ -240,8 +290,14 @@ func (c *kubeletVersionSkewController) sync(ctx context.Context, _ factory.SyncC
 
 type nodeKubeletInfo struct {
 	node    string
+	pool    string
 	version *semver.Version
 	err     error
+
+	// skew to the current API server and the first hop on the upgrade path
+	// at which it becomes unsupported.
+	skew           int
+	unsupportedHop int
 }
 
 type nodeKubeletInfos []nodeKubeletInfo
@@ -275,6 +331,45 @@ func (n nodeKubeletInfos) version() *semver.Version {
 	return nil
 }
 
+func (n nodeKubeletInfos) unsupportedAtHop(hop int) bool {
+	for _, i := range n {
+		if i.unsupportedHop == hop {
+			return true
+		}
+	}
+	return false
+}
+
+// describeHops explains for every hop on the path which kubelets would be
+// unsupported once the control plane reaches it.
+func (n nodeKubeletInfos) describeHops(path upgradeHops) string {
+	var hops []string
+	for hopIndex, hop := range path {
+		var unsupported nodeKubeletInfos
+		for _, i := range n {
+			if i.skew-(hopIndex+1) < hop.minSupportedSkew {
+				unsupported = append(unsupported, i)
+			}
+		}
+
+		switch len(unsupported) {
+		case 0:
+			hops = append(hops, fmt.Sprintf("%s supported", hop))
+		case 1:
+			hops = append(hops, fmt.Sprintf("%s unsupported for Kubelet version (%v) on node %s", hop, unsupported.version(), unsupported.nodes()))
+		case 2, 3:
+			hops = append(hops, fmt.Sprintf("%s unsupported for Kubelet versions on nodes %s", hop, unsupported.nodes()))
+		default:
+			if unsupported.hasPools() {
+				hops = append(hops, fmt.Sprintf("%s unsupported for %s", hop, unsupported.pools()))
+				continue
+			}
+			hops = append(hops, fmt.Sprintf("%s unsupported for Kubelet versions on %d nodes", hop, len(unsupported)))
+		}
+	}
+	return strings.Join(hops, "; ")
+}
+
 func nodeKubeletVersion(node *corev1.Node) (semver.Version, error) {
 	return semver.Parse(strings.TrimPrefix(node.Status.NodeInfo.KubeletVersion, "v"))
 }
@@ -5,7 +5,9 @@ import (
 	"testing"
 
 	"github.com/blang/semver/v4"
+	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -338,3 +340,140 @@ func TestMinSupportedKubeletSkewForOpenShiftVersion(t *testing.T) {
 		t.Errorf("next after 5.0: got %d, want %d", got, want)
 	}
 }
+
+func Test_kubeletVersionSkewController_UpgradePath(t *testing.T) {
+	testCases := []struct {
+		name             string
+		ocpVersion       string
+		apiServerVersion string
+		desiredUpdate    *configv1.Update
+		kubeletVersions  []string
+		expectedStatus   operatorv1.ConditionStatus
+		expectedReason   string
+		expectedMsgLines string
+	}{
+		{
+			name:             "NoDesiredUpdate",
+			ocpVersion:       "5.1.0",
+			apiServerVersion: "1.37.0",
+			kubeletVersions:  []string{"1.37.0", "1.35.0"},
+			expectedStatus:   operatorv1.ConditionTrue,
+			expectedReason:   KubeletMinorVersionSupportedNextUpgradeReason,
+			expectedMsgLines: "Kubelet version (1.35.0) on node test001 is behind the expected API server version; nevertheless, it will continue to be supported in the next OpenShift version upgrade.",
+		},
+		{
+			name:             "DesiredUpdateNextMinor",
+			ocpVersion:       "5.1.0",
+			apiServerVersion: "1.37.0",
+			desiredUpdate:    &configv1.Update{Version: "5.2.3"},
+			kubeletVersions:  []string{"1.37.0", "1.35.0"},
+			expectedStatus:   operatorv1.ConditionTrue,
+			expectedReason:   KubeletMinorVersionSupportedNextUpgradeReason,
+			expectedMsgLines: "Kubelet version (1.35.0) on node test001 is behind the expected API server version; nevertheless, it will continue to be supported in the next OpenShift version upgrade.",
+		},
+		{
+			name:             "SupportedOnEveryHop",
+			ocpVersion:       "5.0.0",
+			apiServerVersion: "1.36.0",
+			desiredUpdate:    &configv1.Update{Version: "5.2.0"},
+			kubeletVersions:  []string{"1.36.0", "1.35.0"},
+			expectedStatus:   operatorv1.ConditionTrue,
+			expectedReason:   KubeletMinorVersionSupportedNextUpgradeReason,
+			expectedMsgLines: "Kubelet version (1.35.0) on node test001 is behind the expected API server version; nevertheless, it will continue to be supported on every hop of the upgrade path 5.1 -> 5.2.",
+		},
+		{
+			name:             "UnsupportedAtSecondHop",
+			ocpVersion:       "5.1.0",
+			apiServerVersion: "1.37.0",
+			desiredUpdate:    &configv1.Update{Version: "5.3.0"},
+			kubeletVersions:  []string{"1.37.0", "1.35.0", "1.35.0"},
+			expectedStatus:   operatorv1.ConditionFalse,
+			expectedReason:   KubeletMinorVersionUnsupportedUpgradePathReason,
+			expectedMsgLines: "Kubelet versions will not be supported on the upgrade path 5.2 -> 5.3: 5.2 supported; 5.3 unsupported for Kubelet versions on nodes test001 and test002.",
+		},
+		{
+			name:             "UnsupportedAtFirstHop",
+			ocpVersion:       "4.22.0",
+			apiServerVersion: "1.35.0",
+			desiredUpdate:    &configv1.Update{Version: "4.24.0"},
+			kubeletVersions:  []string{"1.35.0", "1.34.0"},
+			expectedStatus:   operatorv1.ConditionFalse,
+			expectedReason:   KubeletMinorVersionUnsupportedNextUpgradeReason,
+			expectedMsgLines: "Kubelet versions will not be supported on the upgrade path 4.23 -> 4.24: 4.23 unsupported for Kubelet version (1.34.0) on node test001; 4.24 unsupported for Kubelet version (1.34.0) on node test001.",
+		},
+		{
+			name:             "DesiredUpdateByImage",
+			ocpVersion:       "5.1.0",
+			apiServerVersion: "1.37.0",
+			desiredUpdate:    &configv1.Update{Image: "quay.io/openshift-release-dev/ocp-release@sha256:5.3"},
+			kubeletVersions:  []string{"1.37.0", "1.35.0"},
+			expectedStatus:   operatorv1.ConditionFalse,
+			expectedReason:   KubeletMinorVersionUnsupportedUpgradePathReason,
+			expectedMsgLines: "Kubelet versions will not be supported on the upgrade path 5.2 -> 5.3: 5.2 supported; 5.3 unsupported for Kubelet version (1.35.0) on node test001.",
+		},
+		{
+			name:             "DesiredUpdateNextMajor",
+			ocpVersion:       "4.23.0",
+			apiServerVersion: "1.36.0",
+			desiredUpdate:    &configv1.Update{Version: "5.2.0"},
+			kubeletVersions:  []string{"1.36.0", "1.36.0"},
+			expectedStatus:   operatorv1.ConditionTrue,
+			expectedReason:   KubeletMinorVersionSyncedReason,
+			expectedMsgLines: "Kubelet and API server versions are synced.",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			for i, kv := range tc.kubeletVersions {
+				indexer.Add(&corev1.Node{
+					ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("test%03d", i)},
+					Status:     corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{KubeletVersion: kv}},
+				})
+			}
+			clusterVersionIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			clusterVersionIndexer.Add(&configv1.ClusterVersion{
+				ObjectMeta: metav1.ObjectMeta{Name: "version"},
+				Spec:       configv1.ClusterVersionSpec{DesiredUpdate: tc.desiredUpdate},
+				Status: configv1.ClusterVersionStatus{
+					AvailableUpdates: []configv1.Release{
+						{Version: "5.2.0", Image: "quay.io/openshift-release-dev/ocp-release@sha256:5.2"},
+						{Version: "5.3.0", Image: "quay.io/openshift-release-dev/ocp-release@sha256:5.3"},
+					},
+				},
+			})
+			status := &operatorv1.StaticPodOperatorStatus{}
+			ocpVersion := semver.MustParse(tc.ocpVersion)
+			nextOpenShiftVersion := semver.Version{Major: ocpVersion.Major, Minor: ocpVersion.Minor + 1}
+			c := &kubeletVersionSkewController{
+				operatorClient: v1helpers.NewFakeStaticPodOperatorClient(
+					&operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{ManagementState: operatorv1.Managed}},
+					status, nil, nil,
+				),
+				nodeLister:                  corev1listers.NewNodeLister(indexer),
+				clusterVersionLister:        configv1listers.NewClusterVersionLister(clusterVersionIndexer),
+				apiServerVersion:            semver.MustParse(tc.apiServerVersion),
+				openShiftVersion:            ocpVersion,
+				minSupportedSkew:            minSupportedKubeletSkewForOpenShiftVersion(ocpVersion),
+				minSupportedSkewNextVersion: minSupportedKubeletSkewForOpenShiftVersion(nextOpenShiftVersion),
+			}
+			if err := c.sync(nil, nil); err != nil {
+				t.Fatalf("sync() unexpected err: %v", err)
+			}
+			if len(status.Conditions) != 1 || status.Conditions[0].Type != KubeletMinorVersionUpgradeableConditionType {
+				t.Fatalf("Expected %s condition type.", KubeletMinorVersionUpgradeableConditionType)
+			}
+			condition := status.Conditions[0]
+			if tc.expectedStatus != condition.Status {
+				t.Errorf("Condition status: expected %s, actual %s", tc.expectedStatus, condition.Status)
+			}
+			if tc.expectedReason != condition.Reason {
+				t.Errorf("Condition reason: expected %s, actual %s", tc.expectedReason, condition.Reason)
+			}
+			if tc.expectedMsgLines != condition.Message {
+				t.Errorf("Expected condition message to match %q.", tc.expectedMsgLines)
+				t.Log(diff.Diff(tc.expectedMsgLines, condition.Message))
+			}
+		})
+	}
+}
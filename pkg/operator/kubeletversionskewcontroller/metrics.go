@@ -0,0 +1,58 @@
+package kubeletversionskewcontroller
+
+import (
+	"sync"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+var (
+	registerMetrics sync.Once
+
+	// recordedNodeLabelsLock guards recordedNodeLabels, the node and pool
+	// label values of the series currently set.
+	recordedNodeLabelsLock sync.Mutex
+	recordedNodeLabels     = map[[2]string]bool{}
+
+	kubeletMinorVersionGauge = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Name: "openshift_kube_apiserver_kubelet_minor_version",
+		Help: "Reports the minor Kubernetes version of the kubelet on each node",
+	}, []string{"node", "pool"})
+
+	kubeletVersionSkewGauge = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Name: "openshift_kube_apiserver_kubelet_version_skew",
+		Help: "Reports the minor version skew of the kubelet on each node relative to the API server, negative when the kubelet is behind",
+	}, []string{"node", "pool"})
+)
+
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(kubeletMinorVersionGauge)
+		legacyregistry.MustRegister(kubeletVersionSkewGauge)
+	})
+}
+
+// recordNodeMetrics sets the per-node gauges and deletes the series of the
+// removed nodes, without a window in which a scrape sees no series at all.
+func recordNodeMetrics(infos nodeKubeletInfos) {
+	recordedNodeLabelsLock.Lock()
+	defer recordedNodeLabelsLock.Unlock()
+
+	current := map[[2]string]bool{}
+	for _, i := range infos {
+		if i.version == nil {
+			continue
+		}
+		kubeletMinorVersionGauge.WithLabelValues(i.node, i.pool).Set(float64(i.version.Minor))
+		kubeletVersionSkewGauge.WithLabelValues(i.node, i.pool).Set(float64(i.skew))
+		current[[2]string{i.node, i.pool}] = true
+	}
+	for labels := range recordedNodeLabels {
+		if !current[labels] {
+			kubeletMinorVersionGauge.DeleteLabelValues(labels[0], labels[1])
+			kubeletVersionSkewGauge.DeleteLabelValues(labels[0], labels[1])
+		}
+	}
+	recordedNodeLabels = current
+}
@@ -0,0 +1,45 @@
+package kubeletversionskewcontroller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/component-base/metrics/testutil"
+)
+
+func TestRecordNodeMetrics(t *testing.T) {
+	RegisterMetrics()
+
+	v := func(version string) *semver.Version {
+		parsed := semver.MustParse(version)
+		return &parsed
+	}
+	recordNodeMetrics(nodeKubeletInfos{
+		{node: "master-0", pool: "master", version: v("1.36.0")},
+		{node: "worker-0", pool: "worker", version: v("1.34.0"), skew: -2},
+		{node: "worker-1", pool: "worker", version: v("1.35.0"), skew: -1},
+	})
+	// worker-0 is removed and worker-1 is not reporting a version anymore
+	recordNodeMetrics(nodeKubeletInfos{
+		{node: "master-0", pool: "master", version: v("1.36.0")},
+		{node: "worker-1", pool: "worker"},
+		{node: "worker-2", pool: "worker", version: v("1.35.0"), skew: -1},
+	})
+
+	expected := `
+# HELP openshift_kube_apiserver_kubelet_minor_version [ALPHA] Reports the minor Kubernetes version of the kubelet on each node
+# TYPE openshift_kube_apiserver_kubelet_minor_version gauge
+openshift_kube_apiserver_kubelet_minor_version{node="master-0",pool="master"} 36
+openshift_kube_apiserver_kubelet_minor_version{node="worker-2",pool="worker"} 35
+# HELP openshift_kube_apiserver_kubelet_version_skew [ALPHA] Reports the minor version skew of the kubelet on each node relative to the API server, negative when the kubelet is behind
+# TYPE openshift_kube_apiserver_kubelet_version_skew gauge
+openshift_kube_apiserver_kubelet_version_skew{node="master-0",pool="master"} 0
+openshift_kube_apiserver_kubelet_version_skew{node="worker-2",pool="worker"} -1
+`
+	if err := testutil.GatherAndCompare(legacyregistry.DefaultGatherer, strings.NewReader(expected),
+		"openshift_kube_apiserver_kubelet_minor_version", "openshift_kube_apiserver_kubelet_version_skew"); err != nil {
+		t.Fatal(err)
+	}
+}
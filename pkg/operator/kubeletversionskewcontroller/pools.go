@@ -0,0 +1,91 @@
+package kubeletversionskewcontroller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// currentMachineConfigAnnotation is set by the machine-config-daemon to
+	// the rendered config of the node's pool, named rendered-<pool>-<hash>.
+	currentMachineConfigAnnotation = "machineconfiguration.openshift.io/currentConfig"
+	renderedMachineConfigPrefix    = "rendered-"
+)
+
+// machineConfigPool returns the name of the MachineConfigPool the node
+// belongs to, or an empty string if it is not managed by a pool.
+func machineConfigPool(node *corev1.Node) string {
+	renderedConfig := node.Annotations[currentMachineConfigAnnotation]
+	if !strings.HasPrefix(renderedConfig, renderedMachineConfigPrefix) {
+		return ""
+	}
+
+	pool := strings.TrimPrefix(renderedConfig, renderedMachineConfigPrefix)
+	hashIndex := strings.LastIndex(pool, "-")
+	if hashIndex <= 0 {
+		return ""
+	}
+	return pool[:hashIndex]
+}
+
+// hasPools reports whether any of the nodes belongs to a MachineConfigPool.
+func (n nodeKubeletInfos) hasPools() bool {
+	for _, i := range n {
+		if i.pool != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// pools groups the nodes by MachineConfigPool and kubelet minor version,
+// e.g. "pool workers-paused: 40 nodes at 1.29; pool infra: 1 node at 1.28".
+func (n nodeKubeletInfos) pools() string {
+	type poolVersion struct {
+		pool    string
+		version string
+	}
+
+	counts := map[poolVersion]int{}
+	for _, i := range n {
+		key := poolVersion{pool: i.pool}
+		if i.version != nil {
+			key.version = fmt.Sprintf("%d.%d", i.version.Major, i.version.Minor)
+		}
+		counts[key]++
+	}
+
+	keys := make([]poolVersion, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].pool != keys[j].pool {
+			return keys[i].pool < keys[j].pool
+		}
+		return keys[i].version < keys[j].version
+	})
+
+	var groups []string
+	for _, key := range keys {
+		group := "nodes without a pool"
+		if key.pool != "" {
+			group = "pool " + key.pool
+		}
+
+		nodes := fmt.Sprintf("%d nodes", counts[key])
+		if counts[key] == 1 {
+			nodes = "1 node"
+		}
+
+		if key.version == "" {
+			groups = append(groups, fmt.Sprintf("%s: %s", group, nodes))
+			continue
+		}
+		groups = append(groups, fmt.Sprintf("%s: %s at %s", group, nodes, key.version))
+	}
+	return strings.Join(groups, "; ")
+}
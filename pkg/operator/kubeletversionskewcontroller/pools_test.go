@@ -0,0 +1,102 @@
+package kubeletversionskewcontroller
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/component-base/metrics/testutil"
+)
+
+func TestMachineConfigPool(t *testing.T) {
+	for _, tc := range []struct {
+		renderedConfig string
+		expected       string
+	}{
+		{renderedConfig: "", expected: ""},
+		{renderedConfig: "rendered-worker-5f6a1b2c3d4e", expected: "worker"},
+		{renderedConfig: "rendered-workers-paused-5f6a1b2c3d4e", expected: "workers-paused"},
+		{renderedConfig: "rendered-5f6a1b2c3d4e", expected: ""},
+		{renderedConfig: "custom-config", expected: ""},
+	} {
+		t.Run(tc.renderedConfig, func(t *testing.T) {
+			node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{currentMachineConfigAnnotation: tc.renderedConfig},
+			}}
+			if got := machineConfigPool(node); got != tc.expected {
+				t.Errorf("expected pool %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func Test_kubeletVersionSkewController_Pools(t *testing.T) {
+	RegisterMetrics()
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	addNode := func(name, pool, kubeletVersion string) {
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{KubeletVersion: kubeletVersion}},
+		}
+		if pool != "" {
+			node.Annotations = map[string]string{currentMachineConfigAnnotation: fmt.Sprintf("rendered-%s-0123456789", pool)}
+		}
+		indexer.Add(node)
+	}
+	addNode("master-0", "master", "v1.37.0")
+	for i := 0; i < 4; i++ {
+		addNode(fmt.Sprintf("paused-%d", i), "workers-paused", "v1.34.0")
+	}
+	addNode("infra-0", "infra", "v1.34.0")
+	addNode("edge-0", "", "v1.34.0")
+
+	status := &operatorv1.StaticPodOperatorStatus{}
+	ocpVersion := semver.MustParse("5.1.0")
+	c := &kubeletVersionSkewController{
+		operatorClient: v1helpers.NewFakeStaticPodOperatorClient(
+			&operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{ManagementState: operatorv1.Managed}},
+			status, nil, nil,
+		),
+		nodeLister:                  corev1listers.NewNodeLister(indexer),
+		apiServerVersion:            semver.MustParse("1.37.0"),
+		openShiftVersion:            ocpVersion,
+		minSupportedSkew:            minSupportedKubeletSkewForOpenShiftVersion(ocpVersion),
+		minSupportedSkewNextVersion: minSupportedKubeletSkewForOpenShiftVersion(semver.MustParse("5.2.0")),
+	}
+	if err := c.sync(nil, nil); err != nil {
+		t.Fatalf("sync() unexpected err: %v", err)
+	}
+
+	expectedMessage := "Unsupported Kubelet versions are too far behind the target API server version (1.37.0): nodes without a pool: 1 node at 1.34; pool infra: 1 node at 1.34; pool workers-paused: 4 nodes at 1.34."
+	if len(status.Conditions) != 1 || status.Conditions[0].Message != expectedMessage {
+		t.Errorf("unexpected conditions: %v", status.Conditions)
+	}
+
+	for _, tc := range []struct {
+		node, pool  string
+		minor, skew float64
+	}{
+		{node: "master-0", pool: "master", minor: 37, skew: 0},
+		{node: "paused-3", pool: "workers-paused", minor: 34, skew: -3},
+		{node: "edge-0", pool: "", minor: 34, skew: -3},
+	} {
+		minor, err := testutil.GetGaugeMetricValue(kubeletMinorVersionGauge.WithLabelValues(tc.node, tc.pool))
+		if err != nil {
+			t.Fatal(err)
+		}
+		skew, err := testutil.GetGaugeMetricValue(kubeletVersionSkewGauge.WithLabelValues(tc.node, tc.pool))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if minor != tc.minor || skew != tc.skew {
+			t.Errorf("node %s: expected minor %v and skew %v, got %v and %v", tc.node, tc.minor, tc.skew, minor, skew)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package kubeletversionskewcontroller
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	configv1 "github.com/openshift/api/config/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+)
+
+// upgradeHop is a single minor OpenShift version on the upgrade path.
+type upgradeHop struct {
+	version          semver.Version
+	minSupportedSkew int
+}
+
+func (h upgradeHop) String() string {
+	return fmt.Sprintf("%d.%d", h.version.Major, h.version.Minor)
+}
+
+type upgradeHops []upgradeHop
+
+func (p upgradeHops) String() string {
+	var s []string
+	for _, hop := range p {
+		s = append(s, hop.String())
+	}
+	return strings.Join(s, " -> ")
+}
+
+// upgradePath returns every minor version the control plane passes through
+// on the way to the requested upgrade target, while kubelets stay on their
+// current version (e.g. paused worker pools during an EUS-to-EUS upgrade).
+// Without a target beyond the next minor, only the next minor is considered.
+func (c *kubeletVersionSkewController) upgradePath() upgradeHops {
+	nextHop := upgradeHops{{
+		version:          semver.Version{Major: c.openShiftVersion.Major, Minor: c.openShiftVersion.Minor + 1},
+		minSupportedSkew: c.minSupportedSkewNextVersion,
+	}}
+	if c.clusterVersionLister == nil {
+		return nextHop
+	}
+
+	clusterVersion, err := c.clusterVersionLister.Get("version")
+	if errors.IsNotFound(err) {
+		return nextHop
+	}
+	if err != nil {
+		klog.Warningf("Unable to determine the upgrade target, assuming the next minor version: %v", err)
+		return nextHop
+	}
+
+	target := upgradeTarget(clusterVersion)
+	// Major version upgrades do not map minor versions one to one onto
+	// Kubernetes versions, only the next minor is evaluated for those.
+	if target == nil || target.Major != c.openShiftVersion.Major || target.Minor <= c.openShiftVersion.Minor+1 {
+		return nextHop
+	}
+
+	var path upgradeHops
+	for minor := c.openShiftVersion.Minor + 1; minor <= target.Minor; minor++ {
+		version := semver.Version{Major: c.openShiftVersion.Major, Minor: minor}
+		path = append(path, upgradeHop{
+			version:          version,
+			minSupportedSkew: minSupportedKubeletSkewForOpenShiftVersion(version),
+		})
+	}
+	return path
+}
+
+// upgradeTarget returns the version of the desired update. An update requested
+// by image only is resolved through the available updates.
+func upgradeTarget(clusterVersion *configv1.ClusterVersion) *semver.Version {
+	desired := clusterVersion.Spec.DesiredUpdate
+	if desired == nil {
+		return nil
+	}
+
+	version := desired.Version
+	if version == "" && desired.Image != "" {
+		for _, update := range clusterVersion.Status.AvailableUpdates {
+			if update.Image == desired.Image {
+				version = update.Version
+				break
+			}
+		}
+	}
+	if version == "" {
+		return nil
+	}
+
+	target, err := semver.Parse(version)
+	if err != nil {
+		klog.Warningf("Unable to parse the desired update version %q: %v", version, err)
+		return nil
+	}
+	return &target
+}
+
+// firstUnsupportedHop returns the index of the first hop on the path at which
+// a kubelet with the given skew to the current API server is unsupported, or
+// -1 if it is supported all the way. Every hop is assumed to bump the
+// Kubernetes minor version by one.
+func firstUnsupportedHop(skew int, path upgradeHops) int {
+	for i, hop := range path {
+		if skew-(i+1) < hop.minSupportedSkew {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,191 @@
+package namedcertificatescontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openshift/api/annotations"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configv1informers "github.com/openshift/client-go/config/informers/externalversions/config/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/usercertificatescontroller"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// SecretName is the secret in the operand namespace holding the key pairs
+	// of all user provided named serving certificates. It is synced to the
+	// static-pod-certs directory of every kube-apiserver without a revision.
+	SecretName = "user-serving-certs"
+
+	// sourcesKey lists the copied source secrets. It changes whenever an entry
+	// is removed, which makes the cert syncer replace the whole directory and
+	// so drop the stale key pair files.
+	sourcesKey = "sources"
+)
+
+// CertFileKey returns the key of the certificate copied from the given secret
+// in openshift-config.
+func CertFileKey(sourceSecretName string) string {
+	return sourceSecretName + ".crt"
+}
+
+// KeyFileKey returns the key of the private key copied from the given secret
+// in openshift-config.
+func KeyFileKey(sourceSecretName string) string {
+	return sourceSecretName + ".key"
+}
+
+// NamedCertificatesController combines the secrets referenced by
+// apiserver.config.openshift.io/cluster spec.servingCerts.namedCertificates
+// into a single secret, so that any number of named certificates can be served
+// without a fixed set of secrets to sync.
+type NamedCertificatesController struct {
+	operatorClient v1helpers.StaticPodOperatorClient
+
+	secretClient    coreclientv1.SecretsGetter
+	secretLister    corev1listers.SecretLister
+	apiServerLister configv1listers.APIServerLister
+}
+
+func NewNamedCertificatesController(
+	operatorClient v1helpers.StaticPodOperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	secretClient coreclientv1.SecretsGetter,
+	apiServerInformer configv1informers.APIServerInformer,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &NamedCertificatesController{
+		operatorClient:  operatorClient,
+		secretClient:    secretClient,
+		secretLister:    kubeInformersForNamespaces.SecretLister(),
+		apiServerLister: apiServerInformer.Lister(),
+	}
+
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().Secrets().Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().Secrets().Informer(),
+		apiServerInformer.Informer(),
+	).WithSync(c.sync).WithSyncDegradedOnError(c.operatorClient).ResyncEvery(5*time.Minute).ToController("NamedCertificatesController", eventRecorder.WithComponentSuffix("named-certificates-controller"))
+}
+
+func (c NamedCertificatesController) sync(ctx context.Context, syncContext factory.SyncContext) error {
+	operatorSpec, _, _, err := c.operatorClient.GetStaticPodOperatorState()
+	if err != nil {
+		return err
+	}
+
+	switch operatorSpec.ManagementState {
+	case operatorv1.Managed:
+	case operatorv1.Unmanaged:
+		return nil
+	case operatorv1.Removed:
+		// TODO probably just fail
+		return nil
+	default:
+		syncContext.Recorder().Warningf("ManagementStateUnknown", "Unrecognized operator management state %q", operatorSpec.ManagementState)
+		return nil
+	}
+
+	namesBySource := map[string][]string{}
+	apiServer, err := c.apiServerLister.Get("cluster")
+	switch {
+	case errors.IsNotFound(err):
+	case err != nil:
+		return err
+	default:
+		namesBySource = usercertificatescontroller.ServingCertificateNames(apiServer)
+	}
+
+	if err := ensureNamedCertificates(ctx, c.secretClient, c.secretLister, namesBySource, time.Now(), syncContext.Recorder()); err != nil {
+		return fmt.Errorf("%q: %v", "secret/"+SecretName, err)
+	}
+	return nil
+}
+
+// ensureNamedCertificates copies the key pairs of the given source secrets into
+// the combined secret and deletes the combined secret when there are none.
+// A source secret that cannot be read or fails validation keeps its previous
+// copy, so that the kube-apiserver does not lose a certificate it still serves.
+// For the same reason nothing is pruned until all sources are copied, the
+// config observer keeps the previous named certificates meanwhile. Such sources
+// are reported by the config observer and the UserCertificatesDegraded
+// condition, not here. Named certificates that do not fit into a single secret
+// are returned as an error, which degrades the operator.
+func ensureNamedCertificates(ctx context.Context, client coreclientv1.SecretsGetter, secretLister corev1listers.SecretLister, namesBySource map[string][]string, now time.Time, recorder events.Recorder) error {
+	if len(namesBySource) == 0 {
+		_, _, err := resourceapply.DeleteSecret(ctx, client, recorder, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.TargetNamespace, Name: SecretName},
+		})
+		return err
+	}
+
+	existing, err := secretLister.Secrets(operatorclient.TargetNamespace).Get(SecretName)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	data := map[string][]byte{}
+	copied := sets.New[string]()
+	incomplete := false
+	for _, name := range sets.List(sets.KeySet(namesBySource)) {
+		source, err := secretLister.Secrets(operatorclient.GlobalUserSpecifiedConfigNamespace).Get(name)
+		if err == nil {
+			err = usercertificatescontroller.ValidateServingCertificate(source, namesBySource[name], now)
+		}
+		if err != nil {
+			klog.V(2).Infof("Keeping the previous copy of secret %s/%s: %v", operatorclient.GlobalUserSpecifiedConfigNamespace, name, err)
+			incomplete = true
+			continue
+		}
+
+		data[CertFileKey(name)], data[KeyFileKey(name)] = source.Data[corev1.TLSCertKey], source.Data[corev1.TLSPrivateKeyKey]
+		copied.Insert(name)
+	}
+	if incomplete && existing != nil {
+		for _, name := range strings.Split(string(existing.Data[sourcesKey]), "\n") {
+			cert, key := existing.Data[CertFileKey(name)], existing.Data[KeyFileKey(name)]
+			if copied.Has(name) || len(cert) == 0 || len(key) == 0 {
+				continue
+			}
+			data[CertFileKey(name)], data[KeyFileKey(name)] = cert, key
+			copied.Insert(name)
+		}
+	}
+	data[sourcesKey] = []byte(strings.Join(sets.List(copied), "\n"))
+
+	// the kube-apiserver would reject the secret, keep the previous one and report it instead
+	size := 0
+	for _, value := range data {
+		size += len(value)
+	}
+	if size > corev1.MaxSecretSize {
+		return fmt.Errorf("the named certificates take %d bytes, more than the %d bytes a secret can hold", size, corev1.MaxSecretSize)
+	}
+
+	required := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   operatorclient.TargetNamespace,
+			Name:        SecretName,
+			Annotations: map[string]string{annotations.OpenShiftComponent: "kube-apiserver"},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+	_, _, err = resourceapply.ApplySecret(ctx, client, recorder, required)
+	return err
+}
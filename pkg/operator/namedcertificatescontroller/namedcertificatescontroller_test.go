@@ -0,0 +1,189 @@
+package namedcertificatescontroller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/events"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/clock"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+type keyPair struct {
+	cert, key string
+}
+
+func newKeyPair(t *testing.T, hostnames ...string) keyPair {
+	caConfig, err := crypto.MakeSelfSignedCAConfigForDuration("test-ca", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := &crypto.CA{Config: caConfig, SerialGenerator: &crypto.RandomSerialGenerator{}}
+	servingConfig, err := ca.MakeServerCertForDuration(sets.New(hostnames...), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, key, err := servingConfig.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyPair{cert: string(cert), key: string(key)}
+}
+
+func TestEnsureNamedCertificates(t *testing.T) {
+	one, oneRenewed, two, three := newKeyPair(t, "one.example.com"), newKeyPair(t, "one.example.com"), newKeyPair(t, "two.example.com"), newKeyPair(t, "three.example.com")
+
+	userSecret := func(name string, pair keyPair) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.GlobalUserSpecifiedConfigNamespace, Name: name},
+			Data:       map[string][]byte{corev1.TLSCertKey: []byte(pair.cert), corev1.TLSPrivateKeyKey: []byte(pair.key)},
+		}
+	}
+	copies := func(pairs map[string]keyPair) map[string]string {
+		data := map[string]string{}
+		for name, pair := range pairs {
+			data[CertFileKey(name)], data[KeyFileKey(name)] = pair.cert, pair.key
+		}
+		data[sourcesKey] = strings.Join(sets.List(sets.KeySet(pairs)), "\n")
+		return data
+	}
+	combinedSecret := func(pairs map[string]keyPair) *corev1.Secret {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.TargetNamespace, Name: SecretName},
+			Data:       map[string][]byte{},
+		}
+		for k, v := range copies(pairs) {
+			secret.Data[k] = []byte(v)
+		}
+		return secret
+	}
+
+	testCases := []struct {
+		name          string
+		namesBySource map[string][]string
+		objects       []runtime.Object
+		expectedData  map[string]string
+		expectErr     bool
+	}{
+		{
+			name:          "combines all sources",
+			namesBySource: map[string][]string{"one": {"one.example.com"}, "two": nil, "three": {"three.example.com"}},
+			objects: []runtime.Object{
+				userSecret("one", one),
+				userSecret("two", two),
+				userSecret("three", three),
+			},
+			expectedData: copies(map[string]keyPair{"one": one, "two": two, "three": three}),
+		},
+		{
+			name:          "prunes removed sources",
+			namesBySource: map[string][]string{"one": nil},
+			objects: []runtime.Object{
+				userSecret("one", one),
+				userSecret("two", two),
+				combinedSecret(map[string]keyPair{"one": one, "two": two}),
+			},
+			expectedData: copies(map[string]keyPair{"one": one}),
+		},
+		{
+			name:          "updates changed sources",
+			namesBySource: map[string][]string{"one": nil},
+			objects: []runtime.Object{
+				userSecret("one", oneRenewed),
+				combinedSecret(map[string]keyPair{"one": one}),
+			},
+			expectedData: copies(map[string]keyPair{"one": oneRenewed}),
+		},
+		{
+			name:          "keeps all previous copies while a source is missing",
+			namesBySource: map[string][]string{"one": nil, "three": nil},
+			objects: []runtime.Object{
+				userSecret("three", three),
+				combinedSecret(map[string]keyPair{"one": one, "two": two}),
+			},
+			expectedData: copies(map[string]keyPair{"one": one, "two": two, "three": three}),
+		},
+		{
+			name:          "keeps the previous copy of an invalid source",
+			namesBySource: map[string][]string{"one": {"other.example.com"}},
+			objects: []runtime.Object{
+				userSecret("one", oneRenewed),
+				combinedSecret(map[string]keyPair{"one": one}),
+			},
+			expectedData: copies(map[string]keyPair{"one": one}),
+		},
+		{
+			name:          "skips an invalid source without a previous copy",
+			namesBySource: map[string][]string{"one": nil, "two": nil},
+			objects: []runtime.Object{
+				userSecret("one", keyPair{cert: one.cert, key: two.key}),
+				userSecret("two", two),
+			},
+			expectedData: copies(map[string]keyPair{"two": two}),
+		},
+		{
+			name:          "keeps the previous secret when the sources do not fit",
+			namesBySource: map[string][]string{"one": nil},
+			objects: []runtime.Object{
+				userSecret("one", keyPair{cert: oneRenewed.cert + strings.Repeat("\n", corev1.MaxSecretSize), key: oneRenewed.key}),
+				combinedSecret(map[string]keyPair{"one": one}),
+			},
+			expectedData: copies(map[string]keyPair{"one": one}),
+			expectErr:    true,
+		},
+		{
+			name: "deletes the combined secret without sources",
+			objects: []runtime.Object{
+				combinedSecret(map[string]keyPair{"one": one}),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			for _, obj := range tc.objects {
+				if err := indexer.Add(obj); err != nil {
+					t.Fatal(err)
+				}
+			}
+			client := fake.NewSimpleClientset(tc.objects...)
+
+			err := ensureNamedCertificates(context.Background(), client.CoreV1(), corev1listers.NewSecretLister(indexer), tc.namesBySource, time.Now(), events.NewInMemoryRecorder(t.Name(), clock.RealClock{}))
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+
+			secret, err := client.CoreV1().Secrets(operatorclient.TargetNamespace).Get(context.Background(), SecretName, metav1.GetOptions{})
+			if tc.expectedData == nil {
+				if !apierrors.IsNotFound(err) {
+					t.Fatalf("expected secret %s to be deleted, got %v", SecretName, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			data := map[string]string{}
+			for k, v := range secret.Data {
+				data[k] = string(v)
+			}
+			if diff := cmp.Diff(tc.expectedData, data); diff != "" {
+				t.Errorf("unexpected data (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
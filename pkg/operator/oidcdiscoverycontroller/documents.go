@@ -0,0 +1,154 @@
+package oidcdiscoverycontroller
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/keyutil"
+)
+
+// externalSignerKeyPrefix marks the public keys of an external JWT signer in
+// bound-sa-token-signing-certs. The signer chooses their key IDs, which the
+// configmap doesn't keep, so they are published without one.
+const externalSignerKeyPrefix = "external-signer-"
+
+// openIDConfiguration is the discovery document the kube-apiserver serves at
+// /.well-known/openid-configuration.
+type openIDConfiguration struct {
+	Issuer        string   `json:"issuer"`
+	JWKSURI       string   `json:"jwks_uri"`
+	ResponseTypes []string `json:"response_types_supported"`
+	SubjectTypes  []string `json:"subject_types_supported"`
+	SigningAlgs   []string `json:"id_token_signing_alg_values_supported"`
+}
+
+func newOpenIDConfiguration(issuer, jwksURI string, keySet *jsonWebKeySet) *openIDConfiguration {
+	algorithms := sets.New[string]()
+	for _, key := range keySet.Keys {
+		algorithms.Insert(key.Algorithm)
+	}
+	return &openIDConfiguration{
+		Issuer:        issuer,
+		JWKSURI:       jwksURI,
+		ResponseTypes: []string{"id_token"},
+		SubjectTypes:  []string{"public"},
+		SigningAlgs:   sets.List(algorithms),
+	}
+}
+
+func (c *openIDConfiguration) marshal() (string, error) {
+	raw, err := json.Marshal(c)
+	return string(raw), err
+}
+
+// jsonWebKeySet is the key set the kube-apiserver serves at /openid/v1/jwks.
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type jsonWebKey struct {
+	Use       string `json:"use"`
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+// newKeySet returns the key set of the PEM encoded public keys in the given
+// configmap data, in the order of their keys.
+func newKeySet(data map[string]string) (*jsonWebKeySet, error) {
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	keySet := &jsonWebKeySet{Keys: []jsonWebKey{}}
+	for _, name := range names {
+		publicKeys, err := keyutil.ParsePublicKeysPEM([]byte(data[name]))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		for _, publicKey := range publicKeys {
+			key, err := newJSONWebKey(publicKey, !strings.HasPrefix(name, externalSignerKeyPrefix))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			keySet.Keys = append(keySet.Keys, *key)
+		}
+	}
+	return keySet, nil
+}
+
+func (s *jsonWebKeySet) marshal() (string, error) {
+	raw, err := json.Marshal(s)
+	return string(raw), err
+}
+
+func newJSONWebKey(publicKey crypto.PublicKey, withKeyID bool) (*jsonWebKey, error) {
+	key := &jsonWebKey{Use: "sig"}
+	switch publicKey := publicKey.(type) {
+	case *rsa.PublicKey:
+		key.KeyType = "RSA"
+		key.Algorithm = "RS256"
+		key.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+		key.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	case *ecdsa.PublicKey:
+		key.KeyType = "EC"
+		switch publicKey.Curve {
+		case elliptic.P256():
+			key.Curve, key.Algorithm = "P-256", "ES256"
+		case elliptic.P384():
+			key.Curve, key.Algorithm = "P-384", "ES384"
+		case elliptic.P521():
+			key.Curve, key.Algorithm = "P-521", "ES512"
+		default:
+			return nil, fmt.Errorf("unsupported elliptic curve %s", publicKey.Curve.Params().Name)
+		}
+		// the uncompressed point, both coordinates padded to the size of the curve
+		point, err := publicKey.Bytes()
+		if err != nil {
+			return nil, err
+		}
+		size := (len(point) - 1) / 2
+		key.X = base64.RawURLEncoding.EncodeToString(point[1 : 1+size])
+		key.Y = base64.RawURLEncoding.EncodeToString(point[1+size:])
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+
+	if withKeyID {
+		keyID, err := keyIDFromPublicKey(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		key.KeyID = keyID
+	}
+	return key, nil
+}
+
+// keyIDFromPublicKey derives the key ID the kube-apiserver puts in the tokens
+// it signs, the URL safe base64 encoded SHA-256 hash of the DER encoded key.
+func keyIDFromPublicKey(publicKey crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(hash[:]), nil
+}
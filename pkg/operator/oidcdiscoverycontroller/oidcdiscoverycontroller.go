@@ -0,0 +1,179 @@
+package oidcdiscoverycontroller
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configv1informers "github.com/openshift/client-go/config/informers/externalversions/config/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/boundsatokensignercontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+const (
+	// ConfigMapName is the configmap in openshift-config-managed holding the
+	// OIDC discovery documents of the service account issuer, for a syncer
+	// that publishes them at the issuer URL.
+	ConfigMapName = "service-account-oidc-discovery"
+
+	// OpenIDConfigurationKey holds the document to serve at
+	// <issuer>/.well-known/openid-configuration.
+	OpenIDConfigurationKey = "openid-configuration"
+	// JWKSKey holds the key set to serve at the jwks_uri of the document,
+	// <issuer>/openid/v1/jwks for a custom issuer.
+	JWKSKey = "jwks"
+
+	// defaultServiceAccountIssuer is the issuer when none is configured, only
+	// the kube-apiserver itself serves its discovery documents.
+	defaultServiceAccountIssuer = "https://kubernetes.default.svc"
+	jwksPath                    = "/openid/v1/jwks"
+)
+
+// OIDCDiscoveryController renders the OIDC discovery document and the key set
+// of the active service account issuer from the public keys in
+// bound-sa-token-signing-certs, the same way the kube-apiserver serves them,
+// so that workload identity providers can be given the documents without
+// scraping the kube-apiserver and copying the keys after every rotation.
+type OIDCDiscoveryController struct {
+	configMapClient      coreclientv1.ConfigMapsGetter
+	configMapLister      corev1listers.ConfigMapLister
+	kubeAPIServerLister  operatorv1listers.KubeAPIServerLister
+	infrastructureLister configv1listers.InfrastructureLister
+}
+
+func NewOIDCDiscoveryController(
+	operatorClient v1helpers.StaticPodOperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	configMapClient coreclientv1.ConfigMapsGetter,
+	kubeAPIServerInformer operatorv1informers.KubeAPIServerInformer,
+	infrastructureInformer configv1informers.InfrastructureInformer,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &OIDCDiscoveryController{
+		configMapClient:      configMapClient,
+		configMapLister:      kubeInformersForNamespaces.ConfigMapLister(),
+		kubeAPIServerLister:  kubeAPIServerInformer.Lister(),
+		infrastructureLister: infrastructureInformer.Lister(),
+	}
+
+	return factory.New().WithInformers(
+		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().ConfigMaps().Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalMachineSpecifiedConfigNamespace).Core().V1().ConfigMaps().Informer(),
+		kubeAPIServerInformer.Informer(),
+		infrastructureInformer.Informer(),
+	).WithSync(c.sync).WithSyncDegradedOnError(operatorClient).ResyncEvery(10*time.Minute).ToController("OIDCDiscoveryController", eventRecorder.WithComponentSuffix("oidc-discovery-controller"))
+}
+
+func (c *OIDCDiscoveryController) sync(ctx context.Context, syncContext factory.SyncContext) error {
+	required, err := c.requiredConfigMap()
+	if err != nil {
+		return err
+	}
+	if required == nil {
+		_, _, err := resourceapply.DeleteConfigMap(ctx, c.configMapClient, syncContext.Recorder(), &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace, Name: ConfigMapName},
+		})
+		return err
+	}
+	_, _, err = resourceapply.ApplyConfigMap(ctx, c.configMapClient, syncContext.Recorder(), required)
+	return err
+}
+
+// requiredConfigMap returns the configmap with the discovery documents, or
+// nil when the active issuer has no discovery.
+func (c *OIDCDiscoveryController) requiredConfigMap() (*corev1.ConfigMap, error) {
+	kubeAPIServer, err := c.kubeAPIServerLister.Get("cluster")
+	if err != nil {
+		return nil, err
+	}
+	issuer := activeServiceAccountIssuer(kubeAPIServer.Status.ServiceAccountIssuers)
+
+	jwksURI, err := c.jwksURI(issuer)
+	if err != nil {
+		return nil, err
+	}
+	if len(jwksURI) == 0 {
+		return nil, nil
+	}
+
+	publicKeys, err := c.configMapLister.ConfigMaps(operatorclient.TargetNamespace).Get(boundsatokensignercontroller.PublicKeyConfigMapName)
+	if errors.IsNotFound(err) {
+		// the keys are created by the BoundSATokenSignerController
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	keySet, err := newKeySet(publicKeys.Data)
+	if err != nil {
+		return nil, fmt.Errorf("configmap/%s -n %s: %w", publicKeys.Name, publicKeys.Namespace, err)
+	}
+	if len(keySet.Keys) == 0 {
+		return nil, fmt.Errorf("configmap/%s -n %s has no public keys", publicKeys.Name, publicKeys.Namespace)
+	}
+
+	openIDConfiguration, err := newOpenIDConfiguration(issuer, jwksURI, keySet).marshal()
+	if err != nil {
+		return nil, err
+	}
+	jwks, err := keySet.marshal()
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace, Name: ConfigMapName},
+		Data: map[string]string{
+			OpenIDConfigurationKey: openIDConfiguration,
+			JWKSKey:                jwks,
+		},
+	}, nil
+}
+
+// jwksURI returns the jwks_uri the kube-apiserver announces for the given
+// issuer, see ObserveServiceAccountIssuer. It is empty when the kube-apiserver
+// disables discovery because the issuer isn't an https URL.
+func (c *OIDCDiscoveryController) jwksURI(issuer string) (string, error) {
+	if issuer == defaultServiceAccountIssuer {
+		infrastructure, err := c.infrastructureLister.Get("cluster")
+		if err != nil {
+			return "", err
+		}
+		if len(infrastructure.Status.APIServerURL) == 0 {
+			return "", fmt.Errorf("APIServerURL missing from infrastructure/cluster")
+		}
+		return infrastructure.Status.APIServerURL + jwksPath, nil
+	}
+	parsed, err := url.Parse(issuer)
+	if err != nil || parsed.Scheme != "https" {
+		klog.V(2).Infof("Not publishing the OIDC discovery documents of service account issuer %q, it is not an https URL", issuer)
+		return "", nil
+	}
+	return issuer + jwksPath, nil
+}
+
+// activeServiceAccountIssuer returns the issuer without an expiration time,
+// the one new tokens are issued by.
+func activeServiceAccountIssuer(issuers []operatorv1.ServiceAccountIssuerStatus) string {
+	for _, issuer := range issuers {
+		if issuer.ExpirationTime == nil {
+			return issuer.Name
+		}
+	}
+	return defaultServiceAccountIssuer
+}
@@ -0,0 +1,197 @@
+package oidcdiscoverycontroller
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/clock"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/boundsatokensignercontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+func TestSync(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signerKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeys := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.TargetNamespace, Name: boundsatokensignercontroller.PublicKeyConfigMapName},
+		Data: map[string]string{
+			"service-account-001.pub": publicKeyPEM(t, "RSA PUBLIC KEY", rsaKey.Public()),
+			"service-account-002.pub": publicKeyPEM(t, "PUBLIC KEY", ecKey.Public()),
+			"external-signer-001.pub": publicKeyPEM(t, "PUBLIC KEY", signerKey.Public()),
+		},
+	}
+
+	tests := []struct {
+		name                string
+		issuers             []operatorv1.ServiceAccountIssuerStatus
+		expectedIssuer      string
+		expectedJWKSURI     string
+		expectedNoConfigMap bool
+	}{
+		{
+			name:            "default issuer",
+			expectedIssuer:  "https://kubernetes.default.svc",
+			expectedJWKSURI: "https://api.example.com:6443/openid/v1/jwks",
+		},
+		{
+			name: "custom issuer",
+			issuers: []operatorv1.ServiceAccountIssuerStatus{
+				{Name: "https://issuer.example.com"},
+				{Name: "https://kubernetes.default.svc", ExpirationTime: &metav1.Time{}},
+			},
+			expectedIssuer:  "https://issuer.example.com",
+			expectedJWKSURI: "https://issuer.example.com/openid/v1/jwks",
+		},
+		{
+			name:                "issuer without discovery",
+			issuers:             []operatorv1.ServiceAccountIssuerStatus{{Name: "issuer.example.com"}},
+			expectedNoConfigMap: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			configMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if err := configMapIndexer.Add(publicKeys); err != nil {
+				t.Fatal(err)
+			}
+			operatorIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if err := operatorIndexer.Add(&operatorv1.KubeAPIServer{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Status:     operatorv1.KubeAPIServerStatus{ServiceAccountIssuers: test.issuers},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			infrastructureIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if err := infrastructureIndexer.Add(&configv1.Infrastructure{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Status:     configv1.InfrastructureStatus{APIServerURL: "https://api.example.com:6443"},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			// a stale configmap from a previous issuer
+			kubeClient := fake.NewSimpleClientset(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace, Name: ConfigMapName},
+			})
+			c := &OIDCDiscoveryController{
+				configMapClient:      kubeClient.CoreV1(),
+				configMapLister:      corev1listers.NewConfigMapLister(configMapIndexer),
+				kubeAPIServerLister:  operatorv1listers.NewKubeAPIServerLister(operatorIndexer),
+				infrastructureLister: configv1listers.NewInfrastructureLister(infrastructureIndexer),
+			}
+
+			if err := c.sync(context.Background(), factory.NewSyncContext("test", events.NewInMemoryRecorder("test", clock.RealClock{}))); err != nil {
+				t.Fatal(err)
+			}
+			configMap, err := kubeClient.CoreV1().ConfigMaps(operatorclient.GlobalMachineSpecifiedConfigNamespace).Get(context.Background(), ConfigMapName, metav1.GetOptions{})
+			if test.expectedNoConfigMap {
+				if !apierrors.IsNotFound(err) {
+					t.Errorf("expected the configmap to be deleted, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			openIDConfiguration := &openIDConfiguration{}
+			if err := json.Unmarshal([]byte(configMap.Data[OpenIDConfigurationKey]), openIDConfiguration); err != nil {
+				t.Fatal(err)
+			}
+			if openIDConfiguration.Issuer != test.expectedIssuer || openIDConfiguration.JWKSURI != test.expectedJWKSURI {
+				t.Errorf("expected issuer %q with jwks_uri %q, got %+v", test.expectedIssuer, test.expectedJWKSURI, openIDConfiguration)
+			}
+			if algorithms := openIDConfiguration.SigningAlgs; len(algorithms) != 3 || algorithms[0] != "ES256" || algorithms[1] != "ES384" || algorithms[2] != "RS256" {
+				t.Errorf("expected the algorithms of all the keys, got %v", algorithms)
+			}
+
+			keySet := &jsonWebKeySet{}
+			if err := json.Unmarshal([]byte(configMap.Data[JWKSKey]), keySet); err != nil {
+				t.Fatal(err)
+			}
+			if len(keySet.Keys) != 3 {
+				t.Fatalf("expected 3 keys, got %+v", keySet.Keys)
+			}
+			rsaKeyID, _ := keyIDFromPublicKey(rsaKey.Public())
+			ecKeyID, _ := keyIDFromPublicKey(ecKey.Public())
+			for i, expected := range []struct{ kty, kid, alg string }{
+				{"EC", "", "ES384"},
+				{"RSA", rsaKeyID, "RS256"},
+				{"EC", ecKeyID, "ES256"},
+			} {
+				key := keySet.Keys[i]
+				if key.Use != "sig" || key.KeyType != expected.kty || key.KeyID != expected.kid || key.Algorithm != expected.alg {
+					t.Errorf("expected key %d to be %+v, got %+v", i, expected, key)
+				}
+			}
+		})
+	}
+}
+
+func TestNewJSONWebKey(t *testing.T) {
+	// the key of the RFC 7517 appendix A.1 example
+	point := []byte{0x04}
+	for _, coordinate := range []string{"MKBCTNIcKUSDii11ySs3526iDZ8AiTo7Tu6KPAqv7D4", "4Etl6SRW2YiLUrN5vfvVHuhp7x8PxltmWWlbbM4IFyM"} {
+		raw, err := base64.RawURLEncoding.DecodeString(coordinate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		point = append(point, raw...)
+	}
+	publicKey, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), point)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := newJSONWebKey(publicKey, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.X != "MKBCTNIcKUSDii11ySs3526iDZ8AiTo7Tu6KPAqv7D4" || key.Y != "4Etl6SRW2YiLUrN5vfvVHuhp7x8PxltmWWlbbM4IFyM" || key.Curve != "P-256" || key.KeyID != "" {
+		t.Errorf("unexpected key %+v", key)
+	}
+
+	if _, err := newJSONWebKey(&ecdsa.PublicKey{Curve: elliptic.P224()}, true); err == nil {
+		t.Errorf("expected an error for an unsupported curve")
+	}
+}
+
+func publicKeyPEM(t *testing.T, blockType string, key crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}))
+}
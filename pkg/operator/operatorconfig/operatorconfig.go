@@ -0,0 +1,65 @@
+// Package operatorconfig reads the operator options that have no field in the
+// KubeAPIServer API. Cluster admins set them in a config map of
+// openshift-config, one data key per option. Unlike the
+// spec.unsupportedConfigOverrides, setting them keeps the cluster upgradeable.
+package operatorconfig
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+const (
+	Namespace = operatorclient.GlobalUserSpecifiedConfigNamespace
+	Name      = "kube-apiserver-operator-config"
+)
+
+// Get returns the operator config map from the lister, nil when it doesn't
+// exist.
+func Get(lister corev1listers.ConfigMapLister) (*corev1.ConfigMap, error) {
+	configMap, err := lister.ConfigMaps(Namespace).Get(Name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return configMap, err
+}
+
+// GetLive returns the operator config map from the server, nil when it
+// doesn't exist. It is meant for the startup, before the informers sync.
+func GetLive(ctx context.Context, client corev1client.ConfigMapsGetter) (*corev1.ConfigMap, error) {
+	configMap, err := client.ConfigMaps(Namespace).Get(ctx, Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return configMap, err
+}
+
+// Unmarshal decodes the YAML or JSON value of the key into into. It returns
+// false when the config map is nil or has no such key.
+func Unmarshal(configMap *corev1.ConfigMap, key string, into interface{}) (bool, error) {
+	if configMap == nil {
+		return false, nil
+	}
+	value, ok := configMap.Data[key]
+	if !ok {
+		return false, nil
+	}
+	if err := yaml.Unmarshal([]byte(value), into); err != nil {
+		return true, fmt.Errorf("failed to unmarshal %s: %w", KeyPath(key), err)
+	}
+	return true, nil
+}
+
+// KeyPath describes where the key is set, for messages.
+func KeyPath(key string) string {
+	return fmt.Sprintf("configmaps/%s in %s, key %s", Name, Namespace, key)
+}
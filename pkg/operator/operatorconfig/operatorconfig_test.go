@@ -0,0 +1,54 @@
+package operatorconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestUnmarshal(t *testing.T) {
+	type option struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	lister := corev1listers.NewConfigMapLister(indexer)
+	configMap, err := Get(lister)
+	require.NoError(t, err)
+	require.Nil(t, configMap)
+	found, err := Unmarshal(configMap, "option", &option{})
+	require.NoError(t, err)
+	require.False(t, found)
+
+	require.NoError(t, indexer.Add(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: Namespace, Name: Name},
+		Data: map[string]string{
+			"yaml":    "name: a\ncount: 2\n",
+			"json":    `{"name":"b","count":3}`,
+			"invalid": `{"count":"three"}`,
+		},
+	}))
+	configMap, err = Get(lister)
+	require.NoError(t, err)
+
+	for key, expected := range map[string]option{"yaml": {Name: "a", Count: 2}, "json": {Name: "b", Count: 3}} {
+		actual := option{}
+		found, err := Unmarshal(configMap, key, &actual)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, expected, actual)
+	}
+
+	found, err = Unmarshal(configMap, "missing", &option{})
+	require.NoError(t, err)
+	require.False(t, found)
+
+	found, err = Unmarshal(configMap, "invalid", &option{})
+	require.ErrorContains(t, err, "configmaps/kube-apiserver-operator-config in openshift-config, key invalid")
+	require.True(t, found)
+}
@@ -7,7 +7,7 @@ import (
 
 	securityv1 "github.com/openshift/api/security/v1"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 	psapi "k8s.io/pod-security-admission/api"
@@ -30,6 +30,9 @@ func (c *PodSecurityReadinessController) classifyViolatingNamespace(
 	ns *corev1.Namespace,
 	enforceLevel psapi.Level,
 ) error {
+	nsReport := conditions.report.forNamespace(ns.Name)
+	nsReport.EnforceLevel = enforceLevel
+
 	if runLevelZeroNamespaces.Has(ns.Name) {
 		conditions.addViolatingRunLevelZero(ns)
 		return nil
@@ -44,7 +47,7 @@ func (c *PodSecurityReadinessController) classifyViolatingNamespace(
 	}
 
 	// Evaluate by individual pod.
-	allPods, err := c.kubeClient.CoreV1().Pods(ns.Name).List(ctx, metav1.ListOptions{})
+	allPods, err := c.podLister.Pods(ns.Name).List(labels.Everything())
 	if err != nil {
 		// Will end up in inconclusive as we couldn't diagnose the violation root
 		// cause.
@@ -54,9 +57,9 @@ func (c *PodSecurityReadinessController) classifyViolatingNamespace(
 
 	isViolating := createPodViolationEvaluator(c.psaEvaluator, enforceLevel)
 	violatingPods := []corev1.Pod{}
-	for _, pod := range allPods.Items {
-		if isViolating(pod) {
-			violatingPods = append(violatingPods, pod)
+	for _, pod := range allPods {
+		if isViolating(*pod) {
+			violatingPods = append(violatingPods, *pod)
 		}
 	}
 	if len(violatingPods) == 0 {
@@ -64,6 +67,8 @@ func (c *PodSecurityReadinessController) classifyViolatingNamespace(
 		return errNoViolatingPods
 	}
 
+	nsReport.setViolatingPods(ctx, c.psaEvaluator, c.canUseSCC, violatingPods)
+
 	violatingUserSCCPods := []corev1.Pod{}
 	for _, pod := range violatingPods {
 		if pod.Annotations[securityv1.ValidatedSCCSubjectTypeAnnotation] == "user" {
@@ -10,9 +10,9 @@ import (
 	securityv1 "github.com/openshift/api/security/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/client-go/kubernetes/fake"
-	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 	psapi "k8s.io/pod-security-admission/api"
 	"k8s.io/pod-security-admission/policy"
 )
@@ -24,11 +24,6 @@ func TestClassifyViolatingNamespaceWithAPIErrors(t *testing.T) {
 		},
 	}
 
-	fakeClient := fake.NewSimpleClientset()
-	fakeClient.PrependReactor("list", "pods", func(action clienttesting.Action) (handled bool, ret runtime.Object, err error) {
-		return true, nil, fmt.Errorf("simulated API error: connection refused")
-	})
-
 	latestVersion := psapi.LatestVersion()
 
 	psaEvaluator, err := policy.NewEvaluator(policy.DefaultChecks(), &latestVersion)
@@ -37,14 +32,15 @@ func TestClassifyViolatingNamespaceWithAPIErrors(t *testing.T) {
 	}
 
 	controller := &PodSecurityReadinessController{
-		kubeClient:   fakeClient,
+		podLister:    failingPodLister{err: fmt.Errorf("simulated API error: connection refused")},
 		psaEvaluator: psaEvaluator,
 	}
 
 	conditions := podSecurityOperatorConditions{}
 
 	err = controller.classifyViolatingNamespace(
-		context.Background(), &conditions,
+		context.TODO(),
+		&conditions,
 		namespace, "restricted",
 	)
 
@@ -66,6 +62,26 @@ func TestClassifyViolatingNamespaceWithAPIErrors(t *testing.T) {
 	}
 }
 
+// failingPodLister fails every list, like a lister of an informer that
+// couldn't be listed.
+type failingPodLister struct {
+	corev1listers.PodLister
+	err error
+}
+
+func (l failingPodLister) Pods(string) corev1listers.PodNamespaceLister {
+	return failingPodNamespaceLister{err: l.err}
+}
+
+type failingPodNamespaceLister struct {
+	corev1listers.PodNamespaceLister
+	err error
+}
+
+func (l failingPodNamespaceLister) List(labels.Selector) ([]*corev1.Pod, error) {
+	return nil, l.err
+}
+
 func TestClassifyViolatingNamespace(t *testing.T) {
 	for _, tt := range []struct {
 		name               string
@@ -313,7 +329,8 @@ func TestClassifyViolatingNamespace(t *testing.T) {
 
 			conditions := podSecurityOperatorConditions{}
 			err = controller.classifyViolatingNamespace(
-				context.Background(), &conditions,
+				context.TODO(),
+				&conditions,
 				tt.namespace, tt.enforceLevel,
 			)
 			if hasError := err != nil; hasError != tt.expectError {
@@ -332,14 +349,10 @@ func TestClassifyViolatingNamespace(t *testing.T) {
 }
 
 func createTestController(pods []corev1.Pod) (*PodSecurityReadinessController, error) {
-	fakeClient := fake.NewSimpleClientset()
-
-	for _, pod := range pods {
-		_, err := fakeClient.CoreV1().
-			Pods(pod.Namespace).
-			Create(context.Background(), &pod, metav1.CreateOptions{})
-		if err != nil {
-			return nil, fmt.Errorf("Failed to create test pod: %v", err)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for i := range pods {
+		if err := indexer.Add(&pods[i]); err != nil {
+			return nil, fmt.Errorf("Failed to add test pod: %v", err)
 		}
 	}
 
@@ -351,7 +364,7 @@ func createTestController(pods []corev1.Pod) (*PodSecurityReadinessController, e
 	}
 
 	return &PodSecurityReadinessController{
-		kubeClient:   fakeClient,
+		podLister:    corev1listers.NewPodLister(indexer),
 		psaEvaluator: psaEvaluator,
 	}, nil
 }
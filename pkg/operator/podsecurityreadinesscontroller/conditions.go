@@ -34,30 +34,52 @@ type podSecurityOperatorConditions struct {
 	violatingUserSCCNamespaces        []string
 	violatingUnclassifiedNamespaces   []string
 	inconclusiveNamespaces            []string
+
+	report podSecurityReport
 }
 
 func (c *podSecurityOperatorConditions) addInconclusive(ns *corev1.Namespace) {
 	c.inconclusiveNamespaces = append(c.inconclusiveNamespaces, ns.Name)
+	c.report.forNamespace(ns.Name).addClassification(classificationInconclusive)
 }
 
 func (c *podSecurityOperatorConditions) addViolatingRunLevelZero(ns *corev1.Namespace) {
 	c.violatingRunLevelZeroNamespaces = append(c.violatingRunLevelZeroNamespaces, ns.Name)
+	c.report.forNamespace(ns.Name).addClassification(classificationRunLevelZero)
 }
 
 func (c *podSecurityOperatorConditions) addViolatingOpenShift(ns *corev1.Namespace) {
 	c.violatingOpenShiftNamespaces = append(c.violatingOpenShiftNamespaces, ns.Name)
+	c.report.forNamespace(ns.Name).addClassification(classificationOpenShift)
 }
 
 func (c *podSecurityOperatorConditions) addViolatingDisabledSyncer(ns *corev1.Namespace) {
 	c.violatingDisabledSyncerNamespaces = append(c.violatingDisabledSyncerNamespaces, ns.Name)
+	c.report.forNamespace(ns.Name).addClassification(classificationDisabledSyncer)
 }
 
 func (c *podSecurityOperatorConditions) addUnclassifiedIssue(ns *corev1.Namespace) {
 	c.violatingUnclassifiedNamespaces = append(c.violatingUnclassifiedNamespaces, ns.Name)
+	c.report.forNamespace(ns.Name).addClassification(classificationUnclassified)
 }
 
 func (c *podSecurityOperatorConditions) addViolatingUserSCC(ns *corev1.Namespace) {
 	c.violatingUserSCCNamespaces = append(c.violatingUserSCCNamespaces, ns.Name)
+	c.report.forNamespace(ns.Name).addClassification(classificationUserSCC)
+}
+
+// merge adds the namespaces and report entries of other to c.
+func (c *podSecurityOperatorConditions) merge(other *podSecurityOperatorConditions) {
+	c.violatingOpenShiftNamespaces = append(c.violatingOpenShiftNamespaces, other.violatingOpenShiftNamespaces...)
+	c.violatingRunLevelZeroNamespaces = append(c.violatingRunLevelZeroNamespaces, other.violatingRunLevelZeroNamespaces...)
+	c.violatingDisabledSyncerNamespaces = append(c.violatingDisabledSyncerNamespaces, other.violatingDisabledSyncerNamespaces...)
+	c.violatingUserSCCNamespaces = append(c.violatingUserSCCNamespaces, other.violatingUserSCCNamespaces...)
+	c.violatingUnclassifiedNamespaces = append(c.violatingUnclassifiedNamespaces, other.violatingUnclassifiedNamespaces...)
+	c.inconclusiveNamespaces = append(c.inconclusiveNamespaces, other.inconclusiveNamespaces...)
+
+	for name, nsReport := range other.report.namespaces {
+		*c.report.forNamespace(name) = *nsReport
+	}
 }
 
 func makeCondition(conditionType, conditionReason string, namespaces []string) operatorv1.OperatorCondition {
@@ -0,0 +1,123 @@
+package podsecurityreadinesscontroller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+)
+
+// NewNamespaceInformers returns an informer factory that only lists
+// namespaces without an enforce label, the only ones this controller cares
+// about. Namespaces keep their managed fields, they are needed to tell which
+// labels the syncer owns.
+func NewNamespaceInformers(kubeClient kubernetes.Interface) (informers.SharedInformerFactory, error) {
+	selector, err := nonEnforcingSelector()
+	if err != nil {
+		return nil, err
+	}
+
+	return informers.NewSharedInformerFactoryWithOptions(kubeClient, 0,
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = selector
+		}),
+	), nil
+}
+
+// NewPodInformers returns an informer factory that trims pods down to what
+// the Pod Security evaluation and the report need, so that watching every pod
+// in the cluster stays cheap.
+func NewPodInformers(kubeClient kubernetes.Interface) informers.SharedInformerFactory {
+	return informers.NewSharedInformerFactoryWithOptions(kubeClient, 0,
+		informers.WithTransform(trimPod),
+	)
+}
+
+func trimPod(obj interface{}) (interface{}, error) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return obj, nil
+	}
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            pod.Name,
+			Namespace:       pod.Namespace,
+			UID:             pod.UID,
+			ResourceVersion: pod.ResourceVersion,
+			Labels:          pod.Labels,
+			// Annotations carry the admitting SCC and are still read by some
+			// of the Pod Security checks, e.g. AppArmor.
+			Annotations:     trimAnnotations(pod.Annotations),
+			OwnerReferences: pod.OwnerReferences,
+		},
+		Spec: corev1.PodSpec{
+			ServiceAccountName:  pod.Spec.ServiceAccountName,
+			Volumes:             pod.Spec.Volumes,
+			InitContainers:      trimContainers(pod.Spec.InitContainers),
+			Containers:          trimContainers(pod.Spec.Containers),
+			EphemeralContainers: trimEphemeralContainers(pod.Spec.EphemeralContainers),
+			HostNetwork:         pod.Spec.HostNetwork,
+			HostPID:             pod.Spec.HostPID,
+			HostIPC:             pod.Spec.HostIPC,
+			// Pod Security relaxes runAsNonRoot, runAsUser and procMount for
+			// pods in a user namespace.
+			HostUsers:       pod.Spec.HostUsers,
+			SecurityContext: pod.Spec.SecurityContext,
+			OS:              pod.Spec.OS,
+		},
+	}, nil
+}
+
+// trimAnnotations drops the last applied configuration, a copy of the whole
+// object that is often the largest field of a pod.
+func trimAnnotations(annotations map[string]string) map[string]string {
+	if _, ok := annotations[corev1.LastAppliedConfigAnnotation]; !ok {
+		return annotations
+	}
+
+	trimmed := make(map[string]string, len(annotations)-1)
+	for key, value := range annotations {
+		if key != corev1.LastAppliedConfigAnnotation {
+			trimmed[key] = value
+		}
+	}
+
+	return trimmed
+}
+
+func trimContainers(containers []corev1.Container) []corev1.Container {
+	if containers == nil {
+		return nil
+	}
+
+	trimmed := make([]corev1.Container, 0, len(containers))
+	for _, container := range containers {
+		trimmed = append(trimmed, corev1.Container{
+			Name:            container.Name,
+			Ports:           container.Ports,
+			SecurityContext: container.SecurityContext,
+		})
+	}
+
+	return trimmed
+}
+
+func trimEphemeralContainers(containers []corev1.EphemeralContainer) []corev1.EphemeralContainer {
+	if containers == nil {
+		return nil
+	}
+
+	trimmed := make([]corev1.EphemeralContainer, 0, len(containers))
+	for _, container := range containers {
+		trimmed = append(trimmed, corev1.EphemeralContainer{
+			EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+				Name:            container.Name,
+				Ports:           container.Ports,
+				SecurityContext: container.SecurityContext,
+			},
+		})
+	}
+
+	return trimmed
+}
@@ -0,0 +1,79 @@
+package podsecurityreadinesscontroller
+
+import (
+	"testing"
+
+	securityv1 "github.com/openshift/api/security/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	psapi "k8s.io/pod-security-admission/api"
+	"k8s.io/utils/ptr"
+)
+
+func TestTrimPodKeepsEvaluation(t *testing.T) {
+	psaEvaluator := newPSAEvaluator(t)
+
+	for _, pod := range []corev1.Pod{
+		newUserSCCPodRestricted("restricted", "ns"),
+		newServiceAccountPod("root", "ns"),
+		newUserSCCPodWithPrivilegedContainer("privileged", "ns"),
+	} {
+		pod.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "kubelet"}}
+		pod.Status = corev1.PodStatus{Phase: corev1.PodRunning}
+
+		obj, err := trimPod(&pod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		trimmed := obj.(*corev1.Pod)
+
+		if len(trimmed.ManagedFields) != 0 || trimmed.Status.Phase != "" {
+			t.Errorf("%s: expected managed fields and status to be dropped", pod.Name)
+		}
+		if trimmed.Annotations[securityv1.ValidatedSCCSubjectTypeAnnotation] != pod.Annotations[securityv1.ValidatedSCCSubjectTypeAnnotation] {
+			t.Errorf("%s: expected SCC annotations to be kept", pod.Name)
+		}
+		if want, got := minimumLevelForPod(psaEvaluator, pod), minimumLevelForPod(psaEvaluator, *trimmed); want != got {
+			t.Errorf("%s: expected level %q after trimming, got %q", pod.Name, want, got)
+		}
+	}
+}
+
+func TestTrimPodKeepsHostUsersAndDropsLastApplied(t *testing.T) {
+	psaEvaluator := newPSAEvaluator(t)
+
+	// a root pod in a user namespace is fine for restricted
+	pod := newServiceAccountPod("userns", "ns")
+	pod.Spec.HostUsers = ptr.To(false)
+	pod.Annotations[corev1.LastAppliedConfigAnnotation] = `{"kind":"Pod"}`
+
+	obj, err := trimPod(&pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trimmed := obj.(*corev1.Pod)
+
+	if trimmed.Spec.HostUsers == nil || *trimmed.Spec.HostUsers {
+		t.Errorf("expected hostUsers to be kept")
+	}
+	if _, ok := trimmed.Annotations[corev1.LastAppliedConfigAnnotation]; ok {
+		t.Errorf("expected the last applied configuration to be dropped")
+	}
+	if _, ok := pod.Annotations[corev1.LastAppliedConfigAnnotation]; !ok {
+		t.Errorf("expected the original pod to be left alone")
+	}
+	if got := minimumLevelForPod(psaEvaluator, *trimmed); got != psapi.LevelRestricted {
+		t.Errorf("expected level %q after trimming, got %q", psapi.LevelRestricted, got)
+	}
+}
+
+func TestTrimPodIgnoresOtherObjects(t *testing.T) {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns"}}
+	obj, err := trimPod(ns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj != ns {
+		t.Errorf("expected non-pod objects to be returned as is")
+	}
+}
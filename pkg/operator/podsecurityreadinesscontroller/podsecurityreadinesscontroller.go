@@ -2,26 +2,50 @@ package podsecurityreadinesscontroller
 
 import (
 	"context"
+	"encoding/json"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/selection"
+	corev1informers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
-	"k8s.io/client-go/util/retry"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 	psapi "k8s.io/pod-security-admission/api"
 	"k8s.io/pod-security-admission/policy"
 
+	securityv1informers "github.com/openshift/client-go/security/informers/externalversions/security/v1"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
 )
 
 const (
-	checkInterval = 240 * time.Minute // Adjust the interval as needed.
+	// checkInterval is a safety net only, namespaces are re-evaluated as soon
+	// as they or their pods change.
+	checkInterval = 240 * time.Minute
+
+	// publishDelay batches namespace changes into a single status and report
+	// update, pods churn a lot on big clusters.
+	publishDelay = 30 * time.Second
+
+	// publishQueueKey and the namespace keys can't collide with
+	// factory.DefaultQueueKey nor with each other, underscores and slashes
+	// are not valid in namespace names.
+	publishQueueKey    = "_publish"
+	namespaceKeyPrefix = "namespace/"
 )
 
 // PodSecurityReadinessController checks if namespaces are ready for Pod Security Admission enforcement.
@@ -29,28 +53,32 @@ type PodSecurityReadinessController struct {
 	kubeClient     kubernetes.Interface
 	operatorClient v1helpers.OperatorClient
 
-	warningsHandler   *warningsHandler
-	namespaceSelector string
-	psaEvaluator      policy.Evaluator
+	namespaceLister corev1listers.NamespaceLister
+	podLister       corev1listers.PodLister
+	psaEvaluator    policy.Evaluator
+
+	// resultsLock guards results, the latest evaluation of every violating
+	// namespace, keyed by namespace name.
+	resultsLock sync.Mutex
+	results     map[string]*podSecurityOperatorConditions
+	// published holds the namespaces of the report published before the
+	// operator started, so that a restart only emits events for the
+	// namespaces whose classification changed meanwhile.
+	published map[string]*namespaceReport
+
+	canUseSCC sccAccessFunc
 }
 
+// NewPodSecurityReadinessController expects the namespace informer from
+// NewNamespaceInformers and the pod informer from NewPodInformers.
 func NewPodSecurityReadinessController(
-	kubeConfig *rest.Config,
+	kubeClient kubernetes.Interface,
+	namespaceInformer corev1informers.NamespaceInformer,
+	podInformer corev1informers.PodInformer,
+	sccInformer securityv1informers.SecurityContextConstraintsInformer,
 	operatorClient v1helpers.OperatorClient,
 	recorder events.Recorder,
 ) (factory.Controller, error) {
-	warningsHandler := &warningsHandler{}
-
-	kubeClient, err := newWarningAwareKubeClient(warningsHandler, kubeConfig)
-	if err != nil {
-		return nil, err
-	}
-
-	selector, err := nonEnforcingSelector()
-	if err != nil {
-		return nil, err
-	}
-
 	latestVersion := psapi.LatestVersion()
 
 	psaEvaluator, err := policy.NewEvaluator(policy.DefaultChecks(), &latestVersion)
@@ -59,52 +87,220 @@ func NewPodSecurityReadinessController(
 	}
 
 	c := &PodSecurityReadinessController{
-		operatorClient:    operatorClient,
-		kubeClient:        kubeClient,
-		warningsHandler:   warningsHandler,
-		namespaceSelector: selector,
-		psaEvaluator:      psaEvaluator,
+		operatorClient:  operatorClient,
+		kubeClient:      kubeClient,
+		namespaceLister: namespaceInformer.Lister(),
+		podLister:       podInformer.Lister(),
+		psaEvaluator:    psaEvaluator,
+		results:         map[string]*podSecurityOperatorConditions{},
 	}
+	c.canUseSCC = newSCCAccessChecker(sccInformer.Lister(), kubeClient.AuthorizationV1().SubjectAccessReviews()).canUse
 
 	return factory.New().
 		WithSync(c.sync).
+		WithInformersQueueKeysFunc(namespaceQueueKeys, namespaceInformer.Informer(), podInformer.Informer()).
 		ResyncEvery(checkInterval).
 		ToController("PodSecurityReadinessController", recorder), nil
 }
 
-func (c *PodSecurityReadinessController) sync(ctx context.Context, _ factory.SyncContext) error {
-	nsList, err := c.kubeClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: c.namespaceSelector})
+// namespaceQueueKeys queues the namespace of the changed object. Namespaces
+// are cluster scoped, so for them the name is used. The keys are prefixed, a
+// namespace can be named like factory.DefaultQueueKey.
+func namespaceQueueKeys(obj runtime.Object) []string {
+	metaObj, err := meta.Accessor(obj)
 	if err != nil {
+		return nil
+	}
+	if ns := metaObj.GetNamespace(); ns != "" {
+		return []string{namespaceKeyPrefix + ns}
+	}
+	return []string{namespaceKeyPrefix + metaObj.GetName()}
+}
+
+func (c *PodSecurityReadinessController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	if err := c.loadPublishedReport(ctx); err != nil {
 		return err
 	}
 
-	conditions := podSecurityOperatorConditions{}
-	for _, ns := range nsList.Items {
-		err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
-			isViolating, enforceLevel, err := c.isNamespaceViolating(ctx, &ns)
-			if apierrors.IsNotFound(err) {
-				return nil
-			}
-			if err != nil {
-				return err
-			}
-			if !isViolating {
-				return nil
-			}
-
-			return c.classifyViolatingNamespace(ctx, &conditions, &ns, enforceLevel)
-		})
-		if err != nil {
-			klog.V(2).ErrorS(err, "namespace:", ns.Name)
-
-			conditions.addInconclusive(&ns)
+	switch syncCtx.QueueKey() {
+	case factory.DefaultQueueKey:
+		// Used on start and on resync. Re-evaluating everything only reads
+		// from the informer caches, and it makes sure no event got lost.
+		if err := c.syncAllNamespaces(ctx, syncCtx.Recorder()); err != nil {
+			return err
+		}
+	case publishQueueKey:
+	default:
+		name, ok := strings.CutPrefix(syncCtx.QueueKey(), namespaceKeyPrefix)
+		if !ok {
+			klog.V(2).InfoS("Ignoring unknown queue key", "key", syncCtx.QueueKey())
+			return nil
+		}
+		c.syncNamespace(ctx, name, syncCtx.Recorder())
+		syncCtx.Queue().AddAfter(publishQueueKey, publishDelay)
+		return nil
+	}
+
+	return c.publish(ctx, syncCtx.Recorder())
+}
+
+func (c *PodSecurityReadinessController) syncAllNamespaces(ctx context.Context, recorder events.Recorder) error {
+	nsList, err := c.namespaceLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	results := map[string]*podSecurityOperatorConditions{}
+	for _, ns := range nsList {
+		if nsConditions := c.evaluateNamespace(ctx, ns); nsConditions != nil {
+			results[ns.Name] = nsConditions
+		}
+	}
+
+	c.resultsLock.Lock()
+	defer c.resultsLock.Unlock()
+	for name, nsConditions := range results {
+		c.recordClassificationChange(name, nsConditions, recorder)
+	}
+	c.results = results
+
+	return nil
+}
+
+func (c *PodSecurityReadinessController) syncNamespace(ctx context.Context, name string, recorder events.Recorder) {
+	var nsConditions *podSecurityOperatorConditions
+
+	ns, err := c.namespaceLister.Get(name)
+	switch {
+	case apierrors.IsNotFound(err):
+		// The namespace is gone or is enforcing now.
+	case err != nil:
+		klog.V(2).ErrorS(err, "Failed to get namespace", "namespace", name)
+		return
+	default:
+		nsConditions = c.evaluateNamespace(ctx, ns)
+	}
+
+	c.resultsLock.Lock()
+	defer c.resultsLock.Unlock()
+	if nsConditions == nil {
+		delete(c.results, name)
+		return
+	}
+
+	c.recordClassificationChange(name, nsConditions, recorder)
+	c.results[name] = nsConditions
+}
+
+// recordClassificationChange emits the remediation event of the namespace if
+// its classification changed since the previous evaluation. Only changes are
+// reported, the namespace events queued on start and the resyncs would
+// otherwise emit an event for every violating namespace in the cluster. It
+// expects resultsLock to be held and the results not to be updated yet.
+func (c *PodSecurityReadinessController) recordClassificationChange(name string, nsConditions *podSecurityOperatorConditions, recorder events.Recorder) {
+	nsReport := nsConditions.report.namespaces[name]
+	if nsReport != nil && len(nsReport.Remediations) > 0 && classificationChanged(c.previousReport(name), nsReport) {
+		recorder.Warningf("PodSecurityRemediationSuggested",
+			"Namespace %s needs Pod Security level %q to keep its pods running: %s",
+			name, nsReport.MinimumLevel, strings.Join(nsReport.Remediations, "; "),
+		)
+	}
+}
+
+// previousReport returns the last known report of the namespace, from this
+// run or from the report published before the operator started.
+func (c *PodSecurityReadinessController) previousReport(name string) *namespaceReport {
+	if previous, ok := c.results[name]; ok {
+		return previous.report.namespaces[name]
+	}
+	return c.published[name]
+}
+
+func classificationChanged(previous, current *namespaceReport) bool {
+	return previous == nil ||
+		!slices.Equal(previous.Classifications, current.Classifications) ||
+		!slices.Equal(previous.Remediations, current.Remediations)
+}
+
+// loadPublishedReport reads the published report once, before the first
+// sync.
+func (c *PodSecurityReadinessController) loadPublishedReport(ctx context.Context) error {
+	c.resultsLock.Lock()
+	defer c.resultsLock.Unlock()
+	if c.published != nil {
+		return nil
+	}
+
+	published := map[string]*namespaceReport{}
+	cm, err := c.kubeClient.CoreV1().ConfigMaps(operatorclient.OperatorNamespace).Get(ctx, ReportConfigMapName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return err
+	default:
+		data := podSecurityReportData{}
+		if err := json.Unmarshal([]byte(cm.Data[reportDataKey]), &data); err != nil {
+			// a new report replaces it on the next publish
+			klog.Warningf("Ignoring the published Pod Security readiness report: %v", err)
+		}
+		for _, nsReport := range data.Namespaces {
+			published[nsReport.Name] = nsReport
 		}
 	}
+	c.published = published
+	return nil
+}
+
+// evaluateNamespace returns the conditions of a single namespace, or nil if
+// the namespace is not violating.
+func (c *PodSecurityReadinessController) evaluateNamespace(ctx context.Context, ns *corev1.Namespace) *podSecurityOperatorConditions {
+	conditions := &podSecurityOperatorConditions{}
+
+	isViolating, enforceLevel, err := c.isNamespaceViolating(ns)
+	if err == nil && !isViolating {
+		return nil
+	}
+	if err == nil {
+		err = c.classifyViolatingNamespace(ctx, conditions, ns, enforceLevel)
+	}
+	if err != nil {
+		klog.V(2).ErrorS(err, "namespace:", ns.Name)
+
+		conditions.addInconclusive(ns)
+	}
+
+	return conditions
+}
+
+func (c *PodSecurityReadinessController) publish(ctx context.Context, recorder events.Recorder) error {
+	conditions := podSecurityOperatorConditions{}
+
+	c.resultsLock.Lock()
+	names := make([]string, 0, len(c.results))
+	for name := range c.results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		conditions.merge(c.results[name])
+	}
+	c.resultsLock.Unlock()
 
 	// We expect the Cluster's status conditions to be picked up by the status
 	// controller and push it into the ClusterOperator's status, where it will
 	// be evaluated by the ClusterFleetMechanic.
-	_, _, err = v1helpers.UpdateStatus(ctx, c.operatorClient, conditions.toConditionFuncs()...)
+	_, _, err := v1helpers.UpdateStatus(ctx, c.operatorClient, conditions.toConditionFuncs()...)
+	if err != nil {
+		return err
+	}
+
+	// The conditions only carry namespace names; the report lists the
+	// violating pods and the level each namespace would need.
+	reportConfigMap, err := conditions.report.toConfigMap()
+	if err != nil {
+		return err
+	}
+	_, _, err = resourceapply.ApplyConfigMap(ctx, c.kubeClient.CoreV1(), recorder, reportConfigMap)
 	return err
 }
 
@@ -117,14 +313,3 @@ func nonEnforcingSelector() (string, error) {
 
 	return selector.Add(*labelsRequirement).String(), nil
 }
-
-func newWarningAwareKubeClient(warningsHandler *warningsHandler, kubeConfig *rest.Config) (*kubernetes.Clientset, error) {
-	kubeClientCopy := rest.CopyConfig(kubeConfig)
-	kubeClientCopy.WarningHandler = warningsHandler
-	// We don't want to overwhelm the apiserver with requests. On a cluster with
-	// 10k namespaces, we would send 10k + 1 requests to the apiserver.
-	kubeClientCopy.QPS = 2
-	kubeClientCopy.Burst = 2
-
-	return kubernetes.NewForConfig(kubeClientCopy)
-}
@@ -2,17 +2,26 @@ package podsecurityreadinesscontroller
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	operatorv1 "github.com/openshift/api/operator/v1"
 	securityv1 "github.com/openshift/api/security/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
-	clienttesting "k8s.io/client-go/testing"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 	psapi "k8s.io/pod-security-admission/api"
+	"k8s.io/pod-security-admission/policy"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
 )
 
 func TestPodSecurityViolationController(t *testing.T) {
@@ -84,7 +93,7 @@ func TestPodSecurityViolationController(t *testing.T) {
 	for _, tt := range []struct {
 		name string
 
-		warnings  []string
+		pods      []corev1.Pod
 		namespace *corev1.Namespace
 
 		expectedViolation    bool
@@ -93,10 +102,7 @@ func TestPodSecurityViolationController(t *testing.T) {
 	}{
 		{
 			name: "violating against restricted namespace by sync annotation",
-			warnings: []string{
-				"existing pods in namespace \"violating-namespace\" violate the new PodSecurity enforce level \"restricted:latest\"",
-				"violating-pod: allowPrivilegeEscalation != false, unrestricted capabilities, runAsNonRoot != true, seccompProfile",
-			},
+			pods: []corev1.Pod{newUserSCCPodWithPrivilegedContainer("violating-pod", "violating-namespace")},
 			namespace: &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "violating-namespace",
@@ -112,10 +118,7 @@ func TestPodSecurityViolationController(t *testing.T) {
 		},
 		{
 			name: "violating against restricted namespace by psa label",
-			warnings: []string{
-				"existing pods in namespace \"violating-namespace\" violate the new PodSecurity enforce level \"restricted:latest\"",
-				"violating-pod: allowPrivilegeEscalation != false, unrestricted capabilities, runAsNonRoot != true, seccompProfile",
-			},
+			pods: []corev1.Pod{newUserSCCPodWithPrivilegedContainer("violating-pod", "violating-namespace")},
 			namespace: &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "violating-namespace",
@@ -132,10 +135,7 @@ func TestPodSecurityViolationController(t *testing.T) {
 		},
 		{
 			name: "violating against restricted namespace by sync annotation (taking priority over psa label)",
-			warnings: []string{
-				"existing pods in namespace \"violating-namespace\" violate the new PodSecurity enforce level \"restricted:latest\"",
-				"violating-pod: allowPrivilegeEscalation != false, unrestricted capabilities, runAsNonRoot != true, seccompProfile",
-			},
+			pods: []corev1.Pod{newUserSCCPodWithPrivilegedContainer("violating-pod", "violating-namespace")},
 			namespace: &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "violating-namespace",
@@ -154,8 +154,8 @@ func TestPodSecurityViolationController(t *testing.T) {
 			expectedError:        false,
 		},
 		{
-			name:     "non-violating against restricted namespace by sync annotation (taking priority over psa label)",
-			warnings: []string{},
+			name: "non-violating against restricted namespace by sync annotation (taking priority over psa label)",
+			pods: []corev1.Pod{newServiceAccountPod("root-pod", "violating-namespace")},
 			namespace: &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "violating-namespace",
@@ -175,10 +175,7 @@ func TestPodSecurityViolationController(t *testing.T) {
 		},
 		{
 			name: "violating against baseline namespace by sync annotation",
-			warnings: []string{
-				"existing pods in namespace \"violating-namespace\" violate the new PodSecurity enforce level \"restricted:latest\"",
-				"violating-pod: allowPrivilegeEscalation != false, unrestricted capabilities, runAsNonRoot != true, seccompProfile",
-			},
+			pods: []corev1.Pod{newUserSCCPodWithPrivilegedContainer("violating-pod", "violating-namespace")},
 			namespace: &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "violating-namespace",
@@ -194,10 +191,7 @@ func TestPodSecurityViolationController(t *testing.T) {
 		},
 		{
 			name: "violating against baseline namespace by psa label",
-			warnings: []string{
-				"existing pods in namespace \"violating-namespace\" violate the new PodSecurity enforce level \"restricted:latest\"",
-				"violating-pod: allowPrivilegeEscalation != false, unrestricted capabilities, runAsNonRoot != true, seccompProfile",
-			},
+			pods: []corev1.Pod{newUserSCCPodWithPrivilegedContainer("violating-pod", "violating-namespace")},
 			namespace: &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "violating-namespace",
@@ -213,8 +207,8 @@ func TestPodSecurityViolationController(t *testing.T) {
 			expectedError:        false,
 		},
 		{
-			name:     "non-violating against privileged namespace by sync annotation",
-			warnings: []string{},
+			name: "non-violating against privileged namespace by sync annotation",
+			pods: nil,
 			namespace: &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "violating-namespace",
@@ -229,8 +223,8 @@ func TestPodSecurityViolationController(t *testing.T) {
 			expectedError:        false,
 		},
 		{
-			name:     "non-violating against privileged namespace by psa label",
-			warnings: []string{},
+			name: "non-violating against privileged namespace by psa label",
+			pods: nil,
 			namespace: &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "violating-namespace",
@@ -246,11 +240,10 @@ func TestPodSecurityViolationController(t *testing.T) {
 			expectedError:        false,
 		},
 		{
-			name: "violating against mixed alert labels namespace",
-			warnings: []string{
-				"existing pods in namespace \"violating-namespace\" violate the new PodSecurity enforce level \"restricted:latest\"",
-				"violating-pod: allowPrivilegeEscalation != false, unrestricted capabilities, runAsNonRoot != true, seccompProfile",
-			},
+			// Only the syncer owned audit label counts, the user owned warn
+			// label is ignored.
+			name: "non-violating against mixed alert labels namespace",
+			pods: []corev1.Pod{newUserSCCPodWithPrivilegedContainer("violating-pod", "violating-namespace")},
 			namespace: &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "violating-namespace",
@@ -261,16 +254,13 @@ func TestPodSecurityViolationController(t *testing.T) {
 					ManagedFields: mixedFields,
 				},
 			},
-			expectedViolation:    true,
-			expectedEnforceLabel: "restricted",
+			expectedViolation:    false,
+			expectedEnforceLabel: "privileged",
 			expectedError:        false,
 		},
 		{
 			name: "violating against mixed ownership namespace",
-			warnings: []string{
-				"existing pods in namespace \"violating-namespace\" violate the new PodSecurity enforce level \"restricted:latest\"",
-				"violating-pod: allowPrivilegeEscalation != false, unrestricted capabilities, runAsNonRoot != true, seccompProfile",
-			},
+			pods: []corev1.Pod{newUserSCCPodWithPrivilegedContainer("violating-pod", "violating-namespace")},
 			namespace: &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "violating-namespace",
@@ -285,8 +275,8 @@ func TestPodSecurityViolationController(t *testing.T) {
 			expectedEnforceLabel: "restricted",
 		},
 		{
-			name:     "non violating against mixed ownership namespace",
-			warnings: []string{},
+			name: "non violating against mixed ownership namespace",
+			pods: nil,
 			namespace: &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "violating-namespace",
@@ -302,8 +292,8 @@ func TestPodSecurityViolationController(t *testing.T) {
 			expectedError:        false,
 		},
 		{
-			name:     "non violating against no-ownership namespace",
-			warnings: []string{},
+			name: "non violating against no-ownership namespace",
+			pods: nil,
 			namespace: &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "violating-namespace",
@@ -319,8 +309,8 @@ func TestPodSecurityViolationController(t *testing.T) {
 			expectedError:        false,
 		},
 		{
-			name:     "error against inconclusive namespace",
-			warnings: []string{},
+			name: "error against inconclusive namespace",
+			pods: nil,
 			namespace: &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:          "violating-namespace",
@@ -334,30 +324,12 @@ func TestPodSecurityViolationController(t *testing.T) {
 	} {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			fakeClient := fake.NewSimpleClientset()
-			fakeClient.PrependReactor("patch", "namespaces", func(action clienttesting.Action) (handled bool, ret runtime.Object, err error) {
-				patchAction, ok := action.(clienttesting.PatchAction)
-				if !ok {
-					return false, nil, fmt.Errorf("invalid action type")
-				}
-
-				patchBytes := patchAction.GetPatch()
-				patchMap := make(map[string]interface{})
-				if err := json.Unmarshal(patchBytes, &patchMap); err != nil {
-					return false, nil, fmt.Errorf("failed to unmarshal patch: %v", err)
-				}
-
-				return true, nil, nil
-			})
-
 			controller := &PodSecurityReadinessController{
-				kubeClient: fakeClient,
-				warningsHandler: &warningsHandler{
-					warnings: tt.warnings,
-				},
+				podLister:    newPodLister(t, tt.pods...),
+				psaEvaluator: newPSAEvaluator(t),
 			}
 
-			isViolating, _, err := controller.isNamespaceViolating(context.TODO(), tt.namespace)
+			isViolating, enforceLabel, err := controller.isNamespaceViolating(tt.namespace)
 			if (err != nil) != tt.expectedError {
 				t.Errorf("expected error %v, got %v", tt.expectedError, err)
 			}
@@ -365,6 +337,10 @@ func TestPodSecurityViolationController(t *testing.T) {
 			if isViolating != tt.expectedViolation {
 				t.Errorf("expected violation %v, got %v", tt.expectedViolation, isViolating)
 			}
+
+			if isViolating && string(enforceLabel) != tt.expectedEnforceLabel {
+				t.Errorf("expected enforce label %q, got %q", tt.expectedEnforceLabel, enforceLabel)
+			}
 		})
 	}
 }
@@ -414,3 +390,217 @@ func TestNamespaceSelector(t *testing.T) {
 		}
 	}
 }
+
+func TestSyncIncremental(t *testing.T) {
+	namespaces := []*corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "user-ns"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "clean-ns"}},
+	}
+
+	kubeClient := fake.NewSimpleClientset()
+	operatorClient := v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{}, nil)
+	controller := &PodSecurityReadinessController{
+		kubeClient:      kubeClient,
+		operatorClient:  operatorClient,
+		namespaceLister: newNamespaceLister(t, namespaces...),
+		podLister:       newPodLister(t, newServiceAccountPod("root-pod", "user-ns")),
+		psaEvaluator:    newPSAEvaluator(t),
+		results:         map[string]*podSecurityOperatorConditions{},
+	}
+
+	syncCtx := factory.NewSyncContext("test", events.NewInMemoryRecorder("test", clocktesting.NewFakePassiveClock(time.Now())))
+	requireUnknownCondition := func(expected operatorv1.ConditionStatus) {
+		t.Helper()
+
+		_, status, _, err := operatorClient.GetOperatorState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		condition := v1helpers.FindOperatorCondition(status.Conditions, PodSecurityUnknownType)
+		if condition == nil || condition.Status != expected {
+			t.Fatalf("expected %s to be %s, got %v", PodSecurityUnknownType, expected, condition)
+		}
+	}
+
+	// Start-up evaluates every namespace and publishes right away.
+	if err := controller.sync(context.TODO(), &queueKeySyncContext{SyncContext: syncCtx, queueKey: factory.DefaultQueueKey}); err != nil {
+		t.Fatal(err)
+	}
+	requireUnknownCondition(operatorv1.ConditionTrue)
+	if _, err := kubeClient.CoreV1().ConfigMaps("openshift-kube-apiserver-operator").Get(context.TODO(), ReportConfigMapName, metav1.GetOptions{}); err != nil {
+		t.Fatalf("expected the report to be published: %v", err)
+	}
+
+	// Remediating a namespace only re-evaluates that namespace and defers
+	// publishing.
+	controller.podLister = newPodLister(t, newUserSCCPodRestricted("restricted-pod", "user-ns"))
+	if err := controller.sync(context.TODO(), &queueKeySyncContext{SyncContext: syncCtx, queueKey: namespaceKeyPrefix + "user-ns"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(controller.results) != 0 {
+		t.Fatalf("expected no violating namespaces, got %v", controller.results)
+	}
+	requireUnknownCondition(operatorv1.ConditionTrue)
+
+	if err := controller.sync(context.TODO(), &queueKeySyncContext{SyncContext: syncCtx, queueKey: publishQueueKey}); err != nil {
+		t.Fatal(err)
+	}
+	requireUnknownCondition(operatorv1.ConditionFalse)
+}
+
+func TestSyncNamespaceRemediationEvents(t *testing.T) {
+	controller := &PodSecurityReadinessController{
+		namespaceLister: newNamespaceLister(t, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "user-ns"}}),
+		podLister:       newPodLister(t, newServiceAccountPod("root-pod", "user-ns")),
+		psaEvaluator:    newPSAEvaluator(t),
+		results:         map[string]*podSecurityOperatorConditions{},
+	}
+	recorder := events.NewInMemoryRecorder("test", clocktesting.NewFakePassiveClock(time.Now()))
+
+	controller.syncNamespace(context.TODO(), "user-ns", recorder)
+	controller.syncNamespace(context.TODO(), "user-ns", recorder)
+	if len(recorder.Events()) != 1 {
+		t.Fatalf("expected a single event for unchanged remediations, got %d", len(recorder.Events()))
+	}
+	if event := recorder.Events()[0]; event.Reason != "PodSecurityRemediationSuggested" || !strings.Contains(event.Message, `"anyuid"`) {
+		t.Errorf("unexpected event %s: %s", event.Reason, event.Message)
+	}
+
+	controller.podLister = newPodLister(t, newUserSCCPodWithPrivilegedContainer("privileged-pod", "user-ns"))
+	controller.syncNamespace(context.TODO(), "user-ns", recorder)
+	if len(recorder.Events()) != 2 {
+		t.Fatalf("expected an event for changed remediations, got %d", len(recorder.Events()))
+	}
+}
+
+func TestSyncAllNamespacesRemediationEvents(t *testing.T) {
+	controller := &PodSecurityReadinessController{
+		namespaceLister: newNamespaceLister(t, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "user-ns"}}),
+		podLister:       newPodLister(t, newServiceAccountPod("root-pod", "user-ns")),
+		psaEvaluator:    newPSAEvaluator(t),
+		results:         map[string]*podSecurityOperatorConditions{},
+	}
+	recorder := events.NewInMemoryRecorder("test", clocktesting.NewFakePassiveClock(time.Now()))
+
+	controller.syncNamespace(context.TODO(), "user-ns", recorder)
+	if err := controller.syncAllNamespaces(context.TODO(), recorder); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.Events()) != 1 {
+		t.Fatalf("expected no event for a resync with unchanged remediations, got %d events", len(recorder.Events()))
+	}
+
+	// a change only picked up by a resync is reported too
+	controller.podLister = newPodLister(t, newUserSCCPodWithPrivilegedContainer("privileged-pod", "user-ns"))
+	if err := controller.syncAllNamespaces(context.TODO(), recorder); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.Events()) != 2 {
+		t.Fatalf("expected an event for remediations changed on resync, got %d events", len(recorder.Events()))
+	}
+}
+
+func TestSyncNamespaceRemediationEventsAfterRestart(t *testing.T) {
+	newController := func(kubeClient *fake.Clientset) *PodSecurityReadinessController {
+		return &PodSecurityReadinessController{
+			kubeClient:      kubeClient,
+			operatorClient:  v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{}, nil),
+			namespaceLister: newNamespaceLister(t, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "user-ns"}}),
+			podLister:       newPodLister(t, newServiceAccountPod("root-pod", "user-ns")),
+			psaEvaluator:    newPSAEvaluator(t),
+			results:         map[string]*podSecurityOperatorConditions{},
+		}
+	}
+	recorder := events.NewInMemoryRecorder("test", clocktesting.NewFakePassiveClock(time.Now()))
+
+	// the first run publishes its report
+	kubeClient := fake.NewSimpleClientset()
+	controller := newController(kubeClient)
+	if err := controller.loadPublishedReport(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	controller.syncNamespace(context.TODO(), "user-ns", recorder)
+	if err := controller.publish(context.TODO(), recorder); err != nil {
+		t.Fatal(err)
+	}
+	published, err := kubeClient.CoreV1().ConfigMaps(operatorclient.OperatorNamespace).Get(context.TODO(), ReportConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	eventCount := len(recorder.Events())
+	if eventCount == 0 {
+		t.Fatal("expected an event on the first run")
+	}
+
+	// a restarted operator doesn't repeat the event for an unchanged namespace
+	controller = newController(fake.NewSimpleClientset(published))
+	if err := controller.loadPublishedReport(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	controller.syncNamespace(context.TODO(), "user-ns", recorder)
+	for _, event := range recorder.Events()[eventCount:] {
+		if event.Reason == "PodSecurityRemediationSuggested" {
+			t.Errorf("unexpected event after restart: %s", event.Message)
+		}
+	}
+}
+
+func TestNamespaceQueueKeys(t *testing.T) {
+	if keys := namespaceQueueKeys(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns"}}); !slices.Equal(keys, []string{"namespace/ns"}) {
+		t.Errorf("expected namespace name as key, got %v", keys)
+	}
+	if keys := namespaceQueueKeys(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "ns"}}); !slices.Equal(keys, []string{"namespace/ns"}) {
+		t.Errorf("expected pod namespace as key, got %v", keys)
+	}
+	// a namespace named like the default queue key must not trigger a full resync
+	if keys := namespaceQueueKeys(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: factory.DefaultQueueKey}}); slices.Contains(keys, factory.DefaultQueueKey) {
+		t.Errorf("expected a prefixed key, got %v", keys)
+	}
+}
+
+type queueKeySyncContext struct {
+	factory.SyncContext
+	queueKey string
+}
+
+func (c *queueKeySyncContext) QueueKey() string {
+	return c.queueKey
+}
+
+func newPodLister(t *testing.T, pods ...corev1.Pod) corev1listers.PodLister {
+	t.Helper()
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for i := range pods {
+		if err := indexer.Add(&pods[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return corev1listers.NewPodLister(indexer)
+}
+
+func newNamespaceLister(t *testing.T, namespaces ...*corev1.Namespace) corev1listers.NamespaceLister {
+	t.Helper()
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, ns := range namespaces {
+		if err := indexer.Add(ns); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return corev1listers.NewNamespaceLister(indexer)
+}
+
+func newPSAEvaluator(t *testing.T) policy.Evaluator {
+	t.Helper()
+
+	latestVersion := psapi.LatestVersion()
+	psaEvaluator, err := policy.NewEvaluator(policy.DefaultChecks(), &latestVersion)
+	if err != nil {
+		t.Fatalf("Failed to create PSA evaluator: %v", err)
+	}
+
+	return psaEvaluator
+}
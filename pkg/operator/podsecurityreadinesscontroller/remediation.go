@@ -0,0 +1,79 @@
+package podsecurityreadinesscontroller
+
+import (
+	"context"
+	"fmt"
+
+	securityv1 "github.com/openshift/api/security/v1"
+	corev1 "k8s.io/api/core/v1"
+	psapi "k8s.io/pod-security-admission/api"
+)
+
+// defaultSCCForLevel maps a Pod Security level to the least privileged default
+// SCC that the label syncer translates into that level.
+var defaultSCCForLevel = map[psapi.Level]string{
+	psapi.LevelRestricted: "restricted-v2",
+	psapi.LevelBaseline:   "anyuid",
+	psapi.LevelPrivileged: "privileged",
+}
+
+// remediationHint is the suggested next step for a single violating pod.
+type remediationHint struct {
+	// AdmittedSCC is the SCC the pod was admitted under, if known.
+	AdmittedSCC    string `json:"admittedSCC,omitempty"`
+	SuggestedSCC   string `json:"suggestedSCC"`
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+	Action         string `json:"action"`
+}
+
+// remediationFor suggests how to keep the pod running once its namespace is
+// enforced. The label syncer only considers SCCs available to service
+// accounts, so the usual fix is to grant the SCC to the pod's service
+// account. If the service account can use the SCC already, or the pod was
+// admitted through it, the syncer picked a level for other reasons and the
+// namespace has to be labeled explicitly.
+func remediationFor(ctx context.Context, pod corev1.Pod, level psapi.Level, canUse sccAccessFunc) *remediationHint {
+	hint := &remediationHint{
+		AdmittedSCC:    pod.Annotations[securityv1.ValidatedSCCAnnotation],
+		SuggestedSCC:   suggestedSCC(pod.Annotations[securityv1.ValidatedSCCAnnotation], level),
+		ServiceAccount: pod.Spec.ServiceAccountName,
+	}
+
+	serviceAccount := hint.ServiceAccount
+	if serviceAccount == "" {
+		serviceAccount = "default"
+	}
+
+	if canUse != nil && canUse(ctx, pod.Namespace, serviceAccount, hint.SuggestedSCC) {
+		hint.Action = fmt.Sprintf(
+			"label the namespace with %s=%s, service account %q can use SCC %q already",
+			psapi.EnforceLevelLabel, level, serviceAccount, hint.SuggestedSCC,
+		)
+		return hint
+	}
+
+	if pod.Annotations[securityv1.ValidatedSCCSubjectTypeAnnotation] == "user" {
+		hint.Action = fmt.Sprintf(
+			"grant SCC %q to service account %q so that label syncing picks level %q",
+			hint.SuggestedSCC, serviceAccount, level,
+		)
+		return hint
+	}
+
+	hint.Action = fmt.Sprintf(
+		"label the namespace with %s=%s, or grant SCC %q to service account %q",
+		psapi.EnforceLevelLabel, level, hint.SuggestedSCC, serviceAccount,
+	)
+	return hint
+}
+
+// suggestedSCC prefers the SCC the pod is running under, as it is known to
+// admit the pod. Only the generic privileged SCC is replaced by a less
+// privileged default one when the pod does not need the privileged level.
+func suggestedSCC(admittedSCC string, level psapi.Level) string {
+	if admittedSCC == "" || (admittedSCC == defaultSCCForLevel[psapi.LevelPrivileged] && level != psapi.LevelPrivileged) {
+		return defaultSCCForLevel[level]
+	}
+
+	return admittedSCC
+}
@@ -0,0 +1,157 @@
+package podsecurityreadinesscontroller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	securityv1 "github.com/openshift/api/security/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	psapi "k8s.io/pod-security-admission/api"
+
+	securityv1listers "github.com/openshift/client-go/security/listers/security/v1"
+)
+
+func TestRemediationFor(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		pod      corev1.Pod
+		level    psapi.Level
+		canUse   sccAccessFunc
+		expected *remediationHint
+	}{
+		{
+			name:  "user SCC keeps the admitted SCC",
+			pod:   newSCCPod("user", "hostnetwork-v2", "agent"),
+			level: psapi.LevelPrivileged,
+			expected: &remediationHint{
+				AdmittedSCC:    "hostnetwork-v2",
+				SuggestedSCC:   "hostnetwork-v2",
+				ServiceAccount: "agent",
+				Action:         `grant SCC "hostnetwork-v2" to service account "agent" so that label syncing picks level "privileged"`,
+			},
+		},
+		{
+			name:  "user SCC privileged is narrowed to the needed level",
+			pod:   newSCCPod("user", "privileged", "app"),
+			level: psapi.LevelBaseline,
+			expected: &remediationHint{
+				AdmittedSCC:    "privileged",
+				SuggestedSCC:   "anyuid",
+				ServiceAccount: "app",
+				Action:         `grant SCC "anyuid" to service account "app" so that label syncing picks level "baseline"`,
+			},
+		},
+		{
+			name:  "service account SCC suggests labeling the namespace",
+			pod:   newSCCPod("serviceaccount", "nonroot-v2", ""),
+			level: psapi.LevelBaseline,
+			expected: &remediationHint{
+				AdmittedSCC:  "nonroot-v2",
+				SuggestedSCC: "nonroot-v2",
+				Action:       `label the namespace with pod-security.kubernetes.io/enforce=baseline, or grant SCC "nonroot-v2" to service account "default"`,
+			},
+		},
+		{
+			name:  "service account that can use the SCC already gets the namespace labeled",
+			pod:   newSCCPod("user", "anyuid", "app"),
+			level: psapi.LevelBaseline,
+			canUse: func(ctx context.Context, namespace, serviceAccount, scc string) bool {
+				return namespace == "ns" && serviceAccount == "app" && scc == "anyuid"
+			},
+			expected: &remediationHint{
+				AdmittedSCC:    "anyuid",
+				SuggestedSCC:   "anyuid",
+				ServiceAccount: "app",
+				Action:         `label the namespace with pod-security.kubernetes.io/enforce=baseline, service account "app" can use SCC "anyuid" already`,
+			},
+		},
+		{
+			name:  "unknown SCC falls back to the default for the level",
+			pod:   newSCCPod("", "", "app"),
+			level: psapi.LevelRestricted,
+			expected: &remediationHint{
+				SuggestedSCC:   "restricted-v2",
+				ServiceAccount: "app",
+				Action:         `label the namespace with pod-security.kubernetes.io/enforce=restricted, or grant SCC "restricted-v2" to service account "app"`,
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.expected, remediationFor(context.TODO(), tt.pod, tt.level, tt.canUse)); diff != "" {
+				t.Errorf("unexpected remediation (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSCCAccessChecker(t *testing.T) {
+	sccIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, scc := range []*securityv1.SecurityContextConstraints{
+		{ObjectMeta: metav1.ObjectMeta{Name: "by-user"}, Users: []string{"system:serviceaccount:ns:app"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "by-group"}, Groups: []string{"system:serviceaccounts:ns"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "by-rbac"}},
+	} {
+		if err := sccIndexer.Add(scc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reviews := 0
+	kubeClient := fake.NewSimpleClientset()
+	kubeClient.PrependReactor("create", "subjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		reviews++
+		review := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		review.Status.Allowed = review.Spec.ResourceAttributes.Name == "by-rbac" && review.Spec.User == "system:serviceaccount:ns:app"
+		return true, review, nil
+	})
+	checker := newSCCAccessChecker(securityv1listers.NewSecurityContextConstraintsLister(sccIndexer), kubeClient.AuthorizationV1().SubjectAccessReviews())
+
+	for _, tt := range []struct {
+		serviceAccount, scc string
+		expected            bool
+	}{
+		{"app", "by-user", true},
+		{"other", "by-user", false},
+		{"other", "by-group", true},
+		{"app", "by-rbac", true},
+		{"other", "by-rbac", false},
+		{"app", "missing", false},
+	} {
+		if got := checker.canUse(context.TODO(), "ns", tt.serviceAccount, tt.scc); got != tt.expected {
+			t.Errorf("%s using %s: expected %v, got %v", tt.serviceAccount, tt.scc, tt.expected, got)
+		}
+	}
+	reviewsBefore := reviews
+	checker.canUse(context.TODO(), "ns", "app", "by-rbac")
+	if reviews != reviewsBefore {
+		t.Errorf("expected the RBAC answer to be cached")
+	}
+}
+
+func newSCCPod(subjectType, scc, serviceAccount string) corev1.Pod {
+	annotations := map[string]string{}
+	if subjectType != "" {
+		annotations[securityv1.ValidatedSCCSubjectTypeAnnotation] = subjectType
+	}
+	if scc != "" {
+		annotations[securityv1.ValidatedSCCAnnotation] = scc
+	}
+
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "pod",
+			Namespace:   "ns",
+			Annotations: annotations,
+		},
+		Spec: corev1.PodSpec{
+			ServiceAccountName: serviceAccount,
+		},
+	}
+}
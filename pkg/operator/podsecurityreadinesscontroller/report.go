@@ -0,0 +1,209 @@
+package podsecurityreadinesscontroller
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	psapi "k8s.io/pod-security-admission/api"
+	"k8s.io/pod-security-admission/policy"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+const (
+	// ReportConfigMapName is the name of the ConfigMap in the operator namespace
+	// that holds the detailed Pod Security readiness report.
+	ReportConfigMapName = "pod-security-readiness-report"
+	reportDataKey       = "report.json"
+
+	classificationRunLevelZero   = "RunLevelZero"
+	classificationOpenShift      = "OpenShift"
+	classificationDisabledSyncer = "DisabledSyncer"
+	classificationUserSCC        = "UserSCC"
+	classificationUnclassified   = "Unclassified"
+	classificationInconclusive   = "Inconclusive"
+
+	// maxReportedPodsPerNamespace keeps a namespace with many violating pods
+	// from crowding out the other namespaces of the report.
+	maxReportedPodsPerNamespace = 50
+	// maxReportBytes bounds the report, whatever the number of violating
+	// namespaces, well below the 1MiB limit of a ConfigMap.
+	maxReportBytes = 512 * 1024
+)
+
+// podSecurityReport is the per-namespace counterpart of the aggregated
+// conditions. It is published as JSON so that cluster admins can see which
+// namespaces and pods block restricted enforcement.
+type podSecurityReport struct {
+	namespaces map[string]*namespaceReport
+}
+
+type namespaceReport struct {
+	Name            string   `json:"name"`
+	Classifications []string `json:"classifications"`
+	// EnforceLevel is the level the namespace was tested against.
+	EnforceLevel psapi.Level `json:"enforceLevel,omitempty"`
+	// MinimumLevel is the least privileged level that admits every
+	// violating pod. It is only known for namespaces whose pods were evaluated.
+	MinimumLevel       psapi.Level `json:"minimumLevel,omitempty"`
+	ViolatingPodsCount int         `json:"violatingPodsCount,omitempty"`
+	ViolatingPods      []podReport `json:"violatingPods,omitempty"`
+	// Remediations are the distinct suggested actions for all violating pods.
+	Remediations []string `json:"remediations,omitempty"`
+}
+
+type podReport struct {
+	Name         string             `json:"name"`
+	Owner        *workloadReference `json:"owner,omitempty"`
+	MinimumLevel psapi.Level        `json:"minimumLevel"`
+	Remediation  *remediationHint   `json:"remediation,omitempty"`
+}
+
+type workloadReference struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+type podSecurityReportData struct {
+	Namespaces []*namespaceReport `json:"namespaces"`
+	// Truncated is set when namespaces are left out to keep the report
+	// within maxReportBytes, OmittedNamespaces counts them.
+	Truncated         bool `json:"truncated,omitempty"`
+	OmittedNamespaces int  `json:"omittedNamespaces,omitempty"`
+}
+
+func (r *podSecurityReport) forNamespace(name string) *namespaceReport {
+	if r.namespaces == nil {
+		r.namespaces = map[string]*namespaceReport{}
+	}
+
+	nsReport, ok := r.namespaces[name]
+	if !ok {
+		nsReport = &namespaceReport{Name: name}
+		r.namespaces[name] = nsReport
+	}
+
+	return nsReport
+}
+
+func (r *namespaceReport) addClassification(classification string) {
+	if sets.New(r.Classifications...).Has(classification) {
+		return
+	}
+	r.Classifications = append(r.Classifications, classification)
+	sort.Strings(r.Classifications)
+}
+
+// setViolatingPods records the violating pods together with the minimum level
+// each of them would need and how to get there.
+func (r *namespaceReport) setViolatingPods(ctx context.Context, evaluator policy.Evaluator, canUse sccAccessFunc, pods []corev1.Pod) {
+	r.ViolatingPodsCount = len(pods)
+	r.ViolatingPods = nil
+	r.MinimumLevel = ""
+
+	remediations := sets.New[string]()
+
+	for _, pod := range pods {
+		podLevel := minimumLevelForPod(evaluator, pod)
+		if r.MinimumLevel == "" || psapi.CompareLevels(r.MinimumLevel, podLevel) > 0 {
+			r.MinimumLevel = podLevel
+		}
+
+		remediation := remediationFor(ctx, pod, podLevel, canUse)
+		remediations.Insert(remediation.Action)
+
+		r.ViolatingPods = append(r.ViolatingPods, podReport{
+			Name:         pod.Name,
+			Owner:        owningWorkload(pod),
+			MinimumLevel: podLevel,
+			Remediation:  remediation,
+		})
+	}
+	r.Remediations = sets.List(remediations)
+
+	sort.Slice(r.ViolatingPods, func(i, j int) bool {
+		return r.ViolatingPods[i].Name < r.ViolatingPods[j].Name
+	})
+	if len(r.ViolatingPods) > maxReportedPodsPerNamespace {
+		r.ViolatingPods = r.ViolatingPods[:maxReportedPodsPerNamespace]
+	}
+}
+
+// minimumLevelForPod returns the least privileged Pod Security level that
+// admits the pod.
+func minimumLevelForPod(evaluator policy.Evaluator, pod corev1.Pod) psapi.Level {
+	for _, level := range []psapi.Level{psapi.LevelRestricted, psapi.LevelBaseline} {
+		if !createPodViolationEvaluator(evaluator, level)(pod) {
+			return level
+		}
+	}
+
+	return psapi.LevelPrivileged
+}
+
+// owningWorkload returns the workload that controls the pod. Pods owned by a
+// ReplicaSet are attributed to their Deployment when the ReplicaSet name
+// carries the pod-template-hash suffix, to avoid an extra lookup per pod.
+func owningWorkload(pod corev1.Pod) *workloadReference {
+	owner := metav1.GetControllerOf(&pod)
+	if owner == nil {
+		return nil
+	}
+
+	if owner.Kind == "ReplicaSet" {
+		if hash := pod.Labels["pod-template-hash"]; hash != "" && strings.HasSuffix(owner.Name, "-"+hash) {
+			return &workloadReference{
+				Kind: "Deployment",
+				Name: strings.TrimSuffix(owner.Name, "-"+hash),
+			}
+		}
+	}
+
+	return &workloadReference{Kind: owner.Kind, Name: owner.Name}
+}
+
+func (r *podSecurityReport) toConfigMap() (*corev1.ConfigMap, error) {
+	data := podSecurityReportData{Namespaces: []*namespaceReport{}}
+	for _, nsReport := range r.namespaces {
+		data.Namespaces = append(data.Namespaces, nsReport)
+	}
+	sort.Slice(data.Namespaces, func(i, j int) bool {
+		return data.Namespaces[i].Name < data.Namespaces[j].Name
+	})
+
+	// the namespaces are indented by two levels in the report
+	size := 0
+	for i, nsReport := range data.Namespaces {
+		raw, err := json.MarshalIndent(nsReport, "    ", "  ")
+		if err != nil {
+			return nil, err
+		}
+		size += len(raw) + len("\n    ,")
+		if size > maxReportBytes {
+			data.Truncated = true
+			data.OmittedNamespaces = len(data.Namespaces) - i
+			data.Namespaces = data.Namespaces[:i]
+			break
+		}
+	}
+
+	raw, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ReportConfigMapName,
+			Namespace: operatorclient.OperatorNamespace,
+		},
+		Data: map[string]string{
+			reportDataKey: string(raw),
+		},
+	}, nil
+}
@@ -0,0 +1,238 @@
+package podsecurityreadinesscontroller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	psapi "k8s.io/pod-security-admission/api"
+)
+
+func TestOwningWorkload(t *testing.T) {
+	isController := true
+	for _, tt := range []struct {
+		name     string
+		pod      corev1.Pod
+		expected *workloadReference
+	}{
+		{
+			name:     "bare pod",
+			pod:      corev1.Pod{},
+			expected: nil,
+		},
+		{
+			name: "replicaset owned by deployment",
+			pod: corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"pod-template-hash": "5d8f7b9c4"},
+					OwnerReferences: []metav1.OwnerReference{{
+						Kind:       "ReplicaSet",
+						Name:       "web-5d8f7b9c4",
+						Controller: &isController,
+					}},
+				},
+			},
+			expected: &workloadReference{Kind: "Deployment", Name: "web"},
+		},
+		{
+			name: "standalone replicaset",
+			pod: corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					OwnerReferences: []metav1.OwnerReference{{
+						Kind:       "ReplicaSet",
+						Name:       "web",
+						Controller: &isController,
+					}},
+				},
+			},
+			expected: &workloadReference{Kind: "ReplicaSet", Name: "web"},
+		},
+		{
+			name: "daemonset",
+			pod: corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					OwnerReferences: []metav1.OwnerReference{{
+						Kind:       "DaemonSet",
+						Name:       "agent",
+						Controller: &isController,
+					}},
+				},
+			},
+			expected: &workloadReference{Kind: "DaemonSet", Name: "agent"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.expected, owningWorkload(tt.pod)); diff != "" {
+				t.Errorf("unexpected owner (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMinimumLevelForPod(t *testing.T) {
+	psaEvaluator := newPSAEvaluator(t)
+
+	for _, tt := range []struct {
+		name     string
+		pod      corev1.Pod
+		expected psapi.Level
+	}{
+		{
+			name:     "restricted pod",
+			pod:      newUserSCCPodRestricted("restricted", "ns"),
+			expected: psapi.LevelRestricted,
+		},
+		{
+			name:     "run as root pod",
+			pod:      newServiceAccountPod("baseline", "ns"),
+			expected: psapi.LevelBaseline,
+		},
+		{
+			name:     "privileged pod",
+			pod:      newUserSCCPodWithPrivilegedContainer("privileged", "ns"),
+			expected: psapi.LevelPrivileged,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if level := minimumLevelForPod(psaEvaluator, tt.pod); level != tt.expected {
+				t.Errorf("expected level %q, got %q", tt.expected, level)
+			}
+		})
+	}
+}
+
+func TestReportFromClassification(t *testing.T) {
+	psaEvaluator := newPSAEvaluator(t)
+
+	controller := &PodSecurityReadinessController{
+		podLister: newPodLister(t,
+			newUserSCCPodWithPrivilegedContainer("privileged", "user-ns"),
+			newServiceAccountPod("root", "user-ns"),
+		),
+		psaEvaluator: psaEvaluator,
+	}
+
+	conditions := podSecurityOperatorConditions{}
+	for _, ns := range []string{"user-ns", "openshift-test", "kube-system"} {
+		err := controller.classifyViolatingNamespace(
+			context.TODO(),
+			&conditions,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}},
+			psapi.LevelRestricted,
+		)
+		if err != nil {
+			t.Fatalf("unexpected error classifying %q: %v", ns, err)
+		}
+	}
+	conditions.addInconclusive(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "unreachable"}})
+
+	cm, err := conditions.report.toConfigMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cm.Name != ReportConfigMapName || cm.Namespace != "openshift-kube-apiserver-operator" {
+		t.Errorf("unexpected ConfigMap %s/%s", cm.Namespace, cm.Name)
+	}
+
+	report := podSecurityReportData{}
+	if err := json.Unmarshal([]byte(cm.Data[reportDataKey]), &report); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := podSecurityReportData{
+		Namespaces: []*namespaceReport{
+			{
+				Name:            "kube-system",
+				Classifications: []string{classificationRunLevelZero},
+				EnforceLevel:    psapi.LevelRestricted,
+			},
+			{
+				Name:            "openshift-test",
+				Classifications: []string{classificationOpenShift},
+				EnforceLevel:    psapi.LevelRestricted,
+			},
+			{
+				Name:            "unreachable",
+				Classifications: []string{classificationInconclusive},
+			},
+			{
+				Name:               "user-ns",
+				Classifications:    []string{classificationUnclassified, classificationUserSCC},
+				EnforceLevel:       psapi.LevelRestricted,
+				MinimumLevel:       psapi.LevelPrivileged,
+				ViolatingPodsCount: 2,
+				ViolatingPods: []podReport{
+					{
+						Name:         "privileged",
+						MinimumLevel: psapi.LevelPrivileged,
+						Remediation: &remediationHint{
+							SuggestedSCC: "privileged",
+							Action:       `grant SCC "privileged" to service account "default" so that label syncing picks level "privileged"`,
+						},
+					},
+					{
+						Name:         "root",
+						MinimumLevel: psapi.LevelBaseline,
+						Remediation: &remediationHint{
+							SuggestedSCC: "anyuid",
+							Action:       `label the namespace with pod-security.kubernetes.io/enforce=baseline, or grant SCC "anyuid" to service account "default"`,
+						},
+					},
+				},
+				Remediations: []string{
+					`grant SCC "privileged" to service account "default" so that label syncing picks level "privileged"`,
+					`label the namespace with pod-security.kubernetes.io/enforce=baseline, or grant SCC "anyuid" to service account "default"`,
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, report); diff != "" {
+		t.Errorf("unexpected report (-want +got):\n%s", diff)
+	}
+}
+
+func TestReportTruncatedToBudget(t *testing.T) {
+	psaEvaluator := newPSAEvaluator(t)
+
+	var pods []corev1.Pod
+	for i := 0; i < maxReportedPodsPerNamespace; i++ {
+		pods = append(pods, newUserSCCPodWithPrivilegedContainer(fmt.Sprintf("privileged-pod-with-a-long-name-%d", i), "ns"))
+	}
+	report := &podSecurityReport{}
+	const namespaces = 500
+	for i := 0; i < namespaces; i++ {
+		nsReport := report.forNamespace(fmt.Sprintf("user-namespace-%03d", i))
+		nsReport.addClassification(classificationUserSCC)
+		nsReport.setViolatingPods(context.TODO(), psaEvaluator, nil, pods)
+	}
+
+	cm, err := report.toConfigMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := cm.Data[reportDataKey]
+	if len(raw) > maxReportBytes {
+		t.Errorf("expected the report to be at most %d bytes, got %d", maxReportBytes, len(raw))
+	}
+
+	data := podSecurityReportData{}
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		t.Fatal(err)
+	}
+	if !data.Truncated {
+		t.Error("expected the report to be marked as truncated")
+	}
+	if len(data.Namespaces) == 0 {
+		t.Fatal("expected the report to keep the first namespaces")
+	}
+	if reported := len(data.Namespaces) + data.OmittedNamespaces; reported != namespaces {
+		t.Errorf("expected %d reported and omitted namespaces, got %d reported and %d omitted", namespaces, len(data.Namespaces), data.OmittedNamespaces)
+	}
+	if data.Namespaces[0].Name != "user-namespace-000" {
+		t.Errorf("expected the namespaces to be kept in order, got %q first", data.Namespaces[0].Name)
+	}
+}
@@ -0,0 +1,103 @@
+package podsecurityreadinesscontroller
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/apiserver/pkg/authentication/user"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+
+	securityv1listers "github.com/openshift/client-go/security/listers/security/v1"
+)
+
+// sccAccessFunc tells whether the service account of the namespace can
+// already use the SCC.
+type sccAccessFunc func(ctx context.Context, namespace, serviceAccount, scc string) bool
+
+// sccAccessChecker answers like SCC admission does: a service account can use
+// an SCC listing it or one of its groups, or through the RBAC "use" verb. The
+// RBAC answers are cached for checkInterval, the hints are recomputed on every
+// pod change.
+type sccAccessChecker struct {
+	sccLister securityv1listers.SecurityContextConstraintsLister
+	sarClient authorizationv1client.SubjectAccessReviewInterface
+	clock     clock.PassiveClock
+
+	lock  sync.Mutex
+	cache map[string]cachedSCCAccess
+}
+
+type cachedSCCAccess struct {
+	allowed bool
+	expiry  time.Time
+}
+
+func newSCCAccessChecker(sccLister securityv1listers.SecurityContextConstraintsLister, sarClient authorizationv1client.SubjectAccessReviewInterface) *sccAccessChecker {
+	return &sccAccessChecker{
+		sccLister: sccLister,
+		sarClient: sarClient,
+		clock:     clock.RealClock{},
+		cache:     map[string]cachedSCCAccess{},
+	}
+}
+
+// canUse reports false when it can't tell, the hint then suggests to grant
+// the SCC as before.
+func (c *sccAccessChecker) canUse(ctx context.Context, namespace, serviceAccount, sccName string) bool {
+	scc, err := c.sccLister.Get(sccName)
+	if err != nil {
+		klog.V(2).ErrorS(err, "Failed to get SCC", "scc", sccName)
+		return false
+	}
+	username := serviceaccount.MakeUsername(namespace, serviceAccount)
+	groups := append(serviceaccount.MakeGroupNames(namespace), user.AllAuthenticated)
+	if slices.Contains(scc.Users, username) || slices.ContainsFunc(scc.Groups, func(group string) bool { return slices.Contains(groups, group) }) {
+		return true
+	}
+
+	key := namespace + "/" + serviceAccount + "/" + sccName
+	c.lock.Lock()
+	cached, ok := c.cache[key]
+	c.lock.Unlock()
+	if ok && c.clock.Now().Before(cached.expiry) {
+		return cached.allowed
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	review, err := c.sarClient.Create(ctx, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   username,
+			Groups: groups,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      "use",
+				Group:     "security.openshift.io",
+				Resource:  "securitycontextconstraints",
+				Name:      sccName,
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		klog.V(2).ErrorS(err, "Failed to check SCC access", "namespace", namespace, "serviceAccount", serviceAccount, "scc", sccName)
+		return false
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	now := c.clock.Now()
+	for cachedKey, cached := range c.cache {
+		if !now.Before(cached.expiry) {
+			delete(c.cache, cachedKey)
+		}
+	}
+	c.cache[key] = cachedSCCAccess{allowed: review.Status.Allowed, expiry: now.Add(checkInterval)}
+	return review.Status.Allowed
+}
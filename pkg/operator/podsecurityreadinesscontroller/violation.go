@@ -1,11 +1,9 @@
 package podsecurityreadinesscontroller
 
 import (
-	"context"
-
 	securityv1 "github.com/openshift/api/security/v1"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
 	applyconfiguration "k8s.io/client-go/applyconfigurations/core/v1"
 	"k8s.io/klog/v2"
@@ -19,33 +17,26 @@ const (
 var alertLabels = sets.New(psapi.WarnLevelLabel, psapi.AuditLevelLabel)
 
 // isNamespaceViolating checks if a namespace is ready for Pod Security Admission enforcement.
-// It returns true if the namespace is violating the Pod Security Admission policy, along with
-// the enforce label it was tested against.
-func (c *PodSecurityReadinessController) isNamespaceViolating(ctx context.Context, ns *corev1.Namespace) (bool, psapi.Level, error) {
+// It returns true if any pod in the namespace violates the enforce level the
+// syncer would set, along with that level.
+func (c *PodSecurityReadinessController) isNamespaceViolating(ns *corev1.Namespace) (bool, psapi.Level, error) {
 	nsApplyConfig, err := applyconfiguration.ExtractNamespace(ns, syncerControllerName)
 	if err != nil {
 		return false, "", err
 	}
 
 	enforceLabel := determineEnforceLabelForNamespace(nsApplyConfig)
-	nsApply := applyconfiguration.Namespace(ns.Name).WithLabels(map[string]string{
-		psapi.EnforceLevelLabel: string(enforceLabel),
-	})
-
-	_, err = c.kubeClient.CoreV1().
-		Namespaces().
-		Apply(ctx, nsApply, metav1.ApplyOptions{
-			DryRun:       []string{metav1.DryRunAll},
-			FieldManager: "pod-security-readiness-controller",
-		})
+
+	pods, err := c.podLister.Pods(ns.Name).List(labels.Everything())
 	if err != nil {
 		return false, "", err
 	}
 
-	// If there are warnings, the namespace is violating.
-	warnings := c.warningsHandler.PopAll()
-	if len(warnings) > 0 {
-		return true, enforceLabel, nil
+	isViolating := createPodViolationEvaluator(c.psaEvaluator, enforceLabel)
+	for _, pod := range pods {
+		if isViolating(*pod) {
+			return true, enforceLabel, nil
+		}
 	}
 
 	return false, "", nil
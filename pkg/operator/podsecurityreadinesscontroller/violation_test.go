@@ -1,16 +1,12 @@
 package podsecurityreadinesscontroller
 
 import (
-	"context"
 	"fmt"
 	"testing"
 
 	securityv1 "github.com/openshift/api/security/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	applyconfiguration "k8s.io/client-go/applyconfigurations/core/v1"
-	"k8s.io/client-go/kubernetes"
-	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	psapi "k8s.io/pod-security-admission/api"
 )
 
@@ -36,8 +32,7 @@ func TestIsNamespaceViolating(t *testing.T) {
 	tests := []struct {
 		name            string
 		namespace       *corev1.Namespace
-		warnings        []string
-		setupMockClient func() kubernetes.Interface
+		pods            []corev1.Pod
 		expectViolating bool
 		expectError     bool
 	}{
@@ -51,10 +46,7 @@ func TestIsNamespaceViolating(t *testing.T) {
 					},
 				},
 			},
-			warnings: []string{},
-			setupMockClient: func() kubernetes.Interface {
-				return &mockKubeClientWithResponse{}
-			},
+			pods:            []corev1.Pod{newUserSCCPodRestricted("restricted-pod", "test-ns-1")},
 			expectViolating: false,
 			expectError:     false,
 		},
@@ -68,63 +60,62 @@ func TestIsNamespaceViolating(t *testing.T) {
 					},
 				},
 			},
-			warnings: []string{"violation found"},
-			setupMockClient: func() kubernetes.Interface {
-				return &mockKubeClientWithResponse{}
-			},
+			pods:            []corev1.Pod{newServiceAccountPod("root-pod", "test-ns-2")},
 			expectViolating: true,
 			expectError:     false,
 		},
 		{
-			name: "namespace with no annotation",
+			name: "namespace with baseline annotation and a pod violating restricted only",
 			namespace: &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:   "test-ns-5",
-					Labels: map[string]string{},
+					Name: "test-ns-3",
+					Annotations: map[string]string{
+						securityv1.MinimallySufficientPodSecurityStandard: "baseline",
+					},
 				},
 			},
-			warnings: []string{},
-			setupMockClient: func() kubernetes.Interface {
-				return &mockKubeClientWithResponse{}
-			},
+			pods:            []corev1.Pod{newServiceAccountPod("root-pod", "test-ns-3")},
 			expectViolating: false,
 			expectError:     false,
 		},
 		{
-			name: "Apply returns error",
+			name: "violating pods in other namespaces are ignored",
 			namespace: &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
-					Name: "test-ns-6",
+					Name: "test-ns-4",
 					Annotations: map[string]string{
 						securityv1.MinimallySufficientPodSecurityStandard: "restricted",
 					},
 				},
 			},
-			warnings: []string{},
-			setupMockClient: func() kubernetes.Interface {
-				return &mockKubeClientWithResponse{
-					error: fmt.Errorf("apply error"),
-				}
-			},
+			pods:            []corev1.Pod{newServiceAccountPod("root-pod", "other-ns")},
 			expectViolating: false,
-			expectError:     true,
+			expectError:     false,
+		},
+		{
+			name: "namespace with no annotation",
+			namespace: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "test-ns-5",
+					Labels: map[string]string{},
+				},
+			},
+			pods:            []corev1.Pod{newServiceAccountPod("root-pod", "test-ns-5")},
+			expectViolating: true,
+			expectError:     false,
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			mockWarnings := &warningsHandler{
-				warnings: tc.warnings,
-			}
-
 			controller := &PodSecurityReadinessController{
-				kubeClient:      tc.setupMockClient(),
-				warningsHandler: mockWarnings,
+				podLister:    newPodLister(t, tc.pods...),
+				psaEvaluator: newPSAEvaluator(t),
 			}
 
 			tc.namespace.ManagedFields = managedFields
 
-			violating, _, err := controller.isNamespaceViolating(context.Background(), tc.namespace)
+			violating, _, err := controller.isNamespaceViolating(tc.namespace)
 
 			if (err != nil) != tc.expectError {
 				t.Errorf("isNamespaceViolating() error = %v, expectError %v", err, tc.expectError)
@@ -137,30 +128,3 @@ func TestIsNamespaceViolating(t *testing.T) {
 		})
 	}
 }
-
-type mockKubeClientWithResponse struct {
-	kubernetes.Interface
-	error error
-}
-
-func (m *mockKubeClientWithResponse) CoreV1() typedcorev1.CoreV1Interface {
-	return &mockCoreV1WithResponse{error: m.error}
-}
-
-type mockCoreV1WithResponse struct {
-	typedcorev1.CoreV1Interface
-	error error
-}
-
-func (m *mockCoreV1WithResponse) Namespaces() typedcorev1.NamespaceInterface {
-	return &mockNamespaceInterfaceWithResponse{error: m.error}
-}
-
-type mockNamespaceInterfaceWithResponse struct {
-	typedcorev1.NamespaceInterface
-	error error
-}
-
-func (m *mockNamespaceInterfaceWithResponse) Apply(ctx context.Context, nsApply *applyconfiguration.NamespaceApplyConfiguration, opts metav1.ApplyOptions) (*corev1.Namespace, error) {
-	return nil, m.error
-}
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
 	configinformers "github.com/openshift/client-go/config/informers/externalversions"
@@ -16,6 +18,9 @@ import (
 	operatorlistersv1 "github.com/openshift/client-go/operator/listers/operator/v1"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
 )
 
 const (
@@ -41,21 +46,31 @@ type ServiceAccountIssuerController struct {
 	kubeAPIServerOperatorClient operatorv1client.KubeAPIServerInterface
 	authLister                  configlistersv1.AuthenticationLister
 	kubeAPIserverOperatorLister operatorlistersv1.KubeAPIServerLister
+	configMapLister             corev1listers.ConfigMapLister
+
+	// awaitingRemoval are the issuers that are no longer trusted, but may
+	// still be accepted by a kube-apiserver that didn't roll out yet.
+	awaitingRemoval sets.Set[string]
 
 	// unit testing
 	nowFn func() time.Time
 }
 
-func NewController(kubeAPIServerOperatorClient operatorv1client.KubeAPIServerInterface, operatorInformers operatorinformers.SharedInformerFactory, configInformer configinformers.SharedInformerFactory, eventRecorder events.Recorder) factory.Controller {
+func NewController(kubeAPIServerOperatorClient operatorv1client.KubeAPIServerInterface, operatorInformers operatorinformers.SharedInformerFactory, configInformer configinformers.SharedInformerFactory, kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces, eventRecorder events.Recorder) factory.Controller {
+	RegisterMetrics()
+
 	var ret = &ServiceAccountIssuerController{
 		nowFn:                       time.Now,
 		kubeAPIServerOperatorClient: kubeAPIServerOperatorClient,
 		authLister:                  configInformer.Config().V1().Authentications().Lister(),
 		kubeAPIserverOperatorLister: operatorInformers.Operator().V1().KubeAPIServers().Lister(),
+		configMapLister:             kubeInformersForNamespaces.ConfigMapLister(),
+		awaitingRemoval:             sets.New[string](),
 	}
 	return factory.New().WithInformers(
 		operatorInformers.Operator().V1().KubeAPIServers().Informer(),
 		configInformer.Config().V1().Authentications().Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().ConfigMaps().Informer(),
 	).ResyncEvery(60*time.Second).WithSync(ret.sync).ToController("ServiceAccountIssuerController", eventRecorder)
 }
 
@@ -64,13 +79,24 @@ func (c *ServiceAccountIssuerController) sync(ctx context.Context, controllerCon
 	if err != nil {
 		return err
 	}
-	desiredIssuer := authConfig.Spec.ServiceAccountIssuer
 
 	operator, err := c.kubeAPIserverOperatorLister.Get("cluster")
 	if err != nil {
 		return err
 	}
 
+	if err := c.revokeTrustedIssuers(ctx, controllerContext.Recorder(), operator); err != nil {
+		return err
+	}
+	if err := c.syncIssuers(ctx, controllerContext, authConfig.Spec.ServiceAccountIssuer, operator); err != nil {
+		return err
+	}
+	return c.reportTrustedIssuers(ctx, operator, controllerContext.Recorder())
+}
+
+// syncIssuers makes the desired issuer active and prunes the expired trusted
+// issuers. It returns factory.SyntheticRequeueError when it updated the status.
+func (c *ServiceAccountIssuerController) syncIssuers(ctx context.Context, controllerContext factory.SyncContext, desiredIssuer string, operator *operatorv1.KubeAPIServer) error {
 	// this is a case when issuer is not set in auth config and the operator status already has the default issuer set.
 	if isDefaultServiceAccountIssuer(desiredIssuer, operator.Status.ServiceAccountIssuers) {
 		return nil
@@ -93,6 +119,7 @@ func (c *ServiceAccountIssuerController) sync(ctx context.Context, controllerCon
 	if !issuerChanged {
 		if pruned, err := c.pruneExpiredServiceAccountIssuers(ctx, operator); err != nil {
 			if err == factory.SyntheticRequeueError {
+				c.awaitRemoval(pruned...)
 				controllerContext.Recorder().Eventf("ServiceAccountIssuer",
 					"The following service account issuers were pruned and are no longer trusted: %s", strings.Join(pruned, ","),
 				)
@@ -0,0 +1,271 @@
+package serviceaccountissuercontroller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+const (
+	// RevokeTrustedIssuersAnnotation on the KubeAPIServer revokes the trust in
+	// the listed previous service account issuers right away, instead of when
+	// they expire. Its value is a comma separated list of issuers, or "*" for
+	// all of them. The annotation is removed once the issuers are revoked.
+	RevokeTrustedIssuersAnnotation = "kubeapiservers.operator.openshift.io/revoke-trusted-service-account-issuers"
+
+	trustedIssuersConditionType = "TrustedServiceAccountIssuers"
+)
+
+var (
+	registerMetrics sync.Once
+
+	// recordedIssuerLabelsLock guards recordedTrustedIssuers and
+	// recordedIssuersAwaitingRemoval, the issuer label values of the series
+	// currently set.
+	recordedIssuerLabelsLock       sync.Mutex
+	recordedTrustedIssuers         = sets.New[string]()
+	recordedIssuersAwaitingRemoval = sets.New[string]()
+
+	trustedIssuerExpirationGauge = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Name: "openshift_kube_apiserver_trusted_service_account_issuer_expiration_timestamp_seconds",
+		Help: "Reports when a previous service account issuer that is still trusted expires, tokens it issued are accepted until then",
+	}, []string{"issuer"})
+
+	issuerAwaitingRemovalGauge = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Name: "openshift_kube_apiserver_service_account_issuer_awaiting_removal",
+		Help: "Reports 1 for a service account issuer that expired or was revoked, but is still accepted by the kube-apiserver of a control plane node",
+	}, []string{"issuer"})
+)
+
+// RegisterMetrics registers the metrics of the trusted service account
+// issuers.
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(trustedIssuerExpirationGauge)
+		legacyregistry.MustRegister(issuerAwaitingRemovalGauge)
+	})
+}
+
+// revokeTrustedIssuers removes the previous issuers listed in the revoke
+// annotation from the trusted issuers, and then the annotation. The active
+// issuer can't be revoked, it changes with the authentication config only. It
+// returns factory.SyntheticRequeueError when it updated the KubeAPIServer.
+func (c *ServiceAccountIssuerController) revokeTrustedIssuers(ctx context.Context, recorder events.Recorder, operator *operatorv1.KubeAPIServer) error {
+	value, ok := operator.Annotations[RevokeTrustedIssuersAnnotation]
+	if !ok {
+		return nil
+	}
+	all := strings.TrimSpace(value) == "*"
+	requested := sets.New[string]()
+	for _, issuer := range strings.Split(value, ",") {
+		if issuer = strings.TrimSpace(issuer); len(issuer) > 0 && issuer != "*" {
+			requested.Insert(issuer)
+		}
+	}
+
+	var kept []operatorv1.ServiceAccountIssuerStatus
+	var revoked []string
+	for _, issuer := range operator.Status.ServiceAccountIssuers {
+		if issuer.ExpirationTime != nil && (all || requested.Has(issuer.Name)) {
+			revoked = append(revoked, issuer.Name)
+			continue
+		}
+		kept = append(kept, issuer)
+	}
+	if ignored := sets.List(requested.Delete(revoked...)); len(ignored) > 0 {
+		recorder.Warningf("ServiceAccountIssuerRevocationIgnored", "Not revoking %s: only trusted previous service account issuers can be revoked, not the active one", quoteAll(ignored))
+	}
+
+	if len(revoked) > 0 {
+		operatorCopy := operator.DeepCopy()
+		operatorCopy.Status.ServiceAccountIssuers = kept
+		if _, err := c.kubeAPIServerOperatorClient.UpdateStatus(ctx, operatorCopy, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+		c.awaitRemoval(revoked...)
+		recorder.Warningf("ServiceAccountIssuerRevoked", "Revoked the trust in the service account issuers %s, the tokens they issued are rejected once the kube-apiserver rolled out without them", quoteAll(revoked))
+	}
+
+	// a later issuer of the same name must not be revoked by the same annotation
+	latest, err := c.kubeAPIServerOperatorClient.Get(ctx, operator.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	latest = latest.DeepCopy()
+	delete(latest.Annotations, RevokeTrustedIssuersAnnotation)
+	if _, err := c.kubeAPIServerOperatorClient.Update(ctx, latest, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	return factory.SyntheticRequeueError
+}
+
+// reportTrustedIssuers reports every trusted previous issuer with its expiry,
+// and every issuer that is no longer trusted but still accepted by the
+// kube-apiserver of a node, in a condition and in metrics. An event tells when
+// such an issuer is no longer accepted by any node.
+func (c *ServiceAccountIssuerController) reportTrustedIssuers(ctx context.Context, operator *operatorv1.KubeAPIServer, recorder events.Recorder) error {
+	var trusted []operatorv1.ServiceAccountIssuerStatus
+	known := sets.New[string]()
+	for _, issuer := range operator.Status.ServiceAccountIssuers {
+		known.Insert(issuer.Name)
+		if issuer.ExpirationTime != nil {
+			trusted = append(trusted, issuer)
+		}
+	}
+	slices.SortStableFunc(trusted, func(a, b operatorv1.ServiceAccountIssuerStatus) int {
+		return a.ExpirationTime.Time.Compare(b.ExpirationTime.Time)
+	})
+
+	accepted, complete, err := c.issuersOnNodes(operator.Status.NodeStatuses)
+	if err != nil {
+		return err
+	}
+	if c.awaitingRemoval == nil {
+		c.awaitingRemoval = sets.New[string]()
+	}
+	c.awaitingRemoval.Insert(accepted.Difference(known).UnsortedList()...)
+	if complete {
+		var removed []string
+		for _, issuer := range sets.List(c.awaitingRemoval) {
+			if !accepted.Has(issuer) && !known.Has(issuer) {
+				removed = append(removed, issuer)
+			}
+		}
+		if len(removed) > 0 {
+			recorder.Eventf("ServiceAccountIssuerRemoved", "The service account issuers %s are no longer accepted by the kube-apiserver on any node, the tokens they issued are rejected", quoteAll(removed))
+		}
+		c.awaitingRemoval.Delete(removed...)
+	}
+	// an issuer that is trusted or active again isn't awaiting removal
+	c.awaitingRemoval.Delete(known.UnsortedList()...)
+	awaitingRemoval := sets.List(c.awaitingRemoval)
+
+	recordIssuerMetrics(trusted, awaitingRemoval)
+
+	condition := operatorv1.OperatorCondition{
+		Type:    trustedIssuersConditionType,
+		Status:  operatorv1.ConditionFalse,
+		Reason:  "AsExpected",
+		Message: fmt.Sprintf("Only tokens of the active service account issuer %q are accepted.", getActiveServiceAccountIssuer(operator.Status.ServiceAccountIssuers)),
+	}
+	var messages []string
+	for _, issuer := range trusted {
+		messages = append(messages, fmt.Sprintf("Tokens of the previous service account issuer %q are accepted until %s.", issuer.Name, issuer.ExpirationTime.UTC().Format(time.RFC3339)))
+	}
+	if len(awaitingRemoval) > 0 {
+		messages = append(messages, fmt.Sprintf("The service account issuers %s are no longer trusted, their tokens are accepted until the kube-apiserver rolled out without them.", quoteAll(awaitingRemoval)))
+	}
+	switch {
+	case len(trusted) > 0:
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "PreviousIssuersTrusted"
+		condition.Message = strings.Join(messages, "\n")
+	case len(awaitingRemoval) > 0:
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "AwaitingIssuerRemoval"
+		condition.Message = strings.Join(messages, "\n")
+	}
+
+	operatorCopy := operator.DeepCopy()
+	v1helpers.SetOperatorCondition(&operatorCopy.Status.Conditions, condition)
+	if equality.Semantic.DeepEqual(operator.Status.Conditions, operatorCopy.Status.Conditions) {
+		return nil
+	}
+	_, err = c.kubeAPIServerOperatorClient.UpdateStatus(ctx, operatorCopy, metav1.UpdateOptions{})
+	return err
+}
+
+// issuersOnNodes returns the service account issuers in the config of the
+// current revisions of the nodes. It also indicates whether the config of
+// every revision is known.
+func (c *ServiceAccountIssuerController) issuersOnNodes(nodeStatuses []operatorv1.NodeStatus) (sets.Set[string], bool, error) {
+	issuers := sets.New[string]()
+	complete := len(nodeStatuses) > 0
+	revisions := sets.New[int32]()
+	for _, nodeStatus := range nodeStatuses {
+		revisions.Insert(nodeStatus.CurrentRevision)
+	}
+	for _, revision := range sets.List(revisions) {
+		configMap, err := c.configMapLister.ConfigMaps(operatorclient.TargetNamespace).Get(fmt.Sprintf("config-%d", revision))
+		if apierrors.IsNotFound(err) {
+			complete = false
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		config := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(configMap.Data["config.yaml"]), &config); err != nil {
+			return nil, false, fmt.Errorf("failed to unmarshal configmap/%s: %w", configMap.Name, err)
+		}
+		values, _, err := unstructured.NestedStringSlice(config, "apiServerArguments", "service-account-issuer")
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to read the service account issuers of configmap/%s: %w", configMap.Name, err)
+		}
+		issuers.Insert(values...)
+	}
+	return issuers, complete, nil
+}
+
+// awaitRemoval records issuers that are no longer trusted, they are reported
+// until no kube-apiserver accepts them anymore.
+func (c *ServiceAccountIssuerController) awaitRemoval(issuers ...string) {
+	if c.awaitingRemoval == nil {
+		c.awaitingRemoval = sets.New[string]()
+	}
+	c.awaitingRemoval.Insert(issuers...)
+}
+
+func quoteAll(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, fmt.Sprintf("%q", value))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// recordIssuerMetrics sets the per-issuer gauges and deletes the series of the
+// issuers no longer reported, without a window in which a scrape sees no
+// series at all.
+func recordIssuerMetrics(trusted []operatorv1.ServiceAccountIssuerStatus, awaitingRemoval []string) {
+	recordedIssuerLabelsLock.Lock()
+	defer recordedIssuerLabelsLock.Unlock()
+
+	currentTrusted := sets.New[string]()
+	for _, issuer := range trusted {
+		trustedIssuerExpirationGauge.WithLabelValues(issuer.Name).Set(float64(issuer.ExpirationTime.Unix()))
+		currentTrusted.Insert(issuer.Name)
+	}
+	for _, issuer := range recordedTrustedIssuers.Difference(currentTrusted).UnsortedList() {
+		trustedIssuerExpirationGauge.DeleteLabelValues(issuer)
+	}
+	recordedTrustedIssuers = currentTrusted
+
+	currentAwaitingRemoval := sets.New(awaitingRemoval...)
+	for _, issuer := range awaitingRemoval {
+		issuerAwaitingRemovalGauge.WithLabelValues(issuer).Set(1)
+	}
+	for _, issuer := range recordedIssuersAwaitingRemoval.Difference(currentAwaitingRemoval).UnsortedList() {
+		issuerAwaitingRemovalGauge.DeleteLabelValues(issuer)
+	}
+	recordedIssuersAwaitingRemoval = currentAwaitingRemoval
+}
@@ -0,0 +1,257 @@
+package serviceaccountissuercontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/component-base/metrics/testutil"
+	"k8s.io/utils/clock"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	fakeclient "github.com/openshift/client-go/operator/clientset/versioned/fake"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+func TestReportTrustedIssuers(t *testing.T) {
+	RegisterMetrics()
+	expiration := metav1.NewTime(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	operator := &operatorv1.KubeAPIServer{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status: operatorv1.KubeAPIServerStatus{
+			StaticPodOperatorStatus: operatorv1.StaticPodOperatorStatus{
+				NodeStatuses: []operatorv1.NodeStatus{{NodeName: "master-0", CurrentRevision: 1}, {NodeName: "master-1", CurrentRevision: 2}},
+			},
+			ServiceAccountIssuers: []operatorv1.ServiceAccountIssuerStatus{
+				{Name: "active"},
+				{Name: "trusted", ExpirationTime: &expiration},
+			},
+		},
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	client := fakeclient.NewSimpleClientset(operator)
+	c := &ServiceAccountIssuerController{
+		kubeAPIServerOperatorClient: client.OperatorV1().KubeAPIServers(),
+		configMapLister:             corev1listers.NewConfigMapLister(indexer),
+	}
+	recorder := events.NewInMemoryRecorder("test", clock.RealClock{})
+	report := func() *operatorv1.OperatorCondition {
+		t.Helper()
+		current, err := client.OperatorV1().KubeAPIServers().Get(context.Background(), "cluster", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := c.reportTrustedIssuers(context.Background(), current, recorder); err != nil {
+			t.Fatal(err)
+		}
+		current, err = client.OperatorV1().KubeAPIServers().Get(context.Background(), "cluster", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v1helpers.FindOperatorCondition(current.Status.Conditions, trustedIssuersConditionType)
+	}
+
+	// master-1 still runs a revision that accepts the pruned issuer
+	if err := indexer.Add(issuersConfigMap(1, "active", "trusted")); err != nil {
+		t.Fatal(err)
+	}
+	if err := indexer.Add(issuersConfigMap(2, "active", "trusted", "pruned")); err != nil {
+		t.Fatal(err)
+	}
+	condition := report()
+	if condition == nil || condition.Status != operatorv1.ConditionTrue || condition.Reason != "PreviousIssuersTrusted" {
+		t.Fatalf("expected the previous issuers to be reported, got %v", condition)
+	}
+	for _, expected := range []string{`"trusted" are accepted until 2026-03-01T00:00:00Z`, `"pruned" are no longer trusted`} {
+		if !strings.Contains(condition.Message, expected) {
+			t.Errorf("expected %q in the message, got %q", expected, condition.Message)
+		}
+	}
+	expirationMetric, err := testutil.GetGaugeMetricValue(trustedIssuerExpirationGauge.WithLabelValues("trusted"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expirationMetric != float64(expiration.Unix()) {
+		t.Errorf("expected the issuer to expire at %d, got %v", expiration.Unix(), expirationMetric)
+	}
+	awaitingRemoval, err := testutil.GetGaugeMetricValue(issuerAwaitingRemovalGauge.WithLabelValues("pruned"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if awaitingRemoval != 1 {
+		t.Errorf("expected the pruned issuer to await removal, got %v", awaitingRemoval)
+	}
+
+	// the issuer is only removed once no revision accepts it
+	if err := indexer.Update(issuersConfigMap(2, "active", "trusted")); err != nil {
+		t.Fatal(err)
+	}
+	current, err := client.OperatorV1().KubeAPIServers().Get(context.Background(), "cluster", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	current.Status.ServiceAccountIssuers = current.Status.ServiceAccountIssuers[:1]
+	if _, err := client.OperatorV1().KubeAPIServers().UpdateStatus(context.Background(), current, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	c.awaitRemoval("trusted")
+	condition = report()
+	if condition.Status != operatorv1.ConditionTrue || condition.Reason != "AwaitingIssuerRemoval" {
+		t.Errorf("expected the expired issuer to await removal, got %v", condition)
+	}
+	if len(issuerEvents(recorder, "ServiceAccountIssuerRemoved")) != 1 {
+		t.Errorf("expected an event for the removed issuer, got %v", recorder.Events())
+	}
+
+	if err := indexer.Update(issuersConfigMap(1, "active")); err != nil {
+		t.Fatal(err)
+	}
+	if err := indexer.Update(issuersConfigMap(2, "active")); err != nil {
+		t.Fatal(err)
+	}
+	condition = report()
+	if condition.Status != operatorv1.ConditionFalse || condition.Reason != "AsExpected" {
+		t.Errorf("expected only the active issuer, got %v", condition)
+	}
+	if removed := issuerEvents(recorder, "ServiceAccountIssuerRemoved"); len(removed) != 2 || !strings.Contains(removed[1].Message, `"trusted"`) {
+		t.Errorf("expected an event for every removed issuer, got %v", removed)
+	}
+	for _, gauge := range []metrics.Collector{trustedIssuerExpirationGauge, issuerAwaitingRemovalGauge} {
+		if err := testutil.CollectAndCompare(gauge, strings.NewReader("")); err != nil {
+			t.Errorf("expected no previous issuer in the metrics: %v", err)
+		}
+	}
+}
+
+func TestRevokeTrustedIssuers(t *testing.T) {
+	expiration := metav1.NewTime(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	operator := &operatorv1.KubeAPIServer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "cluster",
+			Annotations: map[string]string{RevokeTrustedIssuersAnnotation: "first, active,unknown"},
+		},
+		Status: operatorv1.KubeAPIServerStatus{
+			ServiceAccountIssuers: []operatorv1.ServiceAccountIssuerStatus{
+				{Name: "active"},
+				{Name: "first", ExpirationTime: &expiration},
+				{Name: "second", ExpirationTime: &expiration},
+			},
+		},
+	}
+	client := fakeclient.NewSimpleClientset(operator)
+	c := &ServiceAccountIssuerController{kubeAPIServerOperatorClient: client.OperatorV1().KubeAPIServers()}
+	recorder := events.NewInMemoryRecorder("test", clock.RealClock{})
+
+	if err := c.revokeTrustedIssuers(context.Background(), recorder, operator); err != factory.SyntheticRequeueError {
+		t.Fatalf("expected a requeue, got %v", err)
+	}
+	current, err := client.OperatorV1().KubeAPIServers().Get(context.Background(), "cluster", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := current.Annotations[RevokeTrustedIssuersAnnotation]; ok {
+		t.Errorf("expected the annotation to be removed")
+	}
+	if names := issuerNames(current.Status.ServiceAccountIssuers); names != "active,second" {
+		t.Errorf("expected only the first trusted issuer to be revoked, got %s", names)
+	}
+	if !c.awaitingRemoval.Has("first") {
+		t.Errorf("expected the revoked issuer to await removal")
+	}
+	if ignored := issuerEvents(recorder, "ServiceAccountIssuerRevocationIgnored"); len(ignored) != 1 || !strings.Contains(ignored[0].Message, `"active", "unknown"`) {
+		t.Errorf("expected the active and unknown issuers to be ignored, got %v", ignored)
+	}
+	if len(issuerEvents(recorder, "ServiceAccountIssuerRevoked")) != 1 {
+		t.Errorf("expected an event for the revoked issuer, got %v", recorder.Events())
+	}
+
+	// all the trusted issuers
+	current.Annotations = map[string]string{RevokeTrustedIssuersAnnotation: "*"}
+	if current, err = client.OperatorV1().KubeAPIServers().Update(context.Background(), current, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.revokeTrustedIssuers(context.Background(), recorder, current); err != factory.SyntheticRequeueError {
+		t.Fatalf("expected a requeue, got %v", err)
+	}
+	if current, err = client.OperatorV1().KubeAPIServers().Get(context.Background(), "cluster", metav1.GetOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if names := issuerNames(current.Status.ServiceAccountIssuers); names != "active" {
+		t.Errorf("expected only the active issuer, got %s", names)
+	}
+
+	// nothing to do without the annotation
+	if err := c.revokeTrustedIssuers(context.Background(), recorder, current); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func issuersConfigMap(revision int32, issuers ...string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.TargetNamespace, Name: fmt.Sprintf("config-%d", revision)},
+		Data:       map[string]string{"config.yaml": fmt.Sprintf(`{"apiServerArguments":{"service-account-issuer":["%s"]}}`, strings.Join(issuers, `","`))},
+	}
+}
+
+func issuerEvents(recorder events.InMemoryRecorder, reason string) []*corev1.Event {
+	var ret []*corev1.Event
+	for _, event := range recorder.Events() {
+		if event.Reason == reason {
+			ret = append(ret, event)
+		}
+	}
+	return ret
+}
+
+func issuerNames(issuers []operatorv1.ServiceAccountIssuerStatus) string {
+	var names []string
+	for _, issuer := range issuers {
+		names = append(names, issuer.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestRecordIssuerMetrics(t *testing.T) {
+	RegisterMetrics()
+
+	expiresAt := func(day int) *metav1.Time {
+		expiration := metav1.NewTime(time.Date(2026, 3, day, 0, 0, 0, 0, time.UTC))
+		return &expiration
+	}
+	recordIssuerMetrics([]operatorv1.ServiceAccountIssuerStatus{
+		{Name: "https://a.example.com", ExpirationTime: expiresAt(1)},
+		{Name: "https://b.example.com", ExpirationTime: expiresAt(2)},
+	}, []string{"https://c.example.com", "https://d.example.com"})
+	// a expired and is awaiting removal, c and d are no longer accepted
+	recordIssuerMetrics([]operatorv1.ServiceAccountIssuerStatus{
+		{Name: "https://b.example.com", ExpirationTime: expiresAt(2)},
+	}, []string{"https://a.example.com"})
+
+	expected := `
+# HELP openshift_kube_apiserver_service_account_issuer_awaiting_removal [ALPHA] Reports 1 for a service account issuer that expired or was revoked, but is still accepted by the kube-apiserver of a control plane node
+# TYPE openshift_kube_apiserver_service_account_issuer_awaiting_removal gauge
+openshift_kube_apiserver_service_account_issuer_awaiting_removal{issuer="https://a.example.com"} 1
+# HELP openshift_kube_apiserver_trusted_service_account_issuer_expiration_timestamp_seconds [ALPHA] Reports when a previous service account issuer that is still trusted expires, tokens it issued are accepted until then
+# TYPE openshift_kube_apiserver_trusted_service_account_issuer_expiration_timestamp_seconds gauge
+openshift_kube_apiserver_trusted_service_account_issuer_expiration_timestamp_seconds{issuer="https://b.example.com"} 1.7724096e+09
+`
+	if err := testutil.GatherAndCompare(legacyregistry.DefaultGatherer, strings.NewReader(expected),
+		"openshift_kube_apiserver_trusted_service_account_issuer_expiration_timestamp_seconds", "openshift_kube_apiserver_service_account_issuer_awaiting_removal"); err != nil {
+		t.Fatal(err)
+	}
+
+	// leave no series behind for the other tests
+	recordIssuerMetrics(nil, nil)
+}
@@ -35,7 +35,9 @@ import (
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/encryptionstatusprovider"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/highcpuusagealertcontroller"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/kubeletversionskewcontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/namedcertificatescontroller"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/nodekubeconfigcontroller"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/oidcdiscoverycontroller"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/podsecurityreadinesscontroller"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/resourcesynccontroller"
@@ -44,6 +46,7 @@ import (
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/startupmonitorreadiness"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/targetconfigcontroller"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/terminationobserver"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/usercertificatescontroller"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/webhooksupportabilitycontroller"
 	"github.com/openshift/library-go/pkg/controller/controllercmd"
 	"github.com/openshift/library-go/pkg/operator/apiserver/controller/auditpolicy"
@@ -80,6 +83,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/kubernetes"
@@ -196,6 +200,7 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 	configObserver := configobservercontroller.NewConfigObserver(
 		operatorClient,
 		kubeInformersForNamespaces,
+		clusterInformers.InformersFor("").Core().V1().Nodes(),
 		configInformers,
 		operatorInformers,
 		resourceSyncController,
@@ -204,7 +209,16 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		groupVersionsByFeatureGate,
 	)
 
-	serviceAccountIssuerController := serviceaccountissuercontroller.NewController(operatorV1Client.OperatorV1().KubeAPIServers(), operatorInformers, configInformers, controllerContext.EventRecorder)
+	serviceAccountIssuerController := serviceaccountissuercontroller.NewController(operatorV1Client.OperatorV1().KubeAPIServers(), operatorInformers, configInformers, kubeInformersForNamespaces, controllerContext.EventRecorder)
+
+	oidcDiscoveryController := oidcdiscoverycontroller.NewOIDCDiscoveryController(
+		operatorClient,
+		kubeInformersForNamespaces,
+		kubeClient.CoreV1(),
+		operatorInformers.Operator().V1().KubeAPIServers(),
+		configInformers.Config().V1().Infrastructures(),
+		controllerContext.EventRecorder,
+	)
 
 	eventWatcher := eventwatch.New().
 		WithEventHandler(operatorclient.TargetNamespace, "LateConnections", terminationobserver.ProcessLateConnectionEvents).
@@ -314,6 +328,21 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		controllerContext.EventRecorder,
 	)
 
+	namedCertificatesController := namedcertificatescontroller.NewNamedCertificatesController(
+		operatorClient,
+		kubeInformersForNamespaces,
+		kubeClient.CoreV1(),
+		configInformers.Config().V1().APIServers(),
+		controllerContext.EventRecorder,
+	)
+
+	userCertificatesController := usercertificatescontroller.NewUserCertificatesController(
+		operatorClient,
+		kubeInformersForNamespaces,
+		configInformers.Config().V1().APIServers(),
+		controllerContext.EventRecorder,
+	)
+
 	apiextensionsInformers := apiextensionsinformers.NewSharedInformerFactory(apiextensionsClient, 10*time.Minute)
 	connectivityCheckController := connectivitycheckcontroller.NewKubeAPIServerConnectivityCheckController(
 		kubeClient,
@@ -391,6 +420,10 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		controllerContext.Clock,
 	).WithDegradedInertia(newDegradedInertia())
 
+	rotationProfile, err := certrotationcontroller.CurrentRotationProfile(ctx, kubeClient.CoreV1())
+	if err != nil {
+		return err
+	}
 	certRotationController, err := certrotationcontroller.NewCertRotationController(
 		kubeClient,
 		operatorClient,
@@ -398,10 +431,30 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		kubeInformersForNamespaces,
 		controllerContext.EventRecorder.WithComponentSuffix("cert-rotation-controller"),
 		featureGates,
+		rotationProfile,
 	)
 	if err != nil {
 		return err
 	}
+	rotationProfileController := certrotationcontroller.NewRotationProfileController(
+		operatorClient,
+		kubeInformersForNamespaces,
+		rotationProfile,
+		certRotationController.SetRotationProfile,
+		controllerContext.EventRecorder,
+	)
+	revisionedSecrets := sets.New[string]()
+	for _, secret := range RevisionSecrets {
+		revisionedSecrets.Insert(secret.Name)
+	}
+	forcedRotationController := certrotationcontroller.NewForcedRotationController(
+		operatorClient,
+		certRotationController.Inventory(),
+		revisionedSecrets,
+		kubeClient.CoreV1(),
+		kubeInformersForNamespaces,
+		controllerContext.EventRecorder,
+	)
 
 	staticPodNodeProvider := encryptiondeployer.StaticPodNodeProvider{OperatorClient: operatorClient}
 	deployer, err := encryptiondeployer.NewRevisionLabelPodDeployer("revision", operatorclient.TargetNamespace, kubeInformersForNamespaces, kubeClient.CoreV1(), kubeClient.CoreV1(), staticPodNodeProvider)
@@ -494,6 +547,7 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		kubeInformersForNamespaces,
 		clusterInformers.InformersFor("").Core().V1().Nodes().Lister(),
 		clusterInformers.InformersFor("").Core().V1().Nodes().Informer(),
+		configInformers.Config().V1().ClusterVersions(),
 		controllerContext.EventRecorder,
 	)
 
@@ -524,8 +578,16 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		controllerContext.EventRecorder,
 	)
 
+	podSecurityNamespaceInformers, err := podsecurityreadinesscontroller.NewNamespaceInformers(kubeClient)
+	if err != nil {
+		return err
+	}
+	podSecurityPodInformers := podsecurityreadinesscontroller.NewPodInformers(kubeClient)
 	podSecurityReadinessController, err := podsecurityreadinesscontroller.NewPodSecurityReadinessController(
-		controllerContext.ProtoKubeConfig,
+		kubeClient,
+		podSecurityNamespaceInformers.Core().V1().Namespaces(),
+		podSecurityPodInformers.Core().V1().Pods(),
+		securityInformers.Security().V1().SecurityContextConstraints(),
 		operatorClient,
 		controllerContext.EventRecorder,
 	)
@@ -536,6 +598,9 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 	// register termination metrics
 	terminationobserver.RegisterMetrics()
 
+	// register kubelet version skew metrics
+	kubeletversionskewcontroller.RegisterMetrics()
+
 	// register config metrics
 	configmetrics.Register(configInformers)
 
@@ -548,15 +613,21 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 	apiextensionsInformers.Start(ctx.Done())
 	operatorInformers.Start(ctx.Done())
 	securityInformers.Start(ctx.Done())
+	podSecurityNamespaceInformers.Start(ctx.Done())
+	podSecurityPodInformers.Start(ctx.Done())
 
 	go staticPodControllers.Start(ctx)
 	go resourceSyncController.Run(ctx, 1)
 	go staticResourceController.Run(ctx, 1)
 	go targetConfigReconciler.Run(ctx, 1)
 	go nodeKubeconfigController.Run(ctx, 1)
+	go namedCertificatesController.Run(ctx, 1)
+	go userCertificatesController.Run(ctx, 1)
 	go configObserver.Run(ctx, 1)
 	go clusterOperatorStatus.Run(ctx, 1)
 	go certRotationController.Run(ctx, 1)
+	go rotationProfileController.Run(ctx, 1)
+	go forcedRotationController.Run(ctx, 1)
 	go encryptionControllers.Run(ctx, 1)
 	go certRotationTimeUpgradeableController.Run(ctx, 1)
 	go terminationObserver.Run(ctx, 1)
@@ -569,6 +640,7 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 	go latencyProfileController.Run(ctx, 1)
 	go webhookSupportabilityController.Run(ctx, 1)
 	go serviceAccountIssuerController.Run(ctx, 1)
+	go oidcDiscoveryController.Run(ctx, 1)
 	go podSecurityReadinessController.Run(ctx, 1)
 	go highCpuUsageAlertController.Run(ctx, 1)
 	go sccReconcileController.Run(ctx, 1)
@@ -761,6 +833,10 @@ var CertSecrets = []installer.UnrevisionedResource{
 	{Name: "node-kubeconfigs"},
 
 	{Name: "user-serving-cert", Optional: true},
+	// all user provided named certificates, combined by the NamedCertificatesController
+	{Name: "user-serving-certs", Optional: true},
+	// the legacy per entry copies of the named certificates, only synced until every node runs a revision that
+	// uses the combined secret
 	{Name: "user-serving-cert-000", Optional: true},
 	{Name: "user-serving-cert-001", Optional: true},
 	{Name: "user-serving-cert-002", Optional: true},
@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/externaljwtsigner"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
 	"github.com/openshift/library-go/pkg/operator/staticpod/startupmonitor"
 
@@ -115,6 +116,9 @@ func (ch *KubeAPIReadinessChecker) IsReady(ctx context.Context, revision int) (
 		// check if the kas pod is running at the expected revision
 		newRevisionPodExists(ch.kubeClient.CoreV1().Pods(operatorclient.TargetNamespace), revision, ch.currentNodeName),
 
+		// check the external JWT signer the kas pod signs service account tokens with, if any
+		goodExternalJWTSigner(ch.kubeClient.CoreV1().Pods(operatorclient.TargetNamespace), ch.currentNodeName),
+
 		// check that kubelet has reporting readiness for the new pod
 		newPodRunning(ch.kubeClient.CoreV1().Pods(operatorclient.TargetNamespace), revision, ch.currentNodeName),
 	} {
@@ -223,6 +227,49 @@ func checkRevisionOnPod(ctx context.Context, podClient corev1client.PodInterface
 	return checkRevision(&kasPod, monitorRevision)
 }
 
+// goodExternalJWTSigner checks that the external JWT signer the kas pod signs service account tokens with serves public keys on its socket
+//
+//	returns true, "", "", when the kas pod doesn't use an external signer
+//	returns false, "ExternalJWTSignerUnavailable", Error when the keys can't be fetched from the signer or it has none
+func goodExternalJWTSigner(podClient corev1client.PodInterface, currentNodeName string) func(context.Context) (bool, string, string) {
+	return func(ctx context.Context) (bool, string, string) {
+		apiServerPods, err := podClient.List(ctx, metav1.ListOptions{LabelSelector: "apiserver=true"})
+		if err != nil {
+			return false, "PodListError", fmt.Sprintf("failed to list kube-apiserver static pods: %v", err)
+		}
+		filteredKasPods := filterByNodeName(apiServerPods.Items, currentNodeName)
+		if len(filteredKasPods) != 1 {
+			return false, "PodListError", fmt.Sprintf("expected one kube-apiserver static pod for node %s, found %d", currentNodeName, len(filteredKasPods))
+		}
+
+		var socketPath string
+		for _, container := range filteredKasPods[0].Spec.Containers {
+			if container.Name == "kube-apiserver" {
+				socketPath = externaljwtsigner.SigningEndpointFromArgs(append(container.Command, container.Args...)...)
+			}
+		}
+		if len(socketPath) == 0 {
+			return true, "", ""
+		}
+
+		client, err := externaljwtsigner.NewClient(socketPath)
+		if err != nil {
+			return false, "ExternalJWTSignerUnavailable", fmt.Sprintf("failed to connect to the external JWT signer at %s: %v", socketPath, err)
+		}
+		defer client.Close()
+		fetchCtx, cancel := context.WithTimeout(ctx, 4*time.Second)
+		defer cancel()
+		keys, err := client.FetchKeys(fetchCtx)
+		if err != nil {
+			return false, "ExternalJWTSignerUnavailable", fmt.Sprintf("waiting for the external JWT signer at %s: %v", socketPath, err)
+		}
+		if len(keys) == 0 {
+			return false, "ExternalJWTSignerUnavailable", fmt.Sprintf("waiting for the external JWT signer at %s to serve public keys", socketPath)
+		}
+		return true, "", ""
+	}
+}
+
 func checkRevision(kasPod *corev1.Pod, monitorRevision int) (bool, string, string) {
 	revisionString, found := kasPod.Labels["revision"]
 	if !found {
@@ -2,9 +2,14 @@ package startupmonitorreadiness
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -13,6 +18,9 @@ import (
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/externaljwtsigner"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/externaljwtsigner/signertest"
 )
 
 func TestNewPodHasStateRunning(t *testing.T) {
@@ -561,6 +569,82 @@ func TestHealthzEtcdEndpoint(t *testing.T) {
 
 }
 
+func TestGoodExternalJWTSigner(t *testing.T) {
+	socketDir, err := os.MkdirTemp("", "signer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(socketDir)
+	socketPath := filepath.Join(socketDir, "signer.sock")
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name     string
+		healthy  bool
+		reason   string
+		msg      string
+		signer   bool
+		keys     []externaljwtsigner.PublicKey
+		endpoint string
+	}{
+		{
+			name:    "scenario 1: no external signer",
+			healthy: true,
+		},
+		{
+			name:     "scenario 2: happy path",
+			healthy:  true,
+			signer:   true,
+			keys:     []externaljwtsigner.PublicKey{{KeyID: "key-1", Key: key.Public()}},
+			endpoint: socketPath,
+		},
+		{
+			name:     "scenario 3: signer without keys",
+			healthy:  false,
+			reason:   "ExternalJWTSignerUnavailable",
+			msg:      fmt.Sprintf("waiting for the external JWT signer at %s to serve public keys", socketPath),
+			signer:   true,
+			endpoint: socketPath,
+		},
+		{
+			name:     "scenario 4: signer not listening",
+			healthy:  false,
+			reason:   "ExternalJWTSignerUnavailable",
+			msg:      fmt.Sprintf("waiting for the external JWT signer at %s", socketPath),
+			endpoint: socketPath,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			// test data
+			if scenario.signer {
+				signer, err := signertest.NewStandInSigner(socketPath, scenario.keys...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer signer.Stop()
+			}
+			pod := newPod(corev1.PodRunning, corev1.ConditionTrue, "3", "kas", "master-1")
+			script := "exec hyperkube kube-apiserver --openshift-config=/etc/kubernetes/static-pod-resources/configmaps/config/config.yaml"
+			if len(scenario.endpoint) > 0 {
+				script = strings.Replace(script, "kube-apiserver ", "kube-apiserver --service-account-signing-endpoint="+scenario.endpoint+" ", 1)
+			}
+			pod.Spec.Containers = []corev1.Container{{Name: "kube-apiserver", Command: []string{"/bin/bash", "-ec"}, Args: []string{script}}}
+			fakeKubeClient := fake.NewSimpleClientset(pod)
+
+			// act and validate
+			doCheckAndValidate(t, func() (bool, string, string) {
+				return goodExternalJWTSigner(fakeKubeClient.CoreV1().Pods("openshift-kube-apiserver"), "master-1")(context.TODO())
+			}, scenario.healthy, scenario.reason, scenario.msg)
+		})
+	}
+}
+
 func doCheckAndValidate(t *testing.T, checkFn func() (bool, string, string), expectedHealthy bool, expectedReason, expectedMessage string) {
 	actualHealthy, actualReason, actualMsg := checkFn()
 	if expectedHealthy != actualHealthy {
@@ -0,0 +1,229 @@
+package targetconfigcontroller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	genericoptions "k8s.io/apiserver/pkg/server/options"
+	"k8s.io/apiserver/pkg/storage/storagebackend"
+	cliflag "k8s.io/component-base/cli/flag"
+	basecompatibility "k8s.io/component-base/compatibility"
+	logsapi "k8s.io/component-base/logs/api/v1"
+	"k8s.io/component-base/metrics"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/externaljwtsigner"
+)
+
+const apiServerArgumentsConditionType = "APIServerArgumentsDegraded"
+
+// kubeAPIServerFlags returns the flags of the kube-apiserver shipped with
+// this operator. A flag the kube-apiserver doesn't know makes it exit on
+// start, so the unknown apiServerArguments are refused. The flags the
+// kube-apiserver shares with the k8s.io/apiserver and component-base options
+// parse their values like it does, the other ones of kubeAPIServerFlagTypes,
+// generated from the help of the operand on rebase, by the type of the help.
+func kubeAPIServerFlags() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("kube-apiserver", pflag.ContinueOnError)
+	addGenericAPIServerFlags(fs)
+	for _, flagTypes := range []map[string]string{kubeAPIServerFlagTypes, deprecatedKubeAPIServerFlagTypes} {
+		for name, flagType := range flagTypes {
+			if fs.Lookup(name) == nil {
+				addFlagOfType(fs, name, flagType)
+			}
+		}
+	}
+	return fs
+}
+
+// deprecatedKubeAPIServerFlagTypes are the deprecated flags the kube-apiserver
+// still accepts but its help doesn't show, so they are missing from
+// kubeAPIServerFlagTypes.
+var deprecatedKubeAPIServerFlagTypes = map[string]string{
+	"admission-control":      "strings",
+	"kubelet-port":           "uint",
+	"kubelet-read-only-port": "uint",
+}
+
+// addGenericAPIServerFlags adds the flags of the k8s.io/apiserver and
+// component-base options the kube-apiserver registers.
+func addGenericAPIServerFlags(fs *pflag.FlagSet) {
+	// a registry of its own, the default one would share the parsed values
+	// and close the feature gates of the process
+	genericoptions.NewServerRunOptionsForComponent(basecompatibility.DefaultKubeComponent, basecompatibility.NewComponentGlobalsRegistry()).AddUniversalFlags(fs)
+	genericoptions.NewEtcdOptions(storagebackend.NewDefaultConfig("/registry", nil)).AddFlags(fs)
+	genericoptions.NewSecureServingOptions().AddFlags(fs)
+	genericoptions.NewAuditOptions().AddFlags(fs)
+	genericoptions.NewFeatureOptions().AddFlags(fs)
+	genericoptions.NewAPIEnablementOptions().AddFlags(fs)
+	genericoptions.NewEgressSelectorOptions().AddFlags(fs)
+	genericoptions.NewTracingOptions().AddFlags(fs)
+	genericoptions.NewAdmissionOptions().AddFlags(fs)
+	logsapi.AddFlags(logsapi.NewLoggingConfiguration(), fs)
+	metrics.NewOptions().AddFlags(fs)
+}
+
+// addFlagOfType adds the given flag with a value of the given type of the
+// kube-apiserver help.
+func addFlagOfType(fs *pflag.FlagSet, name, flagType string) {
+	switch flagType {
+	case "":
+		fs.Bool(name, false, "")
+	case "int":
+		fs.Int64(name, 0, "")
+	case "int32":
+		fs.Int32(name, 0, "")
+	case "uint":
+		fs.Uint64(name, 0, "")
+	case "float":
+		fs.Float64(name, 0, "")
+	case "float32":
+		fs.Float32(name, 0, "")
+	case "duration":
+		fs.Duration(name, 0, "")
+	case "ip":
+		fs.IP(name, nil, "")
+	case "ipSlice":
+		fs.IPSlice(name, nil, "")
+	case "strings":
+		fs.StringSlice(name, nil, "")
+	case "stringArray":
+		fs.StringArray(name, nil, "")
+	case "stringToString":
+		fs.StringToString(name, nil, "")
+	case "mapStringString":
+		fs.Var(cliflag.NewMapStringString(&map[string]string{}), name, "")
+	case "mapStringBool":
+		fs.Var(cliflag.NewMapStringBool(&map[string]bool{}), name, "")
+	case "portRange":
+		fs.Var(&utilnet.PortRange{}, name, "")
+	case "quantity":
+		fs.Var(&resource.QuantityValue{}, name, "")
+	default:
+		// the help names the type after the usage, any value is let through
+		fs.String(name, "", "")
+	}
+}
+
+// argumentValueChecks validate the values of the flags the kube-apiserver
+// parses as strings and checks later on start.
+var argumentValueChecks = map[string]func(value string) error{
+	"feature-gates":            featureGatesValue,
+	"service-cluster-ip-range": cidrListValue,
+}
+
+// featureGatesValue validates a comma-separated list of Name=true|false.
+func featureGatesValue(value string) error {
+	for _, featureGate := range strings.Split(value, ",") {
+		name, enabled, found := strings.Cut(strings.TrimSpace(featureGate), "=")
+		if !found || len(name) == 0 {
+			return fmt.Errorf("%q is not Name=true|false", featureGate)
+		}
+		if _, err := strconv.ParseBool(enabled); err != nil {
+			return fmt.Errorf("%q is not Name=true|false", featureGate)
+		}
+	}
+	return nil
+}
+
+func cidrListValue(value string) error {
+	for _, cidr := range strings.Split(value, ",") {
+		if _, _, err := net.ParseCIDR(strings.TrimSpace(cidr)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// invalidAPIServerArguments returns why the apiServerArguments of the given
+// kube-apiserver config are invalid, sorted by argument.
+func invalidAPIServerArguments(config []byte) ([]string, error) {
+	kubeAPIServerConfig := map[string]interface{}{}
+	if err := json.Unmarshal(config, &kubeAPIServerConfig); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the config: %w", err)
+	}
+	arguments, _, err := unstructured.NestedMap(kubeAPIServerConfig, "apiServerArguments")
+	if err != nil {
+		return nil, err
+	}
+
+	flags := kubeAPIServerFlags()
+	var invalid []string
+	for name, values := range arguments {
+		flag := flags.Lookup(name)
+		if flag == nil {
+			invalid = append(invalid, fmt.Sprintf("%s: unknown kube-apiserver flag", name))
+			continue
+		}
+		valueList, ok := values.([]interface{})
+		if !ok {
+			invalid = append(invalid, fmt.Sprintf("%s: must be a list of strings", name))
+			continue
+		}
+		for _, value := range valueList {
+			stringValue, ok := value.(string)
+			if !ok {
+				invalid = append(invalid, fmt.Sprintf("%s: %v must be a string", name, value))
+				continue
+			}
+			if err := flag.Value.Set(stringValue); err != nil {
+				invalid = append(invalid, fmt.Sprintf("%s: invalid value %q: %v", name, stringValue, err))
+				continue
+			}
+			if check, ok := argumentValueChecks[name]; ok {
+				if err := check(stringValue); err != nil {
+					invalid = append(invalid, fmt.Sprintf("%s: invalid value %q: %v", name, stringValue, err))
+				}
+			}
+		}
+	}
+	sort.Strings(invalid)
+	return invalid, nil
+}
+
+// ValidateKubeAPIServerConfig returns why the apiServerArguments of the
+// config rendered for the given operator spec and external JWT signer are
+// invalid, if they are.
+func ValidateKubeAPIServerConfig(operatorSpec *operatorv1.StaticPodOperatorSpec, signer *externaljwtsigner.Config) ([]string, error) {
+	config, err := RenderKubeAPIServerConfig(operatorSpec, signer)
+	if err != nil {
+		return nil, err
+	}
+	return invalidAPIServerArguments(config)
+}
+
+// reportInvalidAPIServerArguments reports the given invalid apiServerArguments
+// in a condition, and in an event when they change.
+func reportInvalidAPIServerArguments(ctx context.Context, operatorClient v1helpers.StaticPodOperatorClient, recorder events.Recorder, invalid []string) error {
+	condition := operatorv1.OperatorCondition{
+		Type:   apiServerArgumentsConditionType,
+		Status: operatorv1.ConditionFalse,
+		Reason: "AsExpected",
+	}
+	if len(invalid) > 0 {
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "InvalidArguments"
+		condition.Message = fmt.Sprintf("The kube-apiserver config is not rolled out, its apiServerArguments are invalid:\n%s", strings.Join(invalid, "\n"))
+	}
+
+	_, updated, err := v1helpers.UpdateStaticPodStatus(ctx, operatorClient, v1helpers.UpdateStaticPodConditionFn(condition))
+	if err != nil {
+		return err
+	}
+	if updated && len(invalid) > 0 {
+		recorder.Warningf("InvalidAPIServerArguments", "Not rolling out the kube-apiserver config, its apiServerArguments are invalid: %s", strings.Join(invalid, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package targetconfigcontroller
+
+import (
+	"context"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/clock"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestValidateKubeAPIServerConfig(t *testing.T) {
+	observedConfig := `{"apiServerArguments":{"etcd-servers":["https://10.0.0.1:2379"],"feature-gates":["Foo=true","Bar=false"],"runtime-config":["api/all=true"],"service-account-max-token-expiration":["24h"]}}`
+	for _, test := range []struct {
+		name            string
+		overrides       string
+		expectedInvalid []string
+	}{
+		{
+			name: "the defaults and the observed config are valid",
+		},
+		{
+			name:      "valid overrides",
+			overrides: `{"apiServerArguments":{"v":["4"],"service-cluster-ip-range":["172.30.0.0/16,fd02::/112"],"advertise-address":["10.0.0.1"],"goaway-chance":["0.01"]}}`,
+		},
+		{
+			name:      "invalid overrides",
+			overrides: `{"apiServerArguments":{"shutdown-delay-durationn":["70s"],"event-ttl":["3"],"service-cluster-ip-range":["172.30.0.0/33"],"anonymous-auth":["yes"],"v":[4],"feature-gates":["Foo"]}}`,
+			expectedInvalid: []string{
+				`anonymous-auth: invalid value "yes": strconv.ParseBool: parsing "yes": invalid syntax`,
+				`event-ttl: invalid value "3": time: missing unit in duration "3"`,
+				`feature-gates: invalid value "Foo": "Foo" is not Name=true|false`,
+				`service-cluster-ip-range: invalid value "172.30.0.0/33": invalid CIDR address: 172.30.0.0/33`,
+				`shutdown-delay-durationn: unknown kube-apiserver flag`,
+				`v: 4 must be a string`,
+			},
+		},
+		{
+			name:            "unknown arguments can't be allowed",
+			overrides:       `{"allowUnknownAPIServerArguments":["new-flag"],"apiServerArguments":{"new-flag":["anything"]}}`,
+			expectedInvalid: []string{"new-flag: unknown kube-apiserver flag"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			operatorSpec := &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+				ObservedConfig:             runtime.RawExtension{Raw: []byte(observedConfig)},
+				UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(test.overrides)},
+			}}
+			invalid, err := ValidateKubeAPIServerConfig(operatorSpec, nil)
+			require.NoError(t, err)
+			require.Equal(t, test.expectedInvalid, invalid)
+		})
+	}
+}
+
+// TestKubeAPIServerFlagTypesDrift fails when kubeAPIServerFlagTypes weren't
+// regenerated from the help of the kube-apiserver of the vendored Kubernetes.
+func TestKubeAPIServerFlagTypesDrift(t *testing.T) {
+	info, ok := debug.ReadBuildInfo()
+	require.True(t, ok)
+	var apiserverVersion string
+	for _, dep := range info.Deps {
+		if dep.Path == "k8s.io/apiserver" {
+			apiserverVersion = dep.Version
+		}
+	}
+	// k8s.io/apiserver v0.X.Y ships with Kubernetes 1.X
+	require.True(t, strings.HasPrefix(apiserverVersion, "v0."+strings.TrimPrefix(kubeAPIServerFlagsVersion, "1.")+"."),
+		"kubeAPIServerFlagTypes were generated for Kubernetes %s, k8s.io/apiserver is %s: regenerate them with hack/kube-apiserver-flags", kubeAPIServerFlagsVersion, apiserverVersion)
+
+	// the help shows the flags of the vendored options with their type
+	genericFlags := pflag.NewFlagSet("generic", pflag.ContinueOnError)
+	addGenericAPIServerFlags(genericFlags)
+	genericFlags.VisitAll(func(flag *pflag.Flag) {
+		if flag.Hidden || len(flag.Deprecated) > 0 {
+			return
+		}
+		flagType, found := kubeAPIServerFlagTypes[flag.Name]
+		if !assert.True(t, found, "kubeAPIServerFlagTypes miss %s", flag.Name) {
+			return
+		}
+		helpType, _ := pflag.UnquoteUsage(flag)
+		assert.Equal(t, helpType, flagType, "type of %s", flag.Name)
+	})
+
+	// the other flags parse the values of their type
+	for _, flagTypes := range []map[string]string{kubeAPIServerFlagTypes, deprecatedKubeAPIServerFlagTypes} {
+		for name, flagType := range flagTypes {
+			if genericFlags.Lookup(name) != nil {
+				continue
+			}
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			addFlagOfType(fs, name, flagType)
+			helpType, _ := pflag.UnquoteUsage(fs.Lookup(name))
+			assert.Equal(t, flagType, helpType, "%s: addFlagOfType doesn't parse the type %q", name, flagType)
+		}
+	}
+}
+
+func TestReportInvalidAPIServerArguments(t *testing.T) {
+	operatorClient := v1helpers.NewFakeStaticPodOperatorClient(&operatorv1.StaticPodOperatorSpec{}, &operatorv1.StaticPodOperatorStatus{}, nil, nil)
+	recorder := events.NewInMemoryRecorder("test", clock.RealClock{})
+	report := func(invalid []string) *operatorv1.OperatorCondition {
+		t.Helper()
+		require.NoError(t, reportInvalidAPIServerArguments(context.TODO(), operatorClient, recorder, invalid))
+		_, status, _, err := operatorClient.GetStaticPodOperatorState()
+		require.NoError(t, err)
+		return v1helpers.FindOperatorCondition(status.Conditions, apiServerArgumentsConditionType)
+	}
+
+	condition := report(nil)
+	require.Equal(t, operatorv1.ConditionFalse, condition.Status)
+	require.Equal(t, "AsExpected", condition.Reason)
+
+	invalid := []string{"shutdown-delay-durationn: unknown kube-apiserver flag"}
+	condition = report(invalid)
+	require.Equal(t, operatorv1.ConditionTrue, condition.Status)
+	require.Equal(t, "InvalidArguments", condition.Reason)
+	require.Contains(t, condition.Message, "shutdown-delay-durationn: unknown kube-apiserver flag")
+	// the event is only recorded when the invalid arguments change
+	report(invalid)
+	require.Len(t, recorder.Events(), 1)
+}
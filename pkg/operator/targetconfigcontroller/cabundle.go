@@ -0,0 +1,189 @@
+package targetconfigcontroller
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/util/cert"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resourcesynccontroller"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+// ClientCATargets are the secrets with the client certificates that are
+// verified with the client-ca config map.
+var ClientCATargets = []resourcesynccontroller.ResourceLocation{
+	{Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace, Name: "kube-controller-manager-client-cert-key"},
+	{Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace, Name: "kube-scheduler-client-cert-key"},
+	{Namespace: operatorclient.TargetNamespace, Name: "control-plane-node-admin-client-cert-key"},
+	{Namespace: operatorclient.TargetNamespace, Name: "check-endpoints-client-cert-key"},
+	{Namespace: operatorclient.TargetNamespace, Name: "kubelet-client"},
+	{Namespace: operatorclient.OperatorNamespace, Name: "node-system-admin-client"},
+}
+
+// KubeAPIServerServerCATargets are the secrets with the serving certificates
+// that are verified with the kube-apiserver-server-ca config map.
+var KubeAPIServerServerCATargets = []resourcesynccontroller.ResourceLocation{
+	{Namespace: operatorclient.TargetNamespace, Name: "localhost-serving-cert-certkey"},
+	{Namespace: operatorclient.TargetNamespace, Name: "service-network-serving-certkey"},
+	{Namespace: operatorclient.TargetNamespace, Name: "external-loadbalancer-serving-certkey"},
+	{Namespace: operatorclient.TargetNamespace, Name: "internal-loadbalancer-serving-certkey"},
+	{Namespace: operatorclient.TargetNamespace, Name: "localhost-recovery-serving-certkey"},
+}
+
+// CABundleTargetNames returns the names of the ClientCATargets and
+// KubeAPIServerServerCATargets secrets.
+func CABundleTargetNames() []string {
+	var names []string
+	for _, target := range append(append([]resourcesynccontroller.ResourceLocation{}, ClientCATargets...), KubeAPIServerServerCATargets...) {
+		names = append(names, target.Name)
+	}
+	return names
+}
+
+// prunedCertificate is a certificate that was removed from a CA bundle.
+type prunedCertificate struct {
+	certificate *x509.Certificate
+	reason      string
+}
+
+func (p prunedCertificate) String() string {
+	return fmt.Sprintf("%q (serial %s, expires %s): %s", p.certificate.Subject.CommonName, p.certificate.SerialNumber, p.certificate.NotAfter.UTC().Format(time.RFC3339), p.reason)
+}
+
+// combineCABundle combines the CA certificates of the sources into the
+// destination config map like
+// resourcesynccontroller.CombineCABundleConfigMapsOptimistically, dropping
+// expired and duplicate certificates. In addition a CA that was removed from
+// its source stays in the bundle as long as it is valid and the issuer of a
+// valid certificate in one of the targets. It returns the certificates removed
+// from the destination.
+func combineCABundle(
+	destination *corev1.ConfigMap,
+	configMapLister corev1listers.ConfigMapLister,
+	secretLister corev1listers.SecretLister,
+	additionalAnnotations certrotation.AdditionalAnnotations,
+	sources []resourcesynccontroller.ResourceLocation,
+	targets []resourcesynccontroller.ResourceLocation,
+) (*corev1.ConfigMap, bool, []prunedCertificate, error) {
+	now := time.Now()
+	cm, _, err := resourcesynccontroller.CombineCABundleConfigMapsOptimistically(destination, configMapLister, additionalAnnotations, sources...)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	combined, err := cert.ParseCertsPEM([]byte(cm.Data["ca-bundle.crt"]))
+	if err != nil && len(cm.Data["ca-bundle.crt"]) > 0 {
+		return nil, false, nil, err
+	}
+	kept := map[string]bool{}
+	for _, certificate := range combined {
+		kept[string(certificate.Raw)] = true
+	}
+
+	var previous []*x509.Certificate
+	if content := destination.Data["ca-bundle.crt"]; len(content) > 0 {
+		previous, err = cert.ParseCertsPEM([]byte(content))
+		if err != nil {
+			klog.Warningf("Replacing malformed configmap/%s in %q: %v", destination.Name, destination.Namespace, err)
+		}
+	}
+
+	issued, err := validTargetCertificates(secretLister, targets, now)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	var protected []*x509.Certificate
+	for _, certificate := range previous {
+		if kept[string(certificate.Raw)] || now.After(certificate.NotAfter) || !issuesAny(certificate, issued) {
+			continue
+		}
+		kept[string(certificate.Raw)] = true
+		protected = append(protected, certificate)
+	}
+	if len(protected) > 0 {
+		caBytes, err := crypto.EncodeCertificates(append(combined, protected...)...)
+		if err != nil {
+			return nil, false, nil, err
+		}
+		cm.Data["ca-bundle.crt"] = string(caBytes)
+	}
+
+	var pruned []prunedCertificate
+	seen := map[string]bool{}
+	for _, certificate := range previous {
+		switch {
+		case seen[string(certificate.Raw)]:
+			pruned = append(pruned, prunedCertificate{certificate: certificate, reason: "duplicate"})
+		case kept[string(certificate.Raw)]:
+		case now.After(certificate.NotAfter):
+			pruned = append(pruned, prunedCertificate{certificate: certificate, reason: "expired"})
+		default:
+			pruned = append(pruned, prunedCertificate{certificate: certificate, reason: "not in any source"})
+		}
+		seen[string(certificate.Raw)] = true
+	}
+
+	modified := !equality.Semantic.DeepEqual(destination.ObjectMeta, cm.ObjectMeta) || !reflect.DeepEqual(destination.Data, cm.Data)
+	return cm, modified, pruned, nil
+}
+
+// validTargetCertificates returns the certificates of the targets that are
+// not expired.
+func validTargetCertificates(lister corev1listers.SecretLister, targets []resourcesynccontroller.ResourceLocation, now time.Time) ([]*x509.Certificate, error) {
+	var valid []*x509.Certificate
+	for _, target := range targets {
+		secret, err := lister.Secrets(target.Namespace).Get(target.Name)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		certificates, err := cert.ParseCertsPEM(secret.Data[corev1.TLSCertKey])
+		if err != nil {
+			// a broken target gets regenerated by its rotator, it doesn't need a CA
+			klog.V(4).Infof("Ignoring secret/%s in %q: %v", target.Name, target.Namespace, err)
+			continue
+		}
+		if now.After(certificates[0].NotAfter) {
+			continue
+		}
+		valid = append(valid, certificates[0])
+	}
+	return valid, nil
+}
+
+func issuesAny(ca *x509.Certificate, certificates []*x509.Certificate) bool {
+	for _, certificate := range certificates {
+		if bytes.Equal(certificate.RawIssuer, ca.RawSubject) && certificate.CheckSignatureFrom(ca) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// reportPrunedCertificates records an event listing the certificates that were
+// removed from the CA bundle.
+func reportPrunedCertificates(recorder events.Recorder, configMap *corev1.ConfigMap, pruned []prunedCertificate) {
+	if len(pruned) == 0 {
+		return
+	}
+	descriptions := make([]string, 0, len(pruned))
+	for _, p := range pruned {
+		descriptions = append(descriptions, p.String())
+	}
+	recorder.Eventf("CABundleCertificatesPruned", "Removed %d certificates from configmap/%s -n %s: %s", len(pruned), configMap.Name, configMap.Namespace, strings.Join(descriptions, "; "))
+}
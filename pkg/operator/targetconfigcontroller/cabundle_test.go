@@ -0,0 +1,190 @@
+package targetconfigcontroller
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/clock"
+
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resourcesynccontroller"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+func TestCombineCABundle(t *testing.T) {
+	expired := newTestCAAt(t, "expired", time.Now().Add(-2*time.Hour), time.Hour)
+	valid := newTestCA(t, "valid", 48*time.Hour)
+	removed := newTestCA(t, "removed", 48*time.Hour)
+
+	protectedCert, err := removed.MakeClientCertificateForDuration(&user.DefaultInfo{Name: "kubelet"}, 24*time.Hour)
+	require.NoError(t, err)
+	expiredIssuerCert, err := expired.MakeClientCertificateForDuration(&user.DefaultInfo{Name: "kubelet"}, 24*time.Hour)
+	require.NoError(t, err)
+	expiredCert := newTestClientCertAt(t, removed, time.Now().Add(-2*time.Hour), time.Hour)
+
+	source := resourcesynccontroller.ResourceLocation{Namespace: operatorclient.OperatorNamespace, Name: "source-ca"}
+	target := resourcesynccontroller.ResourceLocation{Namespace: operatorclient.TargetNamespace, Name: "kubelet-client"}
+
+	tests := []struct {
+		name            string
+		source          []*crypto.CA
+		destination     []*crypto.CA
+		targetCert      *crypto.TLSCertificateConfig
+		expectedBundle  []*crypto.CA
+		expectedPruned  []string
+		expectedChanged bool
+	}{
+		{
+			name:            "expired and duplicates are pruned",
+			source:          []*crypto.CA{expired, valid, valid},
+			destination:     []*crypto.CA{expired, valid, valid},
+			expectedBundle:  []*crypto.CA{valid},
+			expectedPruned:  []string{`"expired"`, "expired", `"valid"`, "duplicate"},
+			expectedChanged: true,
+		},
+		{
+			name:            "removed from sources",
+			source:          []*crypto.CA{valid},
+			destination:     []*crypto.CA{removed, valid},
+			expectedBundle:  []*crypto.CA{valid},
+			expectedPruned:  []string{`"removed"`, "not in any source"},
+			expectedChanged: true,
+		},
+		{
+			name:            "unchanged",
+			source:          []*crypto.CA{valid},
+			destination:     []*crypto.CA{valid},
+			expectedBundle:  []*crypto.CA{valid},
+			expectedChanged: false,
+		},
+		{
+			name:            "expired signer of a valid target is pruned",
+			source:          []*crypto.CA{expired, valid},
+			destination:     []*crypto.CA{expired, valid},
+			targetCert:      expiredIssuerCert,
+			expectedBundle:  []*crypto.CA{valid},
+			expectedPruned:  []string{`"expired"`, "expired"},
+			expectedChanged: true,
+		},
+		{
+			name:            "expired target does not protect its signer",
+			source:          []*crypto.CA{valid},
+			destination:     []*crypto.CA{removed, valid},
+			targetCert:      expiredCert,
+			expectedBundle:  []*crypto.CA{valid},
+			expectedPruned:  []string{`"removed"`, "not in any source"},
+			expectedChanged: true,
+		},
+		{
+			name:            "signer of a valid target is kept after removal from its source",
+			source:          []*crypto.CA{valid},
+			destination:     []*crypto.CA{removed, valid},
+			targetCert:      protectedCert,
+			expectedBundle:  []*crypto.CA{valid, removed},
+			expectedChanged: true,
+		},
+		{
+			name:            "kept signer of a valid target is unchanged",
+			source:          []*crypto.CA{valid},
+			destination:     []*crypto.CA{valid, removed},
+			targetCert:      protectedCert,
+			expectedBundle:  []*crypto.CA{valid, removed},
+			expectedChanged: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			configMaps := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			require.NoError(t, configMaps.Add(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: source.Namespace, Name: source.Name},
+				Data:       map[string]string{"ca-bundle.crt": encodeTestCAs(t, test.source...)},
+			}))
+			secrets := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if test.targetCert != nil {
+				certPEM, keyPEM, err := test.targetCert.GetPEMBytes()
+				require.NoError(t, err)
+				require.NoError(t, secrets.Add(&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: target.Namespace, Name: target.Name},
+					Data:       map[string][]byte{corev1.TLSCertKey: certPEM, corev1.TLSPrivateKeyKey: keyPEM},
+				}))
+			}
+			destination := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.TargetNamespace, Name: "client-ca"},
+				Data:       map[string]string{"ca-bundle.crt": encodeTestCAs(t, test.destination...)},
+			}
+
+			combined, changed, pruned, err := combineCABundle(
+				destination,
+				corev1listers.NewConfigMapLister(configMaps),
+				corev1listers.NewSecretLister(secrets),
+				certrotation.AdditionalAnnotations{},
+				[]resourcesynccontroller.ResourceLocation{source},
+				[]resourcesynccontroller.ResourceLocation{target},
+			)
+			require.NoError(t, err)
+			require.Equal(t, test.expectedChanged, changed)
+			require.Equal(t, encodeTestCAs(t, test.expectedBundle...), combined.Data["ca-bundle.crt"])
+
+			recorder := events.NewInMemoryRecorder("test", clock.RealClock{})
+			reportPrunedCertificates(recorder, combined, pruned)
+			if len(test.expectedPruned) == 0 {
+				require.Empty(t, pruned)
+				require.Empty(t, recorder.Events())
+				return
+			}
+			require.Len(t, recorder.Events(), 1)
+			event := recorder.Events()[0]
+			require.Equal(t, "CABundleCertificatesPruned", event.Reason)
+			// the pruned certificates are listed in the order of the old bundle
+			message := event.Message
+			for _, expected := range test.expectedPruned {
+				i := strings.Index(message, expected)
+				require.GreaterOrEqual(t, i, 0, "expected %q in %q", expected, event.Message)
+				message = message[i+len(expected):]
+			}
+		})
+	}
+}
+
+func newTestCA(t *testing.T, name string, lifetime time.Duration) *crypto.CA {
+	config, err := crypto.MakeSelfSignedCAConfigForDuration(name, lifetime)
+	require.NoError(t, err)
+	return &crypto.CA{Config: config, SerialGenerator: &crypto.RandomSerialGenerator{}}
+}
+
+func newTestCAAt(t *testing.T, name string, at time.Time, lifetime time.Duration) *crypto.CA {
+	config, err := crypto.UnsafeMakeSelfSignedCAConfigForDurationAtTime(name, func() time.Time { return at }, lifetime)
+	require.NoError(t, err)
+	return &crypto.CA{Config: config, SerialGenerator: &crypto.RandomSerialGenerator{}}
+}
+
+func newTestClientCertAt(t *testing.T, ca *crypto.CA, at time.Time, lifetime time.Duration) *crypto.TLSCertificateConfig {
+	publicKey, privateKey, err := crypto.NewKeyPair()
+	require.NoError(t, err)
+	certificate, err := ca.SignCertificate(crypto.NewClientCertificateTemplateForDuration(pkix.Name{CommonName: "kubelet"}, lifetime, func() time.Time { return at }), publicKey)
+	require.NoError(t, err)
+	return &crypto.TLSCertificateConfig{Certs: append([]*x509.Certificate{certificate}, ca.Config.Certs...), Key: privateKey}
+}
+
+func encodeTestCAs(t *testing.T, cas ...*crypto.CA) string {
+	var certPEM []byte
+	for _, ca := range cas {
+		caPEM, err := crypto.EncodeCertificates(ca.Config.Certs...)
+		require.NoError(t, err)
+		certPEM = append(certPEM, caPEM...)
+	}
+	return string(certPEM)
+}
@@ -1,5 +1,152 @@
 package targetconfigcontroller
 
-func RemoveConfig(dst, src interface{}, currentPath string) (interface{}, error) {
-	return dst, nil
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ghodss/yaml"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const protectedConfigOverridesConditionType = "ProtectedConfigOverridesIgnored"
+
+// allowProtectedConfigOverridesKey lists the protected config paths that
+// unsupportedConfigOverrides may override anyway, for support cases.
+const allowProtectedConfigOverridesKey = "allowProtectedConfigOverrides"
+
+// protectedConfigPaths are the paths of the kube-apiserver config that
+// unsupportedConfigOverrides don't override. With a wrong value the
+// kube-apiserver can't reach etcd or authenticate and authorize requests, and
+// the operator can't roll out a fix through it anymore.
+var protectedConfigPaths = []string{
+	".apiServerArguments.authorization-mode",
+	".apiServerArguments.client-ca-file",
+	".apiServerArguments.etcd-cafile",
+	".apiServerArguments.etcd-certfile",
+	".apiServerArguments.etcd-keyfile",
+	".apiServerArguments.etcd-servers",
+	".authConfig.requestHeader",
+	".servingInfo.clientCA",
+	".storageConfig",
+}
+
+// restrictConfigOverrides returns the given unsupportedConfigOverrides without
+// the protected config paths, along with the paths that are dropped and the
+// protected config paths that are explicitly allowed. An override of an
+// ancestor of a protected path that isn't an object, e.g. a null
+// apiServerArguments, would replace the protected path too and is dropped as a
+// whole.
+func restrictConfigOverrides(unsupportedConfigOverrides []byte) (restricted []byte, ignored, allowed []string, err error) {
+	if len(unsupportedConfigOverrides) == 0 {
+		return unsupportedConfigOverrides, nil, nil, nil
+	}
+	overridesJSON, err := yaml.YAMLToJSON(unsupportedConfigOverrides)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	overrides := map[string]interface{}{}
+	if err := json.Unmarshal(overridesJSON, &overrides); err != nil {
+		return nil, nil, nil, err
+	}
+	allowList, _, err := unstructured.NestedStringSlice(overrides, allowProtectedConfigOverridesKey)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%s must be a list of config paths: %w", allowProtectedConfigOverridesKey, err)
+	}
+	allowSet := sets.New(allowList...)
+
+	// the allowed paths are checked last, an ignored override may contain them
+	for _, path := range protectedConfigPaths {
+		if allowSet.Has(path) {
+			continue
+		}
+		fields, found := overriddenFields(overrides, strings.Split(strings.TrimPrefix(path, "."), "."))
+		if !found {
+			continue
+		}
+		unstructured.RemoveNestedField(overrides, fields...)
+		ignored = append(ignored, "."+strings.Join(fields, "."))
+	}
+	for _, path := range protectedConfigPaths {
+		if !allowSet.Has(path) {
+			continue
+		}
+		if _, found := overriddenFields(overrides, strings.Split(strings.TrimPrefix(path, "."), ".")); found {
+			allowed = append(allowed, path)
+		}
+	}
+	if len(ignored) == 0 {
+		return unsupportedConfigOverrides, nil, allowed, nil
+	}
+
+	restricted, err = json.Marshal(overrides)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return restricted, ignored, allowed, nil
+}
+
+// overriddenFields returns the fields of the given path that the config
+// overrides: the path itself, or its closest ancestor that isn't an object.
+func overriddenFields(config map[string]interface{}, fields []string) ([]string, bool) {
+	current := config
+	for i, field := range fields {
+		value, ok := current[field]
+		if !ok {
+			return nil, false
+		}
+		valueMap, isMap := value.(map[string]interface{})
+		if i == len(fields)-1 || !isMap {
+			return fields[:i+1], true
+		}
+		current = valueMap
+	}
+	return nil, false
+}
+
+// reportProtectedConfigOverrides reports the protected config paths set by
+// unsupportedConfigOverrides in a condition, and in an event when they change.
+func reportProtectedConfigOverrides(ctx context.Context, operatorClient v1helpers.StaticPodOperatorClient, recorder events.Recorder, operatorSpec *operatorv1.StaticPodOperatorSpec) error {
+	_, ignored, allowed, err := restrictConfigOverrides(operatorSpec.UnsupportedConfigOverrides.Raw)
+	if err != nil {
+		// reported along with the config
+		return nil
+	}
+
+	condition := operatorv1.OperatorCondition{
+		Type:   protectedConfigOverridesConditionType,
+		Status: operatorv1.ConditionFalse,
+		Reason: "AsExpected",
+	}
+	var messages []string
+	if len(ignored) > 0 {
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "ProtectedPathsOverridden"
+		messages = append(messages, fmt.Sprintf("unsupportedConfigOverrides can't override protected config paths, the overrides of %s are ignored. Only list the protected paths in %s when asked to by support.", strings.Join(ignored, ", "), allowProtectedConfigOverridesKey))
+	}
+	if len(allowed) > 0 {
+		if len(ignored) == 0 {
+			condition.Reason = "ProtectedPathsAllowed"
+		}
+		messages = append(messages, fmt.Sprintf("unsupportedConfigOverrides override the protected config paths %s, as allowed by %s.", strings.Join(allowed, ", "), allowProtectedConfigOverridesKey))
+	}
+	condition.Message = strings.Join(messages, "\n")
+
+	_, updated, err := v1helpers.UpdateStaticPodStatus(ctx, operatorClient, v1helpers.UpdateStaticPodConditionFn(condition))
+	if err != nil {
+		return err
+	}
+	if updated && len(ignored) > 0 {
+		recorder.Warningf("ProtectedConfigOverridesIgnored", "Ignoring the unsupportedConfigOverrides of the protected config paths %s", strings.Join(ignored, ", "))
+	}
+	if updated && len(allowed) > 0 {
+		recorder.Warningf("ProtectedConfigOverridesAllowed", "Applying the unsupportedConfigOverrides of the protected config paths %s, as allowed by %s", strings.Join(allowed, ", "), allowProtectedConfigOverridesKey)
+	}
+	return nil
 }
@@ -0,0 +1,187 @@
+package targetconfigcontroller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/clock"
+
+	kubecontrolplanev1 "github.com/openshift/api/kubecontrolplane/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+func TestManageKubeAPIServerConfigProtectedOverrides(t *testing.T) {
+	observedConfig := `{"apiServerArguments":{"etcd-servers":["https://10.0.0.1:2379"]},"storageConfig":{"urls":["https://10.0.0.1:2379"]}}`
+	for _, test := range []struct {
+		name                string
+		overrides           string
+		expectedEtcdServers []string
+		expectedStorageURLs []string
+	}{
+		{
+			name:                "protected paths are ignored",
+			overrides:           `{"apiServerArguments":{"etcd-servers":["https://10.0.0.9:2379"],"authorization-mode":["AlwaysAllow"],"v":["4"]},"storageConfig":{"urls":["https://10.0.0.9:2379"]}}`,
+			expectedEtcdServers: []string{"https://10.0.0.1:2379"},
+			expectedStorageURLs: []string{"https://10.0.0.1:2379"},
+		},
+		{
+			name:                "allowed protected paths are overridden",
+			overrides:           `{"allowProtectedConfigOverrides":[".apiServerArguments.etcd-servers"],"apiServerArguments":{"etcd-servers":["https://10.0.0.9:2379"],"v":["4"]},"storageConfig":{"urls":["https://10.0.0.9:2379"]}}`,
+			expectedEtcdServers: []string{"https://10.0.0.9:2379"},
+			expectedStorageURLs: []string{"https://10.0.0.1:2379"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			operatorSpec := &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+				ObservedConfig:             runtime.RawExtension{Raw: []byte(observedConfig)},
+				UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(test.overrides)},
+			}}
+			configMap, _, err := manageKubeAPIServerConfig(context.TODO(), fake.NewSimpleClientset().CoreV1(), events.NewInMemoryRecorder("test", clock.RealClock{}), operatorSpec, nil)
+			require.NoError(t, err)
+
+			config := &kubecontrolplanev1.KubeAPIServerConfig{}
+			require.NoError(t, yaml.Unmarshal([]byte(configMap.Data["config.yaml"]), config))
+			require.Equal(t, test.expectedEtcdServers, []string(config.APIServerArguments["etcd-servers"]))
+			require.Equal(t, test.expectedStorageURLs, config.StorageConfig.URLs)
+			require.NotContains(t, config.APIServerArguments["authorization-mode"], "AlwaysAllow")
+			require.Equal(t, []string{"4"}, []string(config.APIServerArguments["v"]))
+			require.NotContains(t, configMap.Data["config.yaml"], "allowProtectedConfigOverrides")
+		})
+	}
+}
+
+func TestManageKubeAPIServerConfigProtectedParentOverrides(t *testing.T) {
+	observedConfig := `{"apiServerArguments":{"etcd-servers":["https://10.0.0.1:2379"]},"servingInfo":{"clientCA":"/etc/kubernetes/static-pod-certs/configmaps/client-ca/ca-bundle.crt"},"storageConfig":{"urls":["https://10.0.0.1:2379"]}}`
+	for _, overrides := range []string{
+		`{"apiServerArguments":null,"servingInfo":null,"storageConfig":null}`,
+		`{"apiServerArguments":"x","servingInfo":"x","storageConfig":"x"}`,
+		`{"apiServerArguments":["x"],"servingInfo":1,"storageConfig":true}`,
+	} {
+		t.Run(overrides, func(t *testing.T) {
+			operatorSpec := &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+				ObservedConfig:             runtime.RawExtension{Raw: []byte(observedConfig)},
+				UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(overrides)},
+			}}
+			configMap, _, err := manageKubeAPIServerConfig(context.TODO(), fake.NewSimpleClientset().CoreV1(), events.NewInMemoryRecorder("test", clock.RealClock{}), operatorSpec, nil)
+			require.NoError(t, err)
+
+			config := &kubecontrolplanev1.KubeAPIServerConfig{}
+			require.NoError(t, yaml.Unmarshal([]byte(configMap.Data["config.yaml"]), config))
+			require.Equal(t, []string{"https://10.0.0.1:2379"}, []string(config.APIServerArguments["etcd-servers"]))
+			require.Equal(t, "/etc/kubernetes/static-pod-certs/configmaps/client-ca/ca-bundle.crt", config.ServingInfo.ClientCA)
+			require.Equal(t, []string{"https://10.0.0.1:2379"}, config.StorageConfig.URLs)
+		})
+	}
+}
+
+func TestRestrictConfigOverrides(t *testing.T) {
+	for _, test := range []struct {
+		name               string
+		overrides          string
+		expectedRestricted string
+		expectedIgnored    []string
+		expectedAllowed    []string
+	}{
+		{
+			name:               "unprotected paths are kept",
+			overrides:          `{"apiServerArguments":{"v":["4"]},"servingInfo":{"bindAddress":"0.0.0.0:6443"}}`,
+			expectedRestricted: `{"apiServerArguments":{"v":["4"]},"servingInfo":{"bindAddress":"0.0.0.0:6443"}}`,
+		},
+		{
+			name:               "protected children are dropped key by key",
+			overrides:          `{"apiServerArguments":{"etcd-servers":["https://10.0.0.9:2379"],"v":["4"]},"servingInfo":{"clientCA":"/tmp/ca.crt","bindAddress":"0.0.0.0:6443"}}`,
+			expectedRestricted: `{"apiServerArguments":{"v":["4"]},"servingInfo":{"bindAddress":"0.0.0.0:6443"}}`,
+			expectedIgnored:    []string{".apiServerArguments.etcd-servers", ".servingInfo.clientCA"},
+		},
+		{
+			name:               "null parents are dropped",
+			overrides:          `{"apiServerArguments":null,"authConfig":null,"servingInfo":null,"storageConfig":null,"admission":null}`,
+			expectedRestricted: `{"admission":null}`,
+			expectedIgnored:    []string{".apiServerArguments", ".authConfig", ".servingInfo", ".storageConfig"},
+		},
+		{
+			name:               "scalar parents are dropped",
+			overrides:          `{"apiServerArguments":"x","authConfig":1,"servingInfo":["x"],"storageConfig":"x"}`,
+			expectedRestricted: `{}`,
+			expectedIgnored:    []string{".apiServerArguments", ".authConfig", ".servingInfo", ".storageConfig"},
+		},
+		{
+			name:               "empty parents are merged",
+			overrides:          `{"apiServerArguments":{},"servingInfo":{}}`,
+			expectedRestricted: `{"apiServerArguments":{},"servingInfo":{}}`,
+		},
+		{
+			name:               "an allowed path doesn't allow a null parent",
+			overrides:          `{"allowProtectedConfigOverrides":[".apiServerArguments.etcd-servers"],"apiServerArguments":null}`,
+			expectedRestricted: `{"allowProtectedConfigOverrides":[".apiServerArguments.etcd-servers"]}`,
+			expectedIgnored:    []string{".apiServerArguments"},
+		},
+		{
+			name:               "allowed paths are kept",
+			overrides:          `{"allowProtectedConfigOverrides":[".apiServerArguments.etcd-servers",".storageConfig"],"apiServerArguments":{"etcd-servers":["https://10.0.0.9:2379"]},"storageConfig":null}`,
+			expectedRestricted: `{"allowProtectedConfigOverrides":[".apiServerArguments.etcd-servers",".storageConfig"],"apiServerArguments":{"etcd-servers":["https://10.0.0.9:2379"]},"storageConfig":null}`,
+			expectedAllowed:    []string{".apiServerArguments.etcd-servers", ".storageConfig"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			restricted, ignored, allowed, err := restrictConfigOverrides([]byte(test.overrides))
+			require.NoError(t, err)
+			require.JSONEq(t, test.expectedRestricted, string(restricted))
+			require.Equal(t, test.expectedIgnored, ignored)
+			require.Equal(t, test.expectedAllowed, allowed)
+		})
+	}
+}
+
+func TestReportProtectedConfigOverrides(t *testing.T) {
+	operatorClient := v1helpers.NewFakeStaticPodOperatorClient(&operatorv1.StaticPodOperatorSpec{}, &operatorv1.StaticPodOperatorStatus{}, nil, nil)
+	recorder := events.NewInMemoryRecorder("test", clock.RealClock{})
+	report := func(overrides string) *operatorv1.OperatorCondition {
+		t.Helper()
+		operatorSpec := &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+			UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(overrides)},
+		}}
+		require.NoError(t, reportProtectedConfigOverrides(context.TODO(), operatorClient, recorder, operatorSpec))
+		_, status, _, err := operatorClient.GetStaticPodOperatorState()
+		require.NoError(t, err)
+		return v1helpers.FindOperatorCondition(status.Conditions, protectedConfigOverridesConditionType)
+	}
+	eventCount := func(reason string) int {
+		count := 0
+		for _, event := range recorder.Events() {
+			if event.Reason == reason {
+				count++
+			}
+		}
+		return count
+	}
+
+	condition := report(`{"apiServerArguments":{"v":["4"]}}`)
+	require.Equal(t, operatorv1.ConditionFalse, condition.Status)
+	require.Equal(t, "AsExpected", condition.Reason)
+
+	ignored := `{"apiServerArguments":{"client-ca-file":["/tmp/ca.crt"]},"servingInfo":{"clientCA":"/tmp/ca.crt"}}`
+	condition = report(ignored)
+	require.Equal(t, operatorv1.ConditionTrue, condition.Status)
+	require.Equal(t, "ProtectedPathsOverridden", condition.Reason)
+	require.Contains(t, condition.Message, ".apiServerArguments.client-ca-file, .servingInfo.clientCA")
+	// the event is only recorded when the ignored paths change
+	report(ignored)
+	require.Equal(t, 1, eventCount("ProtectedConfigOverridesIgnored"))
+
+	condition = report(`allowProtectedConfigOverrides: [".storageConfig"]
+storageConfig:
+  urls: ["https://10.0.0.9:2379"]`)
+	require.Equal(t, operatorv1.ConditionFalse, condition.Status)
+	require.Equal(t, "ProtectedPathsAllowed", condition.Reason)
+	require.Equal(t, 1, eventCount("ProtectedConfigOverridesAllowed"))
+
+	_, _, _, err := restrictConfigOverrides([]byte(`{"allowProtectedConfigOverrides":".storageConfig"}`))
+	require.Error(t, err)
+}
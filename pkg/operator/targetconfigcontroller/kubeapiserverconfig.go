@@ -0,0 +1,144 @@
+package targetconfigcontroller
+
+import (
+	"encoding/json"
+	"fmt"
+
+	kubecontrolplanev1 "github.com/openshift/api/kubecontrolplane/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/bindata"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation/node"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/externaljwtsigner"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+// The names of the layers of the kube-apiserver config.
+const (
+	DefaultConfigLayer              = "defaultconfig.yaml"
+	AuthorizationModeLayer          = "authorization-mode"
+	ConfigOverridesLayer            = "config-overrides.yaml"
+	ObservedConfigLayer             = "observedConfig"
+	UnsupportedConfigOverridesLayer = "unsupportedConfigOverrides"
+)
+
+// ConfigLayer is one of the sparse configs merged into the config.yaml of the
+// kube-apiserver, each one overriding the ones before it.
+type ConfigLayer struct {
+	Name   string
+	Config []byte
+}
+
+// SliceMergedConfigPaths are the paths whose string slices are merged across
+// the layers instead of being overridden. resourcemerge doesn't merge slice
+// contents.
+var SliceMergedConfigPaths = []string{
+	".apiServerArguments.enable-admission-plugins",
+	".apiServerArguments.disable-admission-plugins",
+}
+
+// KubeAPIServerConfigLayers returns the layers of the kube-apiserver config
+// for the given operator spec, in merge order.
+func KubeAPIServerConfigLayers(operatorSpec *operatorv1.StaticPodOperatorSpec) ([]ConfigLayer, error) {
+	// Guarantee the authorization-mode will be present in the base config, regardless of whether the observer is running
+	authModeOverride := map[string]interface{}{}
+	node.AddAuthorizationModes(authModeOverride, false)
+	authModeOverrideJSON, err := json.Marshal(authModeOverride)
+	if err != nil {
+		return nil, err
+	}
+
+	return []ConfigLayer{
+		{Name: DefaultConfigLayer, Config: bindata.MustAsset("assets/config/defaultconfig.yaml")},
+		{Name: AuthorizationModeLayer, Config: authModeOverrideJSON},
+		{Name: ConfigOverridesLayer, Config: bindata.MustAsset("assets/config/config-overrides.yaml")},
+		{Name: ObservedConfigLayer, Config: operatorSpec.ObservedConfig.Raw},
+		{Name: UnsupportedConfigOverridesLayer, Config: operatorSpec.UnsupportedConfigOverrides.Raw},
+	}, nil
+}
+
+// IgnoredConfigOverrides returns the paths of the unsupportedConfigOverrides
+// that aren't applied because they override protected config paths.
+func IgnoredConfigOverrides(operatorSpec *operatorv1.StaticPodOperatorSpec) ([]string, error) {
+	_, ignored, _, err := restrictConfigOverrides(operatorSpec.UnsupportedConfigOverrides.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid unsupportedConfigOverrides: %w", err)
+	}
+	return ignored, nil
+}
+
+// ExternalJWTSigner reads the external JWT signer settings from the operator
+// config map. An invalid configuration is ignored, the
+// BoundSATokenSignerController reports it.
+func ExternalJWTSigner(configMapLister corev1listers.ConfigMapLister) (*externaljwtsigner.Config, error) {
+	configMap, err := operatorconfig.Get(configMapLister)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := externaljwtsigner.ConfigFromConfigMap(configMap)
+	if err != nil {
+		klog.Warningf("Ignoring the external JWT signer: %v", err)
+		return nil, nil
+	}
+	return signer, nil
+}
+
+// RemovedConfigPaths returns the paths the TargetConfigController removes
+// from the merged config with the given external JWT signer.
+func RemovedConfigPaths(signer *externaljwtsigner.Config) [][]string {
+	if signer != nil {
+		// the kube-apiserver refuses the signing key and the public key files
+		// along with an external signer, the signer provides the keys
+		return externalJWTSignerRemovedFields
+	}
+	return nil
+}
+
+// RenderKubeAPIServerConfig returns the config.yaml of the kube-apiserver for
+// the given operator spec and external JWT signer, as JSON.
+func RenderKubeAPIServerConfig(operatorSpec *operatorv1.StaticPodOperatorSpec, signer *externaljwtsigner.Config) ([]byte, error) {
+	layers, err := KubeAPIServerConfigLayers(operatorSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	// resourcemerge will not merge slice contents; therefore we must set up special merge rules for
+	// enable-admission-plugins/disable-admission-plugins in order to merge the config from the various sources
+	specialMergeRules := map[string]resourcemerge.MergeFunc{}
+	for _, path := range SliceMergedConfigPaths {
+		specialMergeRules[path] = mergeStringSlices
+	}
+
+	var baseLayers [][]byte
+	for _, layer := range layers[:len(layers)-1] {
+		baseLayers = append(baseLayers, layer.Config)
+	}
+	mergedConfig, err := resourcemerge.MergeProcessConfig(specialMergeRules, baseLayers...)
+	if err != nil {
+		return nil, err
+	}
+
+	// the unsupported overrides are merged last, without the protected paths
+	// unless they are explicitly allowed
+	overrides, _, _, err := restrictConfigOverrides(layers[len(layers)-1].Config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid unsupportedConfigOverrides: %w", err)
+	}
+	config, err := resourcemerge.MergePrunedProcessConfig(
+		&kubecontrolplanev1.KubeAPIServerConfig{},
+		specialMergeRules,
+		mergedConfig,
+		overrides,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if removed := RemovedConfigPaths(signer); len(removed) > 0 {
+		return removeConfigFields(config, removed...)
+	}
+	return config, nil
+}
@@ -0,0 +1,206 @@
+package targetconfigcontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation/node"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+const (
+	podResourcesConditionType = "KubeAPIServerPodResources"
+
+	// podResourcesKey is the key of the explicit kube-apiserver resources in
+	// the operator config map.
+	podResourcesKey = "kubeAPIServerResources"
+)
+
+// the kube-apiserver container requests a share of the smallest master node,
+// bounded by the historical requests and by a cap leaving room for etcd and the
+// other control plane components.
+const (
+	minCPURequestMillis  = 265
+	maxCPURequestMillis  = 2000
+	cpuRequestPercent    = 3
+	minMemoryRequest     = 1 << 30
+	maxMemoryRequest     = 8 << 30
+	memoryRequestPercent = 5
+	// GOMEMLIMIT is a soft limit making the Go GC work harder when it is
+	// approached, instead of letting the heap grow until the node runs out of
+	// memory. Below 2GiB the kube-apiserver would mostly be garbage collecting.
+	minGoMemLimit     = 2 << 30
+	goMemLimitPercent = 60
+)
+
+// PodResourcesConfig sets the kube-apiserver container resources explicitly,
+// instead of deriving them from the control plane allocatable.
+type PodResourcesConfig struct {
+	CPURequest    *resource.Quantity `json:"cpuRequest,omitempty"`
+	MemoryRequest *resource.Quantity `json:"memoryRequest,omitempty"`
+	GoMemLimit    *resource.Quantity `json:"goMemLimit,omitempty"`
+}
+
+// PodResourcesConfigFromConfigMap reads the explicit kube-apiserver resources
+// from the operator config map. It returns nil if none are set.
+func PodResourcesConfigFromConfigMap(configMap *corev1.ConfigMap) (*PodResourcesConfig, error) {
+	config := &PodResourcesConfig{}
+	found, err := operatorconfig.Unmarshal(configMap, podResourcesKey, config)
+	if !found || err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// ConfiguredPodResources reads the explicit kube-apiserver resources from the
+// operator config map. An invalid configuration is ignored.
+func ConfiguredPodResources(configMapLister corev1listers.ConfigMapLister) (*PodResourcesConfig, error) {
+	configMap, err := operatorconfig.Get(configMapLister)
+	if err != nil {
+		return nil, err
+	}
+	config, err := PodResourcesConfigFromConfigMap(configMap)
+	if err != nil {
+		klog.Warningf("Ignoring the kube-apiserver resources: %v", err)
+		return nil, nil
+	}
+	return config, nil
+}
+
+// kubeAPIServerPodResources are the resource requests and the GOMEMLIMIT of
+// the kube-apiserver container.
+type kubeAPIServerPodResources struct {
+	cpuRequestMillis   int64
+	memoryRequestBytes int64
+	// goMemLimitBytes is 0 when the Go runtime gets no memory limit.
+	goMemLimitBytes int64
+
+	// source is where the values come from: Default, ControlPlaneAllocatable
+	// or Configured.
+	source      string
+	allocatable string
+}
+
+func (r *kubeAPIServerPodResources) cpuRequest() string {
+	return resource.NewMilliQuantity(r.cpuRequestMillis, resource.DecimalSI).String()
+}
+
+func (r *kubeAPIServerPodResources) memoryRequest() string {
+	return resource.NewQuantity(r.memoryRequestBytes>>20<<20, resource.BinarySI).String()
+}
+
+// goMemLimit returns the GOMEMLIMIT in the format of the Go runtime, empty
+// when unset.
+func (r *kubeAPIServerPodResources) goMemLimit() string {
+	if r.goMemLimitBytes == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%dMiB", r.goMemLimitBytes>>20)
+}
+
+// podResourcesFromConfig derives the kube-apiserver container resources from
+// the allocatable resources of the smallest master node, observed by
+// ObserveControlPlaneAllocatable. The values set explicitly in the operator
+// config map take precedence. All are clamped to safe bounds.
+func podResourcesFromConfig(config map[string]interface{}, configured *PodResourcesConfig) (*kubeAPIServerPodResources, error) {
+	resources := &kubeAPIServerPodResources{
+		cpuRequestMillis:   minCPURequestMillis,
+		memoryRequestBytes: minMemoryRequest,
+		source:             "Default",
+	}
+
+	var allocatableMemory int64
+	allocatable, found, err := unstructured.NestedStringMap(config, node.ControlPlaneAllocatablePath...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to extract %q from the observed config: %v", strings.Join(node.ControlPlaneAllocatablePath, "."), err)
+	}
+	if found {
+		cpu, err := resource.ParseQuantity(allocatable["cpu"])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse observed value of %s.cpu: %v", strings.Join(node.ControlPlaneAllocatablePath, "."), err)
+		}
+		memory, err := resource.ParseQuantity(allocatable["memory"])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse observed value of %s.memory: %v", strings.Join(node.ControlPlaneAllocatablePath, "."), err)
+		}
+		allocatableMemory = memory.Value()
+		resources.cpuRequestMillis = cpu.MilliValue() * cpuRequestPercent / 100
+		resources.memoryRequestBytes = allocatableMemory / 100 * memoryRequestPercent
+		resources.goMemLimitBytes = allocatableMemory / 100 * goMemLimitPercent
+		resources.source = "ControlPlaneAllocatable"
+		resources.allocatable = fmt.Sprintf("cpu %s, memory %s", cpu.String(), memory.String())
+	}
+
+	if configured != nil {
+		if configured.CPURequest != nil {
+			resources.cpuRequestMillis = configured.CPURequest.MilliValue()
+			resources.source = "Configured"
+		}
+		if configured.MemoryRequest != nil {
+			resources.memoryRequestBytes = configured.MemoryRequest.Value()
+			resources.source = "Configured"
+		}
+		if configured.GoMemLimit != nil {
+			resources.goMemLimitBytes = configured.GoMemLimit.Value()
+			resources.source = "Configured"
+		}
+	}
+
+	// clamped to limit surprises
+	resources.cpuRequestMillis = clamp(resources.cpuRequestMillis, minCPURequestMillis, maxCPURequestMillis)
+	resources.memoryRequestBytes = clamp(resources.memoryRequestBytes, minMemoryRequest, maxMemoryRequest)
+	if resources.goMemLimitBytes != 0 {
+		resources.goMemLimitBytes = max(resources.goMemLimitBytes, minGoMemLimit)
+		if allocatableMemory > 0 {
+			resources.goMemLimitBytes = min(resources.goMemLimitBytes, allocatableMemory)
+		}
+	}
+	return resources, nil
+}
+
+func clamp(value, lower, upper int64) int64 {
+	return min(max(value, lower), upper)
+}
+
+// reportPodResources reports the resources rendered into the kube-apiserver
+// pod in a condition.
+func reportPodResources(ctx context.Context, operatorClient v1helpers.StaticPodOperatorClient, operatorSpec *operatorv1.StaticPodOperatorSpec, configured *PodResourcesConfig) error {
+	config, err := effectiveConfiguration(operatorSpec)
+	if err != nil {
+		return err
+	}
+	resources, err := podResourcesFromConfig(config, configured)
+	if err != nil {
+		return err
+	}
+
+	goMemLimit := "no GOMEMLIMIT"
+	if resources.goMemLimitBytes != 0 {
+		goMemLimit = "GOMEMLIMIT " + resources.goMemLimit()
+	}
+	message := fmt.Sprintf("The kube-apiserver requests cpu %s and memory %s, with %s", resources.cpuRequest(), resources.memoryRequest(), goMemLimit)
+	if len(resources.allocatable) > 0 {
+		message += fmt.Sprintf(", for a smallest master node allocatable of %s", resources.allocatable)
+	}
+	if resources.source == "Configured" {
+		message += fmt.Sprintf(", with the values of %s", operatorconfig.KeyPath(podResourcesKey))
+	}
+	condition := operatorv1.OperatorCondition{
+		Type:    podResourcesConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  resources.source,
+		Message: message + ".",
+	}
+	_, _, err = v1helpers.UpdateStaticPodStatus(ctx, operatorClient, v1helpers.UpdateStaticPodConditionFn(condition))
+	return err
+}
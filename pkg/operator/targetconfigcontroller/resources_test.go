@@ -0,0 +1,133 @@
+package targetconfigcontroller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/bindata"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+)
+
+func TestPodResourcesRenderedIntoPod(t *testing.T) {
+	for _, test := range []struct {
+		name               string
+		observedConfig     string
+		expectedCPU        string
+		expectedMemory     string
+		expectedGoMemLimit string
+	}{
+		{
+			name:           "defaults",
+			observedConfig: `{}`,
+			expectedCPU:    "265m",
+			expectedMemory: "1Gi",
+		},
+		{
+			name:               "derived from the control plane allocatable",
+			observedConfig:     `{"targetconfigcontroller":{"controlPlaneAllocatable":{"cpu":"16","memory":"32Gi"}}}`,
+			expectedCPU:        "480m",
+			expectedMemory:     "1638Mi",
+			expectedGoMemLimit: "19660MiB",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			operatorSpec := &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+				ObservedConfig: runtime.RawExtension{Raw: []byte(test.observedConfig)},
+			}}
+			podTemplate, err := manageTemplate(string(bindata.MustAsset("assets/kube-apiserver/pod.yaml")), "kube-apiserver:test", "operator:test", "v1", operatorSpec, nil)
+			require.NoError(t, err)
+			pod := resourceread.ReadPodV1OrDie([]byte(podTemplate))
+
+			var container *corev1.Container
+			for i := range pod.Spec.Containers {
+				if pod.Spec.Containers[i].Name == "kube-apiserver" {
+					container = &pod.Spec.Containers[i]
+				}
+			}
+			require.NotNil(t, container)
+			require.Equal(t, resource.MustParse(test.expectedCPU), container.Resources.Requests[corev1.ResourceCPU])
+			require.Equal(t, resource.MustParse(test.expectedMemory), container.Resources.Requests[corev1.ResourceMemory])
+
+			var goMemLimit string
+			for _, env := range container.Env {
+				if env.Name == "GOMEMLIMIT" {
+					goMemLimit = env.Value
+				}
+			}
+			require.Equal(t, test.expectedGoMemLimit, goMemLimit)
+		})
+	}
+}
+
+func TestPodResourcesFromConfigErrors(t *testing.T) {
+	_, err := podResourcesFromConfig(map[string]interface{}{"targetconfigcontroller": map[string]interface{}{"controlPlaneAllocatable": map[string]interface{}{"cpu": "8"}}}, nil)
+	require.Error(t, err)
+}
+
+func TestConfiguredPodResources(t *testing.T) {
+	configured := func(value string) *PodResourcesConfig {
+		t.Helper()
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+		require.NoError(t, indexer.Add(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: operatorconfig.Namespace, Name: operatorconfig.Name},
+			Data:       map[string]string{podResourcesKey: value},
+		}))
+		config, err := ConfiguredPodResources(corev1listers.NewConfigMapLister(indexer))
+		require.NoError(t, err)
+		return config
+	}
+
+	config := configured("memoryRequest: 4Gi\ngoMemLimit: 20Gi\n")
+	require.NotNil(t, config)
+	require.Nil(t, config.CPURequest)
+	require.Equal(t, int64(4<<30), config.MemoryRequest.Value())
+	require.Equal(t, int64(20<<30), config.GoMemLimit.Value())
+
+	// an invalid quantity is ignored, the resources are derived
+	require.Nil(t, configured("memoryRequest: lots"))
+
+	config, err := ConfiguredPodResources(corev1listers.NewConfigMapLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})))
+	require.NoError(t, err)
+	require.Nil(t, config)
+}
+
+func TestReportPodResources(t *testing.T) {
+	operatorClient := v1helpers.NewFakeStaticPodOperatorClient(&operatorv1.StaticPodOperatorSpec{}, &operatorv1.StaticPodOperatorStatus{}, nil, nil)
+	report := func(observedConfig string, configured *PodResourcesConfig) *operatorv1.OperatorCondition {
+		t.Helper()
+		operatorSpec := &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+			ObservedConfig: runtime.RawExtension{Raw: []byte(observedConfig)},
+		}}
+		require.NoError(t, reportPodResources(context.TODO(), operatorClient, operatorSpec, configured))
+		_, status, _, err := operatorClient.GetStaticPodOperatorState()
+		require.NoError(t, err)
+		return v1helpers.FindOperatorCondition(status.Conditions, podResourcesConditionType)
+	}
+
+	condition := report(`{}`, nil)
+	require.Equal(t, "Default", condition.Reason)
+	require.Equal(t, "The kube-apiserver requests cpu 265m and memory 1Gi, with no GOMEMLIMIT.", condition.Message)
+
+	allocatable := `{"targetconfigcontroller":{"controlPlaneAllocatable":{"cpu":"32","memory":"64Gi"}}}`
+	condition = report(allocatable, nil)
+	require.Equal(t, "ControlPlaneAllocatable", condition.Reason)
+	require.Equal(t, "The kube-apiserver requests cpu 960m and memory 3276Mi, with GOMEMLIMIT 39321MiB, for a smallest master node allocatable of cpu 32, memory 64Gi.", condition.Message)
+
+	memoryRequest := resource.MustParse("4Gi")
+	condition = report(allocatable, &PodResourcesConfig{MemoryRequest: &memoryRequest})
+	require.Equal(t, "Configured", condition.Reason)
+	require.Contains(t, condition.Message, "memory 4Gi")
+	require.Contains(t, condition.Message, "key kubeAPIServerResources")
+}
@@ -13,10 +13,9 @@ import (
 
 	"github.com/ghodss/yaml"
 
-	kubecontrolplanev1 "github.com/openshift/api/kubecontrolplane/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/cluster-kube-apiserver-operator/bindata"
-	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/configobservation/node"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/externaljwtsigner"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/version"
 	"github.com/openshift/library-go/pkg/controller/factory"
@@ -49,6 +48,19 @@ const (
 	etcdEndpointName      = "etcd-endpoints"
 )
 
+// KubeAPIServerServerCASources are combined into the kube-apiserver-server-ca
+// config map, which is part of every kube-apiserver revision.
+var KubeAPIServerServerCASources = []resourcesynccontroller.ResourceLocation{
+	// this bundle is what this operator uses to mint loadbalancers certs
+	{Namespace: operatorclient.OperatorNamespace, Name: "loadbalancer-serving-ca"},
+	// this bundle is what this operator uses to mint localhost certs
+	{Namespace: operatorclient.OperatorNamespace, Name: "localhost-serving-ca"},
+	// this bundle is what a user uses to mint service-network certs
+	{Namespace: operatorclient.OperatorNamespace, Name: "service-network-serving-ca"},
+	// this bundle is what this operator uses to mint localhost-recovery certs
+	{Namespace: operatorclient.OperatorNamespace, Name: "localhost-recovery-serving-ca"},
+}
+
 type TargetConfigController struct {
 	targetImagePullSpec   string
 	operatorImagePullSpec string
@@ -58,6 +70,7 @@ type TargetConfigController struct {
 
 	kubeClient      kubernetes.Interface
 	configMapLister corev1listers.ConfigMapLister
+	secretLister    corev1listers.SecretLister
 
 	featureGateAccessor featuregates.FeatureGateAccess
 
@@ -83,6 +96,7 @@ func NewTargetConfigController(
 		operatorClient:                 operatorClient,
 		kubeClient:                     kubeClient,
 		configMapLister:                kubeInformersForNamespaces.ConfigMapLister(),
+		secretLister:                   kubeInformersForNamespaces.SecretLister(),
 		featureGateAccessor:            featureGateAccessor,
 		isStartupMonitorEnabledFn:      isStartupMonitorEnabledFn,
 		requireMultipleEtcdEndpointsFn: requireMultipleEtcdEndpointsFn,
@@ -97,6 +111,11 @@ func NewTargetConfigController(
 		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalMachineSpecifiedConfigNamespace).Core().V1().ConfigMaps().Informer(),
 		kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().ConfigMaps().Informer(),
 		kubeInformersForNamespaces.InformersFor(operatorclient.TargetNamespace).Core().V1().ConfigMaps().Informer(),
+	).WithFilteredEventsInformers(
+		// the certificates issued by the CAs in the bundles
+		factory.NamesFilter(CABundleTargetNames()...),
+		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalMachineSpecifiedConfigNamespace).Core().V1().Secrets().Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.OperatorNamespace).Core().V1().Secrets().Informer(),
 	).WithSync(c.sync).ResyncEvery(time.Minute).ToController("TargetConfigController", eventRecorder.WithComponentSuffix("target-config-controller"))
 }
 
@@ -221,19 +240,45 @@ func etcdEndpointsPresent(configMapLister corev1listers.ConfigMapLister, config
 func createTargetConfig(ctx context.Context, c TargetConfigController, recorder events.Recorder, operatorSpec *operatorv1.StaticPodOperatorSpec) (bool, error) {
 	errors := []error{}
 
-	_, _, err := manageKubeAPIServerConfig(ctx, c.kubeClient.CoreV1(), recorder, operatorSpec)
+	signer, err := ExternalJWTSigner(c.configMapLister)
+	if err != nil {
+		return true, err
+	}
+	podResources, err := ConfiguredPodResources(c.configMapLister)
+	if err != nil {
+		return true, err
+	}
+
+	// invalid apiServerArguments would crashloop the kube-apiserver, so the
+	// config isn't updated until they are fixed, the revisions keep the last
+	// valid one
+	invalidArguments, err := ValidateKubeAPIServerConfig(operatorSpec, signer)
 	if err != nil {
 		errors = append(errors, fmt.Errorf("%q: %v", "configmap/config", err))
 	}
-	_, _, err = managePods(ctx, c.kubeClient.CoreV1(), c.kubeClient.CoreV1(), c.featureGateAccessor, c.isStartupMonitorEnabledFn, recorder, operatorSpec, c.targetImagePullSpec, c.operatorImagePullSpec, c.operatorImageVersion)
+	if err := reportInvalidAPIServerArguments(ctx, c.operatorClient, recorder, invalidArguments); err != nil {
+		errors = append(errors, fmt.Errorf("%q: %v", "apiServerArguments", err))
+	}
+	if err == nil && len(invalidArguments) == 0 {
+		_, _, err = manageKubeAPIServerConfig(ctx, c.kubeClient.CoreV1(), recorder, operatorSpec, signer)
+		if err != nil {
+			errors = append(errors, fmt.Errorf("%q: %v", "configmap/config", err))
+		}
+	}
+	_, _, err = managePods(ctx, c.kubeClient.CoreV1(), c.kubeClient.CoreV1(), c.featureGateAccessor, c.isStartupMonitorEnabledFn, recorder, operatorSpec, signer, podResources, c.targetImagePullSpec, c.operatorImagePullSpec, c.operatorImageVersion)
 	if err != nil {
 		errors = append(errors, fmt.Errorf("%q: %v", "configmap/kube-apiserver-pod", err))
+	} else if err := reportPodResources(ctx, c.operatorClient, operatorSpec, podResources); err != nil {
+		errors = append(errors, fmt.Errorf("%q: %v", "kubeAPIServerResources", err))
 	}
-	_, _, err = ManageClientCABundle(ctx, c.configMapLister, c.kubeClient.CoreV1(), recorder)
+	if err := reportProtectedConfigOverrides(ctx, c.operatorClient, recorder, operatorSpec); err != nil {
+		errors = append(errors, fmt.Errorf("%q: %v", "unsupportedConfigOverrides", err))
+	}
+	_, _, err = ManageClientCABundle(ctx, c.configMapLister, c.secretLister, c.kubeClient.CoreV1(), recorder)
 	if err != nil {
 		errors = append(errors, fmt.Errorf("%q: %v", "configmap/client-ca", err))
 	}
-	_, _, err = manageKubeAPIServerCABundle(ctx, c.configMapLister, c.kubeClient.CoreV1(), recorder)
+	_, _, err = manageKubeAPIServerCABundle(ctx, c.configMapLister, c.secretLister, c.kubeClient.CoreV1(), recorder)
 	if err != nil {
 		errors = append(errors, fmt.Errorf("%q: %v", "configmap/kube-apiserver-server-ca", err))
 	}
@@ -272,57 +317,61 @@ func createTargetConfig(ctx context.Context, c TargetConfigController, recorder
 	return false, nil
 }
 
-func manageKubeAPIServerConfig(ctx context.Context, client coreclientv1.ConfigMapsGetter, recorder events.Recorder, operatorSpec *operatorv1.StaticPodOperatorSpec) (*corev1.ConfigMap, bool, error) {
-	configMap := resourceread.ReadConfigMapV1OrDie(bindata.MustAsset("assets/kube-apiserver/cm.yaml"))
-	defaultConfig := bindata.MustAsset("assets/config/defaultconfig.yaml")
-	configOverrides := bindata.MustAsset("assets/config/config-overrides.yaml")
-
-	// resourcemerge will not merge slice contents; therefore we must set up special merge rules for
-	// enable-admission-plugins/disable-admission-plugins in order to merge the config from the various sources
-	specialMergeRules := map[string]resourcemerge.MergeFunc{
-		".apiServerArguments.enable-admission-plugins":  mergeStringSlices,
-		".apiServerArguments.disable-admission-plugins": mergeStringSlices,
-	}
-
-	// Guarantee the authorization-mode will be present in the base config, regardless of whether the observer is running
-	authModeOverride := map[string]interface{}{}
-	node.AddAuthorizationModes(authModeOverride, false)
-	authModeOverrideJSON, err := json.Marshal(authModeOverride)
+func manageKubeAPIServerConfig(ctx context.Context, client coreclientv1.ConfigMapsGetter, recorder events.Recorder, operatorSpec *operatorv1.StaticPodOperatorSpec, signer *externaljwtsigner.Config) (*corev1.ConfigMap, bool, error) {
+	requiredConfigMap := resourceread.ReadConfigMapV1OrDie(bindata.MustAsset("assets/kube-apiserver/cm.yaml"))
+	config, err := RenderKubeAPIServerConfig(operatorSpec, signer)
 	if err != nil {
 		return nil, false, err
 	}
+	requiredConfigMap.Data["config.yaml"] = string(config)
+	return resourceapply.ApplyConfigMap(ctx, client, recorder, requiredConfigMap)
+}
 
-	requiredConfigMap, _, err := resourcemerge.MergePrunedConfigMap(
-		&kubecontrolplanev1.KubeAPIServerConfig{},
-		configMap,
-		"config.yaml",
-		specialMergeRules,
-		defaultConfig,
-		authModeOverrideJSON,
-		configOverrides,
-		operatorSpec.ObservedConfig.Raw,
-		operatorSpec.UnsupportedConfigOverrides.Raw,
-	)
+// externalJWTSignerRemovedFields are the fields of the kube-apiserver config
+// that don't apply with an external JWT signer.
+var externalJWTSignerRemovedFields = [][]string{
+	{"apiServerArguments", "service-account-signing-key-file"},
+	{"serviceAccountPublicKeyFiles"},
+}
+
+// removeConfigFields removes the given fields from the JSON config. The merge
+// of the config layers can only override fields, not remove them.
+func removeConfigFields(configJSON []byte, fields ...[]string) ([]byte, error) {
+	config := map[string]interface{}{}
+	if err := json.Unmarshal(configJSON, &config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the config: %w", err)
+	}
+	for _, field := range fields {
+		unstructured.RemoveNestedField(config, field...)
+	}
+	return json.Marshal(config)
+}
+
+func managePods(ctx context.Context, client coreclientv1.ConfigMapsGetter, secretClient coreclientv1.SecretsGetter, featureGateAccessor featuregates.FeatureGateAccess, isStartupMonitorEnabledFn func() (bool, error), recorder events.Recorder, operatorSpec *operatorv1.StaticPodOperatorSpec, signer *externaljwtsigner.Config, podResources *PodResourcesConfig, imagePullSpec, operatorImagePullSpec, operatorImageVersion string) (*corev1.ConfigMap, bool, error) {
+	configMap, err := RenderKubeAPIServerPodConfigMap(ctx, secretClient, featureGateAccessor, isStartupMonitorEnabledFn, operatorSpec, signer, podResources, imagePullSpec, operatorImagePullSpec, operatorImageVersion)
 	if err != nil {
 		return nil, false, err
 	}
-	return resourceapply.ApplyConfigMap(ctx, client, recorder, requiredConfigMap)
+	return resourceapply.ApplyConfigMap(ctx, client, recorder, configMap)
 }
 
-func managePods(ctx context.Context, client coreclientv1.ConfigMapsGetter, secretClient coreclientv1.SecretsGetter, featureGateAccessor featuregates.FeatureGateAccess, isStartupMonitorEnabledFn func() (bool, error), recorder events.Recorder, operatorSpec *operatorv1.StaticPodOperatorSpec, imagePullSpec, operatorImagePullSpec, operatorImageVersion string) (*corev1.ConfigMap, bool, error) {
-	appliedPodTemplate, err := manageTemplate(string(bindata.MustAsset("assets/kube-apiserver/pod.yaml")), imagePullSpec, operatorImagePullSpec, operatorImageVersion, operatorSpec)
+// RenderKubeAPIServerPodConfigMap returns the kube-apiserver-pod ConfigMap for
+// the given operator spec, external JWT signer and explicit resources, with the
+// static pod manifest and the optional startup monitor pod.
+func RenderKubeAPIServerPodConfigMap(ctx context.Context, secretClient coreclientv1.SecretsGetter, featureGateAccessor featuregates.FeatureGateAccess, isStartupMonitorEnabledFn func() (bool, error), operatorSpec *operatorv1.StaticPodOperatorSpec, signer *externaljwtsigner.Config, podResources *PodResourcesConfig, imagePullSpec, operatorImagePullSpec, operatorImageVersion string) (*corev1.ConfigMap, error) {
+	appliedPodTemplate, err := manageTemplate(string(bindata.MustAsset("assets/kube-apiserver/pod.yaml")), imagePullSpec, operatorImagePullSpec, operatorImageVersion, operatorSpec, podResources)
 	if err != nil {
-		return nil, false, err
+		return nil, err
 	}
 	required := resourceread.ReadPodV1OrDie([]byte(appliedPodTemplate))
 
 	var observedConfig map[string]interface{}
 	if err := yaml.Unmarshal(operatorSpec.ObservedConfig.Raw, &observedConfig); err != nil {
-		return nil, false, fmt.Errorf("failed to unmarshal the observedConfig: %v", err)
+		return nil, fmt.Errorf("failed to unmarshal the observedConfig: %v", err)
 	}
 	proxyConfig, _, err := unstructured.NestedStringMap(observedConfig, "targetconfigcontroller", "proxy")
 	if err != nil {
-		return nil, false, fmt.Errorf("couldn't get the proxy config from observedConfig: %v", err)
+		return nil, fmt.Errorf("couldn't get the proxy config from observedConfig: %v", err)
 	}
 
 	proxyEnvVars := proxyMapToEnvVars(proxyConfig)
@@ -330,8 +379,14 @@ func managePods(ctx context.Context, client coreclientv1.ConfigMapsGetter, secre
 		required.Spec.Containers[i].Env = append(container.Env, proxyEnvVars...)
 	}
 
+	if signer != nil {
+		if err := signer.AddToKubeAPIServerPod(&required.Spec, "kube-apiserver"); err != nil {
+			return nil, fmt.Errorf("failed to add the external JWT signer to the pod spec: %w", err)
+		}
+	}
+
 	if err := kmspluginlifecycle.EnsureKMSPluginSidecarInStaticPodSpec(ctx, &required.Spec, "kube-apiserver", operatorclient.TargetNamespace, "encryption-config", "", "cluster-kube-apiserver-operator", operatorImagePullSpec, secretClient, featureGateAccessor); err != nil {
-		return nil, false, fmt.Errorf("failed to add KMS plugin to pod spec: %w", err)
+		return nil, fmt.Errorf("failed to add KMS plugin to pod spec: %w", err)
 	}
 
 	configMap := resourceread.ReadConfigMapV1OrDie(bindata.MustAsset("assets/kube-apiserver/pod-cm.yaml"))
@@ -339,17 +394,17 @@ func managePods(ctx context.Context, client coreclientv1.ConfigMapsGetter, secre
 	configMap.Data["forceRedeploymentReason"] = operatorSpec.ForceRedeploymentReason
 	configMap.Data["version"] = version.Get().String()
 
-	startupMonitorPodKey, optionalStartupMonitor, err := generateOptionalStartupMonitorPod(isStartupMonitorEnabledFn, operatorSpec, operatorImagePullSpec)
+	startupMonitorPodKey, optionalStartupMonitor, err := generateOptionalStartupMonitorPod(isStartupMonitorEnabledFn, operatorSpec, operatorImagePullSpec, signer)
 	if err != nil {
-		return nil, false, fmt.Errorf("failed to apply an optional pod due to %v", err)
+		return nil, fmt.Errorf("failed to apply an optional pod due to %v", err)
 	}
 	if optionalStartupMonitor != nil {
 		configMap.Data[startupMonitorPodKey] = resourceread.WritePodV1OrDie(optionalStartupMonitor)
 	}
-	return resourceapply.ApplyConfigMap(ctx, client, recorder, configMap)
+	return configMap, nil
 }
 
-func generateOptionalStartupMonitorPod(isStartupMonitorEnabledFn func() (bool, error), operatorSpec *operatorv1.StaticPodOperatorSpec, operatorImagePullSpec string) (string, *corev1.Pod, error) {
+func generateOptionalStartupMonitorPod(isStartupMonitorEnabledFn func() (bool, error), operatorSpec *operatorv1.StaticPodOperatorSpec, operatorImagePullSpec string, signer *externaljwtsigner.Config) (string, *corev1.Pod, error) {
 	if enabled, err := isStartupMonitorEnabledFn(); err != nil {
 		return "", nil, err
 	} else if !enabled {
@@ -361,10 +416,16 @@ func generateOptionalStartupMonitorPod(isStartupMonitorEnabledFn func() (bool, e
 		return "", nil, err
 	}
 	required := resourceread.ReadPodV1OrDie([]byte(generatedStartupMonitorPodTemplate))
+	if signer != nil {
+		// the startup monitor checks that the new revision reaches the signer
+		if err := signer.AddToStartupMonitorPod(&required.Spec, "startup-monitor"); err != nil {
+			return "", nil, err
+		}
+	}
 	return "kube-apiserver-startup-monitor-pod.yaml", required, nil
 }
 
-func ManageClientCABundle(ctx context.Context, lister corev1listers.ConfigMapLister, client coreclientv1.ConfigMapsGetter, recorder events.Recorder) (*corev1.ConfigMap, bool, error) {
+func ManageClientCABundle(ctx context.Context, lister corev1listers.ConfigMapLister, secretLister corev1listers.SecretLister, client coreclientv1.ConfigMapsGetter, recorder events.Recorder) (*corev1.ConfigMap, bool, error) {
 
 	additionalAnnotations := certrotation.AdditionalAnnotations{
 		JiraComponent: "kube-apiserver",
@@ -388,25 +449,29 @@ func ManageClientCABundle(ctx context.Context, lister corev1listers.ConfigMapLis
 		return nil, false, err
 	}
 
-	requiredConfigMap, updateRequired, err := resourcesynccontroller.CombineCABundleConfigMapsOptimistically(
+	requiredConfigMap, updateRequired, pruned, err := combineCABundle(
 		caBundleConfigMap,
 		lister,
+		secretLister,
 		additionalAnnotations,
-		// this is from the installer and contains the value to verify the admin.kubeconfig user
-		resourcesynccontroller.ResourceLocation{Namespace: operatorclient.GlobalUserSpecifiedConfigNamespace, Name: "admin-kubeconfig-client-ca"},
-		// this is from the installer and contains the value to verify the node bootstrapping cert that is baked into images
-		// this is from kube-controller-manager and indicates the ca-bundle.crt to verify their signatures (kubelet client certs)
-		resourcesynccontroller.ResourceLocation{Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace, Name: "csr-controller-ca"},
-		// this is from the installer and contains the value to verify the kube-apiserver communicating to the kubelet
-		resourcesynccontroller.ResourceLocation{Namespace: operatorclient.OperatorNamespace, Name: "kube-apiserver-to-kubelet-client-ca"},
-		// this bundle is what this operator uses to mint new client certs it directly manages
-		resourcesynccontroller.ResourceLocation{Namespace: operatorclient.OperatorNamespace, Name: "kube-control-plane-signer-ca"},
-		// this bundle is what a user uses to mint new client certs it directly manages
-		resourcesynccontroller.ResourceLocation{Namespace: operatorclient.TargetNamespace, Name: "user-client-ca"},
-		// this bundle is what validates the master kubelet bootstrap credential.  Users can invalid this by removing it.
-		resourcesynccontroller.ResourceLocation{Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace, Name: "kubelet-bootstrap-kubeconfig"},
-		// this bundle is what validates kubeconfigs placed on masters
-		resourcesynccontroller.ResourceLocation{Namespace: operatorclient.OperatorNamespace, Name: "node-system-admin-ca"},
+		[]resourcesynccontroller.ResourceLocation{
+			// this is from the installer and contains the value to verify the admin.kubeconfig user
+			{Namespace: operatorclient.GlobalUserSpecifiedConfigNamespace, Name: "admin-kubeconfig-client-ca"},
+			// this is from the installer and contains the value to verify the node bootstrapping cert that is baked into images
+			// this is from kube-controller-manager and indicates the ca-bundle.crt to verify their signatures (kubelet client certs)
+			{Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace, Name: "csr-controller-ca"},
+			// this is from the installer and contains the value to verify the kube-apiserver communicating to the kubelet
+			{Namespace: operatorclient.OperatorNamespace, Name: "kube-apiserver-to-kubelet-client-ca"},
+			// this bundle is what this operator uses to mint new client certs it directly manages
+			{Namespace: operatorclient.OperatorNamespace, Name: "kube-control-plane-signer-ca"},
+			// this bundle is what a user uses to mint new client certs it directly manages
+			{Namespace: operatorclient.TargetNamespace, Name: "user-client-ca"},
+			// this bundle is what validates the master kubelet bootstrap credential.  Users can invalid this by removing it.
+			{Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace, Name: "kubelet-bootstrap-kubeconfig"},
+			// this bundle is what validates kubeconfigs placed on masters
+			{Namespace: operatorclient.OperatorNamespace, Name: "node-system-admin-ca"},
+		},
+		ClientCATargets,
 	)
 	if err != nil {
 		return nil, false, err
@@ -426,6 +491,7 @@ func ManageClientCABundle(ctx context.Context, lister corev1listers.ConfigMapLis
 		if err != nil {
 			return nil, false, err
 		}
+		reportPrunedCertificates(recorder, caBundleConfigMap, pruned)
 		klog.V(2).Infof("Updated client CA bundle configmap %s/%s", caBundleConfigMap.Namespace, caBundleConfigMap.Name)
 		return caBundleConfigMap, true, nil
 	}
@@ -433,7 +499,7 @@ func ManageClientCABundle(ctx context.Context, lister corev1listers.ConfigMapLis
 	return caBundleConfigMap, false, nil
 }
 
-func manageKubeAPIServerCABundle(ctx context.Context, lister corev1listers.ConfigMapLister, client coreclientv1.ConfigMapsGetter, recorder events.Recorder) (*corev1.ConfigMap, bool, error) {
+func manageKubeAPIServerCABundle(ctx context.Context, lister corev1listers.ConfigMapLister, secretLister corev1listers.SecretLister, client coreclientv1.ConfigMapsGetter, recorder events.Recorder) (*corev1.ConfigMap, bool, error) {
 
 	additionalAnnotations := certrotation.AdditionalAnnotations{
 		JiraComponent: "kube-apiserver",
@@ -457,18 +523,13 @@ func manageKubeAPIServerCABundle(ctx context.Context, lister corev1listers.Confi
 		return nil, false, err
 	}
 
-	requiredConfigMap, updateRequired, err := resourcesynccontroller.CombineCABundleConfigMapsOptimistically(
+	requiredConfigMap, updateRequired, pruned, err := combineCABundle(
 		caBundleConfigMap,
 		lister,
+		secretLister,
 		additionalAnnotations,
-		// this bundle is what this operator uses to mint loadbalancers certs
-		resourcesynccontroller.ResourceLocation{Namespace: operatorclient.OperatorNamespace, Name: "loadbalancer-serving-ca"},
-		// this bundle is what this operator uses to mint localhost certs
-		resourcesynccontroller.ResourceLocation{Namespace: operatorclient.OperatorNamespace, Name: "localhost-serving-ca"},
-		// this bundle is what a user uses to mint service-network certs
-		resourcesynccontroller.ResourceLocation{Namespace: operatorclient.OperatorNamespace, Name: "service-network-serving-ca"},
-		// this bundle is what this operator uses to mint localhost-recovery certs
-		resourcesynccontroller.ResourceLocation{Namespace: operatorclient.OperatorNamespace, Name: "localhost-recovery-serving-ca"},
+		KubeAPIServerServerCASources,
+		KubeAPIServerServerCATargets,
 	)
 	if err != nil {
 		return nil, false, err
@@ -488,6 +549,7 @@ func manageKubeAPIServerCABundle(ctx context.Context, lister corev1listers.Confi
 		if err != nil {
 			return nil, false, err
 		}
+		reportPrunedCertificates(recorder, caBundleConfigMap, pruned)
 		klog.V(2).Infof("Updated kube apiserver CA bundle configmap %s/%s", caBundleConfigMap.Namespace, caBundleConfigMap.Name)
 		return caBundleConfigMap, true, nil
 	}
@@ -657,6 +719,9 @@ type kasTemplate struct {
 	GracefulTerminationDuration   int
 	SetupContainerTimeoutDuration int
 	GOGC                          int
+	GOMEMLIMIT                    string
+	CPURequest                    string
+	MemoryRequest                 string
 	CheckEndpointsBindIP          string
 }
 
@@ -674,7 +739,7 @@ func effectiveConfiguration(spec *operatorv1.StaticPodOperatorSpec) (map[string]
 	return effectiveConfig, nil
 }
 
-func manageTemplate(rawTemplate string, imagePullSpec string, operatorImagePullSpec, operatorImageVersion string, operatorSpec *operatorv1.StaticPodOperatorSpec) (string, error) {
+func manageTemplate(rawTemplate string, imagePullSpec string, operatorImagePullSpec, operatorImageVersion string, operatorSpec *operatorv1.StaticPodOperatorSpec, podResources *PodResourcesConfig) (string, error) {
 	var verbosity string
 	switch operatorSpec.LogLevel {
 	case operatorv1.Normal:
@@ -704,6 +769,11 @@ func manageTemplate(rawTemplate string, imagePullSpec string, operatorImagePullS
 		return "", err
 	}
 
+	resources, err := podResourcesFromConfig(config, podResources)
+	if err != nil {
+		return "", err
+	}
+
 	checkEndpointBindIP, err := checkEndpointsBindIPFromConfig(config)
 	if err != nil {
 		return "", err
@@ -718,6 +788,9 @@ func manageTemplate(rawTemplate string, imagePullSpec string, operatorImagePullS
 		// 80s for minimum-termination-duration (10s port wait, 65s to let pending requests finish after port has been freed) + 5s extra cri-o's graceful termination period
 		SetupContainerTimeoutDuration: gracefulTerminationDuration + 80 + 5,
 		GOGC:                          gogc,
+		GOMEMLIMIT:                    resources.goMemLimit(),
+		CPURequest:                    resources.cpuRequest(),
+		MemoryRequest:                 resources.memoryRequest(),
 		CheckEndpointsBindIP:          checkEndpointBindIP,
 	}
 	tmpl, err := template.New("kas").Parse(rawTemplate)
@@ -16,6 +16,8 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -23,15 +25,21 @@ import (
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
 	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/utils/clock"
 
 	"github.com/ghodss/yaml"
 	"github.com/openshift/api/annotations"
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/api/features"
 	kubecontrolplanev1 "github.com/openshift/api/kubecontrolplane/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorconfig"
+	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
 	"github.com/stretchr/testify/require"
 )
 
@@ -169,6 +177,7 @@ func TestManageTemplate(t *testing.T) {
 		template     string
 		golden       string
 		operatorSpec *operatorv1.StaticPodOperatorSpec
+		podResources *PodResourcesConfig
 	}{
 
 		// scenario 1
@@ -242,6 +251,57 @@ func TestManageTemplate(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:     "default resources without the control plane allocatable",
+			template: "{{.CPURequest}}, {{.MemoryRequest}}, {{.GOMEMLIMIT}}",
+			golden:   "265m, 1Gi, ",
+			operatorSpec: &operatorv1.StaticPodOperatorSpec{
+				OperatorSpec: operatorv1.OperatorSpec{
+					ObservedConfig: runtime.RawExtension{Raw: []byte(`{}`)},
+				},
+			},
+		},
+		{
+			name:     "resources derived from the control plane allocatable",
+			template: "{{.CPURequest}}, {{.MemoryRequest}}, {{.GOMEMLIMIT}}",
+			golden:   "960m, 3276Mi, 39321MiB",
+			operatorSpec: &operatorv1.StaticPodOperatorSpec{
+				OperatorSpec: operatorv1.OperatorSpec{
+					ObservedConfig: runtime.RawExtension{Raw: []byte(`{"targetconfigcontroller":{"controlPlaneAllocatable":{"cpu":"32","memory":"64Gi"}}}`)},
+				},
+			},
+		},
+		{
+			name:     "resources of a small node clamped to the lower bounds",
+			template: "{{.CPURequest}}, {{.MemoryRequest}}, {{.GOMEMLIMIT}}",
+			golden:   "265m, 1Gi, 2048MiB",
+			operatorSpec: &operatorv1.StaticPodOperatorSpec{
+				OperatorSpec: operatorv1.OperatorSpec{
+					ObservedConfig: runtime.RawExtension{Raw: []byte(`{"targetconfigcontroller":{"controlPlaneAllocatable":{"cpu":"4","memory":"3Gi"}}}`)},
+				},
+			},
+		},
+		{
+			name:     "resources of a large node clamped to the upper bounds",
+			template: "{{.CPURequest}}, {{.MemoryRequest}}, {{.GOMEMLIMIT}}",
+			golden:   "2, 8Gi, 314572MiB",
+			operatorSpec: &operatorv1.StaticPodOperatorSpec{
+				OperatorSpec: operatorv1.OperatorSpec{
+					ObservedConfig: runtime.RawExtension{Raw: []byte(`{"targetconfigcontroller":{"controlPlaneAllocatable":{"cpu":"192","memory":"512Gi"}}}`)},
+				},
+			},
+		},
+		{
+			name:     "resources from the operator config map",
+			template: "{{.CPURequest}}, {{.MemoryRequest}}, {{.GOMEMLIMIT}}",
+			golden:   "1500m, 3276Mi, 65536MiB",
+			operatorSpec: &operatorv1.StaticPodOperatorSpec{
+				OperatorSpec: operatorv1.OperatorSpec{
+					ObservedConfig: runtime.RawExtension{Raw: []byte(`{"targetconfigcontroller":{"controlPlaneAllocatable":{"cpu":"32","memory":"64Gi"}}}`)},
+				},
+			},
+			podResources: &PodResourcesConfig{CPURequest: resource.NewMilliQuantity(1500, resource.DecimalSI), GoMemLimit: resource.NewQuantity(100<<30, resource.BinarySI)},
+		},
 		{
 			name:     "default check endpoints bind IP when no config",
 			template: "{{.CheckEndpointsBindIP}}",
@@ -292,7 +352,8 @@ func TestManageTemplate(t *testing.T) {
 				"CaptainAmerica",
 				"Piper",
 				"v1",
-				scenario.operatorSpec)
+				scenario.operatorSpec,
+				scenario.podResources)
 
 			// validate
 			if err != nil {
@@ -533,6 +594,47 @@ func TestSpecialMergeRules(t *testing.T) {
 	require.ElementsMatch(t, config.APIServerArguments["audit-log-format"], []string{"yaml"})
 }
 
+func TestManageKubeAPIServerConfigExternalJWTSigner(t *testing.T) {
+	for _, test := range []struct {
+		name             string
+		signer           string
+		overrides        string
+		expectedKeyFiles bool
+	}{
+		{name: "in-cluster signing key", expectedKeyFiles: true},
+		{name: "external signer", signer: `{"socketPath":"/var/run/jwt-signer/signer.sock"}`},
+		{name: "invalid external signer", signer: `{"socketPath":"signer.sock"}`, expectedKeyFiles: true},
+		{name: "external signer in the overrides", overrides: `{"externalJWTSigner":{"socketPath":"/var/run/jwt-signer/signer.sock"}}`, expectedKeyFiles: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			kubeClient := fake.NewSimpleClientset()
+			operatorSpec := &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+				UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(test.overrides)},
+			}}
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if len(test.signer) > 0 {
+				require.NoError(t, indexer.Add(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: operatorconfig.Namespace, Name: operatorconfig.Name},
+					Data:       map[string]string{"externalJWTSigner": test.signer},
+				}))
+			}
+			signer, err := ExternalJWTSigner(corev1listers.NewConfigMapLister(indexer))
+			require.NoError(t, err)
+			configMap, _, err := manageKubeAPIServerConfig(context.TODO(), kubeClient.CoreV1(), events.NewInMemoryRecorder("test", clock.RealClock{}), operatorSpec, signer)
+			require.NoError(t, err)
+
+			config := &kubecontrolplanev1.KubeAPIServerConfig{}
+			require.NoError(t, yaml.Unmarshal([]byte(configMap.Data["config.yaml"]), config))
+			require.Equal(t, []string{"https://kubernetes.default.svc"}, []string(config.APIServerArguments["service-account-issuer"]))
+			// the kube-apiserver refuses key files along with a signing endpoint
+			_, hasSigningKeyFile := config.APIServerArguments["service-account-signing-key-file"]
+			require.Equal(t, test.expectedKeyFiles, hasSigningKeyFile)
+			require.Equal(t, test.expectedKeyFiles, len(config.ServiceAccountPublicKeyFiles) > 0)
+			require.Equal(t, test.expectedKeyFiles, strings.Contains(configMap.Data["config.yaml"], "serviceAccountPublicKeyFiles"))
+		})
+	}
+}
+
 func TestMergeStringSlices(t *testing.T) {
 	for _, tt := range []struct {
 		name        string
@@ -896,10 +998,12 @@ func TestManageClientCABundle(t *testing.T) {
 				namespace: "",
 			}
 
+			secretLister := corev1listers.NewSecretLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}))
+
 			recorder := events.NewInMemoryRecorder("test", clock.RealClock{})
 
 			// Call the function under test
-			resultConfigMap, changed, err := ManageClientCABundle(context.Background(), lister, client.CoreV1(), recorder)
+			resultConfigMap, changed, err := ManageClientCABundle(context.Background(), lister, secretLister, client.CoreV1(), recorder)
 
 			// Assert error expectations
 			require.NoError(t, err)
@@ -1166,10 +1270,12 @@ func TestManageKubeAPIServerCABundle(t *testing.T) {
 				namespace: "",
 			}
 
+			secretLister := corev1listers.NewSecretLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}))
+
 			recorder := events.NewInMemoryRecorder("test", clock.RealClock{})
 
 			// Call the function under test
-			resultConfigMap, changed, err := manageKubeAPIServerCABundle(context.Background(), lister, client.CoreV1(), recorder)
+			resultConfigMap, changed, err := manageKubeAPIServerCABundle(context.Background(), lister, secretLister, client.CoreV1(), recorder)
 
 			// Assert error expectations
 			require.NoError(t, err)
@@ -1371,3 +1477,44 @@ func TestCheckEndpointsBindIPFromConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateTargetConfigWithholdsInvalidConfig(t *testing.T) {
+	operatorSpec := &operatorv1.StaticPodOperatorSpec{OperatorSpec: operatorv1.OperatorSpec{
+		ManagementState:            operatorv1.Managed,
+		ObservedConfig:             runtime.RawExtension{Raw: []byte(`{"apiServerArguments":{"etcd-servers":["https://10.0.0.1:2379"]}}`)},
+		UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(`{"apiServerArguments":{"event-ttl":["3"]}}`)},
+	}}
+	operatorClient := v1helpers.NewFakeStaticPodOperatorClient(operatorSpec, &operatorv1.StaticPodOperatorStatus{}, nil, nil)
+	featureSet := features.FeatureSets(4, features.SelfManaged, configv1.Default)
+	var enabled, disabled []configv1.FeatureGateName
+	for _, gate := range featureSet.Enabled {
+		enabled = append(enabled, gate.FeatureGateAttributes.Name)
+	}
+	for _, gate := range featureSet.Disabled {
+		disabled = append(disabled, gate.FeatureGateAttributes.Name)
+	}
+	kubeClient := fake.NewSimpleClientset()
+	c := TargetConfigController{
+		targetImagePullSpec:       "kube-apiserver:test",
+		operatorImagePullSpec:     "kube-apiserver-operator:test",
+		operatorClient:            operatorClient,
+		kubeClient:                kubeClient,
+		configMapLister:           corev1listers.NewConfigMapLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})),
+		secretLister:              corev1listers.NewSecretLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})),
+		featureGateAccessor:       featuregates.NewHardcodedFeatureGateAccess(enabled, disabled),
+		isStartupMonitorEnabledFn: func() (bool, error) { return false, nil },
+	}
+
+	_, err := createTargetConfig(context.TODO(), c, events.NewInMemoryRecorder("test", clock.RealClock{}), operatorSpec)
+	require.NoError(t, err)
+
+	_, err = kubeClient.CoreV1().ConfigMaps(operatorclient.TargetNamespace).Get(context.TODO(), "config", metav1.GetOptions{})
+	require.True(t, apierrors.IsNotFound(err), "expected the invalid config to be withheld, got %v", err)
+	_, err = kubeClient.CoreV1().ConfigMaps(operatorclient.TargetNamespace).Get(context.TODO(), "kube-apiserver-pod", metav1.GetOptions{})
+	require.NoError(t, err, "expected the pod to be managed along with an invalid config")
+	_, status, _, err := operatorClient.GetStaticPodOperatorState()
+	require.NoError(t, err)
+	condition := v1helpers.FindOperatorCondition(status.Conditions, apiServerArgumentsConditionType)
+	require.NotNil(t, condition)
+	require.Equal(t, operatorv1.ConditionTrue, condition.Status)
+}
@@ -0,0 +1,187 @@
+// Code generated by hack/kube-apiserver-flags. DO NOT EDIT.
+
+package targetconfigcontroller
+
+// kubeAPIServerFlagsVersion is the Kubernetes minor version of the
+// kube-apiserver kubeAPIServerFlagTypes were generated from.
+const kubeAPIServerFlagsVersion = "1.36"
+
+// kubeAPIServerFlagTypes are the flags of the kube-apiserver along with the
+// type its help shows for them, empty for the bools.
+var kubeAPIServerFlagTypes = map[string]string{
+	"admission-control-config-file":                "string",
+	"advertise-address":                            "ip",
+	"aggregator-reject-forwarding-redirect":        "",
+	"allow-metric-labels":                          "stringToString",
+	"allow-metric-labels-manifest":                 "string",
+	"allow-privileged":                             "",
+	"anonymous-auth":                               "",
+	"api-audiences":                                "strings",
+	"audit-log-batch-buffer-size":                  "int",
+	"audit-log-batch-max-size":                     "int",
+	"audit-log-batch-max-wait":                     "duration",
+	"audit-log-batch-throttle-burst":               "int",
+	"audit-log-batch-throttle-enable":              "",
+	"audit-log-batch-throttle-qps":                 "float32",
+	"audit-log-compress":                           "",
+	"audit-log-format":                             "string",
+	"audit-log-maxage":                             "int",
+	"audit-log-maxbackup":                          "int",
+	"audit-log-maxsize":                            "int",
+	"audit-log-mode":                               "string",
+	"audit-log-path":                               "string",
+	"audit-log-truncate-enabled":                   "",
+	"audit-log-truncate-max-batch-size":            "int",
+	"audit-log-truncate-max-event-size":            "int",
+	"audit-log-version":                            "string",
+	"audit-policy-file":                            "string",
+	"audit-webhook-batch-buffer-size":              "int",
+	"audit-webhook-batch-max-size":                 "int",
+	"audit-webhook-batch-max-wait":                 "duration",
+	"audit-webhook-batch-throttle-burst":           "int",
+	"audit-webhook-batch-throttle-enable":          "",
+	"audit-webhook-batch-throttle-qps":             "float32",
+	"audit-webhook-config-file":                    "string",
+	"audit-webhook-initial-backoff":                "duration",
+	"audit-webhook-mode":                           "string",
+	"audit-webhook-truncate-enabled":               "",
+	"audit-webhook-truncate-max-batch-size":        "int",
+	"audit-webhook-truncate-max-event-size":        "int",
+	"audit-webhook-version":                        "string",
+	"authentication-config":                        "string",
+	"authentication-token-webhook-cache-ttl":       "duration",
+	"authentication-token-webhook-config-file":     "string",
+	"authentication-token-webhook-version":         "string",
+	"authorization-config":                         "string",
+	"authorization-mode":                           "strings",
+	"authorization-policy-file":                    "string",
+	"authorization-webhook-cache-authorized-ttl":   "duration",
+	"authorization-webhook-cache-unauthorized-ttl": "duration",
+	"authorization-webhook-config-file":            "string",
+	"authorization-webhook-version":                "string",
+	"bind-address":                                 "ip",
+	"cert-dir":                                     "string",
+	"client-ca-file":                               "string",
+	"cloud-config":                                 "string",
+	"cloud-provider":                               "string",
+	"contention-profiling":                         "",
+	"coordinated-leadership-lease-duration":        "duration",
+	"coordinated-leadership-renew-deadline":        "duration",
+	"coordinated-leadership-retry-period":          "duration",
+	"cors-allowed-origins":                         "strings",
+	"debug-socket-path":                            "string",
+	"default-not-ready-toleration-seconds":         "int",
+	"default-unreachable-toleration-seconds":       "int",
+	"delete-collection-workers":                    "int",
+	"disable-admission-plugins":                    "strings",
+	"disable-http2-serving":                        "",
+	"disabled-metrics":                             "strings",
+	"egress-selector-config-file":                  "string",
+	"emulated-version":                             "strings",
+	"emulation-forward-compatible":                 "",
+	"enable-admission-plugins":                     "strings",
+	"enable-aggregator-routing":                    "",
+	"enable-bootstrap-token-auth":                  "",
+	"enable-garbage-collector":                     "",
+	"enable-logs-handler":                          "",
+	"enable-priority-and-fairness":                 "",
+	"encryption-provider-config":                   "string",
+	"encryption-provider-config-automatic-reload":  "",
+	"endpoint-reconciler-type":                     "string",
+	"etcd-cafile":                                  "string",
+	"etcd-certfile":                                "string",
+	"etcd-compaction-interval":                     "duration",
+	"etcd-count-metric-poll-period":                "duration",
+	"etcd-db-metric-poll-interval":                 "duration",
+	"etcd-healthcheck-timeout":                     "duration",
+	"etcd-keyfile":                                 "string",
+	"etcd-prefix":                                  "string",
+	"etcd-readycheck-timeout":                      "duration",
+	"etcd-servers":                                 "strings",
+	"etcd-servers-overrides":                       "strings",
+	"event-ttl":                                    "duration",
+	"external-hostname":                            "string",
+	"feature-gates":                                "colonSeparatedMultimapStringString",
+	"goaway-chance":                                "float",
+	"http2-max-streams-per-connection":             "int",
+	"identity-lease-duration-seconds":              "int",
+	"identity-lease-renew-interval-seconds":        "int",
+	"kubelet-certificate-authority":                "string",
+	"kubelet-client-certificate":                   "string",
+	"kubelet-client-key":                           "string",
+	"kubelet-preferred-address-types":              "strings",
+	"kubelet-timeout":                              "duration",
+	"kubernetes-service-node-port":                 "int",
+	"lease-reuse-duration-seconds":                 "int",
+	"livez-grace-period":                           "duration",
+	"log-flush-frequency":                          "duration",
+	"log-json-info-buffer-size":                    "quantity",
+	"log-json-split-stream":                        "",
+	"log-text-info-buffer-size":                    "quantity",
+	"log-text-split-stream":                        "",
+	"logging-format":                               "string",
+	"max-connection-bytes-per-sec":                 "int",
+	"max-mutating-requests-inflight":               "int",
+	"max-requests-inflight":                        "int",
+	"min-compatibility-version":                    "strings",
+	"min-request-timeout":                          "int",
+	"oidc-ca-file":                                 "string",
+	"oidc-client-id":                               "string",
+	"oidc-groups-claim":                            "string",
+	"oidc-groups-prefix":                           "string",
+	"oidc-issuer-url":                              "string",
+	"oidc-required-claim":                          "mapStringString",
+	"oidc-signing-algs":                            "strings",
+	"oidc-username-claim":                          "string",
+	"oidc-username-prefix":                         "string",
+	"openshift-config":                             "string",
+	"peer-advertise-ip":                            "string",
+	"peer-advertise-port":                          "string",
+	"peer-ca-file":                                 "string",
+	"permit-address-sharing":                       "",
+	"permit-port-sharing":                          "",
+	"profiling":                                    "",
+	"proxy-client-cert-file":                       "string",
+	"proxy-client-key-file":                        "string",
+	"request-timeout":                              "duration",
+	"requestheader-allowed-names":                  "strings",
+	"requestheader-client-ca-file":                 "string",
+	"requestheader-extra-headers-prefix":           "strings",
+	"requestheader-group-headers":                  "strings",
+	"requestheader-uid-headers":                    "strings",
+	"requestheader-username-headers":               "strings",
+	"runtime-config":                               "mapStringString",
+	"runtime-config-emulation-forward-compatible":  "",
+	"secure-port":                                  "int",
+	"send-retry-after-while-not-ready-once":        "",
+	"service-account-extend-token-expiration":      "",
+	"service-account-issuer":                       "stringArray",
+	"service-account-jwks-uri":                     "string",
+	"service-account-key-file":                     "stringArray",
+	"service-account-lookup":                       "",
+	"service-account-max-token-expiration":         "duration",
+	"service-account-signing-endpoint":             "string",
+	"service-account-signing-key-file":             "string",
+	"service-cluster-ip-range":                     "string",
+	"service-node-port-range":                      "portRange",
+	"show-hidden-metrics-for-version":              "string",
+	"shutdown-delay-duration":                      "duration",
+	"shutdown-send-retry-after":                    "",
+	"shutdown-watch-termination-grace-period":      "duration",
+	"storage-backend":                              "string",
+	"storage-initialization-timeout":               "duration",
+	"storage-media-type":                           "string",
+	"strict-transport-security-directives":         "strings",
+	"tls-cert-file":                                "string",
+	"tls-cipher-suites":                            "strings",
+	"tls-curve-preferences":                        "int32Slice",
+	"tls-min-version":                              "string",
+	"tls-private-key-file":                         "string",
+	"tls-sni-cert-key":                             "namedCertKey",
+	"token-auth-file":                              "string",
+	"tracing-config-file":                          "string",
+	"v":                                            "Level",
+	"vmodule":                                      "pattern=N,...",
+	"watch-cache":                                  "",
+	"watch-cache-sizes":                            "strings",
+}
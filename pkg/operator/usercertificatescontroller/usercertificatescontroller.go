@@ -0,0 +1,118 @@
+package usercertificatescontroller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configv1informers "github.com/openshift/client-go/config/informers/externalversions/config/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	"k8s.io/apimachinery/pkg/api/errors"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+)
+
+const userCertificatesDegradedConditionType = "UserCertificatesDegraded"
+
+// UserCertificatesController validates the named serving certificates and the client CA bundle referenced by
+// apiserver.config.openshift.io/cluster and reports the broken ones in the UserCertificatesDegraded condition.
+// The config observers and the NamedCertificatesController run the same validation and keep the previously
+// propagated material instead of the broken one.
+type UserCertificatesController struct {
+	operatorClient v1helpers.OperatorClient
+
+	secretLister    corev1listers.SecretLister
+	configMapLister corev1listers.ConfigMapLister
+	apiServerLister configv1listers.APIServerLister
+	now             func() time.Time
+}
+
+func NewUserCertificatesController(
+	operatorClient v1helpers.OperatorClient,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	apiServerInformer configv1informers.APIServerInformer,
+	eventRecorder events.Recorder,
+) factory.Controller {
+	c := &UserCertificatesController{
+		operatorClient:  operatorClient,
+		secretLister:    kubeInformersForNamespaces.SecretLister(),
+		configMapLister: kubeInformersForNamespaces.ConfigMapLister(),
+		apiServerLister: apiServerInformer.Lister(),
+		now:             time.Now,
+	}
+
+	return factory.New().WithInformers(
+		operatorClient.Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().Secrets().Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().ConfigMaps().Informer(),
+		apiServerInformer.Informer(),
+	).WithSync(c.sync).ResyncEvery(time.Hour).ToController("UserCertificatesController", eventRecorder.WithComponentSuffix("user-certificates-controller"))
+}
+
+func (c *UserCertificatesController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	problems, err := c.validate()
+	if err != nil {
+		return err
+	}
+
+	condition := operatorv1.OperatorCondition{
+		Type:   userCertificatesDegradedConditionType,
+		Status: operatorv1.ConditionFalse,
+		Reason: "AsExpected",
+	}
+	if len(problems) > 0 {
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "InvalidCertificates"
+		condition.Message = strings.Join(problems, "\n")
+	}
+	_, _, err = v1helpers.UpdateStatus(ctx, c.operatorClient, v1helpers.UpdateConditionFn(condition))
+	return err
+}
+
+// validate returns a message for every referenced secret or config map that fails validation. Missing ones are
+// left to the config observers, which report them already.
+func (c *UserCertificatesController) validate() ([]string, error) {
+	apiServer, err := c.apiServerLister.Get("cluster")
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := c.now()
+	var problems []string
+	for secretName, names := range ServingCertificateNames(apiServer) {
+		secret, err := c.secretLister.Secrets(operatorclient.GlobalUserSpecifiedConfigNamespace).Get(secretName)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := ValidateServingCertificate(secret, names, now); err != nil {
+			problems = append(problems, fmt.Sprintf("secret %s/%s: %v", operatorclient.GlobalUserSpecifiedConfigNamespace, secretName, err))
+		}
+	}
+	sort.Strings(problems)
+
+	if configMapName := apiServer.Spec.ClientCA.Name; len(configMapName) > 0 {
+		configMap, err := c.configMapLister.ConfigMaps(operatorclient.GlobalUserSpecifiedConfigNamespace).Get(configMapName)
+		switch {
+		case errors.IsNotFound(err):
+		case err != nil:
+			return nil, err
+		default:
+			if err := ValidateClientCABundle(configMap, now); err != nil {
+				problems = append(problems, fmt.Sprintf("configmap %s/%s: %v", operatorclient.GlobalUserSpecifiedConfigNamespace, configMapName, err))
+			}
+		}
+	}
+	return problems, nil
+}
@@ -0,0 +1,135 @@
+package usercertificatescontroller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/clock"
+
+	"github.com/openshift/cluster-kube-apiserver-operator/pkg/operator/operatorclient"
+)
+
+func TestUserCertificatesController(t *testing.T) {
+	now := time.Now()
+	ca := newCA(t, "ca", now.Add(-time.Minute))
+	certPEM, keyPEM := newServingCertKeyPair(t, ca, "api.example.com")
+	caPEM, _, err := ca.Config.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	namedCertificate := func(secretName string, names ...string) configv1.APIServerNamedServingCert {
+		return configv1.APIServerNamedServingCert{Names: names, ServingCertificate: configv1.SecretNameReference{Name: secretName}}
+	}
+	userSecret := func(name string, certPEM, keyPEM []byte) *corev1.Secret {
+		secret := servingSecret(certPEM, keyPEM)
+		secret.ObjectMeta = metav1.ObjectMeta{Namespace: operatorclient.GlobalUserSpecifiedConfigNamespace, Name: name}
+		return secret
+	}
+	userConfigMap := func(name, bundlePEM string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.GlobalUserSpecifiedConfigNamespace, Name: name},
+			Data:       map[string]string{ClientCABundleKey: bundlePEM},
+		}
+	}
+
+	testCases := []struct {
+		name            string
+		spec            configv1.APIServerSpec
+		objects         []interface{}
+		expectedStatus  operatorv1.ConditionStatus
+		expectedMessage string
+	}{
+		{
+			name: "valid certificates",
+			spec: configv1.APIServerSpec{
+				ServingCerts: configv1.APIServerServingCerts{NamedCertificates: []configv1.APIServerNamedServingCert{namedCertificate("valid", "api.example.com")}},
+				ClientCA:     configv1.ConfigMapNameReference{Name: "client-ca"},
+			},
+			objects:        []interface{}{userSecret("valid", certPEM, keyPEM), userConfigMap("client-ca", string(caPEM))},
+			expectedStatus: operatorv1.ConditionFalse,
+		},
+		{
+			name: "missing certificates are left to the config observer",
+			spec: configv1.APIServerSpec{
+				ServingCerts: configv1.APIServerServingCerts{NamedCertificates: []configv1.APIServerNamedServingCert{namedCertificate("missing")}},
+				ClientCA:     configv1.ConfigMapNameReference{Name: "missing"},
+			},
+			expectedStatus: operatorv1.ConditionFalse,
+		},
+		{
+			name: "invalid certificates",
+			spec: configv1.APIServerSpec{
+				ServingCerts: configv1.APIServerServingCerts{NamedCertificates: []configv1.APIServerNamedServingCert{
+					namedCertificate("valid", "api.example.com"),
+					namedCertificate("uncovered", "api.example.com"),
+					namedCertificate("uncovered", "console.example.com"),
+					namedCertificate("no-key"),
+				}},
+				ClientCA: configv1.ConfigMapNameReference{Name: "client-ca"},
+			},
+			objects: []interface{}{
+				userSecret("valid", certPEM, keyPEM),
+				userSecret("uncovered", certPEM, keyPEM),
+				userSecret("no-key", certPEM, nil),
+				userConfigMap("client-ca", "FOO"),
+			},
+			expectedStatus: operatorv1.ConditionTrue,
+			expectedMessage: "secret openshift-config/no-key: missing tls.key\n" +
+				`secret openshift-config/uncovered: certificate "api.example.com" does not cover the name "console.example.com"` + "\n" +
+				"configmap openshift-config/client-ca: ca-bundle.crt: data does not contain any valid RSA or ECDSA certificates",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if err := indexer.Add(&configv1.APIServer{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}, Spec: tc.spec}); err != nil {
+				t.Fatal(err)
+			}
+			for _, obj := range tc.objects {
+				if err := indexer.Add(obj); err != nil {
+					t.Fatal(err)
+				}
+			}
+			operatorClient := v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{}, nil)
+
+			c := &UserCertificatesController{
+				operatorClient:  operatorClient,
+				secretLister:    corev1listers.NewSecretLister(indexer),
+				configMapLister: corev1listers.NewConfigMapLister(indexer),
+				apiServerLister: configlistersv1.NewAPIServerLister(indexer),
+				now:             func() time.Time { return now },
+			}
+			if err := c.sync(context.Background(), factory.NewSyncContext("test", events.NewInMemoryRecorder(t.Name(), clock.RealClock{}))); err != nil {
+				t.Fatal(err)
+			}
+
+			_, status, _, err := operatorClient.GetOperatorState()
+			if err != nil {
+				t.Fatal(err)
+			}
+			condition := v1helpers.FindOperatorCondition(status.Conditions, userCertificatesDegradedConditionType)
+			if condition == nil {
+				t.Fatalf("missing %s condition", userCertificatesDegradedConditionType)
+			}
+			if condition.Status != tc.expectedStatus {
+				t.Errorf("expected status %s, got %s", tc.expectedStatus, condition.Status)
+			}
+			if condition.Message != tc.expectedMessage {
+				t.Errorf("expected message:\n%s\ngot:\n%s", tc.expectedMessage, condition.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package usercertificatescontroller
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/util/cert"
+)
+
+// ClientCABundleKey is the key of the user provided client CA bundle in its config map.
+const ClientCABundleKey = "ca-bundle.crt"
+
+// ValidateServingCertificate checks a user provided serving certificate secret before it is handed to the
+// kube-apiserver: the certificate chain in tls.crt must start with the certificate matching the private key in
+// tls.key, every certificate must be issued by the one following it, all of them must be valid now and the
+// leaf certificate must cover the given names.
+func ValidateServingCertificate(secret *corev1.Secret, names []string, now time.Time) error {
+	certPEM, keyPEM := secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]
+	if len(certPEM) == 0 {
+		return fmt.Errorf("missing %s", corev1.TLSCertKey)
+	}
+	if len(keyPEM) == 0 {
+		return fmt.Errorf("missing %s", corev1.TLSPrivateKeyKey)
+	}
+	certs, err := cert.ParseCertsPEM(certPEM)
+	if err != nil {
+		return fmt.Errorf("%s: %w", corev1.TLSCertKey, err)
+	}
+	if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		return fmt.Errorf("%s does not match the certificate in %s: %w", corev1.TLSPrivateKeyKey, corev1.TLSCertKey, err)
+	}
+
+	var errs []error
+	for i := 0; i < len(certs)-1; i++ {
+		if err := certs[i].CheckSignatureFrom(certs[i+1]); err != nil {
+			errs = append(errs, fmt.Errorf("certificate %q is not issued by the following certificate %q in %s", certs[i].Subject.CommonName, certs[i+1].Subject.CommonName, corev1.TLSCertKey))
+		}
+	}
+	errs = append(errs, validateValidityPeriods(certs, now)...)
+	for _, name := range names {
+		if err := certs[0].VerifyHostname(name); err != nil {
+			errs = append(errs, fmt.Errorf("certificate %q does not cover the name %q", certs[0].Subject.CommonName, name))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// ValidateClientCABundle checks a user provided client CA bundle config map before it is handed to the
+// kube-apiserver: it must hold at least one certificate and all of them must be valid now.
+func ValidateClientCABundle(configMap *corev1.ConfigMap, now time.Time) error {
+	bundlePEM := configMap.Data[ClientCABundleKey]
+	if len(bundlePEM) == 0 {
+		return fmt.Errorf("missing %s", ClientCABundleKey)
+	}
+	certs, err := cert.ParseCertsPEM([]byte(bundlePEM))
+	if err != nil {
+		return fmt.Errorf("%s: %w", ClientCABundleKey, err)
+	}
+	return utilerrors.NewAggregate(validateValidityPeriods(certs, now))
+}
+
+func validateValidityPeriods(certs []*x509.Certificate, now time.Time) []error {
+	var errs []error
+	for _, c := range certs {
+		switch {
+		case now.After(c.NotAfter):
+			errs = append(errs, fmt.Errorf("certificate %q expired at %s", c.Subject.CommonName, c.NotAfter.UTC().Format(time.RFC3339)))
+		case now.Before(c.NotBefore):
+			errs = append(errs, fmt.Errorf("certificate %q is not valid before %s", c.Subject.CommonName, c.NotBefore.UTC().Format(time.RFC3339)))
+		}
+	}
+	return errs
+}
+
+// ServingCertificateNames returns the names to validate for every secret referenced by
+// spec.servingCerts.namedCertificates. A secret referenced by several entries must cover the names of all of them.
+func ServingCertificateNames(apiServer *configv1.APIServer) map[string][]string {
+	namesBySecret := map[string][]string{}
+	for _, namedCertificate := range apiServer.Spec.ServingCerts.NamedCertificates {
+		secretName := namedCertificate.ServingCertificate.Name
+		if len(secretName) == 0 {
+			continue
+		}
+		namesBySecret[secretName] = append(namesBySecret[secretName], namedCertificate.Names...)
+	}
+	return namesBySecret
+}
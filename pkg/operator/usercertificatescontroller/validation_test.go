@@ -0,0 +1,176 @@
+package usercertificatescontroller
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/library-go/pkg/crypto"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// newCA returns a self-signed CA valid for an hour from notBefore.
+func newCA(t *testing.T, name string, notBefore time.Time) *crypto.CA {
+	config, err := crypto.UnsafeMakeSelfSignedCAConfigForDurationAtTime(name, func() time.Time { return notBefore }, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &crypto.CA{Config: config, SerialGenerator: &crypto.RandomSerialGenerator{}}
+}
+
+// newServingCertKeyPair returns the PEM of a serving certificate chain, including its CA, and its private key.
+func newServingCertKeyPair(t *testing.T, ca *crypto.CA, hostnames ...string) ([]byte, []byte) {
+	config, err := ca.MakeServerCertForDuration(sets.New(hostnames...), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM, keyPEM, err := config.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certPEM, keyPEM
+}
+
+func servingSecret(certPEM, keyPEM []byte) *corev1.Secret {
+	return &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: certPEM, corev1.TLSPrivateKeyKey: keyPEM}}
+}
+
+func TestValidateServingCertificate(t *testing.T) {
+	now := time.Now()
+	ca := newCA(t, "ca", now.Add(-time.Minute))
+	certPEM, keyPEM := newServingCertKeyPair(t, ca, "api.example.com", "*.apps.example.com")
+	_, otherKeyPEM := newServingCertKeyPair(t, ca, "api.example.com")
+	otherCAPEM, _, err := newCA(t, "other-ca", now.Add(-time.Minute)).Config.GetPEMBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafPEM := certPEM[:strings.Index(string(certPEM), "-----END CERTIFICATE-----")+len("-----END CERTIFICATE-----\n")]
+	expiredCertPEM, expiredKeyPEM := newServingCertKeyPair(t, newCA(t, "expired-ca", now.Add(-2*time.Hour)), "api.example.com")
+
+	testCases := []struct {
+		name        string
+		secret      *corev1.Secret
+		names       []string
+		expectedErr string
+	}{
+		{
+			name:   "valid",
+			secret: servingSecret(certPEM, keyPEM),
+			names:  []string{"api.example.com", "console.apps.example.com", "*.apps.example.com"},
+		},
+		{
+			name:   "valid without names",
+			secret: servingSecret(certPEM, keyPEM),
+		},
+		{
+			name:   "leaf only",
+			secret: servingSecret(leafPEM, keyPEM),
+		},
+		{
+			name:        "missing key",
+			secret:      servingSecret(certPEM, nil),
+			expectedErr: "missing tls.key",
+		},
+		{
+			name:        "garbage certificate",
+			secret:      servingSecret([]byte("FOO"), keyPEM),
+			expectedErr: "tls.crt: data does not contain any valid RSA or ECDSA certificates",
+		},
+		{
+			name:        "key does not match",
+			secret:      servingSecret(certPEM, otherKeyPEM),
+			expectedErr: "tls.key does not match the certificate in tls.crt: tls: private key does not match public key",
+		},
+		{
+			name:        "names not covered",
+			secret:      servingSecret(certPEM, keyPEM),
+			names:       []string{"api.example.com", "api.other.com", "a.b.apps.example.com"},
+			expectedErr: `[certificate "*.apps.example.com" does not cover the name "api.other.com", certificate "*.apps.example.com" does not cover the name "a.b.apps.example.com"]`,
+		},
+		{
+			name:        "broken chain",
+			secret:      servingSecret(append(append([]byte{}, leafPEM...), otherCAPEM...), keyPEM),
+			expectedErr: `certificate "*.apps.example.com" is not issued by the following certificate "other-ca" in tls.crt`,
+		},
+		{
+			name:        "expired",
+			secret:      servingSecret(expiredCertPEM, expiredKeyPEM),
+			expectedErr: `certificate "expired-ca" expired at`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateServingCertificate(tc.secret, tc.names, now)
+			switch {
+			case len(tc.expectedErr) == 0 && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case len(tc.expectedErr) > 0 && err == nil:
+				t.Fatalf("expected error %q", tc.expectedErr)
+			case len(tc.expectedErr) > 0 && !strings.Contains(err.Error(), tc.expectedErr):
+				t.Fatalf("expected error %q, got %q", tc.expectedErr, err.Error())
+			}
+		})
+	}
+}
+
+func TestValidateClientCABundle(t *testing.T) {
+	now := time.Now()
+	bundle := func(cas ...*crypto.CA) *corev1.ConfigMap {
+		var bundlePEM []byte
+		for _, ca := range cas {
+			certPEM, _, err := ca.Config.GetPEMBytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			bundlePEM = append(bundlePEM, certPEM...)
+		}
+		return &corev1.ConfigMap{Data: map[string]string{ClientCABundleKey: string(bundlePEM)}}
+	}
+
+	testCases := []struct {
+		name        string
+		configMap   *corev1.ConfigMap
+		expectedErr string
+	}{
+		{
+			name:      "valid",
+			configMap: bundle(newCA(t, "one", now.Add(-time.Minute)), newCA(t, "two", now.Add(-time.Minute))),
+		},
+		{
+			name:        "missing bundle",
+			configMap:   &corev1.ConfigMap{},
+			expectedErr: "missing ca-bundle.crt",
+		},
+		{
+			name:        "garbage bundle",
+			configMap:   &corev1.ConfigMap{Data: map[string]string{ClientCABundleKey: "FOO"}},
+			expectedErr: "ca-bundle.crt: data does not contain any valid RSA or ECDSA certificates",
+		},
+		{
+			name:        "expired CA",
+			configMap:   bundle(newCA(t, "one", now.Add(-time.Minute)), newCA(t, "expired", now.Add(-2*time.Hour))),
+			expectedErr: `certificate "expired" expired at`,
+		},
+		{
+			name:        "CA not valid yet",
+			configMap:   bundle(newCA(t, "future", now.Add(time.Hour))),
+			expectedErr: `certificate "future" is not valid before`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateClientCABundle(tc.configMap, now)
+			switch {
+			case len(tc.expectedErr) == 0 && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case len(tc.expectedErr) > 0 && err == nil:
+				t.Fatalf("expected error %q", tc.expectedErr)
+			case len(tc.expectedErr) > 0 && !strings.Contains(err.Error(), tc.expectedErr):
+				t.Fatalf("expected error %q, got %q", tc.expectedErr, err.Error())
+			}
+		})
+	}
+}